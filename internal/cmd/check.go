@@ -3,13 +3,21 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	portpkg "github.com/paveg/portguard/internal/port"
+	"github.com/paveg/portguard/internal/process"
 	"github.com/spf13/cobra"
 )
 
 var checkCmd = &cobra.Command{
-	Use:   "check",
+	Use:   "check [port|range]",
 	Short: "Quick status check (AI-friendly)",
 	Long: `Perform a quick status check optimized for AI tools and automated scripts.
 Returns concise information about process and port status.
@@ -17,11 +25,22 @@ Returns concise information about process and port status.
 This command is designed to be easily parsable by AI development tools
 and provides the most commonly needed information in a simple format.
 
+With a port or range argument, check reports every conflict in it: whether the port
+is in use, the owning PID and process name, whether portguard manages it, and whether
+an unmanaged process looks like a development server that could be imported.
+
 Examples:
   portguard check --port 3000
   portguard check --json
-  portguard check --available --start 3000`,
-	Run: func(_ *cobra.Command, _ []string) {
+  portguard check --available --start 3000
+  portguard check 3000
+  portguard check 3000-3010 --json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		if len(args) == 1 {
+			return runPortCheckReport(args[0])
+		}
+
 		runner := NewCommandRunner(jsonOutput, false)
 
 		result := map[string]interface{}{
@@ -49,7 +68,7 @@ Examples:
 		if runner.OutputHandler.JSONOutput {
 			if err := runner.OutputHandler.PrintJSON(result); err != nil {
 				runner.OutputHandler.PrintError("Failed to marshal JSON", err)
-				return
+				return nil
 			}
 		} else {
 			// Human-readable output
@@ -69,6 +88,7 @@ Examples:
 			}
 			fmt.Printf("  Managed processes: %d\n", result["managed_processes"])
 		}
+		return nil
 	},
 }
 
@@ -94,3 +114,190 @@ func findAvailablePort(start int) int {
 	// TODO: Use actual port scanner
 	return start
 }
+
+// portCheckEntry describes who holds a single port
+type portCheckEntry struct {
+	Port        int    `json:"port"`
+	InUse       bool   `json:"in_use"`
+	BindAddress string `json:"bind_address,omitempty"`
+	PID         int    `json:"pid,omitempty"`
+	ProcessName string `json:"process_name,omitempty"`
+	Managed     bool   `json:"managed"`
+	ManagedID   string `json:"managed_id,omitempty"`
+	Adoptable   bool   `json:"adoptable"`
+	Reason      string `json:"reason,omitempty"` // Adoption verdict for unmanaged processes
+}
+
+// portCheckReport is the result of checking a port or range for conflicts
+type portCheckReport struct {
+	Start     int              `json:"start"`
+	End       int              `json:"end"`
+	InUse     int              `json:"in_use"`
+	Managed   int              `json:"managed"`
+	Adoptable int              `json:"adoptable"`
+	Ports     []portCheckEntry `json:"ports"`
+	CheckedAt time.Time        `json:"checked_at"`
+}
+
+// runPortCheckReport checks a port or range and prints the conflict report
+func runPortCheckReport(target string) error {
+	cfg, err := currentConfig()
+	if err != nil {
+		return err
+	}
+	scanner, err := newPortScanner(cfg, 5*time.Second)
+	if err != nil {
+		return err
+	}
+	start, end, err := parsePortTarget(scanner, target)
+	if err != nil {
+		return err
+	}
+
+	pm, err := initializeProcessManagerWithScanner(cfg, scanner)
+	if err != nil {
+		return fmt.Errorf("failed to initialize process manager: %w", err)
+	}
+	adopter := process.NewProcessAdopter(effectiveScanTimeout(cfg, 5*time.Second))
+
+	report, err := buildPortCheckReport(scanner, pm.GetProcessByPort, adopter.GetProcessInfo, start, end)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal check report: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	printPortCheckReport(os.Stdout, report)
+	return nil
+}
+
+// parsePortTarget accepts a single port or a "start-end" range
+func parsePortTarget(scanner *portpkg.Scanner, target string) (int, int, error) {
+	if strings.Contains(target, "-") {
+		start, end, err := scanner.ParsePortRange(target)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid port range %s: %w", target, err)
+		}
+		return start, end, nil
+	}
+
+	portNum, err := strconv.Atoi(target)
+	if err != nil || portNum < 1 || portNum > 65535 {
+		return 0, 0, fmt.Errorf("%w: %s", portpkg.ErrInvalidPortRange, target)
+	}
+	return portNum, portNum, nil
+}
+
+// buildPortCheckReport combines the port scan with managed process and adoption information.
+// A single port is always reported; for ranges only ports in use or managed by portguard are listed.
+func buildPortCheckReport(
+	scanner process.PortScanner,
+	managedByPort func(int) (*process.ManagedProcess, bool),
+	describe func(pid int) (*process.AdoptionInfo, error),
+	start, end int,
+) (*portCheckReport, error) {
+	inUse, err := scanner.ScanRange(start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan ports %d-%d: %w", start, end, err)
+	}
+	portInfos := make(map[int]portpkg.PortInfo, len(inUse))
+	for _, info := range inUse {
+		portInfos[info.Port] = info
+	}
+
+	report := &portCheckReport{Start: start, End: end, Ports: []portCheckEntry{}, CheckedAt: time.Now()}
+	for portNum := start; portNum <= end; portNum++ {
+		info, used := portInfos[portNum]
+		managed, isManaged := managedByPort(portNum)
+		if !used && !isManaged && start != end {
+			continue
+		}
+
+		entry := portCheckEntry{Port: portNum, InUse: used, BindAddress: info.BindAddress}
+		if used && info.PID > 0 {
+			entry.PID = info.PID
+			entry.ProcessName = info.ProcessName
+		}
+
+		switch {
+		case isManaged:
+			entry.Managed = true
+			entry.ManagedID = managed.ID
+			if entry.PID == 0 {
+				entry.PID = managed.PID
+			}
+		case entry.PID > 0:
+			if adoption, err := describe(entry.PID); err != nil {
+				entry.Reason = err.Error()
+			} else {
+				entry.Adoptable = adoption.IsSuitable
+				entry.Reason = adoption.Reason
+				if entry.ProcessName == "" || entry.ProcessName == unknownProcessName {
+					entry.ProcessName = adoption.ProcessName
+				}
+			}
+		}
+
+		if entry.InUse {
+			report.InUse++
+		}
+		if entry.Managed {
+			report.Managed++
+		}
+		if entry.Adoptable {
+			report.Adoptable++
+		}
+		report.Ports = append(report.Ports, entry)
+	}
+
+	return report, nil
+}
+
+// printPortCheckReport writes the report as a table
+func printPortCheckReport(w io.Writer, report *portCheckReport) {
+	if len(report.Ports) == 0 {
+		fmt.Fprintf(w, "No ports in use in range %d-%d\n", report.Start, report.End)
+		return
+	}
+
+	fmt.Fprintf(w, "%-6s %-10s %-8s %-15s %-s\n", "PORT", "STATUS", "PID", "PROCESS", "PORTGUARD")
+	for _, entry := range report.Ports {
+		status := "available"
+		if entry.InUse {
+			status = "in use"
+		}
+
+		pidStr := "-"
+		if entry.PID > 0 {
+			pidStr = strconv.Itoa(entry.PID)
+		}
+
+		processName := entry.ProcessName
+		if processName == "" {
+			processName = "-"
+		}
+
+		var portguard string
+		switch {
+		case entry.Managed:
+			portguard = "managed (" + entry.ManagedID + ")"
+		case entry.Adoptable:
+			portguard = "adoptable"
+		case entry.InUse:
+			portguard = "not managed"
+		default:
+			portguard = "-"
+		}
+
+		fmt.Fprintf(w, "%-6d %-10s %-8s %-15s %-s\n", entry.Port, status, pidStr, processName, portguard)
+	}
+
+	fmt.Fprintf(w, "\n%d in use, %d managed, %d adoptable\n", report.InUse, report.Managed, report.Adoptable)
+}
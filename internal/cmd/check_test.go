@@ -5,7 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
+	portpkg "github.com/paveg/portguard/internal/port"
+	"github.com/paveg/portguard/internal/process"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -376,3 +379,104 @@ func TestFindAvailablePort(t *testing.T) {
 		assert.Equal(t, 9000, available) // Currently returns the start port
 	})
 }
+
+func TestParsePortTarget(t *testing.T) {
+	scanner := portpkg.NewScanner(time.Second)
+
+	tests := []struct {
+		target    string
+		wantStart int
+		wantEnd   int
+		wantErr   bool
+	}{
+		{target: "3000", wantStart: 3000, wantEnd: 3000},
+		{target: "3000-3010", wantStart: 3000, wantEnd: 3010},
+		{target: "3010-3000", wantErr: true},
+		{target: "0", wantErr: true},
+		{target: "70000", wantErr: true},
+		{target: "web", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			start, end, err := parsePortTarget(scanner, tt.target)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStart, start)
+			assert.Equal(t, tt.wantEnd, end)
+		})
+	}
+}
+
+func TestBuildPortCheckReport(t *testing.T) {
+	scanner := &mockPortScanner{}
+	scanner.On("ScanRange", 3000, 3005).Return([]portpkg.PortInfo{
+		{Port: 3000, PID: 100, ProcessName: "node", BindAddress: "0.0.0.0"},
+		{Port: 3001, PID: 200, ProcessName: "unknown"},
+		{Port: 3002, PID: 300, ProcessName: "postgres"},
+		{Port: 3003, PID: -1},
+	}, nil)
+
+	managed := map[int]*process.ManagedProcess{
+		3000: {ID: "web", PID: 100},
+		3004: {ID: "api", PID: 400}, // Managed but no longer listening
+	}
+	managedByPort := func(portNum int) (*process.ManagedProcess, bool) {
+		proc, ok := managed[portNum]
+		return proc, ok
+	}
+	describe := func(pid int) (*process.AdoptionInfo, error) {
+		switch pid {
+		case 200:
+			return &process.AdoptionInfo{PID: pid, ProcessName: "vite", IsSuitable: true}, nil
+		case 300:
+			return &process.AdoptionInfo{PID: pid, ProcessName: "postgres", Reason: "not a development server"}, nil
+		}
+		return nil, fmt.Errorf("unexpected PID %d", pid)
+	}
+
+	report, err := buildPortCheckReport(scanner, managedByPort, describe, 3000, 3005)
+	require.NoError(t, err)
+
+	assert.Equal(t, []portCheckEntry{
+		{Port: 3000, InUse: true, BindAddress: "0.0.0.0", PID: 100, ProcessName: "node", Managed: true, ManagedID: "web"},
+		{Port: 3001, InUse: true, PID: 200, ProcessName: "vite", Adoptable: true},
+		{Port: 3002, InUse: true, PID: 300, ProcessName: "postgres", Reason: "not a development server"},
+		{Port: 3003, InUse: true},
+		{Port: 3004, PID: 400, Managed: true, ManagedID: "api"},
+	}, report.Ports)
+	assert.Equal(t, 4, report.InUse)
+	assert.Equal(t, 2, report.Managed)
+	assert.Equal(t, 1, report.Adoptable)
+
+	var buf bytes.Buffer
+	printPortCheckReport(&buf, report)
+	output := buf.String()
+	assert.Contains(t, output, "managed (web)")
+	assert.Contains(t, output, "adoptable")
+	assert.Contains(t, output, "4 in use, 2 managed, 1 adoptable")
+	assert.NotContains(t, output, "3005")
+
+	data, err := json.Marshal(report)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"adoptable":true`)
+}
+
+func TestBuildPortCheckReport_SinglePortAvailable(t *testing.T) {
+	scanner := &mockPortScanner{}
+	scanner.On("ScanRange", 8080, 8080).Return([]portpkg.PortInfo{}, nil)
+
+	noneManaged := func(int) (*process.ManagedProcess, bool) { return nil, false }
+	report, err := buildPortCheckReport(scanner, noneManaged, nil, 8080, 8080)
+	require.NoError(t, err)
+
+	require.Len(t, report.Ports, 1)
+	assert.Equal(t, portCheckEntry{Port: 8080}, report.Ports[0])
+
+	var buf bytes.Buffer
+	printPortCheckReport(&buf, report)
+	assert.Contains(t, buf.String(), "available")
+}
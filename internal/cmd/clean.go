@@ -7,15 +7,20 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// includeProtected makes clean remove protected processes as well
+var includeProtected bool
+
 var cleanCmd = &cobra.Command{
 	Use:   "clean",
 	Short: "Clean up all managed processes",
 	Long: `Stop all managed processes and clean up resources.
 Use with caution as this will terminate all processes managed by portguard.
+Protected processes (see "portguard protect") are kept unless --include-protected is given.
 
 Examples:
   portguard clean --dry-run
-  portguard clean --force`,
+  portguard clean --force
+  portguard clean --force --include-protected`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Initialize process manager
 		pm, err := initializeProcessManager()
@@ -23,25 +28,11 @@ Examples:
 			return fmt.Errorf("failed to initialize process manager: %w", err)
 		}
 
+		options := process.CleanupOptions{Force: force, IncludeProtected: includeProtected}
+
 		if dryRun {
 			fmt.Println("Dry run mode - showing what would be cleaned:")
-
-			// Show what would be cleaned
-			options := process.ProcessListOptions{
-				IncludeStopped: true,
-			}
-
-			processes := pm.ListProcesses(options)
-			stoppedCount := 0
-
-			for _, proc := range processes {
-				if proc.Status == "stopped" || proc.Status == "failed" {
-					stoppedCount++
-					fmt.Printf("  - Process %s (%s): %s\n", proc.ID[:8], proc.Status, proc.Command)
-				}
-			}
-
-			fmt.Printf("\nWould clean up %d stopped/failed process(es)\n", stoppedCount)
+			printCleanupPlan(pm.PlanCleanup(options))
 			return nil
 		}
 
@@ -52,7 +43,7 @@ Examples:
 		}
 
 		// Perform cleanup
-		if err := pm.CleanupProcesses(force); err != nil {
+		if err := pm.CleanupProcesses(options); err != nil {
 			return fmt.Errorf("cleanup failed: %w", err)
 		}
 
@@ -61,9 +52,28 @@ Examples:
 	},
 }
 
+// printCleanupPlan lists the processes and resources a cleanup would remove
+func printCleanupPlan(plan []process.CleanupPlanEntry) {
+	for _, entry := range plan {
+		fmt.Printf("  - Process %s (%s): %s\n", entry.ID, entry.Status, entry.Command)
+		if entry.Terminate {
+			fmt.Printf("      terminate PID %d\n", entry.PID)
+		}
+		if entry.LogFile != "" {
+			fmt.Printf("      remove log file %s\n", entry.LogFile)
+		}
+		if entry.WorkingDir != "" {
+			fmt.Printf("      remove working directory %s\n", entry.WorkingDir)
+		}
+	}
+
+	fmt.Printf("\nWould clean up %d process(es)\n", len(plan))
+}
+
 func init() {
 	rootCmd.AddCommand(cleanCmd)
 
 	cleanCmd.Flags().BoolVar(&dryRun, "dry-run", false, "show what would be cleaned without actually doing it")
 	cleanCmd.Flags().BoolVarP(&force, "force", "f", false, "force cleanup without confirmation")
+	cleanCmd.Flags().BoolVar(&includeProtected, "include-protected", false, "also clean up protected processes")
 }
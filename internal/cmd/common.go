@@ -4,9 +4,19 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
 
+	"github.com/paveg/portguard/internal/config"
+	"github.com/paveg/portguard/internal/lock"
+	"github.com/paveg/portguard/internal/logging"
+	portpkg "github.com/paveg/portguard/internal/port"
+	"github.com/paveg/portguard/internal/process"
+	"github.com/paveg/portguard/internal/state"
 	"github.com/spf13/cobra"
 )
 
@@ -27,8 +37,240 @@ var (
 	background  bool
 	verbose     bool
 	cfgFile     string
+	noPersist   bool
+	lockTimeout time.Duration // --lock-timeout; zero defers to config and the command default
+	scanTimeout time.Duration // --scan-timeout; zero defers to config and the command default
 )
 
+// The configuration of this invocation, loaded once by the root command before any subcommand
+// runs. The error is kept so commands that need the configuration report it, while commands
+// such as config validate still run on a broken file.
+var (
+	invocationConfig    *config.Config
+	invocationConfigErr error
+)
+
+// loadInvocationConfig loads the configuration for this invocation
+func loadInvocationConfig() {
+	invocationConfig, invocationConfigErr = config.Load()
+}
+
+// currentConfig returns the configuration loaded for this invocation. Commands run without the
+// root command, as in tests, load it on each call.
+func currentConfig() (*config.Config, error) {
+	cfg, err := invocationConfig, invocationConfigErr
+	if cfg == nil && err == nil {
+		cfg, err = config.Load()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+// resolveTimeout applies the timeout precedence: flag, then config, then the command default.
+// Zero flag and config values are unset.
+func resolveTimeout(flagValue, configValue, fallback time.Duration) time.Duration {
+	switch {
+	case flagValue > 0:
+		return flagValue
+	case configValue > 0:
+		return configValue
+	default:
+		return fallback
+	}
+}
+
+// effectiveLockTimeout returns how long to wait for the state lock, given the command default
+func effectiveLockTimeout(cfg *config.Config, fallback time.Duration) time.Duration {
+	var configured time.Duration
+	if cfg != nil && cfg.Default != nil {
+		configured = cfg.Default.LockTimeout
+	}
+	return resolveTimeout(lockTimeout, configured, fallback)
+}
+
+// effectiveScanTimeout returns the port scan timeout, given the command default
+func effectiveScanTimeout(cfg *config.Config, fallback time.Duration) time.Duration {
+	var configured time.Duration
+	if cfg != nil && cfg.Default != nil {
+		configured = cfg.Default.ScanTimeout
+	}
+	return resolveTimeout(scanTimeout, configured, fallback)
+}
+
+// newConfiguredLogger builds a stderr logger from the configured log level and format.
+// The --verbose flag raises the level to debug; invalid settings fall back to info-level text.
+func newConfiguredLogger(cfg *config.Config) *slog.Logger {
+	level, format := "info", logging.FormatText
+	if cfg != nil && cfg.Default != nil {
+		level, format = cfg.Default.LogLevel, cfg.Default.LogFormat
+	}
+	if verbose {
+		level = "debug"
+	}
+
+	logger, err := logging.New(os.Stderr, level, format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v, using info-level text logs\n", err)
+		return slog.New(slog.NewTextHandler(os.Stderr, nil))
+	}
+	return logger
+}
+
+// newPortScanner creates a port scanner using the configured discovery backend, common ports,
+// recommended ports, scan rate limit and scan timeout, with timeout as the command default.
+// An invalid backend is an error, as for the lock backend.
+func newPortScanner(cfg *config.Config, timeout time.Duration) (*portpkg.Scanner, error) {
+	scanner := portpkg.NewScanner(effectiveScanTimeout(cfg, timeout))
+	if cfg != nil && cfg.Default != nil {
+		backend, err := portpkg.ParseDiscoveryBackend(cfg.Default.DiscoveryBackend)
+		if err != nil {
+			return nil, fmt.Errorf("invalid discovery backend: %w", err)
+		}
+		if len(cfg.Default.CommonPorts) > 0 || cfg.Default.ReplaceCommonPorts {
+			scanner.SetCommonPorts(cfg.Default.CommonPorts, cfg.Default.ReplaceCommonPorts)
+		}
+		scanner.SetRateLimit(cfg.Default.ScanRateLimit)
+		if len(cfg.Default.RecommendedPorts) > 0 {
+			scanner.SetRecommendedPorts(cfg.Default.RecommendedPorts)
+		}
+		if backend == portpkg.DiscoveryNative && !portpkg.NativeDiscoveryAvailable() {
+			fmt.Fprintf(os.Stderr, "Warning: native discovery reads /proc and is only available on Linux, using shell discovery on %s\n",
+				runtime.GOOS)
+		}
+		scanner.SetDiscoveryBackend(backend)
+	}
+	return scanner, nil
+}
+
+// newStateComponents opens the state store and lock in ~/.portguard. With --no-persist the
+// existing state is only read, so duplicates are still detected, and changes stay in memory.
+func newStateComponents(cfg *config.Config) (process.StateStore, process.LockManager, error) {
+	if noPersist {
+		return newMemoryStateComponents()
+	}
+
+	portguardDir, err := getPortguardDir()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get portguard directory: %w", err)
+	}
+
+	stateStore, err := state.NewJSONStore(filepath.Join(portguardDir, "state.json"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create state store: %w", err)
+	}
+	lockManager, err := newLockManager(cfg, portguardDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	return stateStore, lockManager, nil
+}
+
+// newLockManager creates the lock guarding the state in portguardDir using the configured backend
+func newLockManager(cfg *config.Config, portguardDir string) (lock.Locker, error) {
+	backend, err := lockBackend(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return lock.New(backend, lock.Path(portguardDir), effectiveLockTimeout(cfg, 5*time.Second)), nil
+}
+
+// lockBackend returns the configured lock backend. An invalid backend is an error rather than
+// a silent switch to the default one.
+func lockBackend(cfg *config.Config) (lock.Backend, error) {
+	if cfg == nil || cfg.Default == nil {
+		return lock.BackendFile, nil
+	}
+	backend, err := lock.ParseBackend(cfg.Default.LockBackend)
+	if err != nil {
+		return "", fmt.Errorf("invalid lock backend: %w", err)
+	}
+	return backend, nil
+}
+
+// newMemoryStateComponents seeds an in-memory store from the state file without creating
+// anything. A missing file is empty state; other read or parse errors are returned, since
+// starting from empty state would miss running duplicates.
+func newMemoryStateComponents() (process.StateStore, process.LockManager, error) {
+	fmt.Fprintln(os.Stderr, "Warning: --no-persist is set; processes started now are not visible to other portguard commands")
+
+	portguardDir, err := portguardDirPath()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get portguard directory: %w", err)
+	}
+
+	stored := make(map[string]*process.ManagedProcess)
+	file, err := os.Open(filepath.Join(portguardDir, "state.json"))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, nil, fmt.Errorf("failed to read state: %w", err)
+	default:
+		defer func() { _ = file.Close() }() //nolint:errcheck // Read-only file
+		snapshot, err := state.ReadSnapshot(file)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read state %s: %w", file.Name(), err)
+		}
+		stored = snapshot.Processes
+	}
+	return state.NewMemoryStore(stored), lock.NewMemoryLock(), nil
+}
+
+// configuredManagers are the process managers created for this invocation; Execute closes
+// them before exiting, which stops their janitors and flushes their batched state
+var (
+	configuredManagers   []*process.ProcessManager
+	configuredManagersMu sync.Mutex
+)
+
+// trackProcessManager registers pm to be closed when the command exits, stopping its background
+// monitors and writing its batched state
+func trackProcessManager(pm *process.ProcessManager) {
+	configuredManagersMu.Lock()
+	configuredManagers = append(configuredManagers, pm)
+	configuredManagersMu.Unlock()
+}
+
+// configureProcessManager applies logging, monitoring, limit, save, cleanup, backup and notification settings from configuration
+func configureProcessManager(pm *process.ProcessManager, cfg *config.Config) {
+	trackProcessManager(pm)
+
+	pm.SetLogger(newConfiguredLogger(cfg))
+	if cfg != nil && cfg.Default != nil {
+		pm.SetMonitoringDisabled(cfg.Default.DisableMonitoring)
+		pm.SetMonitorInterval(cfg.Default.MonitorInterval)
+		pm.SetMaxProcesses(cfg.Default.MaxProcesses)
+		pm.SetSaveInterval(cfg.Default.SaveInterval)
+		pm.SetHookTimeout(cfg.Default.HookTimeout)
+		if cleanup := cfg.Default.Cleanup; cleanup != nil {
+			pm.SetBackupRetention(cleanup.BackupRetention)
+			if cleanup.AutoCleanup {
+				pm.SetAutoCleanup(cleanup.Interval, cleanup.MaxIdleTime)
+			}
+		}
+		if notifications := cfg.Default.Notifications; notifications != nil && notifications.WebhookURL != "" {
+			pm.SetNotifier(process.NewWebhookNotifier(notifications.WebhookURL), notifications.Timeout)
+		}
+	}
+}
+
+// closeProcessManagers closes every tracked process manager, writing its batched state
+func closeProcessManagers() error {
+	configuredManagersMu.Lock()
+	managers := configuredManagers
+	configuredManagers = nil
+	configuredManagersMu.Unlock()
+
+	var closeErrors []error
+	for _, pm := range managers {
+		if err := pm.Close(); err != nil {
+			closeErrors = append(closeErrors, err)
+		}
+	}
+	return errors.Join(closeErrors...)
+}
+
 // OutputHandler provides common output formatting
 type OutputHandler struct {
 	JSONOutput bool
@@ -4,12 +4,20 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/paveg/portguard/internal/config"
+	"github.com/paveg/portguard/internal/lock"
+	portpkg "github.com/paveg/portguard/internal/port"
+	"github.com/paveg/portguard/internal/process"
+	"github.com/paveg/portguard/internal/state"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -528,3 +536,181 @@ func TestEnsureDirectoryErrorCases(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestResolveTimeout(t *testing.T) {
+	tests := []struct {
+		name     string
+		flag     time.Duration
+		config   time.Duration
+		expected time.Duration
+	}{
+		{"default", 0, 0, 5 * time.Second},
+		{"config", 0, 2 * time.Second, 2 * time.Second},
+		{"flag_over_config", time.Second, 2 * time.Second, time.Second},
+		{"flag_only", 3 * time.Second, 0, 3 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, resolveTimeout(tt.flag, tt.config, 5*time.Second))
+		})
+	}
+}
+
+func TestTimeoutFlagsWiring(t *testing.T) {
+	oldLock, oldScan := lockTimeout, scanTimeout
+	t.Cleanup(func() { lockTimeout, scanTimeout = oldLock, oldScan })
+
+	require.NoError(t, rootCmd.PersistentFlags().Parse([]string{"--lock-timeout", "250ms", "--scan-timeout", "7s"}))
+	t.Cleanup(func() {
+		_ = rootCmd.PersistentFlags().Set("lock-timeout", "0s")
+		_ = rootCmd.PersistentFlags().Set("scan-timeout", "0s")
+	})
+
+	scanner, err := newPortScanner(nil, 2*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, 7*time.Second, scanner.Timeout())
+
+	locker, err := newLockManager(nil, t.TempDir())
+	require.NoError(t, err)
+	assert.Equal(t, 250*time.Millisecond, locker.Timeout())
+}
+
+func TestNewStateComponents_NoPersist(t *testing.T) {
+	oldNoPersist := noPersist
+	noPersist = true
+	t.Cleanup(func() { noPersist = oldNoPersist })
+
+	setupHome := func(t *testing.T) string {
+		t.Helper()
+		home := t.TempDir()
+		t.Setenv("HOME", home)
+		t.Setenv("USERPROFILE", home)
+		return filepath.Join(home, ".portguard")
+	}
+
+	t.Run("missing_state_is_empty", func(t *testing.T) {
+		portguardDir := setupHome(t)
+
+		store, _, err := newStateComponents(nil)
+		require.NoError(t, err)
+		processes, err := store.Load()
+		require.NoError(t, err)
+		assert.Empty(t, processes)
+		assert.NoDirExists(t, portguardDir, "--no-persist must not create anything")
+	})
+
+	t.Run("reads_configured_state", func(t *testing.T) {
+		portguardDir := setupHome(t)
+		stored, err := state.NewJSONStore(filepath.Join(portguardDir, "state.json"))
+		require.NoError(t, err)
+		require.NoError(t, stored.Save(map[string]*process.ManagedProcess{
+			"web": {ID: "web", Command: "npm run dev", Port: 3000, PID: 4242, Status: process.StatusRunning, CreatedAt: time.Now()},
+		}))
+
+		store, _, err := newStateComponents(nil)
+		require.NoError(t, err)
+		processes, err := store.Load()
+		require.NoError(t, err)
+		assert.Contains(t, processes, "web")
+	})
+
+	t.Run("corrupt_state_is_an_error", func(t *testing.T) {
+		portguardDir := setupHome(t)
+		require.NoError(t, os.MkdirAll(portguardDir, 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(portguardDir, "state.json"), []byte("{not json"), 0o600))
+
+		_, _, err := newStateComponents(nil)
+		require.Error(t, err, "empty state would hide running duplicates")
+	})
+}
+
+func TestNewLockManager_Config(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		backend string
+		wantErr bool
+	}{
+		{name: "default", content: "default:\n  log_level: info\n", backend: "*lock.FileLock"},
+		{name: "flock", content: "default:\n  lock_backend: flock\n", backend: "*lock.FlockLock"},
+		{name: "invalid_backend", content: "default:\n  lock_backend: fcntl\n", wantErr: true},
+		{name: "unreadable_config", content: "default: [unclosed\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "portguard.yml")
+			require.NoError(t, os.WriteFile(configPath, []byte(tt.content), 0o600))
+			viper.Reset()
+			defer viper.Reset()
+			viper.SetConfigFile(configPath)
+
+			cfg, err := currentConfig()
+			var locker lock.Locker
+			if err == nil {
+				locker, err = newLockManager(cfg, t.TempDir())
+			}
+			if tt.wantErr {
+				require.Error(t, err, "a broken configuration must not silently select a backend")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.backend, fmt.Sprintf("%T", locker))
+		})
+	}
+}
+
+func TestNewPortScanner_Config(t *testing.T) {
+	t.Run("invalid_backend_fails_to_load", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "portguard.yml")
+		require.NoError(t, os.WriteFile(configPath, []byte("default:\n  discovery_backend: gopsutil\n"), 0o600))
+		viper.Reset()
+		defer viper.Reset()
+		viper.SetConfigFile(configPath)
+
+		_, err := currentConfig()
+		require.ErrorIs(t, err, portpkg.ErrUnknownDiscoveryBackend)
+	})
+
+	t.Run("invalid_backend_is_an_error", func(t *testing.T) {
+		cfg := &config.Config{Default: &config.DefaultConfig{DiscoveryBackend: "gopsutil"}}
+		_, err := newPortScanner(cfg, time.Second)
+		require.ErrorIs(t, err, portpkg.ErrUnknownDiscoveryBackend, "a broken configuration must not silently select a backend")
+	})
+}
+
+func TestCurrentConfig(t *testing.T) {
+	writeConfig := func(t *testing.T, content string) string {
+		t.Helper()
+		configPath := filepath.Join(t.TempDir(), "portguard.yml")
+		require.NoError(t, os.WriteFile(configPath, []byte(content), 0o600))
+		return configPath
+	}
+	t.Cleanup(func() {
+		invocationConfig, invocationConfigErr = nil, nil
+		viper.Reset()
+	})
+
+	t.Run("loaded_once_per_invocation", func(t *testing.T) {
+		configPath := writeConfig(t, "default:\n  lock_timeout: 2s\n")
+		viper.Reset()
+		viper.SetConfigFile(configPath)
+		loadInvocationConfig()
+
+		require.NoError(t, os.WriteFile(configPath, []byte("default:\n  lock_timeout: 9s\n"), 0o600))
+		cfg, err := currentConfig()
+		require.NoError(t, err)
+		assert.Equal(t, 2*time.Second, effectiveLockTimeout(cfg, time.Second))
+	})
+
+	t.Run("load_error_is_reported", func(t *testing.T) {
+		viper.Reset()
+		viper.SetConfigFile(writeConfig(t, "default: [unclosed\n"))
+		loadInvocationConfig()
+
+		_, err := currentConfig()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to load configuration")
+	})
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/paveg/portguard/internal/process"
+	"github.com/spf13/cobra"
+)
+
+// ErrUnsupportedShell is returned for shells portguard cannot generate completions for
+var ErrUnsupportedShell = errors.New("unsupported shell")
+
+var completionCmd = &cobra.Command{
+	Use:   "completion [bash|zsh|fish|powershell]",
+	Short: "Generate shell completion scripts",
+	Long: `Generate a completion script for portguard's commands and flags. Process IDs are completed
+for stop, signal, status, health and protect from the current state.
+
+Examples:
+  # Bash (requires bash-completion)
+  source <(portguard completion bash)
+  portguard completion bash > /etc/bash_completion.d/portguard
+
+  # Zsh
+  portguard completion zsh > "${fpath[1]}/_portguard"
+
+  # Fish
+  portguard completion fish > ~/.config/fish/completions/portguard.fish
+
+  # PowerShell
+  portguard completion powershell | Out-String | Invoke-Expression`,
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	DisableFlagsInUseLine: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return writeCompletion(cmd.Root(), cmd.OutOrStdout(), args[0])
+	},
+}
+
+// writeCompletion writes the completion script for shell, with descriptions where supported
+func writeCompletion(root *cobra.Command, w io.Writer, shell string) error {
+	switch shell {
+	case "bash":
+		return root.GenBashCompletionV2(w, true)
+	case "zsh":
+		return root.GenZshCompletion(w)
+	case "fish":
+		return root.GenFishCompletion(w, true)
+	case "powershell":
+		return root.GenPowerShellCompletionWithDesc(w)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedShell, shell)
+	}
+}
+
+// completionProcesses lists the managed processes offered as completions; tests replace it
+var completionProcesses = func(includeStopped bool) ([]*process.ManagedProcess, error) {
+	pm, err := initializeProcessManager()
+	if err != nil {
+		return nil, err
+	}
+	return pm.ListProcesses(process.ProcessListOptions{IncludeStopped: includeStopped}), nil
+}
+
+// completeProcessIDs returns a ValidArgsFunction completing the first argument with the IDs of
+// managed processes, described by their command and port. Stopped processes are offered only
+// when includeStopped is set.
+func completeProcessIDs(includeStopped bool) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		processes, err := completionProcesses(includeStopped)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		completions := make([]string, 0, len(processes))
+		for _, proc := range processes {
+			if !strings.HasPrefix(proc.ID, toComplete) {
+				continue
+			}
+			description := proc.Command
+			if proc.Port > 0 {
+				description = fmt.Sprintf("%s (port %d)", description, proc.Port)
+			}
+			completions = append(completions, proc.ID+"\t"+description)
+		}
+		sort.Strings(completions)
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd)
+
+	stopCmd.ValidArgsFunction = completeProcessIDs(false)
+	signalCmd.ValidArgsFunction = completeProcessIDs(false)
+	statusCmd.ValidArgsFunction = completeProcessIDs(true)
+	healthCmd.ValidArgsFunction = completeProcessIDs(true)
+	protectCmd.ValidArgsFunction = completeProcessIDs(true)
+}
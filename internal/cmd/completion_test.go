@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/paveg/portguard/internal/process"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteCompletion(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish", "powershell"} {
+		t.Run(shell, func(t *testing.T) {
+			var out bytes.Buffer
+			require.NoError(t, writeCompletion(rootCmd, &out, shell))
+			assert.Contains(t, out.String(), "portguard")
+		})
+	}
+
+	t.Run("unsupported_shell", func(t *testing.T) {
+		var out bytes.Buffer
+		require.ErrorIs(t, writeCompletion(rootCmd, &out, "tcsh"), ErrUnsupportedShell)
+	})
+}
+
+func TestCompleteProcessIDs(t *testing.T) {
+	original := completionProcesses
+	t.Cleanup(func() { completionProcesses = original })
+
+	processes := []*process.ManagedProcess{
+		{ID: "npm-dev-a1b2c3", Command: "npm run dev", Port: 3000, Status: process.StatusRunning},
+		{ID: "go-run-d4e5f6", Command: "go run main.go", Status: process.StatusRunning},
+		{ID: "npm-build-0a1b2c", Command: "npm run build", Status: process.StatusStopped},
+	}
+	var includedStopped bool
+	completionProcesses = func(includeStopped bool) ([]*process.ManagedProcess, error) {
+		includedStopped = includeStopped
+		if includeStopped {
+			return processes, nil
+		}
+		return processes[:2], nil
+	}
+
+	t.Run("running_processes", func(t *testing.T) {
+		completions, directive := completeProcessIDs(false)(stopCmd, nil, "")
+		assert.Equal(t, []string{"go-run-d4e5f6\tgo run main.go", "npm-dev-a1b2c3\tnpm run dev (port 3000)"}, completions)
+		assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+		assert.False(t, includedStopped)
+	})
+
+	t.Run("prefix_and_stopped_processes", func(t *testing.T) {
+		completions, _ := completeProcessIDs(true)(statusCmd, nil, "npm-")
+		assert.Equal(t, []string{"npm-build-0a1b2c\tnpm run build", "npm-dev-a1b2c3\tnpm run dev (port 3000)"}, completions)
+		assert.True(t, includedStopped)
+	})
+
+	t.Run("only_first_argument", func(t *testing.T) {
+		completions, directive := completeProcessIDs(false)(signalCmd, []string{"npm-dev-a1b2c3"}, "")
+		assert.Empty(t, completions)
+		assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+	})
+
+	t.Run("state_unavailable", func(t *testing.T) {
+		completionProcesses = func(bool) ([]*process.ManagedProcess, error) { return nil, errors.New("no state") }
+		_, directive := completeProcessIDs(false)(stopCmd, nil, "")
+		assert.Equal(t, cobra.ShellCompDirectiveError, directive)
+	})
+}
@@ -2,13 +2,22 @@ package cmd
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 
+	"github.com/paveg/portguard/internal/config"
+	"github.com/paveg/portguard/internal/lock"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
 )
 
+// ErrInvalidConfig is returned by config validate when problems are found
+var ErrInvalidConfig = errors.New("configuration is invalid")
+
 var configCmd = &cobra.Command{
 	Use:   "config",
 	Short: "Configuration management commands",
@@ -55,7 +64,22 @@ default:
     max_idle_time: 1h
     backup_retention: 168h
   
-  log_level: info
+  log_level: info    # debug, info, warn or error
+  log_format: text   # text or json
+  disable_monitoring: false  # true skips background monitors (useful in CI)
+  monitor_interval: 500ms    # liveness polling; health checks use health_check.interval
+
+  # POST a JSON event when a monitored process goes unhealthy, stops or recovers
+  # notifications:
+  #   webhook_url: "https://hooks.example.com/portguard"
+  #   timeout: 5s
+
+  # Additional server command patterns (regular expressions) for in-house tooling
+  # server_patterns:
+  #   - "mycli dev"
+  # port_patterns:
+  #   - pattern: "mycli dev"
+  #     port: 4500
 
 # Project-specific configurations
 projects:
@@ -107,48 +131,190 @@ projects:
 
 var configShowCmd = &cobra.Command{
 	Use:   "show",
-	Short: "Show current configuration",
-	Long:  `Display the current configuration values including defaults and project settings.`,
+	Short: "Show the effective configuration",
+	Long: `Display the configuration in effect after merging defaults, the config file, PORTGUARD_*
+environment variables and flags, with paths expanded. Also shows where this invocation keeps
+its state and lock files, to debug state ending up in an unexpected place.`,
 	Run: func(_ *cobra.Command, _ []string) {
-		fmt.Println("Current Configuration:")
-		fmt.Printf("Config file: %s\n", viper.ConfigFileUsed())
+		out := NewOutputHandler(jsonOutput)
+
+		cfg, err := currentConfig()
+		if err != nil {
+			out.PrintError("failed to load configuration", err)
+			return
+		}
+		resolved, err := resolveConfig(cfg)
+		if err != nil {
+			out.PrintError("failed to resolve configuration", err)
+			return
+		}
 
 		if jsonOutput {
-			// Output all configuration as JSON
-			allSettings := viper.AllSettings()
-			if data, err := jsonMarshalIndent(allSettings); err == nil {
-				fmt.Println(string(data))
-			} else {
-				fmt.Printf("Error marshaling config: %v\n", err)
+			_ = out.PrintJSON(resolved) //nolint:errcheck // Nothing left to report a marshal error to
+			return
+		}
+		if err := printResolvedConfig(os.Stdout, resolved); err != nil {
+			out.PrintError("failed to print configuration", err)
+		}
+	},
+}
+
+// resolvedConfig is the configuration in effect, as printed by config show
+type resolvedConfig struct {
+	ConfigFile  string                 `json:"config_file"` // Empty when only defaults and the environment apply
+	StateFile   string                 `json:"state_file"`  // Where commands keep process state
+	LockFile    string                 `json:"lock_file"`   // Lock guarding the state file
+	LockBackend string                 `json:"lock_backend"`
+	Notes       []string               `json:"notes,omitempty"`
+	Settings    map[string]interface{} `json:"settings"` // Merged values, keyed like the config file
+}
+
+// resolveConfig computes the state and lock locations this invocation uses for cfg
+func resolveConfig(cfg *config.Config) (*resolvedConfig, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	portguardDir := filepath.Join(homeDir, ".portguard")
+	backend, err := lockBackend(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := &resolvedConfig{
+		ConfigFile:  cfg.SourceFile(),
+		StateFile:   filepath.Join(portguardDir, "state.json"),
+		LockFile:    lock.Path(portguardDir),
+		LockBackend: string(backend),
+		Settings:    cfg.Settings(),
+	}
+
+	if noPersist {
+		resolved.Notes = append(resolved.Notes, "--no-persist is set: state is read from the state file but changes stay in memory")
+	}
+	if configured := cfg.Default.StateFile; configured != "" && configured != resolved.StateFile {
+		resolved.Notes = append(resolved.Notes, fmt.Sprintf("default.state_file (%s) is not used; state is kept in %s", configured, resolved.StateFile))
+	}
+	if configured := cfg.Default.LockFile; configured != "" && configured != resolved.LockFile {
+		resolved.Notes = append(resolved.Notes, fmt.Sprintf("default.lock_file (%s) is not used; the lock is %s", configured, resolved.LockFile))
+	}
+	return resolved, nil
+}
+
+// printResolvedConfig prints the resolved locations followed by the settings as YAML
+func printResolvedConfig(w io.Writer, resolved *resolvedConfig) error {
+	configFile := resolved.ConfigFile
+	if configFile == "" {
+		configFile = "(none, using defaults)"
+	}
+	fmt.Fprintf(w, "Config file:  %s\n", configFile)
+	fmt.Fprintf(w, "State file:   %s\n", resolved.StateFile)
+	fmt.Fprintf(w, "Lock file:    %s (%s backend)\n", resolved.LockFile, resolved.LockBackend)
+	for _, note := range resolved.Notes {
+		fmt.Fprintf(w, "Note: %s\n", note)
+	}
+
+	fmt.Fprintln(w, "\nEffective settings:")
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(resolved.Settings); err != nil {
+		return fmt.Errorf("failed to marshal settings: %w", err)
+	}
+	return encoder.Close()
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [path]",
+	Short: "Validate a configuration file",
+	Long: `Load a configuration file and report every problem found, with the offending field.
+Without a path the usual search order is used (--config, then $HOME and the current directory).
+
+Examples:
+  portguard config validate
+  portguard config validate ./portguard.toml --json`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(_ *cobra.Command, args []string) error {
+		if len(args) == 1 {
+			viper.SetConfigFile(args[0])
+		}
+
+		result := validateConfig()
+		if jsonOutput {
+			data, err := jsonMarshalIndent(result)
+			if err != nil {
+				return fmt.Errorf("failed to marshal validation result: %w", err)
 			}
+			fmt.Println(string(data))
 		} else {
-			// Human-readable output
-			fmt.Println("\nDefault Settings:")
-			fmt.Printf("  State file: %s\n", viper.GetString("default.state_file"))
-			fmt.Printf("  Lock file: %s\n", viper.GetString("default.lock_file"))
-			fmt.Printf("  Port range: %d-%d\n",
-				viper.GetInt("default.port_range.start"),
-				viper.GetInt("default.port_range.end"))
-			fmt.Printf("  Health check enabled: %v\n", viper.GetBool("default.health_check.enabled"))
-			fmt.Printf("  Auto cleanup: %v\n", viper.GetBool("default.cleanup.auto_cleanup"))
-
-			// Show project configurations
-			projects := viper.GetStringMap("projects")
-			if len(projects) > 0 {
-				fmt.Println("\nProject Configurations:")
-				for name := range projects {
-					fmt.Printf("  %s:\n", name)
-					fmt.Printf("    Command: %s\n", viper.GetString(fmt.Sprintf("projects.%s.command", name)))
-					//nolint:govet // TODO: Rename variable to avoid shadowing (e.g., projectPort)
-					if port := viper.GetInt(fmt.Sprintf("projects.%s.port", name)); port > 0 {
-						fmt.Printf("    Port: %d\n", port)
-					}
-				}
-			}
+			printConfigValidation(result)
 		}
+
+		if !result.Valid {
+			return fmt.Errorf("%w: %d problem(s)", ErrInvalidConfig, len(result.Errors))
+		}
+		return nil
 	},
 }
 
+// configValidationResult is the outcome of config validate
+type configValidationResult struct {
+	ConfigFile string                  `json:"config_file"`
+	Valid      bool                    `json:"valid"`
+	Errors     []configValidationIssue `json:"errors"`
+}
+
+// configValidationIssue is a single validation problem
+type configValidationIssue struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// validateConfig loads the configuration selected in viper and collects every problem
+func validateConfig() configValidationResult {
+	result := configValidationResult{
+		ConfigFile: viper.ConfigFileUsed(),
+		Errors:     []configValidationIssue{},
+	}
+
+	cfg, err := currentConfig()
+	if err != nil {
+		// Settings checked while loading are reported against their field
+		issue := configValidationIssue{Field: "file", Message: err.Error()}
+		var problem *config.ValidationError
+		if errors.As(err, &problem) {
+			issue = configValidationIssue{Field: problem.Field, Message: problem.Err.Error()}
+		}
+		result.Errors = append(result.Errors, issue)
+		return result
+	}
+	result.ConfigFile = cfg.SourceFile()
+
+	for _, problem := range cfg.ValidateAll() {
+		result.Errors = append(result.Errors, configValidationIssue{Field: problem.Field, Message: problem.Err.Error()})
+	}
+	result.Valid = len(result.Errors) == 0
+	return result
+}
+
+// printConfigValidation prints a validation result for humans
+func printConfigValidation(result configValidationResult) {
+	source := result.ConfigFile
+	if source == "" {
+		source = "(no config file, using defaults)"
+	}
+
+	if result.Valid {
+		fmt.Printf("✅ %s is valid\n", source)
+		return
+	}
+
+	fmt.Printf("❌ %s has %d problem(s):\n", source, len(result.Errors))
+	for _, issue := range result.Errors {
+		fmt.Printf("  - %s: %s\n", issue.Field, issue.Message)
+	}
+}
+
 var (
 	configFile string
 )
@@ -157,11 +323,13 @@ func init() {
 	rootCmd.AddCommand(configCmd)
 	configCmd.AddCommand(configInitCmd)
 	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configValidateCmd)
 
 	configInitCmd.Flags().StringVar(&configFile, "file", "", "configuration file path")
 	configInitCmd.Flags().BoolVarP(&force, "force", "f", false, "overwrite existing configuration")
 
 	configShowCmd.Flags().BoolVar(&jsonOutput, "json", false, "output in JSON format")
+	configValidateCmd.Flags().BoolVar(&jsonOutput, "json", false, "output validation errors in JSON format")
 }
 
 // Helper function for JSON marshaling with indentation
@@ -1,8 +1,14 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/paveg/portguard/internal/config"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -103,3 +109,153 @@ func TestJsonMarshalIndent(t *testing.T) {
 		assert.Contains(t, string(result), "[]")
 	})
 }
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name           string
+		content        string
+		expectValid    bool
+		expectedFields []string
+	}{
+		{
+			name: "valid_config",
+			content: `
+default:
+  port_range:
+    start: 3000
+    end: 4000
+projects:
+  web:
+    command: "npm run dev"
+    port: 3000
+`,
+			expectValid: true,
+		},
+		{
+			name: "reports_every_problem",
+			content: `
+default:
+  port_range:
+    start: 0
+    end: 4000
+  log_level: loud
+projects:
+  api:
+    command: "go run ."
+    port: 70000
+  web:
+    port: 3000
+`,
+			expectedFields: []string{
+				"default.port_range.start",
+				"default.log_level",
+				"projects.api.port",
+				"projects.web.command",
+			},
+		},
+		{
+			name: "invalid_discovery_backend",
+			content: `
+default:
+  discovery_backend: gopsutil
+`,
+			expectedFields: []string{"default.discovery_backend"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "portguard.yml")
+			require.NoError(t, os.WriteFile(configPath, []byte(tt.content), 0o600))
+
+			viper.Reset()
+			defer viper.Reset()
+			viper.SetConfigFile(configPath)
+
+			result := validateConfig()
+			assert.Equal(t, tt.expectValid, result.Valid)
+			assert.Equal(t, configPath, result.ConfigFile)
+
+			fields := make([]string, 0, len(result.Errors))
+			for _, issue := range result.Errors {
+				fields = append(fields, issue.Field)
+				assert.NotEmpty(t, issue.Message)
+			}
+			if tt.expectValid {
+				assert.Empty(t, fields)
+			} else {
+				assert.Equal(t, tt.expectedFields, fields)
+			}
+		})
+	}
+
+	t.Run("missing_file", func(t *testing.T) {
+		viper.Reset()
+		defer viper.Reset()
+		viper.SetConfigFile(filepath.Join(t.TempDir(), "missing.yml"))
+
+		result := validateConfig()
+		assert.False(t, result.Valid)
+		require.Len(t, result.Errors, 1)
+		assert.Equal(t, "file", result.Errors[0].Field)
+	})
+}
+
+func TestResolveConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	configPath := filepath.Join(t.TempDir(), "portguard.yml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+default:
+  state_file: /srv/portguard/state.json
+  port_range:
+    start: 4000
+    end: 4100
+projects:
+  web:
+    command: "npm run dev"
+    port: 4000
+`), 0o600))
+
+	viper.Reset()
+	defer viper.Reset()
+	viper.SetConfigFile(configPath)
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	resolved, err := resolveConfig(cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, configPath, resolved.ConfigFile)
+	assert.Equal(t, filepath.Join(home, ".portguard", "state.json"), resolved.StateFile)
+	assert.Equal(t, filepath.Join(home, ".portguard", "portguard.lock"), resolved.LockFile)
+	assert.Equal(t, "file", resolved.LockBackend)
+	require.Len(t, resolved.Notes, 1)
+	assert.Contains(t, resolved.Notes[0], "default.state_file (/srv/portguard/state.json) is not used")
+
+	defaults := resolved.Settings["default"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"start": 4000, "end": 4100}, defaults["port_range"])
+	assert.Equal(t, "30s", defaults["health_check"].(map[string]interface{})["timeout"], "defaults are included")
+	assert.Contains(t, resolved.Settings["projects"], "web")
+
+	t.Run("json", func(t *testing.T) {
+		data, err := json.Marshal(resolved)
+		require.NoError(t, err)
+		var payload map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &payload))
+		assert.Equal(t, resolved.StateFile, payload["state_file"])
+		assert.Equal(t, resolved.LockFile, payload["lock_file"])
+		assert.NotNil(t, payload["settings"])
+	})
+
+	t.Run("text", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, printResolvedConfig(&buf, resolved))
+		output := buf.String()
+		assert.Contains(t, output, "Config file:  "+configPath)
+		assert.Contains(t, output, "State file:   "+resolved.StateFile)
+		assert.Contains(t, output, "Note: default.state_file")
+		assert.Contains(t, output, "  port_range:\n    end: 4100\n    start: 4000\n")
+	})
+}
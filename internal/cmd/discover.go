@@ -2,15 +2,10 @@ package cmd
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
 	"time"
 
 	"github.com/paveg/portguard/internal/config"
-	"github.com/paveg/portguard/internal/lock"
-	portpkg "github.com/paveg/portguard/internal/port"
 	"github.com/paveg/portguard/internal/process"
-	"github.com/paveg/portguard/internal/state"
 	"github.com/spf13/cobra"
 )
 
@@ -25,7 +20,8 @@ This command is useful for finding servers that you might want to import into po
 Examples:
   portguard discover                    # Scan default port range (3000-9000)
   portguard discover --range 8000-8100 # Scan specific range
-  portguard discover --auto-import     # Discover and automatically import suitable processes`,
+  portguard discover --auto-import     # Discover and automatically import suitable processes
+  portguard discover --match-env NODE_ENV=development  # Only servers running in development`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := runDiscoverCommand(); err != nil {
 			fmt.Printf("Discovery failed: %v\n", err)
@@ -40,32 +36,21 @@ var (
 )
 
 func runDiscoverCommand() error {
-	// Load configuration
-	cfg, err := config.Load()
+	cfg, err := currentConfig()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
 	}
 
 	// Create process adopter for discovery
-	adopter := process.NewProcessAdopter(30 * time.Second)
+	adopter, err := newImportAdopter(cfg)
+	if err != nil {
+		return err
+	}
 
 	// Parse port range or use default
-	var rangeStart, rangeEnd int
-	if portRange != "" {
-		scanner := portpkg.NewScanner(5 * time.Second)
-		rangeStart, rangeEnd, err = scanner.ParsePortRange(portRange)
-		if err != nil {
-			return fmt.Errorf("invalid port range %s: %w", portRange, err)
-		}
-	} else {
-		// Use default range from config or fallback
-		if cfg.Default != nil && cfg.Default.PortRange != nil {
-			rangeStart = cfg.Default.PortRange.Start
-			rangeEnd = cfg.Default.PortRange.End
-		} else {
-			rangeStart = 3000
-			rangeEnd = 9000
-		}
+	rangeStart, rangeEnd, err := resolvePortRange(cfg, portRange)
+	if err != nil {
+		return err
 	}
 
 	fmt.Printf("Discovering development servers in port range %d-%d...\n", rangeStart, rangeEnd)
@@ -90,25 +75,42 @@ func runDiscoverCommand() error {
 		return outputDiscoveryResultsJSON(adoptableProcesses)
 	}
 
-	return outputDiscoveryResults(adoptableProcesses, autoImport)
+	return outputDiscoveryResults(cfg, adoptableProcesses, autoImport)
+}
+
+// resolvePortRange parses a "start-end" range flag, falling back to the configured default range
+func resolvePortRange(cfg *config.Config, rangeFlag string) (int, int, error) {
+	if rangeFlag != "" {
+		scanner, err := newPortScanner(cfg, 5*time.Second)
+		if err != nil {
+			return 0, 0, err
+		}
+		rangeStart, rangeEnd, err := scanner.ParsePortRange(rangeFlag)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid port range %s: %w", rangeFlag, err)
+		}
+		return rangeStart, rangeEnd, nil
+	}
+
+	// Use default range from config or fallback
+	if cfg != nil && cfg.Default != nil && cfg.Default.PortRange != nil {
+		return cfg.Default.PortRange.Start, cfg.Default.PortRange.End, nil
+	}
+	return 3000, 9000, nil
 }
 
-func outputDiscoveryResults(processes []*process.AdoptionInfo, shouldAutoImport bool) error {
+func outputDiscoveryResults(cfg *config.Config, processes []*process.AdoptionInfo, shouldAutoImport bool) error {
 	var processManager *process.ProcessManager
 
 	// Initialize process manager if auto-import is enabled
 	if shouldAutoImport {
-		cfg, err := config.Load()
-		if err != nil {
-			return fmt.Errorf("failed to load config for auto-import: %w", err)
-		}
-
 		stateStore, lockManager, portScanner, err := createDiscoveryManagementComponents(cfg)
 		if err != nil {
 			return fmt.Errorf("failed to create management components: %w", err)
 		}
 
 		processManager = process.NewProcessManager(stateStore, lockManager, portScanner)
+		trackProcessManager(processManager)
 	}
 
 	for i, proc := range processes {
@@ -128,7 +130,7 @@ func outputDiscoveryResults(processes []*process.AdoptionInfo, shouldAutoImport
 		// Auto-import if requested and process is suitable
 		if shouldAutoImport && proc.IsSuitable {
 			fmt.Print("    Auto-importing... ")
-			if err := autoImportProcess(processManager, proc); err != nil {
+			if err := autoImportProcess(cfg, processManager, proc); err != nil {
 				fmt.Printf("Failed: %v\n", err)
 			} else {
 				fmt.Println("Success ✓")
@@ -168,9 +170,12 @@ func outputDiscoveryResultsJSON(processes []*process.AdoptionInfo) error {
 	return nil
 }
 
-func autoImportProcess(processManager *process.ProcessManager, adoptionInfo *process.AdoptionInfo) error {
+func autoImportProcess(cfg *config.Config, processManager *process.ProcessManager, adoptionInfo *process.AdoptionInfo) error {
 	// Create process adopter
-	adopter := process.NewProcessAdopter(30 * time.Second)
+	adopter, err := newImportAdopter(cfg)
+	if err != nil {
+		return err
+	}
 
 	// Adopt the process by PID
 	managedProcess, err := adopter.AdoptProcessByPID(adoptionInfo.PID)
@@ -207,31 +212,17 @@ func countSuitableProcesses(processes []*process.AdoptionInfo) int {
 
 // createDiscoveryManagementComponents creates management components for discovery operations
 func createDiscoveryManagementComponents(cfg *config.Config) (process.StateStore, process.LockManager, process.PortScanner, error) {
-	// Get or create portguard directory
-	homeDir, err := os.UserHomeDir()
+	// Initialize port scanner
+	portScanner, err := newPortScanner(cfg, 5*time.Second)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to get user home directory: %w", err)
-	}
-
-	portguardDir := filepath.Join(homeDir, ".portguard")
-	if mkdirErr := os.MkdirAll(portguardDir, 0o755); mkdirErr != nil {
-		return nil, nil, nil, fmt.Errorf("failed to create portguard directory: %w", mkdirErr)
+		return nil, nil, nil, err
 	}
 
-	// Initialize state store
-	stateFile := filepath.Join(portguardDir, "state.json")
-	stateStore, err := state.NewJSONStore(stateFile)
+	stateStore, lockManager, err := newStateComponents(cfg)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to create state store: %w", err)
+		return nil, nil, nil, err
 	}
 
-	// Initialize lock manager
-	lockFile := filepath.Join(portguardDir, "portguard.lock")
-	lockManager := lock.NewFileLock(lockFile, 5*time.Second)
-
-	// Initialize port scanner
-	portScanner := portpkg.NewScanner(5 * time.Second)
-
 	return stateStore, lockManager, portScanner, nil
 }
 
@@ -242,4 +233,6 @@ func init() {
 	discoverCmd.Flags().StringVar(&portRange, "range", "", "port range to scan (e.g., '3000-4000')")
 	discoverCmd.Flags().BoolVar(&autoImport, "auto-import", false, "automatically import suitable processes")
 	discoverCmd.Flags().BoolVar(&jsonOutput, "json", false, "output results in JSON format")
+	discoverCmd.Flags().StringArrayVar(&matchEnv, "match-env", nil,
+		"only treat processes whose environment matches KEY=VALUE, KEY!=VALUE or KEY as suitable (repeatable)")
 }
@@ -210,7 +210,7 @@ func TestOutputDiscoveryResults(t *testing.T) {
 		done := make(chan error, 1)
 		go func() {
 			defer func() { _ = w.Close() }()
-			done <- outputDiscoveryResults(processes, false)
+			done <- outputDiscoveryResults(nil, processes, false)
 		}()
 
 		err := <-done
@@ -241,7 +241,7 @@ func TestOutputDiscoveryResults(t *testing.T) {
 		done := make(chan error, 1)
 		go func() {
 			defer func() { _ = w.Close() }()
-			done <- outputDiscoveryResults(processes, true)
+			done <- outputDiscoveryResults(nil, processes, true)
 		}()
 
 		err := <-done
@@ -286,7 +286,7 @@ func TestAutoImportProcess(t *testing.T) {
 	}
 
 	t.Run("auto_import_successful", func(t *testing.T) {
-		err := autoImportProcess(processManager, adoptionInfo)
+		err := autoImportProcess(cfg, processManager, adoptionInfo)
 		// May fail due to process not being a real server, but should not panic
 		// The important thing is the function executes without panicking
 		if err != nil {
@@ -303,7 +303,7 @@ func TestAutoImportProcess(t *testing.T) {
 			IsSuitable:  true,
 		}
 
-		err := autoImportProcess(processManager, invalidInfo)
+		err := autoImportProcess(cfg, processManager, invalidInfo)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "failed to adopt process")
 	})
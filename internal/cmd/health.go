@@ -17,7 +17,7 @@ Performs HTTP, TCP, or command-based health checks depending on configuration.
 
 Examples:
   portguard health
-  portguard health abc123
+  portguard health npm-dev-a1b2c3
   portguard health --json`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(_ *cobra.Command, args []string) error {
@@ -158,8 +158,8 @@ func handleAllProcessesHealth(pm *process.ProcessManager) error {
 	fmt.Printf("  Checked At: %s\n\n", time.Now().Format(time.RFC3339))
 
 	// Show individual results
-	fmt.Printf("%-12s %-10s %-10s %-s\n", "PROCESS ID", "STATUS", "HEALTHY", "COMMAND")
-	fmt.Println("---------------------------------------------------------------")
+	fmt.Printf("%-20s %-10s %-10s %-s\n", "PROCESS ID", "STATUS", "HEALTHY", "COMMAND")
+	fmt.Println("-----------------------------------------------------------------------")
 
 	for _, result := range results {
 		healthyStr := "No"
@@ -167,8 +167,8 @@ func handleAllProcessesHealth(pm *process.ProcessManager) error {
 			healthyStr = "Yes"
 		}
 
-		fmt.Printf("%-12s %-10s %-10s %-s\n",
-			result.ProcessID[:8]+"...",
+		fmt.Printf("%-20s %-10s %-10s %-s\n",
+			result.ProcessID,
 			result.Status,
 			healthyStr,
 			result.Command)
@@ -200,7 +200,7 @@ func performHealthCheck(pm *process.ProcessManager, proc *process.ManagedProcess
 	}
 
 	// If no health check configured, just check if process is alive
-	if proc.HealthCheck == nil {
+	if proc.HealthCheck == nil || !proc.HealthCheck.Enabled {
 		if proc.IsHealthy() {
 			result.Status = "running"
 			result.Healthy = true
@@ -213,18 +213,21 @@ func performHealthCheck(pm *process.ProcessManager, proc *process.ManagedProcess
 		return result, nil
 	}
 
-	// Use ProcessManager's runHealthCheck method via reflection/interface
-	// Since runHealthCheck is private, we'll implement a basic health check here
-	// This matches the current ProcessManager implementation
-	if proc.HealthCheck != nil {
-		// For now, assume process is healthy if running (matches ProcessManager logic)
-		// This will be enhanced when we implement proper health check types later
+	// Run the configured check now instead of trusting the recorded status
+	healthy, err := pm.CheckHealth(proc.ID)
+	switch {
+	case err != nil:
+		result.Status = "error"
+		result.Error = err.Error()
+	case healthy:
 		result.Status = "healthy"
 		result.Healthy = true
-	} else {
-		// No health check configured, just verify process is alive
-		result.Status = "running"
-		result.Healthy = true
+	default:
+		result.Status = string(process.StatusUnhealthy)
+		if current, exists := pm.GetProcess(proc.ID); exists {
+			result.Status = string(current.Status)
+		}
+		result.Error = "health check failed"
 	}
 
 	result.ResponseTime = time.Since(start).String()
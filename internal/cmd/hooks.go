@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/paveg/portguard/internal/hooks"
 	"github.com/spf13/cobra"
@@ -289,6 +290,13 @@ func printHooksStatus(status *hooks.StatusResult) {
 			fmt.Printf("  - %s\n", missing)
 		}
 	}
+
+	if len(status.HooksActive) > 0 {
+		fmt.Printf("Active Hooks: %s\n", strings.Join(status.HooksActive, ", "))
+	}
+	for _, message := range status.Messages {
+		fmt.Printf("  • %s\n", message)
+	}
 }
 
 func init() {
@@ -1,17 +1,18 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strconv"
+	"syscall"
 	"time"
 
 	"github.com/paveg/portguard/internal/config"
-	"github.com/paveg/portguard/internal/lock"
-	portpkg "github.com/paveg/portguard/internal/port"
 	"github.com/paveg/portguard/internal/process"
-	"github.com/paveg/portguard/internal/state"
 	"github.com/spf13/cobra"
 )
 
@@ -24,7 +25,14 @@ This is useful for processes that were started outside of portguard but you want
 Examples:
   portguard import --port 8080          # Import process running on port 8080
   portguard import --pid 12345          # Import process with PID 12345
-  portguard import --port 3000 --name my-app  # Import with custom name`,
+  portguard import pidfile /var/run/app.pid  # Import the process named by a PID file
+  portguard import --port 3000 --name my-app  # Import with custom name
+  portguard import all --range 3000-9000     # Import every suitable dev server
+  portguard import pid 12345 --attach-log   # Also capture its output in a log file
+  portguard import all --match-env NODE_ENV=development  # Skip servers running in another environment
+
+--attach-log copies what the process writes from now on into ~/.portguard/logs. This works on
+Linux when the process's stdout goes to a file; otherwise the reason is recorded on the process.`,
 }
 
 var importPortCmd = &cobra.Command{
@@ -34,18 +42,15 @@ var importPortCmd = &cobra.Command{
 Portguard will detect the process using that port and add it to management.`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		out := NewOutputHandler(jsonOutput)
 		portNum, err := strconv.Atoi(args[0])
 		if err != nil {
-			fmt.Printf("Invalid port number: %s\n", args[0])
+			out.PrintError("invalid port number: "+args[0], nil)
 			return
 		}
 
-		if err := importProcessByPort(portNum); err != nil {
-			fmt.Printf("Failed to import process on port %d: %v\n", portNum, err)
-			return
-		}
-
-		fmt.Printf("Successfully imported process on port %d\n", portNum)
+		managedProcess, err := importProcessByPort(portNum)
+		reportImport(out, fmt.Sprintf("process on port %d", portNum), managedProcess, err)
 	},
 }
 
@@ -56,83 +61,380 @@ var importPidCmd = &cobra.Command{
 Portguard will adopt the process and add it to management.`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		out := NewOutputHandler(jsonOutput)
 		pid, err := strconv.Atoi(args[0])
 		if err != nil {
-			fmt.Printf("Invalid PID: %s\n", args[0])
+			out.PrintError("invalid PID: "+args[0], nil)
 			return
 		}
 
-		if err := importProcessByPID(pid); err != nil {
-			fmt.Printf("Failed to import process with PID %d: %v\n", pid, err)
-			return
+		managedProcess, err := importProcessByPID(pid)
+		reportImport(out, fmt.Sprintf("process with PID %d", pid), managedProcess, err)
+	},
+}
+
+var importPidfileCmd = &cobra.Command{
+	Use:   "pidfile <path>",
+	Short: "Import the process named by a PID file",
+	Long: `Import a daemon by the PID file it wrote, e.g. /var/run/nginx.pid.
+The PID is read from the first line of the file and must belong to a running process.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		managedProcess, err := importProcessByPIDFile(args[0])
+		reportImport(NewOutputHandler(jsonOutput), "process from PID file "+args[0], managedProcess, err)
+	},
+}
+
+var importTailLogCmd = &cobra.Command{
+	Use:    "tail-log <pid> <log-file> <source>...",
+	Short:  "Copy the output of an adopted process into a log file",
+	Long:   `Started in the background by --attach-log; runs until the process exits.`,
+	Hidden: true,
+	Args:   cobra.MinimumNArgs(3),
+	RunE: func(_ *cobra.Command, args []string) error {
+		pid, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid PID %q: %w", args[0], err)
 		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		return process.TailOutput(ctx, pid, args[2:], args[1], outputTailInterval)
+	},
+}
 
-		fmt.Printf("Successfully imported process with PID %d\n", pid)
+// outputTailInterval is how often the tail-log helper polls an adopted process's output
+const outputTailInterval = 500 * time.Millisecond
+
+// newTailerExecutor returns the executor that starts tail-log helpers; tests replace it
+var newTailerExecutor = func() process.Executor { return process.NewOSExecutor() }
+
+var importAllCmd = &cobra.Command{
+	Use:   "all",
+	Short: "Import all discovered development servers",
+	Long: `Discover development servers in a port range and import every one that is suitable for adoption.
+Use --dry-run to list the candidates without importing them.`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		return runImportAll()
 	},
 }
 
-func importProcessByPort(port int) error {
-	// Load configuration
-	cfg, err := config.Load()
+// Import outcomes reported by import all
+const (
+	importStatusImported    = "imported"
+	importStatusWouldImport = "would_import"
+	importStatusSkipped     = "skipped"
+	importStatusFailed      = "failed"
+)
+
+// importAllResult records what happened to one discovered process
+type importAllResult struct {
+	PID         int    `json:"pid"`
+	Port        int    `json:"port,omitempty"`
+	ProcessName string `json:"process_name"`
+	Command     string `json:"command"`
+	Status      string `json:"status"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+func runImportAll() error {
+	cfg, err := currentConfig()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
 	}
 
-	// Create process adopter
-	adopter := process.NewProcessAdopter(30 * time.Second)
+	rangeStart, rangeEnd, err := resolvePortRange(cfg, portRange)
+	if err != nil {
+		return err
+	}
+
+	adopter, err := newImportAdopter(cfg)
+	if err != nil {
+		return err
+	}
+	discovered, err := adopter.DiscoverAdoptableProcesses(process.PortRange{Start: rangeStart, End: rangeEnd})
+	if err != nil {
+		return fmt.Errorf("failed to discover processes: %w", err)
+	}
+
+	var adopt func(*process.AdoptionInfo) error
+	if !dryRun {
+		stateStore, lockManager, portScanner, err := createManagementComponents(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create management components: %w", err)
+		}
+		processManager := process.NewProcessManager(stateStore, lockManager, portScanner)
+		trackProcessManager(processManager)
+		adopt = func(info *process.AdoptionInfo) error {
+			return adoptDiscoveredProcess(adopter, processManager, info)
+		}
+	}
+
+	results := importDiscoveredProcesses(discovered, adopt)
+
+	if jsonOutput {
+		data, err := jsonMarshalIndent(map[string]interface{}{
+			"range":   fmt.Sprintf("%d-%d", rangeStart, rangeEnd),
+			"dry_run": dryRun,
+			"results": results,
+			"summary": summarizeImportResults(results),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to marshal import results: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	printImportResults(results, rangeStart, rangeEnd)
+	return nil
+}
+
+// importDiscoveredProcesses adopts every suitable process with adopt.
+// A nil adopt function reports suitable processes as candidates without importing them.
+func importDiscoveredProcesses(discovered []*process.AdoptionInfo, adopt func(*process.AdoptionInfo) error) []importAllResult {
+	results := make([]importAllResult, 0, len(discovered))
+	for _, info := range discovered {
+		result := importAllResult{
+			PID:         info.PID,
+			Port:        info.Port,
+			ProcessName: info.ProcessName,
+			Command:     info.Command,
+		}
+
+		switch {
+		case !info.IsSuitable:
+			result.Status = importStatusSkipped
+			result.Reason = info.Reason
+		case adopt == nil:
+			result.Status = importStatusWouldImport
+		default:
+			if err := adopt(info); err != nil {
+				result.Status = importStatusFailed
+				result.Reason = err.Error()
+			} else {
+				result.Status = importStatusImported
+			}
+		}
+
+		results = append(results, result)
+	}
+	return results
+}
 
-	// Adopt process by port
-	managedProcess, err := adopter.AdoptProcessByPort(port)
+// adoptDiscoveredProcess adopts a discovered process and adds it to management
+func adoptDiscoveredProcess(adopter *process.ProcessAdopter, processManager *process.ProcessManager, info *process.AdoptionInfo) error {
+	managedProcess, err := adopter.AdoptProcessByPID(info.PID)
 	if err != nil {
 		return fmt.Errorf("failed to adopt process: %w", err)
 	}
 
-	// Create process manager to save the adopted process
-	stateStore, lockManager, portScanner, err := createManagementComponents(cfg)
+	// Carry over what discovery already knows about the process
+	if managedProcess.Command == "" {
+		managedProcess.Command = info.Command
+	}
+	if managedProcess.Port == 0 {
+		managedProcess.Port = info.Port
+	}
+
+	tailerPID := attachOutputIfRequested(managedProcess)
+	if err := processManager.AdoptProcess(managedProcess); err != nil {
+		stopOutputTailer(tailerPID)
+		return fmt.Errorf("failed to add to management: %w", err)
+	}
+	return nil
+}
+
+// attachOutputIfRequested attaches the output of an adopted process when --attach-log is set
+// and returns the PID of the tail-log helper, or 0 when none was started
+func attachOutputIfRequested(managedProcess *process.ManagedProcess) int {
+	if !attachLog {
+		return 0
+	}
+	return attachAdoptedOutput(managedProcess)
+}
+
+// attachAdoptedOutput starts a detached tail-log helper that copies the output of an adopted
+// process into a log file under ~/.portguard/logs, points LogFile at it and returns the helper's
+// PID. When the output cannot be captured, the reason is recorded in LogAttachError instead.
+func attachAdoptedOutput(managedProcess *process.ManagedProcess) int {
+	logFile, tailerPID, err := startOutputTailer(managedProcess.PID)
 	if err != nil {
-		return fmt.Errorf("failed to create management components: %w", err)
+		managedProcess.LogAttachError = err.Error()
+		return 0
 	}
+	managedProcess.LogFile = logFile
+	managedProcess.LogAttachError = ""
+	return tailerPID
+}
 
-	processManager := process.NewProcessManager(stateStore, lockManager, portScanner)
+// startOutputTailer starts the tail-log helper for the process and returns its log file and PID
+func startOutputTailer(pid int) (string, int, error) {
+	sources, err := process.OutputFiles(pid)
+	if err != nil {
+		return "", 0, err
+	}
 
-	// Add the adopted process to management
-	if err := addAdoptedProcess(processManager, managedProcess); err != nil {
-		return fmt.Errorf("failed to add adopted process to management: %w", err)
+	portguardDir, err := getPortguardDir()
+	if err != nil {
+		return "", 0, err
 	}
+	logDir := filepath.Join(portguardDir, "logs")
+	if err := os.MkdirAll(logDir, 0o755); err != nil {
+		return "", 0, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	logFile := filepath.Join(logDir, fmt.Sprintf("adopted-%d.log", pid))
 
-	return nil
+	executable, err := os.Executable()
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to locate the portguard executable: %w", err)
+	}
+	args := append([]string{"import", "tail-log", strconv.Itoa(pid), logFile}, sources...)
+	tailerPID, err := newTailerExecutor().Start(process.ExecSpec{Command: executable, Args: args, Detached: true})
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to start output capture: %w", err)
+	}
+	return logFile, tailerPID, nil
+}
+
+// stopOutputTailer stops a tail-log helper whose process ended up not being managed
+func stopOutputTailer(tailerPID int) {
+	if tailerPID > 0 {
+		_ = newTailerExecutor().Kill(tailerPID, false) //nolint:errcheck // Best effort; the helper also exits with the process
+	}
+}
+
+// summarizeImportResults counts results by status
+func summarizeImportResults(results []importAllResult) map[string]int {
+	summary := map[string]int{
+		importStatusImported:    0,
+		importStatusWouldImport: 0,
+		importStatusSkipped:     0,
+		importStatusFailed:      0,
+	}
+	for _, result := range results {
+		summary[result.Status]++
+	}
+	return summary
+}
+
+// printImportResults prints a table of import outcomes followed by a summary line
+func printImportResults(results []importAllResult, rangeStart, rangeEnd int) {
+	if len(results) == 0 {
+		fmt.Printf("No development servers found in port range %d-%d\n", rangeStart, rangeEnd)
+		return
+	}
+
+	fmt.Printf("%-8s %-6s %-13s %-s\n", "PID", "PORT", "STATUS", "COMMAND / REASON")
+	fmt.Println("------------------------------------------------------------------------")
+	for _, result := range results {
+		portStr := "-"
+		if result.Port > 0 {
+			portStr = strconv.Itoa(result.Port)
+		}
+		fmt.Printf("%-8d %-6s %-13s %-s\n", result.PID, portStr, result.Status, result.Command)
+		if result.Reason != "" {
+			fmt.Printf("%-29s reason: %s\n", "", result.Reason)
+		}
+	}
+
+	summary := summarizeImportResults(results)
+	fmt.Println()
+	if dryRun {
+		fmt.Printf("Dry run: %d would be imported, %d skipped\n",
+			summary[importStatusWouldImport], summary[importStatusSkipped])
+		return
+	}
+	fmt.Printf("Imported %d, skipped %d, failed %d\n",
+		summary[importStatusImported], summary[importStatusSkipped], summary[importStatusFailed])
 }
 
-func importProcessByPID(pid int) error {
-	// Load configuration
-	cfg, err := config.Load()
+// reportImport prints the outcome of importing subject. In JSON mode a successful import prints
+// the managed process and a rejected one prints its adoption evaluation with the reason.
+func reportImport(out *OutputHandler, subject string, managedProcess *process.ManagedProcess, err error) {
+	var notSuitable *process.NotSuitableError
+	switch {
+	case err == nil && out.JSONOutput:
+		_ = out.PrintJSON(managedProcess) //nolint:errcheck // Nothing left to report a marshal error to
+	case err == nil:
+		fmt.Printf("Successfully imported %s\n", subject)
+	case out.JSONOutput && errors.As(err, &notSuitable):
+		_ = out.PrintJSON(notSuitable.Info) //nolint:errcheck // Nothing left to report a marshal error to
+	default:
+		out.PrintError("failed to import "+subject, err)
+	}
+}
+
+func importProcessByPort(port int) (*process.ManagedProcess, error) {
+	return importAdoptedProcess(func(adopter *process.ProcessAdopter) (*process.ManagedProcess, error) {
+		return adopter.AdoptProcessByPort(port)
+	})
+}
+
+func importProcessByPID(pid int) (*process.ManagedProcess, error) {
+	return importAdoptedProcess(func(adopter *process.ProcessAdopter) (*process.ManagedProcess, error) {
+		return adopter.AdoptProcessByPID(pid)
+	})
+}
+
+func importProcessByPIDFile(path string) (*process.ManagedProcess, error) {
+	return importAdoptedProcess(func(adopter *process.ProcessAdopter) (*process.ManagedProcess, error) {
+		return adopter.AdoptProcessByPIDFile(path)
+	})
+}
+
+// importAdoptedProcess adopts a process with the given strategy, adds it to management and returns it
+func importAdoptedProcess(adopt func(*process.ProcessAdopter) (*process.ManagedProcess, error)) (*process.ManagedProcess, error) {
+	cfg, err := currentConfig()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return nil, err
 	}
 
 	// Create process adopter
-	adopter := process.NewProcessAdopter(30 * time.Second)
+	adopter, err := newImportAdopter(cfg)
+	if err != nil {
+		return nil, err
+	}
 
-	// Adopt process by PID
-	managedProcess, err := adopter.AdoptProcessByPID(pid)
+	managedProcess, err := adopt(adopter)
 	if err != nil {
-		return fmt.Errorf("failed to adopt process: %w", err)
+		return nil, fmt.Errorf("failed to adopt process: %w", err)
 	}
 
 	// Create process manager to save the adopted process
 	stateStore, lockManager, portScanner, err := createManagementComponents(cfg)
 	if err != nil {
-		return fmt.Errorf("failed to create management components: %w", err)
+		return nil, fmt.Errorf("failed to create management components: %w", err)
 	}
 
 	processManager := process.NewProcessManager(stateStore, lockManager, portScanner)
+	trackProcessManager(processManager)
 
 	// Add the adopted process to management
+	tailerPID := attachOutputIfRequested(managedProcess)
 	if err := addAdoptedProcess(processManager, managedProcess); err != nil {
-		return fmt.Errorf("failed to add adopted process to management: %w", err)
+		stopOutputTailer(tailerPID)
+		return nil, fmt.Errorf("failed to add adopted process to management: %w", err)
 	}
 
-	return nil
+	return managedProcess, nil
+}
+
+// newImportAdopter creates the adopter used by import and discover. With --match-env only
+// processes whose environment meets every predicate are suitable.
+func newImportAdopter(cfg *config.Config) (*process.ProcessAdopter, error) {
+	filters := make([]process.EnvPredicate, 0, len(matchEnv))
+	for _, expr := range matchEnv {
+		predicate, err := process.ParseEnvPredicate(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --match-env: %w", err)
+		}
+		filters = append(filters, predicate)
+	}
+
+	adopter := process.NewProcessAdopter(effectiveScanTimeout(cfg, 30*time.Second))
+	adopter.SetEnvFilters(filters)
+	return adopter, nil
 }
 
 func addAdoptedProcess(processManager *process.ProcessManager, managedProcess *process.ManagedProcess) error {
@@ -142,39 +444,28 @@ func addAdoptedProcess(processManager *process.ProcessManager, managedProcess *p
 
 // createManagementComponents creates the necessary components for process management
 func createManagementComponents(cfg *config.Config) (process.StateStore, process.LockManager, process.PortScanner, error) {
-	// Get or create portguard directory
-	portguardDir, err := getPortguardDir()
+	// Initialize port scanner
+	portScanner, err := newPortScanner(cfg, 5*time.Second)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to get portguard directory: %w", err)
+		return nil, nil, nil, err
 	}
 
-	// Initialize state store
-	stateFile := filepath.Join(portguardDir, "state.json")
-	stateStore, err := state.NewJSONStore(stateFile)
+	stateStore, lockManager, err := newStateComponents(cfg)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to create state store: %w", err)
+		return nil, nil, nil, err
 	}
 
-	// Initialize lock manager
-	lockFile := filepath.Join(portguardDir, "portguard.lock")
-	lockManager := lock.NewFileLock(lockFile, 5*time.Second)
-
-	// Initialize port scanner
-	portScanner := portpkg.NewScanner(5 * time.Second)
-
 	return stateStore, lockManager, portScanner, nil
 }
 
 // getPortguardDir gets or creates the portguard directory
 func getPortguardDir() (string, error) {
-	// Get home directory for state file
-	homeDir, err := os.UserHomeDir()
+	portguardDir, err := portguardDirPath()
 	if err != nil {
-		return "", fmt.Errorf("failed to get user home directory: %w", err)
+		return "", err
 	}
 
 	// Create .portguard directory if it doesn't exist
-	portguardDir := filepath.Join(homeDir, ".portguard")
 	if mkdirErr := os.MkdirAll(portguardDir, 0o755); mkdirErr != nil {
 		return "", fmt.Errorf("failed to create portguard directory: %w", mkdirErr)
 	}
@@ -182,14 +473,36 @@ func getPortguardDir() (string, error) {
 	return portguardDir, nil
 }
 
+// portguardDirPath returns the portguard directory without creating it
+func portguardDirPath() (string, error) {
+	// Get home directory for state file
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".portguard"), nil
+}
+
 func init() {
 	rootCmd.AddCommand(importCmd)
 	importCmd.AddCommand(importPortCmd)
 	importCmd.AddCommand(importPidCmd)
+	importCmd.AddCommand(importPidfileCmd)
+	importCmd.AddCommand(importAllCmd)
+	importCmd.AddCommand(importTailLogCmd)
 
 	// Add flags
 	importCmd.PersistentFlags().StringVar(&processName, "name", "", "custom name for the imported process")
 	importCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "output in JSON format")
+	importCmd.PersistentFlags().StringArrayVar(&matchEnv, "match-env", nil,
+		"only adopt processes whose environment matches KEY=VALUE, KEY!=VALUE or KEY (repeatable)")
+	importCmd.PersistentFlags().BoolVar(&attachLog, "attach-log", false, "capture the process's output in a log file under ~/.portguard/logs (Linux)")
+	importAllCmd.Flags().StringVar(&portRange, "range", "", "port range to scan (e.g., '3000-9000')")
+	importAllCmd.Flags().BoolVar(&dryRun, "dry-run", false, "list candidates without importing them")
 }
 
-var processName string
+var (
+	processName string
+	attachLog   bool
+	matchEnv    []string
+)
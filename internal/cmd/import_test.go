@@ -1,7 +1,14 @@
 package cmd
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
 	"testing"
 
 	"github.com/paveg/portguard/internal/config"
@@ -19,19 +26,19 @@ func TestImportCommand(t *testing.T) {
 	_ = os.Setenv("HOME", tempDir)
 
 	t.Run("import_by_port_not_found", func(t *testing.T) {
-		err := importProcessByPort(99999) // Use a very high port that should be available
+		_, err := importProcessByPort(99999) // Use a very high port that should be available
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "port")
 	})
 
 	t.Run("import_by_invalid_pid", func(t *testing.T) {
-		err := importProcessByPID(-1)
+		_, err := importProcessByPID(-1)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "invalid PID")
 	})
 
 	t.Run("import_by_nonexistent_pid", func(t *testing.T) {
-		err := importProcessByPID(99999) // Use a very high PID that should not exist
+		_, err := importProcessByPID(99999) // Use a very high PID that should not exist
 		assert.Error(t, err)
 	})
 }
@@ -90,6 +97,7 @@ func TestAddAdoptedProcess(t *testing.T) {
 
 		managedProcess := &process.ManagedProcess{
 			ID:         "test-process-add",
+			Command:    "npm run dev",
 			PID:        12345,
 			Status:     process.StatusRunning,
 			IsExternal: true,
@@ -125,3 +133,184 @@ func TestAddAdoptedProcess(t *testing.T) {
 		assert.Contains(t, err.Error(), "cannot adopt nil process")
 	})
 }
+
+func TestImportDiscoveredProcesses(t *testing.T) {
+	discovered := []*process.AdoptionInfo{
+		{PID: 100, Port: 3000, Command: "npm run dev", IsSuitable: true},
+		{PID: 200, Port: 5432, Command: "postgres", IsSuitable: false, Reason: "not a recognized development server"},
+		{PID: 300, Port: 8080, Command: "go run main.go", IsSuitable: true},
+	}
+
+	t.Run("adopts_suitable_processes", func(t *testing.T) {
+		var adopted []int
+		results := importDiscoveredProcesses(discovered, func(info *process.AdoptionInfo) error {
+			if info.PID == 300 {
+				return errors.New("permission denied")
+			}
+			adopted = append(adopted, info.PID)
+			return nil
+		})
+
+		require.Len(t, results, 3)
+		assert.Equal(t, []int{100}, adopted)
+		assert.Equal(t, importStatusImported, results[0].Status)
+		assert.Equal(t, importStatusSkipped, results[1].Status)
+		assert.Equal(t, "not a recognized development server", results[1].Reason)
+		assert.Equal(t, importStatusFailed, results[2].Status)
+		assert.Equal(t, "permission denied", results[2].Reason)
+
+		summary := summarizeImportResults(results)
+		assert.Equal(t, 1, summary[importStatusImported])
+		assert.Equal(t, 1, summary[importStatusSkipped])
+		assert.Equal(t, 1, summary[importStatusFailed])
+	})
+
+	t.Run("dry_run_lists_candidates", func(t *testing.T) {
+		results := importDiscoveredProcesses(discovered, nil)
+
+		require.Len(t, results, 3)
+		assert.Equal(t, importStatusWouldImport, results[0].Status)
+		assert.Equal(t, importStatusSkipped, results[1].Status)
+		assert.Equal(t, importStatusWouldImport, results[2].Status)
+	})
+
+	t.Run("command_is_registered", func(t *testing.T) {
+		require.NotNil(t, importAllCmd.Flags().Lookup("range"))
+		require.NotNil(t, importAllCmd.Flags().Lookup("dry-run"))
+		assert.Equal(t, importCmd, importAllCmd.Parent())
+	})
+}
+
+func TestReportImport(t *testing.T) {
+	decode := func(t *testing.T, output string) map[string]interface{} {
+		t.Helper()
+		var payload map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(output), &payload), output)
+		return payload
+	}
+
+	t.Run("success_json", func(t *testing.T) {
+		managed := &process.ManagedProcess{ID: "node-3000", Command: "node server.js", Port: 3000, PID: 4321, Status: process.StatusRunning}
+		payload := decode(t, captureOutput(func() {
+			reportImport(NewOutputHandler(true), "process on port 3000", managed, nil)
+		}))
+
+		assert.Equal(t, "node-3000", payload["id"])
+		assert.Equal(t, "node server.js", payload["command"])
+		assert.InDelta(t, 3000, payload["port"], 0)
+		assert.InDelta(t, 4321, payload["pid"], 0)
+		assert.Equal(t, string(process.StatusRunning), payload["status"])
+	})
+
+	t.Run("not_suitable_json", func(t *testing.T) {
+		err := fmt.Errorf("failed to adopt process: %w", &process.NotSuitableError{Info: &process.AdoptionInfo{
+			PID:         1,
+			ProcessName: "launchd",
+			Command:     "/sbin/launchd",
+			Port:        3000,
+			Reason:      "system process",
+		}})
+		require.ErrorIs(t, err, process.ErrProcessNotSuitable)
+
+		payload := decode(t, captureOutput(func() {
+			reportImport(NewOutputHandler(true), "process on port 3000", nil, err)
+		}))
+
+		assert.Equal(t, map[string]interface{}{
+			"pid":          float64(1),
+			"process_name": "launchd",
+			"command":      "/sbin/launchd",
+			"port":         float64(3000),
+			"is_suitable":  false,
+			"reason":       "system process",
+		}, payload)
+	})
+
+	t.Run("failure_json", func(t *testing.T) {
+		payload := decode(t, captureOutput(func() {
+			reportImport(NewOutputHandler(true), "process with PID 42", nil, process.ErrProcessNotFound)
+		}))
+
+		assert.Equal(t, true, payload["error"])
+		assert.Equal(t, "failed to import process with PID 42", payload["message"])
+		assert.Equal(t, process.ErrProcessNotFound.Error(), payload["details"])
+	})
+
+	t.Run("text", func(t *testing.T) {
+		output := captureOutput(func() {
+			reportImport(NewOutputHandler(false), "process on port 3000", &process.ManagedProcess{}, nil)
+		})
+		assert.Equal(t, "Successfully imported process on port 3000\n", output)
+	})
+}
+
+// recordingExecutor records the specs it is asked to start without running anything
+type recordingExecutor struct {
+	refusingExecutor
+	specs []process.ExecSpec
+}
+
+func (e *recordingExecutor) Start(spec process.ExecSpec) (int, error) {
+	e.specs = append(e.specs, spec)
+	return 4242, nil
+}
+
+func TestAttachAdoptedOutput(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	executor := &recordingExecutor{}
+	originalExecutor := newTailerExecutor
+	newTailerExecutor = func() process.Executor { return executor }
+	defer func() { newTailerExecutor = originalExecutor }()
+
+	startChild := func(t *testing.T, stdout *os.File) int {
+		t.Helper()
+		child := exec.Command("sleep", "5")
+		if stdout != nil {
+			child.Stdout = stdout
+		}
+		require.NoError(t, child.Start())
+		t.Cleanup(func() {
+			_ = child.Process.Kill() //nolint:errcheck // Test cleanup
+			_ = child.Wait()         //nolint:errcheck // Test cleanup
+		})
+		return child.Process.Pid
+	}
+
+	t.Run("attachable", func(t *testing.T) {
+		if runtime.GOOS != "linux" {
+			t.Skip("attaching to a running process's output requires Linux")
+		}
+		output, err := os.Create(filepath.Join(t.TempDir(), "app.out"))
+		require.NoError(t, err)
+		defer func() { _ = output.Close() }() //nolint:errcheck // Test cleanup
+		pid := startChild(t, output)
+
+		managedProcess := &process.ManagedProcess{PID: pid, IsExternal: true}
+		tailerPID := attachAdoptedOutput(managedProcess)
+
+		assert.Equal(t, 4242, tailerPID)
+		assert.Empty(t, managedProcess.LogAttachError)
+		expectedLog := filepath.Join(home, ".portguard", "logs", fmt.Sprintf("adopted-%d.log", pid))
+		assert.Equal(t, expectedLog, managedProcess.LogFile)
+
+		require.Len(t, executor.specs, 1)
+		spec := executor.specs[0]
+		assert.True(t, spec.Detached, "the helper must outlive the import command")
+		assert.Equal(t, []string{"import", "tail-log", strconv.Itoa(pid), expectedLog, fmt.Sprintf("/proc/%d/fd/1", pid)}, spec.Args)
+	})
+
+	t.Run("not_attachable", func(t *testing.T) {
+		executor.specs = nil
+		pid := startChild(t, nil) // stdout is the null device
+
+		managedProcess := &process.ManagedProcess{PID: pid, IsExternal: true}
+		tailerPID := attachAdoptedOutput(managedProcess)
+
+		assert.Zero(t, tailerPID)
+		assert.Empty(t, managedProcess.LogFile)
+		assert.Contains(t, managedProcess.LogAttachError, process.ErrOutputNotAttachable.Error())
+		assert.Empty(t, executor.specs)
+	})
+}
@@ -1,20 +1,23 @@
 package cmd
 
 import (
-	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/paveg/portguard/internal/lock"
-	portscanner "github.com/paveg/portguard/internal/port"
+	"github.com/paveg/portguard/internal/config"
 	"github.com/paveg/portguard/internal/process"
-	"github.com/paveg/portguard/internal/state"
 	"github.com/spf13/cobra"
 )
 
@@ -23,6 +26,9 @@ var (
 	ErrUnknownEvent = errors.New("unknown event type")
 )
 
+// maxPortNumber is the highest valid TCP/UDP port
+const maxPortNumber = 65535
+
 // ProcessManagerFactory can be overridden in tests
 // Ensure thread-safe access for concurrent test execution
 var (
@@ -30,8 +36,8 @@ var (
 	processManagerFactory   = createDefaultProcessManager
 )
 
-// ProcessManagerFactory returns the current factory function thread-safely
-func ProcessManagerFactory() *process.ProcessManager {
+// ProcessManagerFactory creates a process manager with the current factory function thread-safely
+func ProcessManagerFactory() (*process.ProcessManager, error) {
 	processManagerFactoryMu.RLock()
 	factory := processManagerFactory
 	processManagerFactoryMu.RUnlock()
@@ -40,6 +46,11 @@ func ProcessManagerFactory() *process.ProcessManager {
 
 // SetProcessManagerFactory sets the factory function thread-safely (for tests)
 func SetProcessManagerFactory(factory func() *process.ProcessManager) func() {
+	return setProcessManagerFactory(func() (*process.ProcessManager, error) { return factory(), nil })
+}
+
+// setProcessManagerFactory sets a factory function that may fail
+func setProcessManagerFactory(factory func() (*process.ProcessManager, error)) func() {
 	processManagerFactoryMu.Lock()
 	original := processManagerFactory
 	processManagerFactory = factory
@@ -87,41 +98,90 @@ type PostToolUseResponse struct {
 	Data    map[string]interface{} `json:"data,omitempty"`
 }
 
+// SuggestionAction identifies what a suggestion asks the caller to do
+type SuggestionAction string
+
+// Suggestion actions emitted in hook responses
+const (
+	SuggestionReuse      SuggestionAction = "reuse"       // Use the server that is already running
+	SuggestionStop       SuggestionAction = "stop"        // Stop the process holding the port
+	SuggestionChangePort SuggestionAction = "change_port" // Start on a different port
+	SuggestionList       SuggestionAction = "list"        // Review all managed processes
+	SuggestionImport     SuggestionAction = "import"      // Bring the existing process under management
+	SuggestionProceed    SuggestionAction = "proceed"     // Start anyway
+	SuggestionInspect    SuggestionAction = "inspect"     // Look at what holds the port
+)
+
+// Suggestion is a machine-actionable next step returned in hook response data
+type Suggestion struct {
+	Action      SuggestionAction `json:"action"`
+	Command     string           `json:"command,omitempty"` // portguard command that performs the action, if any
+	Description string           `json:"description"`       // Human-readable text for display
+}
+
 var interceptCmd = &cobra.Command{
 	Use:   "intercept",
 	Short: "Claude Code hooks intercept with official format",
 	Long: `Process hook requests from Claude Code using the official JSON format.
 Fully compatible with the Claude Code hooks specification.`,
 	Run: func(_ *cobra.Command, args []string) {
-		var request InterceptRequest
+		restore := guardProtocolOutput()
+		defer restore()
 
-		// Read JSON from stdin
-		scanner := bufio.NewScanner(os.Stdin)
-		var jsonInput string
-		for scanner.Scan() {
-			jsonInput += scanner.Text()
-		}
+		// Merge user-defined server command patterns from configuration
+		loadCustomCommandPatterns()
 
-		if err := scanner.Err(); err != nil {
-			outputErrorResponse(err)
+		if interceptStream {
+			processInterceptStream(os.Stdin)
 			return
 		}
 
-		if err := json.Unmarshal([]byte(jsonInput), &request); err != nil {
-			outputErrorResponse(err)
+		processInterceptRequest(os.Stdin)
+	},
+}
+
+// processInterceptRequest decodes a single request, which may span multiple lines
+// and carry arbitrarily large tool output
+func processInterceptRequest(input io.Reader) {
+	var request InterceptRequest
+	if err := json.NewDecoder(input).Decode(&request); err != nil {
+		outputErrorResponse(err)
+		return
+	}
+
+	routeInterceptRequest(&request)
+}
+
+// interceptStream enables newline-delimited request processing
+var interceptStream bool
+
+// processInterceptStream decodes requests until EOF, writing one response line per request
+func processInterceptStream(input io.Reader) {
+	decoder := json.NewDecoder(input)
+	for {
+		var request InterceptRequest
+		if err := decoder.Decode(&request); err != nil {
+			if !errors.Is(err, io.EOF) {
+				// The decoder cannot resynchronize after a syntax error
+				outputErrorResponse(err)
+			}
 			return
 		}
 
-		// Route based on event type
-		switch request.Event {
-		case "preToolUse":
-			handlePreToolUse(&request)
-		case "postToolUse":
-			handlePostToolUse(&request)
-		default:
-			outputErrorResponse(fmt.Errorf("%w: %s", ErrUnknownEvent, request.Event))
-		}
-	},
+		routeInterceptRequest(&request)
+	}
+}
+
+// routeInterceptRequest dispatches a request to the handler for its event type
+func routeInterceptRequest(request *InterceptRequest) {
+	switch request.Event {
+	case "preToolUse":
+		handlePreToolUse(request)
+	case "postToolUse":
+		handlePostToolUse(request)
+	default:
+		outputErrorResponse(fmt.Errorf("%w: %s", ErrUnknownEvent, request.Event))
+	}
 }
 
 func handlePreToolUse(request *InterceptRequest) {
@@ -152,6 +212,13 @@ func handlePreToolUse(request *InterceptRequest) {
 		return
 	}
 
+	// Commands excluded by the configured allow and deny lists always proceed untouched
+	if !interceptPermits(command) {
+		response.Message = "Command excluded from intercept"
+		outputJSON(response)
+		return
+	}
+
 	// Check if it's a server command
 	if !isServerCommand(command) {
 		response.Message = "Not a server command"
@@ -162,27 +229,51 @@ func handlePreToolUse(request *InterceptRequest) {
 	// Extract port and create process manager
 	//nolint:govet // TODO: Rename variable to avoid shadowing (e.g., detectedPort)
 	port := extractPort(command)
-	pm := ProcessManagerFactory()
+	pm, err := ProcessManagerFactory()
+	if err != nil {
+		// Without the managed-process state a conflict check would be guesswork
+		fmt.Fprintf(os.Stderr, "Warning: %v; command not checked\n", err)
+		response.Message = "Command not checked: portguard state unavailable"
+		response.Data["error"] = err.Error()
+		outputJSON(response)
+		return
+	}
 
-	// Check for conflicts with managed processes
-	if existing := checkForConflict(pm, command, port); existing != nil {
+	// Check for conflicts with managed and external processes
+	decision := pm.DecideStart(command, port)
+	switch decision.Kind {
+	case process.DecisionReuse:
+		existing := decision.Process
 		response.Proceed = false
-		response.Message = fmt.Sprintf("Port %d already in use by: %s", existing.Port, existing.Command)
-		response.Data["existing_process"] = map[string]interface{}{
-			"id":      existing.ID,
-			"command": existing.Command,
-			"port":    existing.Port,
-			"status":  existing.Status,
+		response.Message = fmt.Sprintf("Same server is already running as managed process %s: %s", existing.ID, existing.Command)
+		response.Data["existing_process"] = describeManagedProcess(existing)
+		describeOccupant(response.Data, existing, existing.IsHealthy())
+		response.Data["suggestions"] = []Suggestion{
+			{Action: SuggestionReuse, Description: "Reuse the running server instead of starting another one"},
+			stopSuggestion(existing.ID, "Stop it before restarting"),
+			listSuggestion(),
 		}
-		response.Data["suggestions"] = []string{
-			"Use 'portguard stop' to stop the existing process",
-			"Choose a different port",
-			"Check 'portguard list' for all processes",
+	case process.DecisionConflictManaged:
+		existing := decision.Process
+		response.Proceed = false
+		response.Message = fmt.Sprintf("Port %d already in use by managed process %s: %s", decision.Port, existing.ID, existing.Command)
+		response.Data["existing_process"] = describeManagedProcess(existing)
+		response.Data["conflict"] = pm.PortConflict(decision)
+		describeOccupant(response.Data, existing, false)
+		response.Data["suggestions"] = []Suggestion{
+			stopSuggestion(existing.ID, "Stop the existing process"),
+			{Action: SuggestionChangePort, Description: "Choose a different port"},
+			listSuggestion(),
 		}
-	} else {
+	case process.DecisionConflictExternal, process.DecisionStartNew:
 		// Check for existing unmanaged processes that could be imported
 		if port > 0 {
-			if adoptableInfo := checkForAdoptableProcess(port); adoptableInfo != nil {
+			adoptableInfo, adoptErr := checkForAdoptableProcess(port)
+			if adoptErr != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v; not checking for an importable process\n", adoptErr)
+			}
+			if adoptableInfo != nil {
+				describeOccupant(response.Data, nil, false)
 				response.Data["adoptable_process"] = map[string]interface{}{
 					"pid":          adoptableInfo.PID,
 					"process_name": adoptableInfo.ProcessName,
@@ -194,13 +285,46 @@ func handlePreToolUse(request *InterceptRequest) {
 
 				if adoptableInfo.IsSuitable {
 					response.Message = fmt.Sprintf("Found existing process on port %d that could be imported", port)
-					response.Data["suggestions"] = []string{
-						fmt.Sprintf("Use 'portguard import port %d' to import the existing process", port),
-						"Or proceed to start a new process (may cause conflicts)",
+					response.Data["suggestions"] = []Suggestion{
+						{
+							Action:      SuggestionImport,
+							Command:     fmt.Sprintf("portguard import port %d", port),
+							Description: "Import the existing process",
+						},
+						{Action: SuggestionProceed, Description: "Proceed to start a new process (may cause conflicts)"},
 					}
 				} else {
 					response.Message = fmt.Sprintf("Found process on port %d, but not suitable for import: %s", port, adoptableInfo.Reason)
 				}
+			} else if decision.Kind == process.DecisionConflictExternal {
+				conflict := pm.PortConflict(decision)
+				response.Message = fmt.Sprintf("Port %d is in use by a process not managed by portguard", decision.Port)
+				if conflict.PID > 0 {
+					holder := fmt.Sprintf("PID %d", conflict.PID)
+					if conflict.ProcessName != "" {
+						holder = conflict.ProcessName + ", " + holder
+					}
+					response.Message = fmt.Sprintf("Port %d is in use by a process not managed by portguard (%s)", decision.Port, holder)
+				}
+				response.Data["detected_port"] = port
+				response.Data["conflict"] = conflict
+				describeOccupant(response.Data, nil, false)
+				response.Data["suggestions"] = []Suggestion{
+					{Action: SuggestionChangePort, Description: "Choose a different port"},
+					{
+						Action:      SuggestionInspect,
+						Command:     fmt.Sprintf("portguard check %d", decision.Port),
+						Description: "Inspect what is using the port",
+					},
+				}
+			} else if pm.PortNeedsPrivileges(port) {
+				response.Message = fmt.Sprintf("Port %d requires elevated privileges; the server will likely fail to bind as the current user", port)
+				response.Data["detected_port"] = port
+				response.Data["privileged_port"] = true
+				response.Data["suggestions"] = []Suggestion{
+					{Action: SuggestionChangePort, Description: "Choose a port of 1024 or higher"},
+					{Action: SuggestionProceed, Description: "Proceed if the server runs with elevated privileges"},
+				}
 			} else {
 				response.Message = "Server command allowed, no conflicts detected"
 				response.Data["detected_port"] = port
@@ -213,6 +337,74 @@ func handlePreToolUse(request *InterceptRequest) {
 	outputJSON(response)
 }
 
+// registrationWindow is how long a server registration suppresses identical ones, e.g. from an
+// agent retrying a command that already started the server
+const registrationWindow = 5 * time.Second
+
+// recentRegistrations coalesces postToolUse registrations within registrationWindow
+var recentRegistrations = newRegistrationCoalescer("", registrationWindow)
+
+// registrationCoalescer remembers recently registered keys for a time window. Every hook call
+// runs in its own portguard process, so keys are recorded as marker files that all of them see.
+type registrationCoalescer struct {
+	dir    string // Marker directory; empty uses "registrations" in the portguard directory
+	window time.Duration
+}
+
+func newRegistrationCoalescer(dir string, window time.Duration) *registrationCoalescer {
+	return &registrationCoalescer{dir: dir, window: window}
+}
+
+// claim reports whether key was not registered within the window, recording it if so.
+// When no marker can be written the registration goes ahead; StartProcess still reuses
+// a server that is already registered.
+func (rc *registrationCoalescer) claim(key string) bool {
+	dir := rc.dir
+	if dir == "" {
+		portguardDir, err := getPortguardDir()
+		if err != nil {
+			return true
+		}
+		dir = filepath.Join(portguardDir, "registrations")
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return true
+	}
+	rc.expire(dir)
+
+	// Creating the marker exclusively lets exactly one concurrent caller claim the key
+	sum := sha256.Sum256([]byte(key))
+	marker, err := os.OpenFile(filepath.Join(dir, hex.EncodeToString(sum[:8])), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return !errors.Is(err, fs.ErrExist)
+	}
+	_ = marker.Close() //nolint:errcheck // Empty marker file
+	return true
+}
+
+// expire removes the markers of registrations older than the window
+func (rc *registrationCoalescer) expire(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if info, err := entry.Info(); err == nil && time.Since(info.ModTime()) >= rc.window {
+			_ = os.Remove(filepath.Join(dir, entry.Name())) //nolint:errcheck // Another caller may have removed it
+		}
+	}
+}
+
+// stopSuggestion suggests stopping a managed process
+func stopSuggestion(id, description string) Suggestion {
+	return Suggestion{Action: SuggestionStop, Command: "portguard stop " + id, Description: description}
+}
+
+// listSuggestion suggests reviewing all managed processes
+func listSuggestion() Suggestion {
+	return Suggestion{Action: SuggestionList, Command: "portguard list", Description: "Check all managed processes"}
+}
+
 func handlePostToolUse(request *InterceptRequest) {
 	response := PostToolUseResponse{
 		Status:  "success",
@@ -236,7 +428,7 @@ func handlePostToolUse(request *InterceptRequest) {
 
 	// Extract command
 	command, ok := request.Parameters["command"].(string)
-	if !ok || !isServerCommand(command) {
+	if !ok || !interceptPermits(command) || !isServerCommand(command) {
 		outputJSON(response)
 		return
 	}
@@ -244,9 +436,30 @@ func handlePostToolUse(request *InterceptRequest) {
 	// Check if server started successfully
 	//nolint:govet // TODO: Rename variable to avoid shadowing (e.g., outputPort)
 	if port := extractPortFromOutput(request.Result.Output); port > 0 {
+		response.Data["port"] = port
+
+		// Registering without the managed-process state would start an untracked server. This is
+		// checked before claiming the registration, so a retry is not reported as registered.
+		pm, err := ProcessManagerFactory()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v; server not registered\n", err)
+			response.Status = "error"
+			response.Message = fmt.Sprintf("Server on port %d not registered: portguard state unavailable", port)
+			response.Data["error"] = err.Error()
+			outputJSON(response)
+			return
+		}
+
+		// A quick retry reports the same server; registering it twice would race in StartProcess
+		if !recentRegistrations.claim(fmt.Sprintf("%s|%d", process.CommandSignature(command, nil), port)) {
+			response.Message = fmt.Sprintf("Server on port %d was already registered moments ago", port)
+			response.Data["coalesced"] = true
+			outputJSON(response)
+			return
+		}
+
 		// Register the process (async to not block)
 		go func() {
-			pm := ProcessManagerFactory()
 			_, _ = pm.StartProcess(command, []string{}, process.StartOptions{
 				Port:       port,
 				WorkingDir: request.WorkingDir,
@@ -255,58 +468,193 @@ func handlePostToolUse(request *InterceptRequest) {
 		}()
 
 		response.Message = fmt.Sprintf("Server registered on port %d", port)
-		response.Data["port"] = port
 	}
 
 	outputJSON(response)
 }
 
-func isServerCommand(command string) bool {
-	patterns := []string{
-		// Node.js patterns
-		"npm run dev", "npm start", "yarn dev", "pnpm dev", "pnpm run dev",
-		"node .*\\.js", "next dev", "vite", "webpack-dev-server",
+// builtinServerPatterns are the server command patterns recognized out of the box
+var builtinServerPatterns = mustCompilePatterns([]string{
+	// Node.js patterns
+	"npm run dev", "npm start", "yarn dev", "pnpm dev", "pnpm run dev",
+	"node .*\\.js", "next dev", "vite", "webpack-dev-server",
 
-		// Modern JavaScript tooling
-		"turbo run dev", "turbo dev", "nx serve", "nx dev",
-		"bun run dev", "bun dev", "deno run.*dev",
+	// Modern JavaScript tooling
+	"turbo run dev", "turbo dev", "nx serve", "nx dev",
+	"bun run dev", "bun dev", "deno run.*dev",
 
-		// Go patterns
-		"go run.*\\.go", "air", "gin", "realize start",
-		"go run main\\.go", "go run \\./cmd/.*",
+	// Go patterns
+	"go run.*\\.go", "air", "gin", "realize start",
+	"go run main\\.go", "go run \\./cmd/.*",
 
-		// Python patterns
-		"python.*-m http\\.server", "python3.*-m http\\.server",
-		"flask run", "python.*manage\\.py runserver", "uvicorn",
-		"gunicorn", "fastapi dev", "python.*-m flask run",
+	// Python patterns
+	"python.*-m http\\.server", "python3.*-m http\\.server",
+	"flask run", "python.*manage\\.py runserver", "uvicorn",
+	"gunicorn", "fastapi dev", "python.*-m flask run",
 
-		// Rust patterns
-		"cargo run", "cargo watch -x run", "trunk serve",
+	// Rust patterns
+	"cargo run", "cargo watch -x run", "trunk serve",
 
-		// Docker/Container patterns
-		"docker run.*-p \\d+", "docker-compose up", "podman run.*-p \\d+",
+	// Docker/Container patterns
+	"docker run.*-p \\d+", "docker-compose up", "podman run.*-p \\d+",
 
-		// Other server patterns
-		"hugo server", "jekyll serve", "php.*-S", "rails server",
-		"serve", "http-server", "live-server", "browser-sync start",
+	// Other server patterns
+	"hugo server", "jekyll serve", "php.*-S", "rails server",
+	"serve", "http-server", "live-server", "browser-sync start",
 
-		// Database servers
-		"mongodb", "postgres", "mysql", "redis-server",
+	// Database servers
+	"mongodb", "postgres", "mysql", "redis-server",
 
-		// Development proxy/tunneling
-		"ngrok http", "lt --port", "localtunnel",
+	// Development proxy/tunneling
+	"ngrok http", "lt --port", "localtunnel",
 
-		// Static site generators
-		"gatsby develop", "nuxt dev", "gridsome develop",
-		"eleventy --serve", "astro dev",
-	}
+	// Static site generators
+	"gatsby develop", "nuxt dev", "gridsome develop",
+	"eleventy --serve", "astro dev",
+})
 
+// Custom server and port patterns and intercept allow and deny lists loaded from configuration
+var (
+	customPatternsMu     sync.RWMutex
+	customServerPatterns []*regexp.Regexp
+	customPortPatterns   []portPattern
+	interceptAllow       []*regexp.Regexp
+	interceptDeny        []*regexp.Regexp
+	interceptDenyAll     bool // The lists could not be loaded, so no command is acted on
+)
+
+// portPattern associates a command pattern with its default port
+type portPattern struct {
+	pattern *regexp.Regexp
+	port    int
+}
+
+// mustCompilePatterns compiles builtin patterns, panicking on programmer error
+func mustCompilePatterns(patterns []string) []*regexp.Regexp {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
 	for _, pattern := range patterns {
-		matched, err := regexp.MatchString(pattern, command)
-		if err != nil {
-			continue // Skip invalid patterns
+		compiled = append(compiled, regexp.MustCompile(pattern))
+	}
+	return compiled
+}
+
+// setCustomCommandPatterns replaces the custom server and port patterns
+func setCustomCommandPatterns(serverPatterns []string, portPatterns []config.PortPattern) error {
+	compiledServer, err := config.CompilePatterns(serverPatterns)
+	if err != nil {
+		return fmt.Errorf("%w: %w", config.ErrInvalidServerPattern, err)
+	}
+
+	// Entries keep their configured order, so the first matching pattern wins
+	compiledPorts := make([]portPattern, 0, len(portPatterns))
+	for _, entry := range portPatterns {
+		re, compileErr := regexp.Compile(entry.Pattern)
+		if compileErr != nil {
+			return fmt.Errorf("%w: %q: %w", config.ErrInvalidPortPattern, entry.Pattern, compileErr)
 		}
-		if matched {
+		if entry.Port < 1 || entry.Port > maxPortNumber {
+			return fmt.Errorf("%w: %q (port: %d)", config.ErrInvalidPortPattern, entry.Pattern, entry.Port)
+		}
+		compiledPorts = append(compiledPorts, portPattern{pattern: re, port: entry.Port})
+	}
+
+	customPatternsMu.Lock()
+	customServerPatterns = compiledServer
+	customPortPatterns = compiledPorts
+	customPatternsMu.Unlock()
+	return nil
+}
+
+// setInterceptRules replaces the intercept allow and deny lists
+func setInterceptRules(allow, deny []string) error {
+	compiledAllow, err := config.CompilePatterns(allow)
+	if err != nil {
+		return fmt.Errorf("%w: %w", config.ErrInvalidInterceptRule, err)
+	}
+	compiledDeny, err := config.CompilePatterns(deny)
+	if err != nil {
+		return fmt.Errorf("%w: %w", config.ErrInvalidInterceptRule, err)
+	}
+
+	customPatternsMu.Lock()
+	interceptAllow = compiledAllow
+	interceptDeny = compiledDeny
+	interceptDenyAll = false
+	customPatternsMu.Unlock()
+	return nil
+}
+
+// denyAllIntercepts makes the hook leave every command alone until rules load successfully
+func denyAllIntercepts() {
+	customPatternsMu.Lock()
+	interceptDenyAll = true
+	customPatternsMu.Unlock()
+}
+
+// interceptPermits reports whether the hook may act on a command. A deny match always wins;
+// with an allowlist only matching commands are permitted, and without one everything is.
+// Nothing is permitted while the lists could not be loaded.
+func interceptPermits(command string) bool {
+	customPatternsMu.RLock()
+	defer customPatternsMu.RUnlock()
+
+	if interceptDenyAll {
+		return false
+	}
+	for _, re := range interceptDeny {
+		if re.MatchString(command) {
+			return false
+		}
+	}
+	if len(interceptAllow) == 0 {
+		return true
+	}
+	for _, re := range interceptAllow {
+		if re.MatchString(command) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadCustomCommandPatterns merges configured patterns into the matcher. Server and port
+// patterns and the intercept allow and deny lists load independently. Invalid server or port
+// patterns are reported on stderr and ignored; allow and deny lists that cannot be loaded make
+// the hook act on no command, since a denied command must never be touched.
+func loadCustomCommandPatterns() {
+	cfg, err := currentConfig()
+	if err != nil {
+		denyAllIntercepts()
+		fmt.Fprintf(os.Stderr, "Warning: %v; intercept acts on no command\n", err)
+		return
+	}
+
+	if err := setCustomCommandPatterns(cfg.Default.ServerPatterns, cfg.Default.PortPatterns); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring custom command patterns: %v\n", err)
+	}
+
+	var allow, deny []string
+	if intercept := cfg.Default.Intercept; intercept != nil {
+		allow, deny = intercept.Allow, intercept.Deny
+	}
+	if err := setInterceptRules(allow, deny); err != nil {
+		denyAllIntercepts()
+		fmt.Fprintf(os.Stderr, "Warning: %v; intercept acts on no command\n", err)
+	}
+}
+
+func isServerCommand(command string) bool {
+	_, command = splitEnvAssignments(command)
+	for _, re := range builtinServerPatterns {
+		if re.MatchString(command) {
+			return true
+		}
+	}
+
+	customPatternsMu.RLock()
+	defer customPatternsMu.RUnlock()
+	for _, re := range customServerPatterns {
+		if re.MatchString(command) {
 			return true
 		}
 	}
@@ -314,31 +662,137 @@ func isServerCommand(command string) bool {
 }
 
 func extractPort(command string) int {
+	env, command := splitEnvAssignments(command)
+
 	// First try to extract explicitly specified port
 	if explicitPort := extractExplicitPort(command); explicitPort > 0 {
 		return explicitPort
 	}
 
+	// Then try a port passed through the environment, e.g. PORT=4000 npm start
+	for _, name := range portEnvVars {
+		if envPort := parseValidPort(env[name]); envPort > 0 {
+			return envPort
+		}
+	}
+
+	// Then try ports declared for custom commands in configuration
+	if customPort := extractCustomPort(command); customPort > 0 {
+		return customPort
+	}
+
 	// Then try framework-specific default ports
 	return extractDefaultPort(command)
 }
 
+// extractCustomPort returns the default port for a command matching a configured port pattern
+func extractCustomPort(command string) int {
+	customPatternsMu.RLock()
+	defer customPatternsMu.RUnlock()
+
+	for _, pp := range customPortPatterns {
+		if pp.pattern.MatchString(command) {
+			return pp.port
+		}
+	}
+	return 0
+}
+
+// portEnvVars are environment variables commonly read by servers for their port, in priority order
+var portEnvVars = []string{"PORT", "SERVER_PORT"}
+
+// envAssignment matches one leading KEY=VALUE shell assignment and the whitespace after it
+var envAssignment = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)=("[^"]*"|'[^']*'|\S*)\s+`)
+
+// splitEnvAssignments separates leading KEY=VALUE assignments from the command they apply to
+func splitEnvAssignments(command string) (map[string]string, string) {
+	env := make(map[string]string)
+	command = strings.TrimSpace(command)
+	for {
+		matches := envAssignment.FindStringSubmatch(command)
+		if matches == nil {
+			return env, command
+		}
+		value := matches[2]
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') {
+			value = value[1 : len(value)-1]
+		}
+		env[matches[1]] = value
+		command = command[len(matches[0]):]
+	}
+}
+
+// Port extraction helpers
+var (
+	// hostPortToken matches a whole token of the form [scheme://][host]:port[/path]
+	hostPortToken = regexp.MustCompile(`^(?:([a-zA-Z][a-zA-Z0-9+.-]*)://)?([a-zA-Z0-9.-]*|\[[0-9a-fA-F:]*\]):(\d{1,5})(/\S*)?$`)
+	// leadingPortValue matches a flag value starting with a port, e.g. "8080" or "8080:80"
+	leadingPortValue = regexp.MustCompile(`^(\d{1,5})(?::\d{1,5})?$`)
+)
+
+// portFlags are command-line flags whose value carries the server port or address
+var portFlags = map[string]bool{
+	"--port": true,
+	"-p":     true,
+	"--addr": true,
+	"--bind": true,
+}
+
 func extractExplicitPort(command string) int {
-	portRegex := regexp.MustCompile(`--port[=\s]+(\d+)|:(\d+)|-p[=\s]+(\d+)|--addr[=\s]+.*:(\d+)`)
-	if matches := portRegex.FindStringSubmatch(command); len(matches) > 0 {
-		for i := 1; i < len(matches); i++ {
-			if matches[i] != "" {
-				//nolint:govet // TODO: Rename variable to avoid shadowing (e.g., extractedPort)
-				var port int
-				if _, err := fmt.Sscanf(matches[i], "%d", &port); err == nil && port > 0 {
-					return port
-				}
+	tokens := strings.Fields(command)
+	for i, token := range tokens {
+		// Flags such as --port 3000, --port=3000 or --addr localhost:3000
+		flag, value, hasValue := strings.Cut(token, "=")
+		if portFlags[flag] {
+			if !hasValue && i+1 < len(tokens) {
+				value = tokens[i+1]
+			}
+			if flagPort := parsePortValue(value); flagPort > 0 {
+				return flagPort
 			}
+			continue
+		}
+
+		// Bare host:port tokens such as :8080 or localhost:8080
+		if tokenPort := parseHostPortToken(token); tokenPort > 0 {
+			return tokenPort
 		}
 	}
 	return 0
 }
 
+// parsePortValue extracts a port from a port flag value
+func parsePortValue(value string) int {
+	if matches := leadingPortValue.FindStringSubmatch(value); matches != nil {
+		return parseValidPort(matches[1])
+	}
+	return parseHostPortToken(value)
+}
+
+// parseHostPortToken extracts the port from a [scheme://][host]:port token.
+// A trailing path is only accepted for URLs so that scp-style paths such as
+// git@host:22/repo or version strings such as node@18:3000 are not mistaken for ports.
+func parseHostPortToken(token string) int {
+	matches := hostPortToken.FindStringSubmatch(token)
+	if matches == nil {
+		return 0
+	}
+	scheme, path := matches[1], matches[4]
+	if path != "" && scheme == "" {
+		return 0
+	}
+	return parseValidPort(matches[3])
+}
+
+// parseValidPort converts a string to a port number, returning 0 if outside 1-65535
+func parseValidPort(value string) int {
+	portNum, err := strconv.Atoi(value)
+	if err != nil || portNum < 1 || portNum > maxPortNumber {
+		return 0
+	}
+	return portNum
+}
+
 func extractDefaultPort(command string) int {
 	// JavaScript/Node.js frameworks
 	if jsPort := extractJavaScriptFrameworkPort(command); jsPort > 0 {
@@ -468,131 +922,204 @@ func extractOtherFrameworkPort(command string) int {
 	return 0
 }
 
-func extractPortFromOutput(output string) int {
-	patterns := []string{
-		// Common server output patterns
-		`localhost:(\d+)`,
-		`127\.0\.0\.1:(\d+)`,
-		`0\.0\.0\.0:(\d+)`,
-		`listening on :(\d+)`,
-		`listening on port (\d+)`,
-		`port (\d+)`,
-		`https?://[^:]+:(\d+)`,
-		`serving at [^:]+:(\d+)`,
-		`server running on [^:]+:(\d+)`,
-
-		// Framework-specific patterns
-		`Local:.*:(\d+)`,                // Vite, Webpack Dev Server
-		`Network:.*:(\d+)`,              // Vite, Webpack Dev Server
-		`ready on [^:]*:(\d+)`,          // Next.js
-		`started server on [^:]*:(\d+)`, // Next.js
-		`local:.*localhost:(\d+)`,       // Gatsby
-		`on your network:.*:(\d+)`,      // Gatsby
-		`listening at [^:]+:(\d+)`,      // Express.js
-		`server started at [^:]+:(\d+)`, // Various frameworks
-
-		// Rust patterns
-		`listening on [^:]+:(\d+)`, // Actix, Warp
-		`serving on [^:]+:(\d+)`,   // Trunk
-
-		// Go patterns
-		`gin running on [^:]+:(\d+)`,           // Gin
-		`listening and serving on [^:]+:(\d+)`, // Go HTTP servers
-
-		// Python patterns
-		`running on [^:]+:(\d+)`,            // Flask
-		`development server at [^:]+:(\d+)`, // Django
-		`uvicorn running on [^:]+:(\d+)`,    // Uvicorn
-		`application startup complete`,      // FastAPI (followed by address)
-
-		// Database patterns
-		`listening on port (\d+)`,                   // PostgreSQL, MySQL
-		`server is ready on port (\d+)`,             // MongoDB
-		`ready to accept connections on port (\d+)`, // Redis
-
-		// Development tools
-		`proxy server listening on [^:]+:(\d+)`, // Browser Sync
-		`live reload enabled on port (\d+)`,     // Live Server
-		`forwarding [^:]+:(\d+)`,                // ngrok
-
-		// Container patterns
-		`exposed on.*:(\d+)`, // Docker
-		`mapped to.*:(\d+)`,  // Docker port mapping
-
-		// Generic patterns (should be last to avoid false positives)
-		`\*:(\d+)`,             // Wildcard binding
-		`bound to [^:]*:(\d+)`, // Generic binding message
+// outputPortPatterns recognize the listening port in server startup output.
+// Host segments stop at whitespace, colons and slashes so a port is never taken
+// from an unrelated URL path or a later line.
+var outputPortPatterns = compileOutputPatterns([]string{
+	// Common server output patterns
+	`localhost:(\d{1,5})\b`,
+	`127\.0\.0\.1:(\d{1,5})\b`,
+	`0\.0\.0\.0:(\d{1,5})\b`,
+	`listening on :(\d{1,5})\b`,
+	`listening on port (\d{1,5})\b`,
+	`port (\d{1,5})\b`,
+	`https?://[^\s:/]+:(\d{1,5})\b`,
+	`serving at [^\s:/]+:(\d{1,5})\b`,
+	`server running on [^\s:/]+:(\d{1,5})\b`,
+
+	// Framework-specific patterns
+	`Local:.*:(\d{1,5})\b`,                   // Vite, Webpack Dev Server
+	`Network:.*:(\d{1,5})\b`,                 // Vite, Webpack Dev Server
+	`ready on [^\s:/]*:(\d{1,5})\b`,          // Next.js
+	`started server on [^\s:/]*:(\d{1,5})\b`, // Next.js
+	`local:.*localhost:(\d{1,5})\b`,          // Gatsby
+	`on your network:.*:(\d{1,5})\b`,         // Gatsby
+	`listening at [^\s:/]+:(\d{1,5})\b`,      // Express.js
+	`server started at [^\s:/]+:(\d{1,5})\b`, // Various frameworks
+
+	// Rust patterns
+	`listening on [^\s:/]+:(\d{1,5})\b`, // Actix, Warp
+	`serving on [^\s:/]+:(\d{1,5})\b`,   // Trunk
+
+	// Go patterns
+	`gin running on [^\s:/]+:(\d{1,5})\b`,           // Gin
+	`listening and serving on [^\s:/]+:(\d{1,5})\b`, // Go HTTP servers
+
+	// Python patterns
+	`running on [^\s:/]+:(\d{1,5})\b`,            // Flask
+	`development server at [^\s:/]+:(\d{1,5})\b`, // Django
+	`uvicorn running on [^\s:/]+:(\d{1,5})\b`,    // Uvicorn
+	`application startup complete`,               // FastAPI (followed by address)
+
+	// Database patterns
+	`listening on port (\d{1,5})\b`,                   // PostgreSQL, MySQL
+	`server is ready on port (\d{1,5})\b`,             // MongoDB
+	`ready to accept connections on port (\d{1,5})\b`, // Redis
+
+	// Development tools
+	`proxy server listening on [^\s:/]+:(\d{1,5})\b`, // Browser Sync
+	`live reload enabled on port (\d{1,5})\b`,        // Live Server
+	`forwarding [^\s:/]+:(\d{1,5})\b`,                // ngrok
+
+	// Container patterns
+	`exposed on.*:(\d{1,5})\b`, // Docker
+	`mapped to.*:(\d{1,5})\b`,  // Docker port mapping
+
+	// Generic patterns (should be last to avoid false positives)
+	`\*:(\d{1,5})\b`,                // Wildcard binding
+	`bound to [^\s:/]*:(\d{1,5})\b`, // Generic binding message
+})
+
+// compileOutputPatterns compiles output patterns case-insensitively
+func compileOutputPatterns(patterns []string) []*regexp.Regexp {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		compiled = append(compiled, regexp.MustCompile("(?i)"+pattern))
 	}
+	return compiled
+}
 
-	for _, pattern := range patterns {
-		re := regexp.MustCompile("(?i)" + pattern) // Case insensitive
+func extractPortFromOutput(output string) int {
+	for _, re := range outputPortPatterns {
 		if matches := re.FindStringSubmatch(output); len(matches) > 1 {
-			//nolint:govet // TODO: Rename variable to avoid shadowing (e.g., parsedPort)
-			var port int
-			_, _ = fmt.Sscanf(matches[1], "%d", &port)
-			if port > 0 {
-				return port
+			if outputPort := parseValidPort(matches[1]); outputPort > 0 {
+				return outputPort
 			}
 		}
 	}
 	return 0
 }
 
-func createDefaultProcessManager() *process.ProcessManager {
-	stateStore, _ := state.NewJSONStore("~/.portguard/state.json")
-	lockManager := lock.NewFileLock("~/.portguard/portguard.lock", 5*time.Second)
-	//nolint:noctx // TODO: Add context support to port scanner for better timeout control
-	scanner := portscanner.NewScanner(2 * time.Second)
-	return process.NewProcessManager(stateStore, lockManager, scanner)
+// createDefaultProcessManager opens the same state and lock as the other commands. It fails
+// rather than falling back to empty state, which would hide managed servers and record nothing.
+func createDefaultProcessManager() (*process.ProcessManager, error) {
+	cfg, err := currentConfig()
+	if err != nil {
+		return nil, err
+	}
+	scanner, err := newPortScanner(cfg, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	stateStore, lockManager, err := newStateComponents(cfg)
+	if err != nil {
+		return nil, err
+	}
+	pm := process.NewProcessManager(stateStore, lockManager, scanner)
+	configureProcessManager(pm, cfg)
+	return pm, nil
 }
 
-func checkForConflict(pm *process.ProcessManager, command string, port int) *process.ManagedProcess {
-	processes := pm.ListProcesses(process.ProcessListOptions{
-		IncludeStopped: false,
-	})
+// describeManagedProcess summarizes a managed process for intercept responses
+func describeManagedProcess(proc *process.ManagedProcess) map[string]interface{} {
+	return map[string]interface{}{
+		"id":      proc.ID,
+		"command": proc.Command,
+		"port":    proc.Port,
+		"ports":   proc.AllPorts(),
+		"status":  proc.Status,
+	}
+}
 
-	for _, proc := range processes {
-		if proc.Command == command && proc.IsHealthy() {
-			return proc
-		}
-		if port > 0 && proc.Port == port && proc.IsRunning() {
-			return proc
-		}
+// describeOccupant tells the agent who holds the server's port: whether portguard manages it,
+// whether the running server can be reused instead of starting a duplicate, and the managed
+// process's health. managed is nil for processes portguard does not manage.
+func describeOccupant(data map[string]interface{}, managed *process.ManagedProcess, reusable bool) {
+	data["managed"] = managed != nil
+	data["reusable"] = managed != nil && reusable
+	if managed != nil {
+		data["health_status"] = managed.Status
 	}
-	return nil
 }
 
-// checkForAdoptableProcess checks if there's an existing process on the given port that could be adopted
-func checkForAdoptableProcess(port int) *process.AdoptionInfo {
+// checkForAdoptableProcess checks if there's an existing process on the given port that could be adopted.
+// Without a valid configuration the scan is skipped and the error returned.
+func checkForAdoptableProcess(port int) (*process.AdoptionInfo, error) {
+	cfg, err := currentConfig()
+	if err != nil {
+		return nil, err
+	}
+
 	// Create a process adopter to check for adoptable processes
-	adopter := process.NewProcessAdopter(5 * time.Second)
+	adopter := process.NewProcessAdopter(effectiveScanTimeout(cfg, 5*time.Second))
 
 	// Get the PID of the process using this port
-	pid := getProcessByPort(port)
+	pid, err := getProcessByPort(cfg, port)
+	if err != nil {
+		return nil, err
+	}
 	if pid <= 0 {
-		return nil // No process found on this port
+		return nil, nil // No process found on this port
 	}
 
 	// Try to get process info for the PID
 	if adoptionInfo, err := adopter.GetProcessInfo(pid); err == nil {
-		return adoptionInfo
+		return adoptionInfo, nil
 	}
 
-	return nil
+	return nil, nil
 }
 
-// getProcessByPort gets the PID of the process using the specified port
-func getProcessByPort(port int) int {
-	scanner := portscanner.NewScanner(2 * time.Second)
+// getProcessByPort gets the PID of the process using the specified port, or 0 if none is found
+func getProcessByPort(cfg *config.Config, port int) (int, error) {
+	scanner, err := newPortScanner(cfg, 2*time.Second)
+	if err != nil {
+		return 0, err
+	}
 	if portInfo, err := scanner.GetPortInfo(port); err == nil && portInfo.PID > 0 {
-		return portInfo.PID
+		return portInfo.PID, nil
 	}
-	return 0
+	return 0, nil
 }
 
+// protocolOutput is the real stdout while guardProtocolOutput is in effect, guarded by protocolOutputMu
+var (
+	protocolOutputMu sync.Mutex
+	protocolOutput   io.Writer
+)
+
+// guardProtocolOutput reserves stdout for hook responses until restore is called: os.Stdout is
+// pointed at stderr so stray diagnostics cannot corrupt the JSON channel, and outputJSON keeps
+// writing to the real stdout
+func guardProtocolOutput() (restore func()) {
+	protocolOutputMu.Lock()
+	stdout := os.Stdout
+	protocolOutput = stdout
+	os.Stdout = os.Stderr
+	protocolOutputMu.Unlock()
+
+	return func() {
+		protocolOutputMu.Lock()
+		os.Stdout = stdout
+		protocolOutput = nil
+		protocolOutputMu.Unlock()
+	}
+}
+
+// outputJSON writes a hook response, the only thing intercept writes to stdout
 func outputJSON(v interface{}) {
-	encoder := json.NewEncoder(os.Stdout)
-	encoder.SetIndent("", "  ")
+	protocolOutputMu.Lock()
+	defer protocolOutputMu.Unlock()
+
+	out := protocolOutput
+	if out == nil {
+		out = os.Stdout
+	}
+	encoder := json.NewEncoder(out)
+	if !interceptStream {
+		// Stream mode keeps each response on a single line
+		encoder.SetIndent("", "  ")
+	}
 	_ = encoder.Encode(v)
 }
 
@@ -606,4 +1133,6 @@ func outputErrorResponse(err error) {
 
 func init() {
 	rootCmd.AddCommand(interceptCmd)
+
+	interceptCmd.Flags().BoolVar(&interceptStream, "stream", false, "process newline-delimited JSON requests until EOF")
 }
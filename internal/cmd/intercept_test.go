@@ -4,14 +4,20 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/paveg/portguard/internal/config"
 	portpkg "github.com/paveg/portguard/internal/port"
 	"github.com/paveg/portguard/internal/process"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -40,6 +46,16 @@ func (m *mockStateStore) Delete(id string) error {
 	return args.Error(0)
 }
 
+func (m *mockStateStore) BackupState() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *mockStateStore) CleanupOldBackups(maxAge time.Duration) error {
+	args := m.Called(maxAge)
+	return args.Error(0)
+}
+
 type mockLockManager struct {
 	mock.Mock
 }
@@ -291,7 +307,278 @@ func TestInterceptCommand_PreToolUse(t *testing.T) {
 	}
 }
 
+func TestInterceptCommand_PreToolUse_ManagedConflict(t *testing.T) {
+	restoreFactory := SetProcessManagerFactory(func() *process.ProcessManager {
+		mockStore := &mockStateStore{}
+		mockLock := &mockLockManager{}
+		mockScanner := &mockPortScanner{}
+
+		mockStore.On("Load").Return(map[string]*process.ManagedProcess{
+			"abc12345": {
+				ID:      "abc12345",
+				Command: "npm run storybook",
+				Port:    3000,
+				Status:  process.StatusRunning,
+			},
+		}, nil)
+		mockScanner.On("IsPortInUse", 3000).Return(true)
+
+		return process.NewProcessManager(mockStore, mockLock, mockScanner)
+	})
+	defer restoreFactory()
+
+	request := createTestInterceptRequest("preToolUse", "Bash", createBashParameters("npm run dev -- --port 3000"), nil)
+	input, err := json.Marshal(request)
+	require.NoError(t, err)
+
+	output, err := executeInterceptCmd(t, string(input))
+	require.NoError(t, err)
+
+	var response PreToolUseResponse
+	require.NoError(t, json.Unmarshal([]byte(output), &response))
+
+	assert.False(t, response.Proceed)
+	assert.Contains(t, response.Message, "managed process abc12345")
+	assert.Contains(t, response.Data, "existing_process")
+	assert.Equal(t, true, response.Data["managed"])
+	assert.Equal(t, false, response.Data["reusable"], "a different command cannot reuse the port holder")
+	assert.Equal(t, "running", response.Data["health_status"])
+
+	suggestions := decodeSuggestions(t, response.Data)
+	require.Len(t, suggestions, 3)
+	assert.Equal(t, Suggestion{
+		Action:      SuggestionStop,
+		Command:     "portguard stop abc12345",
+		Description: "Stop the existing process",
+	}, suggestions[0])
+	assert.Equal(t, SuggestionChangePort, suggestions[1].Action)
+	assert.Empty(t, suggestions[1].Command)
+	assert.Equal(t, SuggestionList, suggestions[2].Action)
+	assert.Equal(t, "portguard list", suggestions[2].Command)
+}
+
+func TestInterceptCommand_PreToolUse_Occupant(t *testing.T) {
+	// A port nothing listens on, so the real adoption lookup finds no process behind it
+	const occupiedPort = 39517
+
+	tests := []struct {
+		name           string
+		managed        *process.ManagedProcess
+		expectManaged  bool
+		expectReusable bool
+		expectHealth   interface{}
+		expectMessage  string
+	}{
+		{
+			name: "healthy_managed_server_is_reusable",
+			managed: &process.ManagedProcess{
+				ID: "abc12345", Command: "npm run dev -- --port 39517", Port: occupiedPort, Status: process.StatusRunning,
+			},
+			expectManaged:  true,
+			expectReusable: true,
+			expectHealth:   "running",
+			expectMessage:  "already running as managed process abc12345",
+		},
+		{
+			name: "unhealthy_managed_server_is_not_reusable",
+			managed: &process.ManagedProcess{
+				ID: "abc12345", Command: "npm run dev -- --port 39517", Port: occupiedPort, Status: process.StatusUnhealthy,
+			},
+			expectManaged:  true,
+			expectReusable: false,
+			expectHealth:   "unhealthy",
+			expectMessage:  "already running as managed process abc12345",
+		},
+		{
+			name:           "external_occupant",
+			expectManaged:  false,
+			expectReusable: false,
+			expectMessage:  "not managed by portguard (python3, PID 4242)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restoreFactory := SetProcessManagerFactory(func() *process.ProcessManager {
+				mockStore := &mockStateStore{}
+				mockScanner := &mockPortScanner{}
+				stored := map[string]*process.ManagedProcess{}
+				if tt.managed != nil {
+					stored[tt.managed.ID] = tt.managed
+				}
+				mockStore.On("Load").Return(stored, nil)
+				mockScanner.On("IsPortInUse", occupiedPort).Return(true)
+				mockScanner.On("GetPortInfo", occupiedPort).Return(&portpkg.PortInfo{
+					Port: occupiedPort, PID: 4242, ProcessName: "python3", InUse: true,
+				}, nil)
+
+				return process.NewProcessManager(mockStore, &mockLockManager{}, mockScanner)
+			})
+			defer restoreFactory()
+
+			request := createTestInterceptRequest("preToolUse", "Bash", createBashParameters("npm run dev -- --port 39517"), nil)
+			input, err := json.Marshal(request)
+			require.NoError(t, err)
+
+			output, err := executeInterceptCmd(t, string(input))
+			require.NoError(t, err)
+
+			var response PreToolUseResponse
+			require.NoError(t, json.Unmarshal([]byte(output), &response))
+
+			assert.Contains(t, response.Message, tt.expectMessage)
+			assert.Equal(t, tt.expectManaged, response.Data["managed"])
+			assert.Equal(t, tt.expectReusable, response.Data["reusable"])
+			if tt.expectHealth != nil {
+				assert.Equal(t, tt.expectHealth, response.Data["health_status"])
+			} else {
+				assert.NotContains(t, response.Data, "health_status")
+			}
+		})
+	}
+}
+
+// unprivilegedPortScanner is a mock scanner for a user who cannot bind ports below 1024
+type unprivilegedPortScanner struct {
+	*mockPortScanner
+}
+
+func (unprivilegedPortScanner) NeedsPrivileges(port int) bool {
+	return port > 0 && port < 1024
+}
+
+func TestInterceptCommand_PreToolUse_PrivilegedPort(t *testing.T) {
+	restoreFactory := SetProcessManagerFactory(func() *process.ProcessManager {
+		mockStore := &mockStateStore{}
+		mockScanner := &mockPortScanner{}
+		mockStore.On("Load").Return(map[string]*process.ManagedProcess{}, nil)
+		mockScanner.On("IsPortInUse", 80).Return(false)
+
+		return process.NewProcessManager(mockStore, &mockLockManager{}, unprivilegedPortScanner{mockScanner})
+	})
+	defer restoreFactory()
+
+	request := createTestInterceptRequest("preToolUse", "Bash", createBashParameters("npm run dev -- --port 80"), nil)
+	input, err := json.Marshal(request)
+	require.NoError(t, err)
+
+	output, err := executeInterceptCmd(t, string(input))
+	require.NoError(t, err)
+
+	var response PreToolUseResponse
+	require.NoError(t, json.Unmarshal([]byte(output), &response))
+
+	assert.True(t, response.Proceed, "the warning does not block the command")
+	assert.Contains(t, response.Message, "Port 80 requires elevated privileges")
+	assert.Equal(t, true, response.Data["privileged_port"])
+
+	suggestions := decodeSuggestions(t, response.Data)
+	require.Len(t, suggestions, 2)
+	assert.Equal(t, SuggestionChangePort, suggestions[0].Action)
+}
+
+// refusingExecutor fails every start so registration tests never spawn real commands
+type refusingExecutor struct{}
+
+func (refusingExecutor) Start(process.ExecSpec) (int, error) { return 0, os.ErrPermission }
+func (refusingExecutor) Wait(int) error                      { return process.ErrNotStarted }
+func (refusingExecutor) Signal(int, bool, os.Signal) error   { return os.ErrProcessDone }
+func (refusingExecutor) Kill(int, bool) error                { return os.ErrProcessDone }
+func (refusingExecutor) IsAlive(int) bool                    { return false }
+
+// countingExecutor is a refusingExecutor that counts start attempts
+type countingExecutor struct {
+	refusingExecutor
+	starts *atomic.Int32
+}
+
+func (e countingExecutor) Start(spec process.ExecSpec) (int, error) {
+	e.starts.Add(1)
+	return e.refusingExecutor.Start(spec)
+}
+
+func TestInterceptCommand_PostToolUse_CoalescesRetries(t *testing.T) {
+	useTempRegistrations(t)
+
+	// Every registration reaches the executor once
+	var registrations atomic.Int32
+	restoreFactory := SetProcessManagerFactory(func() *process.ProcessManager {
+		pm := createMockProcessManager()
+		pm.SetExecutor(countingExecutor{starts: &registrations})
+		return pm
+	})
+	defer restoreFactory()
+
+	request := createTestInterceptRequest("postToolUse", "Bash", createBashParameters("npm run dev"), &ToolResult{
+		Success: true,
+		Output:  "Server running on http://localhost:4321",
+	})
+	request.WorkingDir = t.TempDir() // The registration must get as far as the executor
+	input, err := json.Marshal(request)
+	require.NoError(t, err)
+
+	var responses [2]PostToolUseResponse
+	for i := range responses {
+		output, err := executeInterceptCmd(t, string(input))
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal([]byte(output), &responses[i]))
+	}
+
+	assert.Equal(t, "Server registered on port 4321", responses[0].Message)
+	assert.NotContains(t, responses[0].Data, "coalesced")
+	assert.Equal(t, true, responses[1].Data["coalesced"], "the retry is folded into the first registration")
+	assert.InDelta(t, 4321, responses[1].Data["port"], 0)
+
+	require.Eventually(t, func() bool { return registrations.Load() >= 1 }, 2*time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond) // Give a duplicate registration time to show up
+	assert.Equal(t, int32(1), registrations.Load())
+
+	// Another port is a different server
+	other := createTestInterceptRequest("postToolUse", "Bash", createBashParameters("npm run dev"), &ToolResult{
+		Success: true,
+		Output:  "Server running on http://localhost:4322",
+	})
+	input, err = json.Marshal(other)
+	require.NoError(t, err)
+	output, err := executeInterceptCmd(t, string(input))
+	require.NoError(t, err)
+	var response PostToolUseResponse
+	require.NoError(t, json.Unmarshal([]byte(output), &response))
+	assert.NotContains(t, response.Data, "coalesced")
+}
+
+// useTempRegistrations keeps the registration markers of a test out of the real portguard directory
+func useTempRegistrations(t *testing.T) {
+	t.Helper()
+	original := recentRegistrations
+	recentRegistrations = newRegistrationCoalescer(t.TempDir(), time.Minute)
+	t.Cleanup(func() { recentRegistrations = original })
+}
+
+func TestRegistrationCoalescer(t *testing.T) {
+	coalescer := newRegistrationCoalescer(t.TempDir(), 20*time.Millisecond)
+	assert.True(t, coalescer.claim("npm run dev|3000"))
+	assert.False(t, coalescer.claim("npm run dev|3000"))
+	assert.True(t, coalescer.claim("npm run dev|3001"))
+
+	time.Sleep(30 * time.Millisecond)
+	assert.True(t, coalescer.claim("npm run dev|3000"), "the window has passed")
+}
+
+// decodeSuggestions converts the generic suggestions entry of response data back into typed suggestions
+func decodeSuggestions(t *testing.T, data map[string]interface{}) []Suggestion {
+	t.Helper()
+
+	raw, err := json.Marshal(data["suggestions"])
+	require.NoError(t, err)
+
+	var suggestions []Suggestion
+	require.NoError(t, json.Unmarshal(raw, &suggestions))
+	return suggestions
+}
+
 func TestInterceptCommand_PostToolUse(t *testing.T) {
+	useTempRegistrations(t)
 	// Set up mock ProcessManager factory for all tests (thread-safe)
 	restoreFactory := SetProcessManagerFactory(createMockProcessManager)
 	defer restoreFactory()
@@ -496,6 +783,11 @@ func TestIsServerCommand(t *testing.T) {
 		{name: "jekyll_serve", command: "jekyll serve", expected: true},
 		{name: "php_server", command: "php -S localhost:8000", expected: true},
 
+		// Environment-prefixed commands
+		{name: "env_prefixed_npm_start", command: "NODE_ENV=production PORT=4000 npm start", expected: true},
+		{name: "env_prefixed_quoted_value", command: `APP_NAME="my app" flask run`, expected: true},
+		{name: "env_prefixed_non_server", command: "CI=1 npm install", expected: false},
+
 		// Non-server commands
 		{name: "ls", command: "ls -la", expected: false},
 		{name: "npm_install", command: "npm install", expected: false},
@@ -539,6 +831,19 @@ func TestExtractPort(t *testing.T) {
 		// Edge cases
 		{name: "multiple_port_flags", command: "cmd --port 3000 --backup-port 3001", expected: 3000}, // First one wins
 		{name: "port_in_middle", command: "npm run dev --env production --port 4000 --verbose", expected: 4000},
+		{name: "host_port_token", command: "php -S localhost:8000", expected: 8000},
+		{name: "addr_flag", command: "go run main.go --addr 127.0.0.1:9090", expected: 9090},
+		{name: "docker_port_mapping", command: "docker run -p 8080:80 nginx", expected: 8080},
+		{name: "url_with_path", command: "ngrok http http://localhost:4040/inspect", expected: 4040},
+
+		// Environment-prefixed commands
+		{name: "env_port", command: "NODE_ENV=production PORT=4000 npm start", expected: 4000},
+		{name: "env_server_port", command: "SERVER_PORT=9000 go run main.go", expected: 9000},
+		{name: "env_port_overrides_default", command: "PORT=3005 npm run dev", expected: 3005},
+		{name: "env_port_quoted", command: `PORT="4100" node server.js`, expected: 4100},
+		{name: "explicit_flag_beats_env", command: "PORT=4000 npm run dev --port 3001", expected: 3001},
+		{name: "invalid_env_port_ignored", command: "PORT=abc npm run dev", expected: 3000},
+		{name: "env_port_after_command_ignored", command: "go run main.go PORT=4000", expected: 0},
 	}
 
 	for _, tt := range tests {
@@ -549,6 +854,336 @@ func TestExtractPort(t *testing.T) {
 	}
 }
 
+func TestSplitEnvAssignments(t *testing.T) {
+	env, command := splitEnvAssignments(`  NODE_ENV=production GREETING='hello world' EMPTY= npm start --port=3000`)
+	assert.Equal(t, map[string]string{"NODE_ENV": "production", "GREETING": "hello world", "EMPTY": ""}, env)
+	assert.Equal(t, "npm start --port=3000", command)
+
+	env, command = splitEnvAssignments("npm start")
+	assert.Empty(t, env)
+	assert.Equal(t, "npm start", command)
+}
+
+func TestExtractPort_FalsePositives(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+	}{
+		{name: "scp_style_git_url", command: "git clone git@host:22/repo"},
+		{name: "version_string", command: "npx node@18:3000"},
+		{name: "path_after_host", command: "rsync backup:8080/data ./data"},
+		{name: "out_of_range_port", command: "go run main.go --port 70000"},
+		{name: "out_of_range_colon", command: "go run main.go :99999"},
+		{name: "zero_port", command: "go run main.go --port 0"},
+		{name: "time_of_day", command: "go run main.go --at 12:30pm"},
+		{name: "backup_port_flag", command: "go run main.go --backup-port 3001"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, 0, extractExplicitPort(tt.command))
+		})
+	}
+}
+
+func TestCustomCommandPatterns(t *testing.T) {
+	require.NoError(t, setCustomCommandPatterns(
+		[]string{`^mycli dev`},
+		[]config.PortPattern{{Pattern: `^mycli dev`, Port: 4500}},
+	))
+	t.Cleanup(func() { _ = setCustomCommandPatterns(nil, nil) })
+
+	t.Run("custom_command_detected", func(t *testing.T) {
+		assert.True(t, isServerCommand("mycli dev --watch"))
+		assert.False(t, isServerCommand("mycli build"))
+	})
+
+	t.Run("custom_default_port", func(t *testing.T) {
+		assert.Equal(t, 4500, extractPort("mycli dev"))
+	})
+
+	t.Run("explicit_port_wins", func(t *testing.T) {
+		assert.Equal(t, 4600, extractPort("mycli dev --port 4600"))
+	})
+
+	t.Run("anchored_pattern_after_env_assignments", func(t *testing.T) {
+		assert.True(t, isServerCommand("DEBUG=1 mycli dev"))
+		assert.Equal(t, 4500, extractPort("DEBUG=1 mycli dev"))
+	})
+
+	t.Run("builtin_patterns_still_apply", func(t *testing.T) {
+		assert.True(t, isServerCommand("npm run dev"))
+		assert.Equal(t, 3000, extractPort("npm run dev"))
+	})
+
+	t.Run("invalid_pattern_rejected", func(t *testing.T) {
+		err := setCustomCommandPatterns([]string{"mycli (dev"}, nil)
+		require.Error(t, err)
+		// Previously configured patterns remain in effect
+		assert.True(t, isServerCommand("mycli dev"))
+	})
+}
+
+func TestInterceptPermits(t *testing.T) {
+	t.Cleanup(func() { _ = setInterceptRules(nil, nil) })
+
+	tests := []struct {
+		name     string
+		allow    []string
+		deny     []string
+		command  string
+		expected bool
+	}{
+		{name: "default_open", command: "npm run dev", expected: true},
+		{name: "denied", deny: []string{`\bkubectl\b`}, command: "kubectl port-forward svc/api 8080:80", expected: false},
+		{name: "not_denied", deny: []string{`\bkubectl\b`}, command: "npm run dev", expected: true},
+		{name: "allowed", allow: []string{`^npm `}, command: "npm run dev", expected: true},
+		{name: "not_allowed", allow: []string{`^npm `}, command: "python -m http.server 8000", expected: false},
+		{name: "deny_wins_over_allow", allow: []string{`^npm `}, deny: []string{`storybook`}, command: "npm run storybook", expected: false},
+		{name: "whole_command_line", deny: []string{`^PORT=4000 `}, command: "PORT=4000 npm start", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, setInterceptRules(tt.allow, tt.deny))
+			assert.Equal(t, tt.expected, interceptPermits(tt.command))
+		})
+	}
+
+	t.Run("invalid_pattern_rejected", func(t *testing.T) {
+		require.NoError(t, setInterceptRules(nil, []string{"kubectl"}))
+		err := setInterceptRules(nil, []string{"kubectl ("})
+		require.ErrorIs(t, err, config.ErrInvalidInterceptRule)
+		// Previously configured rules remain in effect
+		assert.False(t, interceptPermits("kubectl get pods"))
+	})
+}
+
+func TestLoadCustomCommandPatterns(t *testing.T) {
+	t.Cleanup(func() {
+		_ = setInterceptRules(nil, nil)
+		_ = setCustomCommandPatterns(nil, nil)
+		viper.Reset()
+	})
+
+	tests := []struct {
+		name       string
+		content    string
+		permitted  []string
+		denied     []string
+		serverMode bool // mycli dev is recognized as a server command
+	}{
+		{
+			name:      "deny_list",
+			content:   "default:\n  intercept:\n    deny: ['\\bkubectl\\b']\n",
+			permitted: []string{"npm run dev"},
+			denied:    []string{"kubectl port-forward svc/api 8080:80"},
+		},
+		{
+			name:    "invalid_deny_rule_denies_everything",
+			content: "default:\n  intercept:\n    deny: ['kubectl (']\n",
+			denied:  []string{"npm run dev", "kubectl port-forward svc/api 8080:80"},
+		},
+		{
+			name:    "invalid_allow_rule_denies_everything",
+			content: "default:\n  intercept:\n    allow: ['npm (']\n",
+			denied:  []string{"npm run dev"},
+		},
+		{
+			name:      "invalid_server_pattern_keeps_rules",
+			content:   "default:\n  server_patterns: ['mycli (dev']\n  intercept:\n    deny: ['\\bkubectl\\b']\n",
+			permitted: []string{"npm run dev"},
+			denied:    []string{"kubectl port-forward svc/api 8080:80"},
+		},
+		{
+			name:       "invalid_port_range_keeps_patterns",
+			content:    "default:\n  port_range:\n    start: 9000\n    end: 3000\n  server_patterns: ['mycli dev']\n",
+			permitted:  []string{"npm run dev"},
+			serverMode: true,
+		},
+		{
+			name:    "unreadable_config_denies_everything",
+			content: "default: [unclosed\n",
+			denied:  []string{"npm run dev"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, setInterceptRules(nil, nil))
+			require.NoError(t, setCustomCommandPatterns(nil, nil))
+			configPath := filepath.Join(t.TempDir(), "portguard.yml")
+			require.NoError(t, os.WriteFile(configPath, []byte(tt.content), 0o600))
+			viper.Reset()
+			viper.SetConfigFile(configPath)
+
+			loadCustomCommandPatterns()
+
+			for _, command := range tt.permitted {
+				assert.True(t, interceptPermits(command), command)
+			}
+			for _, command := range tt.denied {
+				assert.False(t, interceptPermits(command), command)
+			}
+			assert.Equal(t, tt.serverMode, isServerCommand("mycli dev"))
+		})
+	}
+}
+
+func TestCheckForAdoptableProcess_ConfigError(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	configPath := filepath.Join(t.TempDir(), "portguard.yml")
+	require.NoError(t, os.WriteFile(configPath, []byte("default: [unclosed\n"), 0o600))
+	viper.Reset()
+	viper.SetConfigFile(configPath)
+
+	info, err := checkForAdoptableProcess(3000)
+	require.Error(t, err, "an unreadable configuration skips the scan instead of using defaults")
+	assert.Nil(t, info)
+}
+
+func TestInterceptCommand_Rules(t *testing.T) {
+	useTempRegistrations(t)
+	restoreFactory := SetProcessManagerFactory(func() *process.ProcessManager {
+		pm := createMockProcessManager()
+		pm.SetExecutor(refusingExecutor{})
+		return pm
+	})
+	defer restoreFactory()
+	require.NoError(t, setInterceptRules(nil, []string{`\bkubectl\b`, `storybook`}))
+	t.Cleanup(func() { _ = setInterceptRules(nil, nil) })
+
+	t.Run("denied_command_proceeds_untouched", func(t *testing.T) {
+		request := createTestInterceptRequest("preToolUse", "Bash", createBashParameters("npm run storybook -- --port 6006"), nil)
+		input, err := json.Marshal(request)
+		require.NoError(t, err)
+		output, err := executeInterceptCmd(t, string(input))
+		require.NoError(t, err)
+
+		var response PreToolUseResponse
+		require.NoError(t, json.Unmarshal([]byte(output), &response))
+		assert.True(t, response.Proceed)
+		assert.Equal(t, "Command excluded from intercept", response.Message)
+		assert.Empty(t, response.Data)
+	})
+
+	t.Run("denied_command_not_registered", func(t *testing.T) {
+		request := createTestInterceptRequest("postToolUse", "Bash", createBashParameters("npm run storybook"), &ToolResult{
+			Success: true,
+			Output:  "Storybook started on http://localhost:6006",
+		})
+		input, err := json.Marshal(request)
+		require.NoError(t, err)
+		output, err := executeInterceptCmd(t, string(input))
+		require.NoError(t, err)
+
+		var response PostToolUseResponse
+		require.NoError(t, json.Unmarshal([]byte(output), &response))
+		assert.Equal(t, "Command processed", response.Message)
+		assert.NotContains(t, response.Data, "port")
+	})
+}
+
+func TestInterceptCommand_StateUnavailable(t *testing.T) {
+	useTempRegistrations(t)
+	errState := errors.New("failed to open state: permission denied")
+	restoreFactory := setProcessManagerFactory(func() (*process.ProcessManager, error) {
+		return nil, errState
+	})
+	defer restoreFactory()
+
+	t.Run("pre_tool_use_skips_check", func(t *testing.T) {
+		request := createTestInterceptRequest("preToolUse", "Bash", createBashParameters("npm run dev"), nil)
+		input, err := json.Marshal(request)
+		require.NoError(t, err)
+		output, err := executeInterceptCmd(t, string(input))
+		require.NoError(t, err)
+
+		var response PreToolUseResponse
+		require.NoError(t, json.Unmarshal([]byte(output), &response))
+		assert.True(t, response.Proceed)
+		assert.Equal(t, "Command not checked: portguard state unavailable", response.Message)
+		assert.Equal(t, errState.Error(), response.Data["error"])
+		assert.NotContains(t, response.Data, "suggestions")
+	})
+
+	t.Run("post_tool_use_registers_nothing", func(t *testing.T) {
+		request := createTestInterceptRequest("postToolUse", "Bash", createBashParameters("npm run dev"), &ToolResult{
+			Success: true,
+			Output:  "Server running on http://localhost:4323",
+		})
+		input, err := json.Marshal(request)
+		require.NoError(t, err)
+		output, err := executeInterceptCmd(t, string(input))
+		require.NoError(t, err)
+
+		var response PostToolUseResponse
+		require.NoError(t, json.Unmarshal([]byte(output), &response))
+		assert.Equal(t, "error", response.Status)
+		assert.Equal(t, "Server on port 4323 not registered: portguard state unavailable", response.Message)
+		assert.Equal(t, errState.Error(), response.Data["error"])
+
+		// Once the state opens again, a retry registers the server instead of being coalesced
+		restoreWorking := SetProcessManagerFactory(func() *process.ProcessManager {
+			pm := createMockProcessManager()
+			pm.SetExecutor(refusingExecutor{})
+			return pm
+		})
+		defer restoreWorking()
+		output, err = executeInterceptCmd(t, string(input))
+		require.NoError(t, err)
+		var retry PostToolUseResponse
+		require.NoError(t, json.Unmarshal([]byte(output), &retry))
+		assert.Equal(t, "Server registered on port 4323", retry.Message)
+		assert.NotContains(t, retry.Data, "coalesced")
+	})
+}
+
+func TestInterceptCommand_StdoutCarriesOnlyResponses(t *testing.T) {
+	restoreFactory := SetProcessManagerFactory(func() *process.ProcessManager {
+		fmt.Println("stray diagnostic") // Anything but a response must not reach stdout
+		return createMockProcessManager()
+	})
+	defer restoreFactory()
+
+	request := createTestInterceptRequest("preToolUse", "Bash", createBashParameters("npm run dev"), nil)
+	input, err := json.Marshal(request)
+	require.NoError(t, err)
+	stdinFile := filepath.Join(t.TempDir(), "request.json")
+	require.NoError(t, os.WriteFile(stdinFile, input, 0o600))
+	stdin, err := os.Open(stdinFile)
+	require.NoError(t, err)
+	defer stdin.Close()
+
+	stdoutReader, stdoutWriter, err := os.Pipe()
+	require.NoError(t, err)
+	stderrReader, stderrWriter, err := os.Pipe()
+	require.NoError(t, err)
+	oldStdin, oldStdout, oldStderr := os.Stdin, os.Stdout, os.Stderr
+	os.Stdin, os.Stdout, os.Stderr = stdin, stdoutWriter, stderrWriter
+	defer func() { os.Stdin, os.Stdout, os.Stderr = oldStdin, oldStdout, oldStderr }()
+
+	var stdout, stderr bytes.Buffer
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() { defer wg.Done(); _, _ = stdout.ReadFrom(stdoutReader) }()
+	go func() { defer wg.Done(); _, _ = stderr.ReadFrom(stderrReader) }()
+
+	interceptCmd.Run(interceptCmd, nil)
+	assert.Same(t, stdoutWriter, os.Stdout, "stdout is restored after handling")
+
+	_ = stdoutWriter.Close()
+	_ = stderrWriter.Close()
+	wg.Wait()
+
+	decoder := json.NewDecoder(&stdout)
+	var response PreToolUseResponse
+	require.NoError(t, decoder.Decode(&response), "stdout: %s", stdout.String())
+	assert.True(t, response.Proceed)
+	assert.False(t, decoder.More(), "nothing follows the response on stdout")
+	assert.Contains(t, stderr.String(), "stray diagnostic")
+}
+
 func TestExtractPortFromOutput(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -572,6 +1207,11 @@ func TestExtractPortFromOutput(t *testing.T) {
 		// Edge cases
 		{name: "port_with_path", output: "Server running on http://localhost:3000/api", expected: 3000},
 		{name: "https_port", output: "HTTPS server on https://localhost:8443", expected: 8443},
+
+		// False positives
+		{name: "url_path_segment", output: "See https://example.com/docs:1234 for details", expected: 0},
+		{name: "out_of_range", output: "Listening on :123456", expected: 0},
+		{name: "host_across_lines", output: "Running on\nhttp://example.com/a:9999", expected: 0},
 	}
 
 	for _, tt := range tests {
@@ -583,6 +1223,7 @@ func TestExtractPortFromOutput(t *testing.T) {
 }
 
 func TestInterceptCommand_JSONResponseFormat(t *testing.T) {
+	useTempRegistrations(t)
 	t.Run("pre_tool_use_response_format", func(t *testing.T) {
 		request := createTestInterceptRequest(
 			"preToolUse",
@@ -687,6 +1328,7 @@ func TestInterceptCommand_ErrorHandling(t *testing.T) {
 }
 
 func TestInterceptCommand_ComplexScenarios(t *testing.T) {
+	useTempRegistrations(t)
 	t.Run("chained_commands", func(t *testing.T) {
 		request := createTestInterceptRequest(
 			"preToolUse",
@@ -792,3 +1434,100 @@ func TestOutputErrorResponse(t *testing.T) {
 		assert.Contains(t, response.Message, "test error message")
 	})
 }
+
+func TestProcessInterceptStream(t *testing.T) {
+	restoreFactory := SetProcessManagerFactory(createMockProcessManager)
+	defer restoreFactory()
+
+	originalStream := interceptStream
+	interceptStream = true
+	defer func() { interceptStream = originalStream }()
+
+	var input strings.Builder
+	for _, request := range []InterceptRequest{
+		createTestInterceptRequest("preToolUse", "Bash", createBashParameters("echo test"), nil),
+		createTestInterceptRequest("postToolUse", "Bash", createBashParameters("ls"), &ToolResult{Success: true}),
+		createTestInterceptRequest("unknownEvent", "Bash", createBashParameters("echo test"), nil),
+	} {
+		line, err := json.Marshal(request)
+		require.NoError(t, err)
+		input.Write(line)
+		input.WriteString("\n")
+	}
+
+	oldStdout := os.Stdout
+	defer func() { os.Stdout = oldStdout }()
+	reader, writer, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stdout = writer
+
+	processInterceptStream(strings.NewReader(input.String()))
+
+	_ = writer.Close() // Close pipe to signal end of output
+	output, err := io.ReadAll(reader)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	require.Len(t, lines, 3, "expected one response line per request")
+
+	var preResponse PreToolUseResponse
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &preResponse))
+	assert.True(t, preResponse.Proceed)
+
+	var postResponse PostToolUseResponse
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &postResponse))
+	assert.Equal(t, "success", postResponse.Status)
+
+	var errorResponse PreToolUseResponse
+	require.NoError(t, json.Unmarshal([]byte(lines[2]), &errorResponse))
+	assert.Contains(t, errorResponse.Message, ErrUnknownEvent.Error())
+}
+
+func TestProcessInterceptRequest_LargeOutput(t *testing.T) {
+	useTempRegistrations(t)
+	restoreFactory := SetProcessManagerFactory(createMockProcessManager)
+	defer restoreFactory()
+
+	// Several megabytes of build noise before the startup line, well past bufio.Scanner's 64KB token limit
+	largeOutput := strings.Repeat("webpack compiling modules...\n", 128*1024) + "Server running on http://localhost:3000\n"
+	require.Greater(t, len(largeOutput), 3*1024*1024)
+
+	request := createTestInterceptRequest("postToolUse", "Bash", createBashParameters("npm run dev"), &ToolResult{
+		Success: true,
+		Output:  largeOutput,
+	})
+	input, err := json.MarshalIndent(request, "", "  ")
+	require.NoError(t, err)
+
+	oldStdout := os.Stdout
+	defer func() { os.Stdout = oldStdout }()
+	reader, writer, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stdout = writer
+
+	processInterceptRequest(bytes.NewReader(input))
+
+	_ = writer.Close() // Close pipe to signal end of output
+	output, err := io.ReadAll(reader)
+	require.NoError(t, err)
+
+	var response PostToolUseResponse
+	require.NoError(t, json.Unmarshal(output, &response))
+	assert.Equal(t, "success", response.Status)
+	assert.InDelta(t, 3000, response.Data["port"], 0)
+}
+
+func TestRegistrationCoalescer_AcrossProcesses(t *testing.T) {
+	dir := t.TempDir()
+	// Each hook call is a separate portguard process with its own coalescer
+	first := newRegistrationCoalescer(dir, time.Minute)
+	second := newRegistrationCoalescer(dir, time.Minute)
+
+	assert.True(t, first.claim("npm run dev|3000"))
+	assert.False(t, second.claim("npm run dev|3000"), "the marker is shared between processes")
+	assert.True(t, second.claim("npm run dev|3001"))
+
+	// Markers older than the window no longer suppress registrations
+	expired := newRegistrationCoalescer(dir, 0)
+	assert.True(t, expired.claim("npm run dev|3000"))
+}
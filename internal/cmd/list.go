@@ -1,29 +1,95 @@
 package cmd
 
 import (
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/paveg/portguard/internal/process"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
+// List command errors
+var (
+	ErrUnsupportedFormat = errors.New("unsupported output format")
+	ErrInvalidTimeFilter = errors.New("invalid time filter")
+)
+
+// Output formats supported by list
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+	formatCSV   = "csv"
+)
+
+// listFormat selects the list output format
+var listFormat string
+
+// listGrep filters listed processes by a command line substring
+var listGrep string
+
+// listSince and listUntil bound process creation times, as durations ago or RFC3339 timestamps
+var listSince, listUntil string
+
+// csvHeader lists the CSV columns in output order
+var csvHeader = []string{
+	"id", "pid", "status", "port", "ports", "command", "args",
+	"working_dir", "restart_count", "ref_count", "exit_code", "exit_reason", "created_at", "updated_at",
+}
+
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all managed processes",
 	Long: `List all managed processes with their status, ports, and health information.
-Supports both human-readable table format and JSON output for AI tools.
+Supports a human-readable table and json, yaml or csv output for scripts and AI tools.
 
 Examples:
   portguard list
-  portguard list --json
-  portguard list --all`,
-	RunE: func(_ *cobra.Command, _ []string) error {
-		fmt.Println("Listing managed processes...")
+  portguard list --format json
+  portguard list --format yaml | yq '.processes[].id'
+  portguard list --format csv > processes.csv
+  portguard list --all
+  portguard list --port 3000
+  portguard list --grep vite   # Case-insensitive command line match
+  portguard list --all --since 1h --until 15m
+  portguard list --since 2024-05-01T09:00:00Z
+  portguard list --refresh   # Re-check PIDs and health before listing`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		format := listFormat
+		if jsonOutput && !cmd.Flags().Changed("format") {
+			format = formatJSON
+		}
+		if !isListFormat(format) {
+			return fmt.Errorf("%w: %q (expected table, json, yaml or csv)", ErrUnsupportedFormat, format)
+		}
+
+		// Keep machine-readable output free of progress messages
+		if format == formatTable {
+			fmt.Println("Listing managed processes...")
 
-		if showAll {
-			fmt.Println("Showing all processes (including stopped)")
+			if showAll {
+				fmt.Println("Showing all processes (including stopped)")
+			}
+		}
+
+		now := time.Now()
+		createdAfter, err := parseTimeFilter(listSince, now)
+		if err != nil {
+			return fmt.Errorf("--since: %w", err)
+		}
+		createdBefore, err := parseTimeFilter(listUntil, now)
+		if err != nil {
+			return fmt.Errorf("--until: %w", err)
 		}
 
 		// Initialize process manager
@@ -32,56 +98,306 @@ Examples:
 			return fmt.Errorf("failed to initialize process manager: %w", err)
 		}
 
+		// Reconcile statuses when background monitoring may not have caught up
+		if refreshStatuses {
+			if err := pm.RefreshStatuses(); err != nil {
+				fmt.Printf("Warning: some statuses could not be refreshed: %v\n", err)
+			}
+		}
+
 		// Get process list options
 		options := process.ProcessListOptions{
-			IncludeStopped: showAll,
+			IncludeStopped:  showAll,
+			FilterByPort:    port,
+			FilterByCommand: listGrep,
+			CreatedAfter:    createdAfter,
+			CreatedBefore:   createdBefore,
 		}
 
 		processes := pm.ListProcesses(options)
+		sort.Slice(processes, func(i, j int) bool { return processes[i].ID < processes[j].ID })
 
-		if jsonOutput {
-			data := map[string]interface{}{
-				"processes": processes,
-				"total":     len(processes),
-			}
+		return writeProcessList(os.Stdout, format, processes)
+	},
+}
 
-			output, err := json.MarshalIndent(data, "", "  ")
-			if err != nil {
-				return fmt.Errorf("failed to marshal JSON: %w", err)
-			}
-			fmt.Println(string(output))
-			return nil
+// parseTimeFilter parses a --since/--until value: a duration such as "1h" meaning that long
+// before now, or an RFC3339 timestamp. Empty disables the filter.
+func parseTimeFilter(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if duration, err := time.ParseDuration(value); err == nil {
+		if duration < 0 {
+			return time.Time{}, fmt.Errorf("%w: %q is a negative duration", ErrInvalidTimeFilter, value)
 		}
+		return now.Add(-duration), nil
+	}
+	timestamp, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %q (expected a duration like 1h or an RFC3339 timestamp)", ErrInvalidTimeFilter, value)
+	}
+	return timestamp, nil
+}
 
-		// Text output
+// isListFormat reports whether format is a supported list output format
+func isListFormat(format string) bool {
+	switch format {
+	case formatTable, formatJSON, formatYAML, formatCSV:
+		return true
+	}
+	return false
+}
+
+// writeProcessList writes processes in the given output format
+func writeProcessList(w io.Writer, format string, processes []*process.ManagedProcess) error {
+	switch format {
+	case formatJSON:
+		output, err := json.MarshalIndent(processListDocument(processes), "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Fprintln(w, string(output))
+		return nil
+	case formatYAML:
+		return writeProcessYAML(w, processes)
+	case formatCSV:
+		return writeProcessCSV(w, processes)
+	case formatTable:
 		if len(processes) == 0 {
-			fmt.Println("No processes found")
+			fmt.Fprintln(w, "No processes found")
 			return nil
 		}
+		fmt.Fprintf(w, "Found %d process(es):\n\n", len(processes))
+		printProcessTable(w, processes, nil)
+		return nil
+	}
+	return fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+}
+
+// processListDocument is the document written by the json and yaml formats
+func processListDocument(processes []*process.ManagedProcess) map[string]interface{} {
+	return map[string]interface{}{
+		"processes": processes,
+		"total":     len(processes),
+	}
+}
+
+// writeProcessYAML writes the same document as the json format, using the JSON field names
+func writeProcessYAML(w io.Writer, processes []*process.ManagedProcess) error {
+	// Round-trip through JSON so YAML keys match the json tags and times use RFC 3339
+	data, err := json.Marshal(processListDocument(processes))
+	if err != nil {
+		return fmt.Errorf("failed to marshal processes: %w", err)
+	}
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return fmt.Errorf("failed to convert processes: %w", err)
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(document); err != nil {
+		return fmt.Errorf("failed to write YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to write YAML: %w", err)
+	}
+	return nil
+}
+
+// writeProcessCSV writes one row per process with the columns in csvHeader
+func writeProcessCSV(w io.Writer, processes []*process.ManagedProcess) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	for _, proc := range processes {
+		exitCode := ""
+		if proc.ExitCode != nil {
+			exitCode = strconv.Itoa(*proc.ExitCode)
+		}
+		portNum := ""
+		if proc.Port > 0 {
+			portNum = strconv.Itoa(proc.Port)
+		}
+		ports := make([]string, 0, len(proc.AllPorts()))
+		for _, p := range proc.AllPorts() {
+			ports = append(ports, strconv.Itoa(p))
+		}
+		record := []string{
+			proc.ID,
+			strconv.Itoa(proc.PID),
+			string(proc.Status),
+			portNum,
+			strings.Join(ports, " "),
+			proc.Command,
+			strings.Join(proc.Args, " "),
+			proc.WorkingDir,
+			strconv.Itoa(proc.RestartCount),
+			strconv.Itoa(proc.RefCount),
+			exitCode,
+			proc.ExitReason,
+			formatCSVTime(proc.CreatedAt),
+			formatCSVTime(proc.UpdatedAt),
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
 
-		fmt.Printf("Found %d process(es):\n\n", len(processes))
+// formatCSVTime renders a timestamp as RFC 3339, or empty when unset
+func formatCSVTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
 
-		// Table header
-		fmt.Printf("%-10s %-8s %-10s %-6s %-s\n", "ID", "PID", "STATUS", "PORT", "COMMAND")
-		fmt.Println("------------------------------------------------------------------------")
+// refreshStatuses makes list re-check process liveness and health before printing
+var refreshStatuses bool
 
-		for _, proc := range processes {
-			portStr := "-"
-			if proc.Port > 0 {
-				portStr = strconv.Itoa(proc.Port)
-			}
+// formatPorts renders a process's ports as a comma-separated list, or "-" if none
+func formatPorts(ports []int) string {
+	if len(ports) == 0 {
+		return "-"
+	}
+	portStrs := make([]string, 0, len(ports))
+	for _, portNum := range ports {
+		portStrs = append(portStrs, strconv.Itoa(portNum))
+	}
+	return strings.Join(portStrs, ",")
+}
 
-			fmt.Printf("%-10s %-8d %-10s %-6s %-s\n",
-				proc.ID[:8], proc.PID, proc.Status, portStr, proc.Command)
+// printProcessTable writes the process table, highlighting the status of processes in changed.
+// On a terminal, statuses get icons and colors and commands are cut to fit the terminal width.
+func printProcessTable(w io.Writer, processes []*process.ManagedProcess, changed map[string]bool) {
+	style := newTableStyle(w)
+	statusWidth := style.statusWidth()
+
+	fmt.Fprintf(w, "%-20s %-8s %-*s %-6s %-s\n", "ID", "PID", statusWidth, "STATUS", "PORT", "COMMAND")
+	separatorWidth := 82
+	if style.width > 0 && style.width < separatorWidth {
+		separatorWidth = style.width
+	}
+	fmt.Fprintln(w, strings.Repeat("-", separatorWidth))
+
+	for _, proc := range processes {
+		ports := formatPorts(proc.AllPorts())
+		used := max(20, utf8.RuneCountInString(proc.ID)) + 1 + 8 + 1 + statusWidth + 1 + max(6, len(ports)) + 1
+		fmt.Fprintf(w, "%-20s %-8d %s %-6s %-s\n",
+			proc.ID, proc.PID, style.statusCell(proc.Status, changed[proc.ID]), ports, style.fit(proc.Command, used))
+		if detail := formatExitDetail(proc, verbose); detail != "" {
+			fmt.Fprintf(w, "%-20s %s\n", "", detail)
 		}
+		if proc.IsRunning() && proc.References() > 1 {
+			fmt.Fprintf(w, "%-20s shared by %d callers\n", "", proc.References())
+		}
+	}
+}
 
-		return nil
-	},
+// highlight renders text in reverse video so it stands out in a terminal
+func highlight(text string) string {
+	return "\033[7m" + text + "\033[0m"
+}
+
+// colorReset ends an ANSI color sequence
+const colorReset = "\033[0m"
+
+// statusDecorations maps each status to its table icon and ANSI color
+var statusDecorations = map[process.ProcessStatus]struct{ icon, color string }{
+	process.StatusRunning:   {"●", "\033[32m"}, // Green
+	process.StatusUnhealthy: {"◐", "\033[33m"}, // Yellow
+	process.StatusStopped:   {"○", "\033[90m"}, // Gray
+	process.StatusFailed:    {"✖", "\033[31m"}, // Red
+	process.StatusPending:   {"◌", "\033[36m"}, // Cyan
+}
+
+// tableStyle holds the terminal-only decorations of the process table
+type tableStyle struct {
+	icons bool // Prefix statuses with a status icon
+	color bool // Color statuses
+	width int  // Terminal width to fit rows into, 0 for no limit
+}
+
+// newTableStyle enables icons and width fitting when w is a terminal, and colors unless
+// NO_COLOR is set. Pipes, files and buffers get the plain table.
+func newTableStyle(w io.Writer) tableStyle {
+	file, ok := w.(*os.File)
+	if !ok {
+		return tableStyle{}
+	}
+	width, isTerminal := terminalWidth(file)
+	if !isTerminal {
+		return tableStyle{}
+	}
+	return tableStyle{icons: true, color: os.Getenv("NO_COLOR") == "", width: width}
+}
+
+// statusWidth is the width of the status column
+func (s tableStyle) statusWidth() int {
+	if s.icons {
+		return 12
+	}
+	return 10
+}
+
+// statusCell renders a padded status, decorated per the style and highlighted when changed
+func (s tableStyle) statusCell(status process.ProcessStatus, changed bool) string {
+	decoration, known := statusDecorations[status]
+	text := string(status)
+	if s.icons && known {
+		text = decoration.icon + " " + text
+	}
+	text = fmt.Sprintf("%-*s", s.statusWidth(), text)
+	if s.color && known {
+		text = decoration.color + text + colorReset
+	}
+	if changed {
+		text = highlight(text)
+	}
+	return text
+}
+
+// fit cuts text so a row with used columns before it stays within the terminal width.
+// Text is left alone when there is no width or too little room to show anything useful.
+func (s tableStyle) fit(text string, used int) string {
+	const minColumn = 10
+	available := s.width - used
+	if s.width <= 0 || available < minColumn || utf8.RuneCountInString(text) <= available {
+		return text
+	}
+	return string([]rune(text)[:available-1]) + "…"
+}
+
+// formatExitDetail describes a process's last exit for stopped processes, or always when verbose
+func formatExitDetail(proc *process.ManagedProcess, verbose bool) string {
+	if proc.ExitReason == "" || (proc.IsRunning() && !verbose) {
+		return ""
+	}
+	if proc.ExitCode != nil {
+		return fmt.Sprintf("last exit: code %d (%s)", *proc.ExitCode, proc.ExitReason)
+	}
+	return "last exit: " + proc.ExitReason
 }
 
 func init() {
 	rootCmd.AddCommand(listCmd)
 
+	listCmd.Flags().StringVar(&listFormat, "format", formatTable, "output format: table, json, yaml or csv")
 	listCmd.Flags().BoolVar(&jsonOutput, "json", false, "output in JSON format (AI-friendly)")
+	_ = listCmd.Flags().MarkDeprecated("json", "use --format json instead") //nolint:errcheck // The flag is defined above
 	listCmd.Flags().BoolVarP(&showAll, "all", "a", false, "show all processes including stopped ones")
+	listCmd.Flags().IntVarP(&port, "port", "p", 0, "only show processes using this port")
+	listCmd.Flags().StringVar(&listGrep, "grep", "", "only show processes whose command line contains this text (case-insensitive)")
+	listCmd.Flags().StringVar(&listGrep, "command", "", "alias for --grep")
+	listCmd.Flags().StringVar(&listSince, "since", "", "only show processes created after this duration ago (e.g. 1h) or RFC3339 time")
+	listCmd.Flags().StringVar(&listUntil, "until", "", "only show processes created before this duration ago (e.g. 15m) or RFC3339 time")
+	listCmd.Flags().BoolVar(&refreshStatuses, "refresh", false, "re-check process liveness and health before listing")
 }
@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
 	"testing"
@@ -11,6 +13,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 
 	"github.com/paveg/portguard/internal/process"
 )
@@ -553,3 +556,190 @@ func TestListCommand_FlagValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatPorts(t *testing.T) {
+	assert.Equal(t, "-", formatPorts(nil))
+	assert.Equal(t, "3000", formatPorts([]int{3000}))
+	assert.Equal(t, "5173,24678", formatPorts([]int{5173, 24678}))
+}
+
+func TestFormatExitDetail(t *testing.T) {
+	code := 3
+	tests := []struct {
+		name     string
+		proc     *process.ManagedProcess
+		verbose  bool
+		expected string
+	}{
+		{
+			name:     "no_exit_recorded",
+			proc:     &process.ManagedProcess{Status: process.StatusStopped},
+			expected: "",
+		},
+		{
+			name:     "stopped_with_code",
+			proc:     &process.ManagedProcess{Status: process.StatusStopped, ExitCode: &code, ExitReason: "exit status 3"},
+			expected: "last exit: code 3 (exit status 3)",
+		},
+		{
+			name:     "failed_by_signal",
+			proc:     &process.ManagedProcess{Status: process.StatusFailed, ExitReason: "signal: killed"},
+			expected: "last exit: signal: killed",
+		},
+		{
+			name:     "running_hidden_without_verbose",
+			proc:     &process.ManagedProcess{Status: process.StatusRunning, ExitCode: &code, ExitReason: "exit status 3"},
+			expected: "",
+		},
+		{
+			name:     "running_shown_with_verbose",
+			proc:     &process.ManagedProcess{Status: process.StatusRunning, ExitCode: &code, ExitReason: "exit status 3"},
+			verbose:  true,
+			expected: "last exit: code 3 (exit status 3)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, formatExitDetail(tt.proc, tt.verbose))
+		})
+	}
+}
+
+func TestWriteProcessList(t *testing.T) {
+	code := 1
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	processes := []*process.ManagedProcess{
+		{ID: "npm-dev-a1b2c3", Command: "npm", Args: []string{"run", "dev"}, Port: 3000, Ports: []int{3000, 9229},
+			PID: 1234, Status: process.StatusRunning, RefCount: 2, CreatedAt: createdAt},
+		{ID: "go-run-d4e5f6", Command: "go", Args: []string{"run", "main.go"}, Status: process.StatusFailed,
+			ExitCode: &code, ExitReason: "exit status 1, retrying"},
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeProcessList(&buf, formatCSV, processes))
+
+		records, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		assert.Equal(t, csvHeader, records[0])
+		assert.Equal(t, []string{"npm-dev-a1b2c3", "1234", "running", "3000", "3000 9229", "npm", "run dev",
+			"", "0", "2", "", "", "2025-01-02T03:04:05Z", ""}, records[1])
+		assert.Equal(t, []string{"go-run-d4e5f6", "0", "failed", "", "", "go", "run main.go",
+			"", "0", "0", "1", "exit status 1, retrying", "", ""}, records[2])
+	})
+
+	t.Run("yaml_uses_json_field_names", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeProcessList(&buf, formatYAML, processes))
+
+		var document struct {
+			Processes []map[string]interface{} `yaml:"processes"`
+			Total     int                      `yaml:"total"`
+		}
+		require.NoError(t, yaml.Unmarshal(buf.Bytes(), &document))
+		assert.Equal(t, 2, document.Total)
+		require.Len(t, document.Processes, 2)
+		assert.Equal(t, "npm-dev-a1b2c3", document.Processes[0]["id"])
+		assert.Equal(t, "2025-01-02T03:04:05Z", document.Processes[0]["created_at"])
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeProcessList(&buf, formatJSON, processes))
+
+		var document map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &document))
+		assert.InDelta(t, 2, document["total"], 0)
+	})
+
+	t.Run("table_shows_shared_processes", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeProcessList(&buf, formatTable, processes))
+		assert.Contains(t, buf.String(), "shared by 2 callers")
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		assert.False(t, isListFormat("xml"))
+		require.ErrorIs(t, writeProcessList(&bytes.Buffer{}, "xml", processes), ErrUnsupportedFormat)
+	})
+}
+
+func TestParseTimeFilter(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		value       string
+		expected    time.Time
+		expectedErr bool
+	}{
+		{name: "empty_disables_filter", value: "", expected: time.Time{}},
+		{name: "relative_hours", value: "1h", expected: now.Add(-time.Hour)},
+		{name: "relative_compound", value: "1h30m", expected: now.Add(-90 * time.Minute)},
+		{name: "rfc3339_utc", value: "2025-05-31T09:00:00Z", expected: time.Date(2025, 5, 31, 9, 0, 0, 0, time.UTC)},
+		{
+			name:     "rfc3339_offset",
+			value:    "2025-05-31T09:00:00+09:00",
+			expected: time.Date(2025, 5, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{name: "negative_duration", value: "-1h", expectedErr: true},
+		{name: "date_only", value: "2025-05-31", expectedErr: true},
+		{name: "garbage", value: "yesterday", expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := parseTimeFilter(tt.value, now)
+			if tt.expectedErr {
+				require.ErrorIs(t, err, ErrInvalidTimeFilter)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tt.expected.Equal(parsed), "expected %s, got %s", tt.expected, parsed)
+		})
+	}
+}
+
+func TestTableStyle(t *testing.T) {
+	t.Run("plain_when_not_a_terminal", func(t *testing.T) {
+		assert.Equal(t, tableStyle{}, newTableStyle(&bytes.Buffer{}))
+
+		reader, writer, err := os.Pipe()
+		require.NoError(t, err)
+		defer func() { _ = reader.Close() }() //nolint:errcheck // Test cleanup
+		defer func() { _ = writer.Close() }() //nolint:errcheck // Test cleanup
+		assert.Equal(t, tableStyle{}, newTableStyle(writer))
+	})
+
+	t.Run("status_cell", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			style    tableStyle
+			status   process.ProcessStatus
+			changed  bool
+			expected string
+		}{
+			{"plain", tableStyle{}, process.StatusRunning, false, "running   "},
+			{"plain_changed", tableStyle{}, process.StatusFailed, true, highlight("failed    ")},
+			{"icons", tableStyle{icons: true}, process.StatusStopped, false, "○ stopped   "},
+			{"color", tableStyle{icons: true, color: true}, process.StatusUnhealthy, false, "\033[33m◐ unhealthy \033[0m"},
+			{"unknown_status", tableStyle{icons: true, color: true}, process.ProcessStatus("weird"), false, "weird       "},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				assert.Equal(t, tt.expected, tt.style.statusCell(tt.status, tt.changed))
+			})
+		}
+	})
+
+	t.Run("fit", func(t *testing.T) {
+		style := tableStyle{width: 60}
+		assert.Equal(t, "npm run dev", style.fit("npm run dev", 40))
+		assert.Equal(t, "node server.js --inspec…", style.fit("node server.js --inspect --port 3000", 36))
+		assert.Equal(t, "node server.js --inspect --port 3000", style.fit("node server.js --inspect --port 3000", 55),
+			"too narrow to cut usefully")
+		assert.Equal(t, "node server.js --inspect --port 3000", tableStyle{}.fit("node server.js --inspect --port 3000", 36))
+	})
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/paveg/portguard/internal/metrics"
+	"github.com/paveg/portguard/internal/process"
+	"github.com/spf13/cobra"
+)
+
+// metricsListen is the address the metrics endpoint listens on
+var metricsListen string
+
+var metricsCmd = &cobra.Command{
+	Use:   "metrics",
+	Short: "Serve Prometheus metrics about managed processes",
+	Long: `Serve an HTTP /metrics endpoint in the Prometheus text format.
+
+Every scrape reloads the state file, re-checks PIDs and health checks, and probes the ports
+of running processes, so the numbers reflect processes started by any portguard command.
+
+Exported metrics:
+  portguard_processes{status}                    managed processes by status
+  portguard_process_restarts_total{process_id}   restarts performed by restart policies
+  portguard_health_check_failures_total          failed health checks since the server started
+  portguard_managed_ports_listening              ports of running processes that are in use
+  portguard_port_scan_duration_seconds           time spent scanning ports
+
+Examples:
+  portguard metrics
+  portguard metrics --listen 0.0.0.0:9108`,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		cfg, err := currentConfig()
+		if err != nil {
+			return err
+		}
+		portScanner, err := newPortScanner(cfg, 5*time.Second)
+		if err != nil {
+			return err
+		}
+		scanner := metrics.NewInstrumentedScanner(portScanner)
+		pm, err := initializeProcessManagerWithScanner(cfg, scanner)
+		if err != nil {
+			return fmt.Errorf("failed to initialize process manager: %w", err)
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", newMetricsHandler(pm, scanner))
+		server := &http.Server{
+			Addr:              metricsListen,
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = server.Shutdown(shutdownCtx) //nolint:errcheck // Exiting anyway
+		}()
+
+		fmt.Printf("Serving metrics on http://%s/metrics\n", metricsListen)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("metrics server failed: %w", err)
+		}
+		return nil
+	},
+}
+
+// newMetricsHandler serves metrics after bringing the manager's view of processes up to date
+func newMetricsHandler(pm *process.ProcessManager, scanner *metrics.InstrumentedScanner) http.Handler {
+	var listeningPorts atomic.Int64
+
+	registry := metrics.NewRegistry()
+	registry.Register(metrics.NewProcessCollector(pm))
+	registry.Register(scanner)
+	registry.Register(metrics.CollectorFunc(func() []metrics.Family {
+		return []metrics.Family{{
+			Name:    "portguard_managed_ports_listening",
+			Help:    "Ports of running managed processes that are in use.",
+			Type:    metrics.TypeGauge,
+			Samples: []metrics.Sample{{Value: float64(listeningPorts.Load())}},
+		}}
+	}))
+	metricsHandler := registry.Handler()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := pm.ReloadState(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// Failed checks are counted by the manager and show up in the metrics
+		_ = pm.RefreshStatuses() //nolint:errcheck // Reflected in portguard_health_check_failures_total
+
+		var listening int64
+		for _, proc := range pm.ListProcesses(process.ProcessListOptions{}) {
+			for _, portNum := range proc.AllPorts() {
+				if scanner.IsPortInUse(portNum) {
+					listening++
+				}
+			}
+		}
+		listeningPorts.Store(listening)
+
+		metricsHandler.ServeHTTP(w, r)
+	})
+}
+
+func init() {
+	rootCmd.AddCommand(metricsCmd)
+
+	metricsCmd.Flags().StringVar(&metricsListen, "listen", "127.0.0.1:9108", "address to serve /metrics on")
+}
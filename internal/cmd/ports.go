@@ -29,8 +29,16 @@ Examples:
   portguard ports --start 3000 --end 4000
   portguard ports --check 3000`,
 	RunE: func(_ *cobra.Command, _ []string) error {
+		cfg, err := currentConfig()
+		if err != nil {
+			return err
+		}
+
 		// Initialize port scanner
-		scanner := portpkg.NewScanner(5 * time.Second)
+		scanner, err := newPortScanner(cfg, 5*time.Second)
+		if err != nil {
+			return err
+		}
 
 		// Handle single port check
 		if checkPort > 0 {
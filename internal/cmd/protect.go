@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// unprotect clears the protected flag instead of setting it
+var unprotect bool
+
+var protectCmd = &cobra.Command{
+	Use:   "protect <id>",
+	Short: "Protect a managed process from cleanup",
+	Long: `Mark a managed process as protected so "portguard clean" and stale-process cleanup keep it,
+e.g. a shared database. Use clean --include-protected to remove it anyway, or --remove to clear the mark.
+Stopping the process explicitly with "portguard stop" still works.
+
+Examples:
+  portguard protect postgres-a1b2c3
+  portguard protect postgres-a1b2c3 --remove`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		pm, err := initializeProcessManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize process manager: %w", err)
+		}
+
+		if err := pm.SetProtected(args[0], !unprotect); err != nil {
+			return fmt.Errorf("failed to update process %s: %w", args[0], err)
+		}
+
+		if unprotect {
+			fmt.Printf("✅ Process %s is no longer protected\n", args[0])
+		} else {
+			fmt.Printf("✅ Process %s is protected from cleanup\n", args[0])
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(protectCmd)
+
+	protectCmd.Flags().BoolVar(&unprotect, "remove", false, "remove the protection instead of adding it")
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/paveg/portguard/internal/process"
+	"github.com/spf13/cobra"
+)
+
+// ErrRestartsFailed is returned when at least one unhealthy process could not be restarted
+var ErrRestartsFailed = errors.New("some processes could not be restarted")
+
+// restartMax caps how many processes one sweep restarts; 0 means no limit
+var restartMax int
+
+// Outcomes of a restart sweep for one process
+const (
+	restartActionRestarted    = "restarted"
+	restartActionWouldRestart = "would_restart"
+	restartActionSkipped      = "skipped"
+	restartActionFailed       = "failed"
+)
+
+var restartUnhealthyCmd = &cobra.Command{
+	Use:   "restart-unhealthy",
+	Short: "Restart every process whose health check is failing",
+	Long: `Restart all managed processes currently marked unhealthy, keeping their IDs and options.
+Protected processes (see "portguard protect") are reported but left alone. The command exits
+with an error if any restart fails.
+
+Examples:
+  portguard restart-unhealthy --dry-run
+  portguard restart-unhealthy --max 2
+  portguard restart-unhealthy --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		pm, err := initializeProcessManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize process manager: %w", err)
+		}
+		return runRestartUnhealthy(cmd.OutOrStdout(), pm, dryRun, jsonOutput, restartMax)
+	},
+}
+
+// RestartOutcome reports what a restart sweep did with one unhealthy process
+type RestartOutcome struct {
+	ProcessID string `json:"process_id"`
+	Command   string `json:"command"`
+	Action    string `json:"action"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// runRestartUnhealthy restarts unhealthy processes in ID order, skipping protected ones and
+// stopping after maxRestarts restarts when it is positive
+func runRestartUnhealthy(w io.Writer, pm *process.ProcessManager, dryRun, asJSON bool, maxRestarts int) error {
+	var unhealthy []*process.ManagedProcess
+	for _, proc := range pm.ListProcesses(process.ProcessListOptions{}) {
+		if proc.Status == process.StatusUnhealthy {
+			unhealthy = append(unhealthy, proc)
+		}
+	}
+	sort.Slice(unhealthy, func(i, j int) bool { return unhealthy[i].ID < unhealthy[j].ID })
+
+	outcomes := make([]RestartOutcome, 0, len(unhealthy))
+	restarted, failed := 0, 0
+	for _, proc := range unhealthy {
+		outcome := RestartOutcome{ProcessID: proc.ID, Command: proc.Command}
+		switch {
+		case proc.Protected:
+			outcome.Action = restartActionSkipped
+			outcome.Reason = "protected"
+		case maxRestarts > 0 && restarted >= maxRestarts:
+			outcome.Action = restartActionSkipped
+			outcome.Reason = fmt.Sprintf("--max %d reached", maxRestarts)
+		case dryRun:
+			outcome.Action = restartActionWouldRestart
+			restarted++
+		default:
+			if err := pm.RestartProcess(proc.ID, false); err != nil {
+				outcome.Action = restartActionFailed
+				outcome.Reason = err.Error()
+				failed++
+			} else {
+				outcome.Action = restartActionRestarted
+			}
+			restarted++
+		}
+		outcomes = append(outcomes, outcome)
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(map[string]interface{}{
+			"dry_run":  dryRun,
+			"outcomes": outcomes,
+		}); err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+	} else {
+		printRestartOutcomes(w, outcomes, dryRun)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%w: %d of %d", ErrRestartsFailed, failed, len(outcomes))
+	}
+	return nil
+}
+
+// printRestartOutcomes writes one line per process followed by a summary
+func printRestartOutcomes(w io.Writer, outcomes []RestartOutcome, dryRun bool) {
+	if len(outcomes) == 0 {
+		fmt.Fprintln(w, "No unhealthy processes")
+		return
+	}
+
+	if dryRun {
+		fmt.Fprintln(w, "Dry run mode - showing what would be restarted:")
+	}
+	counts := make(map[string]int)
+	for _, outcome := range outcomes {
+		counts[outcome.Action]++
+		switch outcome.Action {
+		case restartActionRestarted:
+			fmt.Fprintf(w, "✅ %s restarted: %s\n", outcome.ProcessID, outcome.Command)
+		case restartActionWouldRestart:
+			fmt.Fprintf(w, "  - %s would be restarted: %s\n", outcome.ProcessID, outcome.Command)
+		case restartActionSkipped:
+			fmt.Fprintf(w, "⏭️  %s skipped (%s)\n", outcome.ProcessID, outcome.Reason)
+		case restartActionFailed:
+			fmt.Fprintf(w, "❌ %s failed: %s\n", outcome.ProcessID, outcome.Reason)
+		}
+	}
+
+	if dryRun {
+		fmt.Fprintf(w, "\nWould restart %d of %d unhealthy process(es)\n", counts[restartActionWouldRestart], len(outcomes))
+		return
+	}
+	fmt.Fprintf(w, "\nRestarted %d, skipped %d, failed %d\n",
+		counts[restartActionRestarted], counts[restartActionSkipped], counts[restartActionFailed])
+}
+
+func init() {
+	rootCmd.AddCommand(restartUnhealthyCmd)
+
+	restartUnhealthyCmd.Flags().BoolVar(&dryRun, "dry-run", false, "show what would be restarted without restarting anything")
+	restartUnhealthyCmd.Flags().BoolVar(&jsonOutput, "json", false, "output in JSON format")
+	restartUnhealthyCmd.Flags().IntVar(&restartMax, "max", 0, "restart at most N processes (0 means no limit)")
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/paveg/portguard/internal/process"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sweepExecutor starts every command except "broken" and reports all processes as exited
+type sweepExecutor struct {
+	mutex   sync.Mutex
+	started []string
+}
+
+func (e *sweepExecutor) Start(spec process.ExecSpec) (int, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if spec.Command == "broken" {
+		return 0, os.ErrPermission
+	}
+	e.started = append(e.started, spec.Command)
+	return 50000 + len(e.started), nil
+}
+func (e *sweepExecutor) Wait(int) error                    { return nil }
+func (e *sweepExecutor) Signal(int, bool, os.Signal) error { return os.ErrProcessDone }
+func (e *sweepExecutor) Kill(int, bool) error              { return os.ErrProcessDone }
+func (e *sweepExecutor) IsAlive(int) bool                  { return false }
+
+func TestRunRestartUnhealthy(t *testing.T) {
+	newProcess := func(id, command string, status process.ProcessStatus, protected bool) *process.ManagedProcess {
+		return &process.ManagedProcess{
+			ID:        id,
+			Command:   command,
+			PID:       40000,
+			Status:    status,
+			Protected: protected,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			LastSeen:  time.Now(),
+		}
+	}
+
+	tests := []struct {
+		name        string
+		processes   []*process.ManagedProcess
+		dryRun      bool
+		max         int
+		expectedErr error
+		actions     map[string]string
+		started     []string
+	}{
+		{
+			name: "restarts_only_unhealthy",
+			processes: []*process.ManagedProcess{
+				newProcess("api", "api-server", process.StatusUnhealthy, false),
+				newProcess("web", "web-server", process.StatusRunning, false),
+			},
+			actions: map[string]string{"api": restartActionRestarted},
+			started: []string{"api-server"},
+		},
+		{
+			name: "skips_protected",
+			processes: []*process.ManagedProcess{
+				newProcess("api", "api-server", process.StatusUnhealthy, false),
+				newProcess("db", "db-server", process.StatusUnhealthy, true),
+			},
+			actions: map[string]string{"api": restartActionRestarted, "db": restartActionSkipped},
+			started: []string{"api-server"},
+		},
+		{
+			name: "respects_max",
+			processes: []*process.ManagedProcess{
+				newProcess("a", "a-server", process.StatusUnhealthy, false),
+				newProcess("b", "b-server", process.StatusUnhealthy, false),
+			},
+			max:     1,
+			actions: map[string]string{"a": restartActionRestarted, "b": restartActionSkipped},
+			started: []string{"a-server"},
+		},
+		{
+			name: "dry_run_restarts_nothing",
+			processes: []*process.ManagedProcess{
+				newProcess("api", "api-server", process.StatusUnhealthy, false),
+			},
+			dryRun:  true,
+			actions: map[string]string{"api": restartActionWouldRestart},
+		},
+		{
+			name: "reports_failures",
+			processes: []*process.ManagedProcess{
+				newProcess("api", "api-server", process.StatusUnhealthy, false),
+				newProcess("bad", "broken", process.StatusUnhealthy, false),
+			},
+			expectedErr: ErrRestartsFailed,
+			actions:     map[string]string{"api": restartActionRestarted, "bad": restartActionFailed},
+			started:     []string{"api-server"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			store, err := createTestStateStore(filepath.Join(tempDir, "state.json"))
+			require.NoError(t, err)
+			processes := make(map[string]*process.ManagedProcess, len(tt.processes))
+			for _, proc := range tt.processes {
+				processes[proc.ID] = proc
+			}
+			require.NoError(t, store.Save(processes))
+
+			pm := createTestProcessManager(t, tempDir)
+			pm.SetMonitoringDisabled(true)
+			executor := &sweepExecutor{}
+			pm.SetExecutor(executor)
+
+			var buf bytes.Buffer
+			err = runRestartUnhealthy(&buf, pm, tt.dryRun, true, tt.max)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			var report struct {
+				DryRun   bool             `json:"dry_run"`
+				Outcomes []RestartOutcome `json:"outcomes"`
+			}
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &report))
+			assert.Equal(t, tt.dryRun, report.DryRun)
+
+			actions := make(map[string]string, len(report.Outcomes))
+			for _, outcome := range report.Outcomes {
+				actions[outcome.ProcessID] = outcome.Action
+			}
+			assert.Equal(t, tt.actions, actions)
+			assert.Equal(t, tt.started, executor.started)
+		})
+	}
+
+	t.Run("text_output", func(t *testing.T) {
+		pm := createTestProcessManager(t, t.TempDir())
+
+		var buf bytes.Buffer
+		require.NoError(t, runRestartUnhealthy(&buf, pm, false, false, 0))
+		assert.Contains(t, buf.String(), "No unhealthy processes")
+	})
+}
@@ -4,12 +4,20 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/paveg/portguard/internal/config"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
-// Version will be set during build time via ldflags
-var Version = "dev"
+// Build metadata, set during build time via ldflags
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildDate = "unknown"
+)
+
+// configEnvVar names a config file to load when --config is not given
+const configEnvVar = "PORTGUARD_CONFIG"
 
 var (
 	rootCmd = &cobra.Command{
@@ -24,8 +32,9 @@ GitHub Copilot, and Cursor repeatedly start servers without checking
 if they're already running, causing port conflicts and resource waste.`,
 		Version: Version,
 		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			loadInvocationConfig()
 			if verbose {
-				fmt.Println("Using config file:", viper.ConfigFileUsed())
+				fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 			}
 		},
 	}
@@ -33,7 +42,12 @@ if they're already running, causing port conflicts and resource waste.`,
 
 // Execute runs the root command
 func Execute() error {
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+	// Monitors must stop and batched state changes reach disk however the command ended
+	if closeErr := closeProcessManagers(); closeErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", closeErr)
+	}
+	if err != nil {
 		return fmt.Errorf("command execution failed: %w", err)
 	}
 	return nil
@@ -42,25 +56,40 @@ func Execute() error {
 func init() {
 	cobra.OnInitialize(initConfig)
 
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.portguard.yml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file: .yml, .yaml, .toml or .json (default is $"+configEnvVar+", then $HOME/.portguard.yml)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().DurationVar(&lockTimeout, "lock-timeout", 0, "how long to wait for the state lock (overrides default.lock_timeout; 0 keeps it)")
+	rootCmd.PersistentFlags().DurationVar(&scanTimeout, "scan-timeout", 0, "upper bound for port and process scans (overrides default.scan_timeout; 0 keeps it)")
+	rootCmd.PersistentFlags().BoolVar(&noPersist, "no-persist", false, "keep process state in memory instead of writing ~/.portguard, e.g. on read-only CI images")
 
 	if err := viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose")); err != nil {
 		fmt.Printf("Warning: failed to bind verbose flag: %v\n", err)
 	}
 }
 
+// initConfig selects the config file: --config, then $PORTGUARD_CONFIG, then a search of
+// the home and current directories
 func initConfig() {
-	if cfgFile != "" {
-		viper.SetConfigFile(cfgFile)
+	configFile := cfgFile
+	if configFile == "" {
+		configFile = os.Getenv(configEnvVar)
+	}
+
+	if configFile != "" {
+		viper.SetConfigFile(configFile)
 	} else {
 		home, err := os.UserHomeDir()
 		cobra.CheckErr(err)
 
-		viper.AddConfigPath(home)
-		viper.AddConfigPath(".")
-		viper.SetConfigType("yaml")
-		viper.SetConfigName(".portguard")
+		// Pick up YAML, TOML or JSON config files; the format follows the extension
+		if configFile, found := config.FindConfigFile(home, "."); found {
+			viper.SetConfigFile(configFile)
+		} else {
+			viper.AddConfigPath(home)
+			viper.AddConfigPath(".")
+			viper.SetConfigType("yaml")
+			viper.SetConfigName(".portguard")
+		}
 	}
 
 	viper.AutomaticEnv()
@@ -68,7 +97,7 @@ func initConfig() {
 
 	if err := viper.ReadInConfig(); err == nil {
 		if verbose {
-			fmt.Println("Using config file:", viper.ConfigFileUsed())
+			fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 		}
 	}
 }
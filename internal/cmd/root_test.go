@@ -187,6 +187,43 @@ default:
 		assert.Empty(t, viper.ConfigFileUsed())
 	})
 
+	t.Run("config_file_precedence", func(t *testing.T) {
+		tempDir := t.TempDir()
+		writeConfig := func(name, logLevel string) string {
+			path := filepath.Join(tempDir, name)
+			require.NoError(t, os.WriteFile(path, []byte("default:\n  log_level: "+logLevel+"\n"), 0o600))
+			return path
+		}
+		flagFile := writeConfig("flag.yml", "error")
+		envFile := writeConfig("env.yml", "warn")
+		writeConfig(".portguard.yml", "debug") // Found by the search order
+		t.Setenv("HOME", tempDir)
+
+		tests := []struct {
+			name     string
+			flag     string
+			env      string
+			expected string
+		}{
+			{name: "flag_wins_over_env", flag: flagFile, env: envFile, expected: flagFile},
+			{name: "env_wins_over_search", env: envFile, expected: envFile},
+			{name: "search_order_without_flag_or_env", expected: filepath.Join(tempDir, ".portguard.yml")},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				viper.Reset()
+				cfgFile = tt.flag
+				defer func() { cfgFile = "" }()
+				t.Setenv(configEnvVar, tt.env)
+
+				initConfig()
+
+				assert.Equal(t, tt.expected, viper.ConfigFileUsed())
+			})
+		}
+	})
+
 	t.Run("environment_variable_support", func(t *testing.T) {
 		// Reset viper
 		viper.Reset()
@@ -215,6 +252,7 @@ default:
 
 func TestRootCommandPersistentPreRun(t *testing.T) {
 	// Test the PersistentPreRun function
+	t.Cleanup(func() { invocationConfig, invocationConfigErr = nil, nil })
 	tempDir := t.TempDir()
 	configFile := filepath.Join(tempDir, "test.yml")
 
@@ -231,16 +269,16 @@ func TestRootCommandPersistentPreRun(t *testing.T) {
 	// Test with verbose = true
 	verbose = true
 
-	// Capture output by running PersistentPreRun
-	oldStdout := os.Stdout
+	// Diagnostics go to stderr so they never mix with command output
+	oldStderr := os.Stderr
 	pipeReader, pipeWriter, pipeErr := os.Pipe()
 	require.NoError(t, pipeErr)
-	os.Stdout = pipeWriter
+	os.Stderr = pipeWriter
 
 	rootCmd.PersistentPreRun(rootCmd, []string{})
 
 	_ = pipeWriter.Close() // Close pipe to signal end of input
-	os.Stdout = oldStdout
+	os.Stderr = oldStderr
 
 	output := make([]byte, 1024)
 	readLen, readErr := pipeReader.Read(output)
@@ -258,12 +296,12 @@ func TestRootCommandPersistentPreRun(t *testing.T) {
 	// Should not produce output
 	pipeReader2, pipeWriter2, pipeErr2 := os.Pipe()
 	require.NoError(t, pipeErr2)
-	os.Stdout = pipeWriter2
+	os.Stderr = pipeWriter2
 
 	rootCmd.PersistentPreRun(rootCmd, []string{})
 
 	_ = pipeWriter2.Close() // Close pipe to signal end of input
-	os.Stdout = oldStdout
+	os.Stderr = oldStderr
 
 	output2 := make([]byte, 1024)
 	readLen2, readErr2 := pipeReader2.Read(output2)
@@ -273,6 +311,11 @@ func TestRootCommandPersistentPreRun(t *testing.T) {
 
 	// Should be empty or very minimal output
 	assert.Equal(t, 0, readLen2)
+
+	// The configuration is loaded once for the subcommand
+	require.NoError(t, invocationConfigErr)
+	require.NotNil(t, invocationConfig)
+	assert.Equal(t, configFile, invocationConfig.SourceFile())
 }
 
 func TestRootCommandIntegration(t *testing.T) {
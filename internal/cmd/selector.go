@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/paveg/portguard/internal/process"
+)
+
+var (
+	ErrNoProcessMatch   = errors.New("no managed process matches")
+	ErrAmbiguousProcess = errors.New("process selector is ambiguous")
+)
+
+// resolveProcess maps a selector to exactly one process. The selector is a full ID, a unique
+// ID prefix (like a short git hash) or ":port" for the running process bound to that port,
+// looked up with byPort. An ambiguous selector fails with the candidate IDs.
+func resolveProcess(
+	processes []*process.ManagedProcess,
+	byPort func(int) (*process.ManagedProcess, bool),
+	selector string,
+) (*process.ManagedProcess, error) {
+	if portText, isPort := strings.CutPrefix(selector, ":"); isPort {
+		portNum, err := strconv.Atoi(portText)
+		if err != nil || portNum < 1 || portNum > 65535 {
+			return nil, fmt.Errorf("invalid port selector %q: %w", selector, ErrNoProcessMatch)
+		}
+		proc, found := byPort(portNum)
+		if !found {
+			return nil, fmt.Errorf("%w %q", ErrNoProcessMatch, selector)
+		}
+		return proc, nil
+	}
+
+	if selector == "" {
+		return nil, fmt.Errorf("%w: empty selector", ErrNoProcessMatch)
+	}
+	for _, proc := range processes {
+		if proc.ID == selector {
+			return proc, nil
+		}
+	}
+	return selectOne(selector, processes, func(proc *process.ManagedProcess) bool {
+		return strings.HasPrefix(proc.ID, selector)
+	})
+}
+
+// selectOne returns the only process accepted by match
+func selectOne(selector string, processes []*process.ManagedProcess, match func(*process.ManagedProcess) bool) (*process.ManagedProcess, error) {
+	var matches []*process.ManagedProcess
+	for _, proc := range processes {
+		if match(proc) {
+			matches = append(matches, proc)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("%w %q", ErrNoProcessMatch, selector)
+	case 1:
+		return matches[0], nil
+	default:
+		ids := make([]string, 0, len(matches))
+		for _, proc := range matches {
+			ids = append(ids, proc.ID)
+		}
+		sort.Strings(ids)
+		return nil, fmt.Errorf("%w: %q matches %s", ErrAmbiguousProcess, selector, strings.Join(ids, ", "))
+	}
+}
+
+// resolveProcessID resolves a selector against all processes known to pm
+func resolveProcessID(pm *process.ProcessManager, selector string) (string, error) {
+	proc, err := resolveProcess(pm.ListProcesses(process.ProcessListOptions{IncludeStopped: true}), pm.GetProcessByPort, selector)
+	if err != nil {
+		return "", err
+	}
+	return proc.ID, nil
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/paveg/portguard/internal/process"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveProcess(t *testing.T) {
+	processes := []*process.ManagedProcess{
+		{ID: "npm-dev-a1b2c3", Status: process.StatusRunning, Port: 3000},
+		{ID: "npm-dev-a1f4e5", Status: process.StatusUnhealthy, Port: 3001, Ports: []int{3001, 24678}},
+		{ID: "npm-dev-b7c8d9", Status: process.StatusStopped, Port: 3002},
+		{ID: "vite", Status: process.StatusRunning, Port: 5173},
+		{ID: "vite-preview", Status: process.StatusRunning, Port: 4173},
+		{ID: "api", Status: process.StatusRunning, Port: 8080},
+	}
+	// Stands in for the manager's port index, which only holds running processes
+	byPort := func(portNum int) (*process.ManagedProcess, bool) {
+		for _, proc := range processes {
+			if proc.IsRunning() && proc.UsesPort(portNum) {
+				return proc, true
+			}
+		}
+		return nil, false
+	}
+
+	tests := []struct {
+		name     string
+		selector string
+		wantID   string
+		wantErr  error
+	}{
+		{name: "full_id", selector: "npm-dev-a1b2c3", wantID: "npm-dev-a1b2c3"},
+		{name: "unique_prefix", selector: "npm-dev-a1b", wantID: "npm-dev-a1b2c3"},
+		{name: "prefix_of_stopped_process", selector: "npm-dev-b", wantID: "npm-dev-b7c8d9"},
+		{name: "exact_id_wins_over_prefix", selector: "vite", wantID: "vite"},
+		{name: "ambiguous_prefix", selector: "npm-dev-a1", wantErr: ErrAmbiguousProcess},
+		{name: "unknown_prefix", selector: "rails", wantErr: ErrNoProcessMatch},
+		{name: "port", selector: ":3000", wantID: "npm-dev-a1b2c3"},
+		{name: "additional_port", selector: ":24678", wantID: "npm-dev-a1f4e5"},
+		{name: "port_of_stopped_process", selector: ":3002", wantErr: ErrNoProcessMatch},
+		{name: "unused_port", selector: ":9090", wantErr: ErrNoProcessMatch},
+		{name: "invalid_port", selector: ":http", wantErr: ErrNoProcessMatch},
+		{name: "empty", selector: "", wantErr: ErrNoProcessMatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proc, err := resolveProcess(processes, byPort, tt.selector)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, proc)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantID, proc.ID)
+		})
+	}
+
+	t.Run("ambiguous_error_lists_candidates", func(t *testing.T) {
+		_, err := resolveProcess(processes, byPort, "npm-dev-a1")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "npm-dev-a1b2c3, npm-dev-a1f4e5")
+	})
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/paveg/portguard/internal/process"
+	"github.com/spf13/cobra"
+)
+
+var signalCmd = &cobra.Command{
+	Use:   "signal <id|prefix|:port|port> <signal>",
+	Short: "Send a signal to a managed process",
+	Long: `Send a signal to a managed process without stopping it.
+Useful for servers that reload their configuration on SIGHUP. The process status is left unchanged.
+
+The process is selected like for stop: by ID, unique ID prefix, or a port (bare or ":port").
+
+Signals can be given by name (HUP, SIGHUP, usr1) or by number.
+
+Examples:
+  portguard signal npm-dev-a1b2c3 HUP
+  portguard signal npm-dev-a1 USR2
+  portguard signal :8080 SIGUSR1`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(_ *cobra.Command, args []string) error {
+		target := args[0]
+
+		sig, err := process.ParseSignal(args[1])
+		if err != nil {
+			return err
+		}
+
+		// Initialize process manager
+		pm, err := initializeProcessManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize process manager: %w", err)
+		}
+
+		// Check if target is a port number
+		if port, err := strconv.Atoi(target); err == nil {
+			proc, found := pm.GetProcessByPort(port)
+			if !found {
+				fmt.Printf("No running processes found on port %d\n", port)
+				return nil
+			}
+			target = proc.ID
+		} else if target, err = resolveProcessID(pm, target); err != nil {
+			return err
+		}
+
+		if err := pm.SignalProcess(target, sig); err != nil {
+			return fmt.Errorf("failed to signal process %s: %w", target, err)
+		}
+
+		fmt.Printf("✅ Sent %v to process %s\n", sig, target)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(signalCmd)
+}
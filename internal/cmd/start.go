@@ -3,19 +3,37 @@ package cmd
 import (
 	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/paveg/portguard/internal/config"
 	"github.com/paveg/portguard/internal/lock"
-	portpkg "github.com/paveg/portguard/internal/port"
 	"github.com/paveg/portguard/internal/process"
-	"github.com/paveg/portguard/internal/state"
 	"github.com/spf13/cobra"
 )
 
+// ErrWaitHealthyWithoutCheck is returned when --wait-healthy is used without a health check
+var ErrWaitHealthyWithoutCheck = errors.New("--wait-healthy requires a health check")
+
+// Flags specific to the start command
+var (
+	waitHealthy   bool
+	waitTimeout   time.Duration
+	restartPolicy string
+	maxRestarts   int
+	autoPort      bool
+	noMonitor     bool
+	envFile       string
+	protected     bool
+	logFile       string
+	readyPattern  string
+	allowPrivPort bool
+	startProject  bool
+	bindAddress   string
+	detach        bool
+)
+
 var startCmd = &cobra.Command{
 	Use:   "start <command|project>",
 	Short: "Start a new process or reuse existing one",
@@ -28,128 +46,50 @@ Examples:
   # Direct command
   portguard start "go run main.go" --port 3000
   portguard start "npm run dev" --port 3001 --health-check http://localhost:3001/health
+  portguard start "npm run dev" --health-check http://localhost:3001/health --wait-healthy --wait-timeout 1m
+  portguard start "vite --port {port}" --port 5173 --auto-port
+  portguard start "npm run dev" --env-file .env.local
+  portguard start "postgres -D ./data" --port 5432 --protected
+  portguard start "npm run dev" --log-file dev.log --ready-pattern "ready in \d+ ms"
+  portguard start "vite" --port 5173 --bind 0.0.0.0   # vite gets --host 0.0.0.0
+  portguard start "python3 -m http.server {port} --bind {host}" --port 8000 --bind 127.0.0.1
   
   # Project from configuration
   portguard start api          # Uses projects.api.command from config
-  portguard start web          # Uses projects.web.command from config`,
+  portguard start web          # Uses projects.web.command from config
+  portguard start web --project --dry-run  # Fails if web is not a project; shows what would run`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(_ *cobra.Command, args []string) error {
-		input := args[0]
-
-		// Load configuration
-		cfg, err := config.Load()
+		cfg, err := currentConfig()
 		if err != nil {
-			// Configuration loading failed, but we can still proceed with direct commands
-			fmt.Printf("Warning: Failed to load configuration: %v\n", err)
-		}
-
-		// ENHANCED: Check if input is a project name first
-		var command string
-		var projectConfig *config.ProjectConfig
-		var isProject bool
-
-		if cfg != nil {
-			if project, exists := cfg.GetProject(input); exists {
-				// Input is a project name
-				command = project.Command
-				projectConfig = project
-				isProject = true
-				fmt.Printf("Using project '%s' with command: %s\n", input, command)
-			}
+			return err
 		}
 
-		if !isProject {
-			// Input is a direct command
-			command = input
-			fmt.Printf("Starting command: %s\n", command)
-		}
-
-		// Use project configuration for defaults if available
-		effectivePort := port
-		effectiveHealthCheck := healthCheck
-
-		if projectConfig != nil {
-			// Override with project config if not specified via flags
-			if port == 0 && projectConfig.Port > 0 {
-				effectivePort = projectConfig.Port
-				fmt.Printf("Using project port: %d\n", effectivePort)
-			}
-			if healthCheck == "" && projectConfig.HealthCheck != nil {
-				// Convert project health check to string format for parsing
-				switch projectConfig.HealthCheck.Type {
-				case process.HealthCheckHTTP:
-					effectiveHealthCheck = projectConfig.HealthCheck.Target
-				case process.HealthCheckTCP:
-					effectiveHealthCheck = projectConfig.HealthCheck.Target
-				case process.HealthCheckCommand:
-					effectiveHealthCheck = projectConfig.HealthCheck.Target
-				case process.HealthCheckNone:
-					// No health check configured
-					effectiveHealthCheck = ""
-				}
-				if effectiveHealthCheck != "" {
-					fmt.Printf("Using project health check: %s\n", effectiveHealthCheck)
-				}
-			}
+		plan, err := resolveStart(cfg, args[0], startProject)
+		if err != nil {
+			return err
 		}
+		printStartPlan(plan)
 
-		if effectivePort > 0 {
-			fmt.Printf("Target port: %d\n", effectivePort)
-		}
-		if effectiveHealthCheck != "" {
-			fmt.Printf("Health check: %s\n", effectiveHealthCheck)
-		}
-		if background {
-			fmt.Println("Running in background mode")
+		if dryRun {
+			fmt.Println("Dry run mode - the process was not started")
+			return nil
 		}
 
 		// Initialize process manager
-		pm, err := initializeProcessManager()
+		scanner, err := newPortScanner(cfg, 5*time.Second)
 		if err != nil {
-			return fmt.Errorf("failed to initialize process manager: %w", err)
+			return err
 		}
-
-		// Parse command and arguments
-		commandParts, err := parseCommand(command)
+		pm, err := initializeProcessManagerWithScanner(cfg, scanner)
 		if err != nil {
-			return fmt.Errorf("failed to parse command: %w", err)
-		}
-
-		var cmd string
-		var cmdArgs []string
-		if len(commandParts) > 0 {
-			cmd = commandParts[0]
-			if len(commandParts) > 1 {
-				cmdArgs = commandParts[1:]
-			}
-		}
-
-		// Setup start options
-		options := process.StartOptions{
-			Port:       effectivePort,
-			Background: background,
-		}
-
-		// Add project-specific options if available
-		if projectConfig != nil {
-			options.Environment = projectConfig.Environment
-			options.WorkingDir = projectConfig.WorkingDir
-			options.LogFile = projectConfig.LogFile
-		}
-
-		// Parse health check if provided
-		if effectiveHealthCheck != "" {
-			healthCheckObj, parseErr := parseHealthCheck(effectiveHealthCheck)
-			if parseErr != nil {
-				return fmt.Errorf("failed to parse health check: %w", parseErr)
-			}
-			options.HealthCheck = healthCheckObj
+			return fmt.Errorf("failed to initialize process manager: %w", err)
 		}
 
 		// Start the process
-		process, err := pm.StartProcess(cmd, cmdArgs, options)
+		process, err := pm.StartProcess(plan.Command, plan.Args, plan.Options)
 		if err != nil {
-			return fmt.Errorf("failed to start process: %w", err)
+			return startError(err)
 		}
 
 		fmt.Printf("✅ Process started successfully:\n")
@@ -160,60 +100,270 @@ Examples:
 		if process.Port > 0 {
 			fmt.Printf("   Port: %d\n", process.Port)
 		}
-		if isProject {
-			fmt.Printf("   Project: %s\n", input)
+		if plan.Project != "" {
+			fmt.Printf("   Project: %s\n", plan.Project)
 		}
 
 		return nil
 	},
 }
 
+// startPlan is the process start resolves from its argument, flags and configuration
+type startPlan struct {
+	Project string // Configured project the process comes from; empty for a direct command
+	Command string
+	Args    []string
+	Options process.StartOptions
+}
+
+// resolveStart turns the start argument into a plan. A configured project's settings apply
+// wherever no flag overrides them. With requireProject the argument must name a project;
+// otherwise names that are not projects run as direct commands.
+func resolveStart(cfg *config.Config, input string, requireProject bool) (*startPlan, error) {
+	command := input
+	var projectConfig *config.ProjectConfig
+	plan := &startPlan{}
+
+	if cfg != nil {
+		if _, exists := cfg.GetProject(input); exists {
+			// Input is a project name; layer its settings over the configured defaults
+			project, err := cfg.ResolveProject(input)
+			if err != nil {
+				return nil, fmt.Errorf("invalid project configuration: %w", err)
+			}
+			command = project.Command
+			projectConfig = project
+			plan.Project = input
+		}
+	}
+	if requireProject && projectConfig == nil {
+		return nil, projectNotFoundError(cfg, input)
+	}
+
+	// Parse command and arguments
+	commandParts, err := parseCommand(command)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse command: %w", err)
+	}
+	plan.Command = commandParts[0]
+	plan.Args = commandParts[1:]
+
+	// Flags win over the project configuration
+	effectivePort := port
+	if projectConfig != nil && port == 0 && projectConfig.Port > 0 {
+		effectivePort = projectConfig.Port
+	}
+
+	// Setup start options
+	options := process.StartOptions{
+		Port:        effectivePort,
+		Background:  background,
+		Detached:    detach,
+		WaitHealthy: waitHealthy,
+		WaitTimeout: waitTimeout,
+
+		RestartPolicy: process.RestartPolicy(restartPolicy),
+		MaxRestarts:   maxRestarts,
+		AutoPort:      autoPort,
+		NoMonitor:     noMonitor,
+		EnvFile:       envFile,
+		Protected:     protected,
+
+		AllowPrivilegedPort: allowPrivPort,
+	}
+
+	// Search upward from the requested port, bounded by the configured port range
+	if autoPort && cfg != nil && cfg.Default != nil && cfg.Default.PortRange != nil &&
+		cfg.Default.PortRange.End >= effectivePort {
+		options.PortRangeEnd = cfg.Default.PortRange.End
+	}
+
+	// Add project-specific options if available
+	if projectConfig != nil {
+		options.Environment = projectConfig.Environment
+		options.WorkingDir = projectConfig.WorkingDir
+		options.LogFile = projectConfig.LogFile
+		options.ReadyLogPattern = projectConfig.ReadyLogPattern
+		options.BindAddress = projectConfig.BindAddress
+		options.PreStart = projectConfig.PreStart
+		options.PostStop = projectConfig.PostStop
+		if envFile == "" {
+			options.EnvFile = projectConfig.EnvFile
+		}
+	}
+	if logFile != "" {
+		options.LogFile = logFile
+	}
+	if bindAddress != "" {
+		options.BindAddress = bindAddress
+	}
+	if readyPattern != "" {
+		options.ReadyLogPattern = readyPattern
+	}
+
+	// Parse health check if provided; a project check keeps its resolved timeouts and expectations
+	switch {
+	case healthCheck != "":
+		healthCheckObj, parseErr := parseHealthCheck(healthCheck)
+		if parseErr != nil {
+			return nil, fmt.Errorf("failed to parse health check: %w", parseErr)
+		}
+		options.HealthCheck = healthCheckObj
+	case projectConfig != nil && projectConfig.HealthCheck != nil && runsHealthCheck(projectConfig.HealthCheck, options.Port):
+		projectHealthCheck := *projectConfig.HealthCheck
+		options.HealthCheck = &projectHealthCheck
+	}
+
+	if waitHealthy {
+		if options.HealthCheck == nil {
+			return nil, ErrWaitHealthyWithoutCheck
+		}
+		options.HealthCheck.Enabled = true
+	}
+
+	plan.Options = options
+	return plan, nil
+}
+
+// runsHealthCheck reports whether a configured health check has a type that probes something and a target.
+// TCP and gRPC checks without a target dial the process port, so they run whenever there is one.
+func runsHealthCheck(healthCheck *process.HealthCheck, port int) bool {
+	switch healthCheck.Type {
+	case process.HealthCheckTCP, process.HealthCheckGRPC:
+		return healthCheck.Target != "" || port > 0
+	case process.HealthCheckHTTP, process.HealthCheckCommand:
+		return healthCheck.Target != ""
+	case process.HealthCheckNone:
+		return false
+	}
+	return false
+}
+
+// projectNotFoundError explains that name is not a configured project, listing the ones that are
+func projectNotFoundError(cfg *config.Config, name string) error {
+	if cfg == nil {
+		return fmt.Errorf("%w: %s (no configuration loaded)", config.ErrProjectNotFound, name)
+	}
+	projects := cfg.ListProjects()
+	if len(projects) == 0 {
+		return fmt.Errorf("%w: %s (no projects are configured)", config.ErrProjectNotFound, name)
+	}
+	sort.Strings(projects)
+	return fmt.Errorf("%w: %s (configured projects: %s)", config.ErrProjectNotFound, name, strings.Join(projects, ", "))
+}
+
+// printStartPlan describes what start is about to run
+func printStartPlan(plan *startPlan) {
+	command := strings.Join(append([]string{plan.Command}, plan.Args...), " ")
+	if plan.Project != "" {
+		fmt.Printf("Using project '%s' with command: %s\n", plan.Project, command)
+	} else {
+		fmt.Printf("Starting command: %s\n", command)
+	}
+
+	options := plan.Options
+	if options.Port > 0 {
+		fmt.Printf("Target port: %d\n", options.Port)
+	}
+	if options.WorkingDir != "" {
+		fmt.Printf("Working directory: %s\n", options.WorkingDir)
+	}
+	if options.BindAddress != "" {
+		fmt.Printf("Bind address: %s\n", options.BindAddress)
+	}
+	if options.HealthCheck != nil {
+		fmt.Printf("Health check: %s %s\n", options.HealthCheck.Type, options.HealthCheck.Target)
+	}
+	for _, hook := range options.PreStart {
+		fmt.Printf("Pre-start hook: %s\n", hook)
+	}
+	if options.Background {
+		fmt.Println("Running in background mode")
+	}
+	if options.Detached {
+		fmt.Println("Detached from the terminal")
+	}
+	if options.WaitHealthy {
+		fmt.Printf("Waiting up to %v for process to become healthy\n", options.WaitTimeout)
+	}
+	if options.ReadyLogPattern != "" {
+		fmt.Printf("Waiting up to %v for %q in %s\n", options.WaitTimeout, options.ReadyLogPattern, options.LogFile)
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(startCmd)
 
 	startCmd.Flags().IntVarP(&port, "port", "p", 0, "target port for the process")
 	startCmd.Flags().StringVar(&healthCheck, "health-check", "", "health check URL or command")
 	startCmd.Flags().BoolVarP(&background, "background", "b", false, "run process in background")
+	startCmd.Flags().BoolVar(&detach, "detach", false, "start the process in its own session so it survives the terminal closing (output goes to --log-file or is discarded)")
+	startCmd.Flags().BoolVar(&waitHealthy, "wait-healthy", false, "wait until the health check passes before returning")
+	startCmd.Flags().DurationVar(&waitTimeout, "wait-timeout", 30*time.Second, "maximum time to wait with --wait-healthy or --ready-pattern")
+	startCmd.Flags().StringVar(&restartPolicy, "restart", string(process.RestartNever), "restart policy when the process exits: never, on-failure or always")
+	startCmd.Flags().IntVar(&maxRestarts, "max-restarts", 3, "maximum number of crash restarts before the process is marked failed")
+	startCmd.Flags().BoolVar(&noMonitor, "no-monitor", false, "do not monitor the process in the background (restart policies are ignored)")
+	startCmd.Flags().StringVar(&envFile, "env-file", "", "load environment variables from a .env file")
+	startCmd.Flags().StringVar(&logFile, "log-file", "", "write process output to this file")
+	startCmd.Flags().StringVar(&readyPattern, "ready-pattern", "", "wait until a new log line matches this regular expression (requires a log file)")
+	startCmd.Flags().BoolVar(&protected, "protected", false, "keep the process when running clean unless --include-protected is given")
+	startCmd.Flags().BoolVar(&allowPrivPort, "allow-privileged-port", false, "start on a port below 1024 even when portguard is not root (e.g. the binary has CAP_NET_BIND_SERVICE)")
+	startCmd.Flags().StringVar(&bindAddress, "bind", "", "interface to listen on, e.g. 127.0.0.1 or 0.0.0.0 ({host} in the command is replaced; known dev servers get their host flag)")
+	startCmd.Flags().BoolVar(&startProject, "project", false, "treat the argument as a configured project name and fail if it is not defined")
+	startCmd.Flags().BoolVar(&dryRun, "dry-run", false, "show what would be started without starting it")
+	startCmd.Flags().BoolVar(&autoPort, "auto-port", false, "use the next free port if the target port is taken by another program ({port} in the command is replaced)")
 }
 
 // initializeProcessManager creates a new ProcessManager with default configurations
 func initializeProcessManager() (*process.ProcessManager, error) {
-	// Get home directory for state file
-	homeDir, err := os.UserHomeDir()
+	cfg, err := currentConfig()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user home directory: %w", err)
+		return nil, err
 	}
-
-	// Create .portguard directory if it doesn't exist
-	portguardDir := filepath.Join(homeDir, ".portguard")
-	if mkdirErr := os.MkdirAll(portguardDir, 0o755); mkdirErr != nil {
-		return nil, fmt.Errorf("failed to create portguard directory: %w", mkdirErr)
+	scanner, err := newPortScanner(cfg, 5*time.Second)
+	if err != nil {
+		return nil, err
 	}
+	return initializeProcessManagerWithScanner(cfg, scanner)
+}
 
-	// Initialize state store
-	stateFile := filepath.Join(portguardDir, "state.json")
-	stateStore, err := state.NewJSONStore(stateFile)
+// initializeProcessManagerWithScanner creates a ProcessManager configured by cfg that uses the given port scanner
+func initializeProcessManagerWithScanner(cfg *config.Config, portScanner process.PortScanner) (*process.ProcessManager, error) {
+	stateStore, lockManager, err := newStateComponents(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create state store: %w", err)
+		return nil, err
 	}
 
-	// Initialize lock manager
-	lockFile := filepath.Join(portguardDir, "portguard.lock")
-	lockManager := lock.NewFileLock(lockFile, 5*time.Second)
-
-	// Initialize port scanner
-	portScanner := portpkg.NewScanner(5 * time.Second)
-
 	// Create and return process manager
 	pm := process.NewProcessManager(stateStore, lockManager, portScanner)
+	configureProcessManager(pm, cfg)
 	return pm, nil
 }
 
-// parseCommand parses a command string into command and arguments
+// startError explains a StartProcess failure, telling apart processes that could not be
+// started from state that could not be saved and suggesting how to resolve port conflicts
+func startError(err error) error {
+	var conflict *process.PortConflictError
+	switch {
+	case errors.As(err, &conflict) && conflict.Managed:
+		return fmt.Errorf("failed to start process: %w; stop it with 'portguard stop %s' or choose another port", err, conflict.ProcessID)
+	case errors.As(err, &conflict):
+		return fmt.Errorf("failed to start process: %w; inspect it with 'portguard check %d' or use --auto-port", err, conflict.Port)
+	case errors.Is(err, process.ErrStateNotWritable), errors.Is(err, lock.ErrLockNotWritable):
+		return fmt.Errorf("%w (use --no-persist to run without saving state)", err)
+	case errors.Is(err, process.ErrStartFailed):
+		return err
+	default:
+		return fmt.Errorf("failed to start process: %w", err)
+	}
+}
+
+// parseCommand parses a command string into command and arguments, honoring shell quoting
 func parseCommand(command string) ([]string, error) {
-	// Simple parsing by splitting on whitespace
-	// For more complex parsing with quotes, we'd need a proper shell parser
-	parts := strings.Fields(strings.TrimSpace(command))
+	parts, err := process.SplitCommandLine(command)
+	if err != nil {
+		return nil, err
+	}
 	if len(parts) == 0 {
 		return nil, errors.New("empty command")
 	}
@@ -1,10 +1,14 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/paveg/portguard/internal/config"
+	"github.com/paveg/portguard/internal/lock"
 	"github.com/paveg/portguard/internal/process"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -62,6 +66,16 @@ func TestParseCommand(t *testing.T) {
 			input:    "ls",
 			expected: []string{"ls"},
 		},
+		{
+			name:     "quoted_argument",
+			input:    `python -c "import x; x.run()"`,
+			expected: []string{"python", "-c", "import x; x.run()"},
+		},
+		{
+			name:      "unterminated_quote",
+			input:     `echo 'hello`,
+			expectErr: true,
+		},
 		{
 			name:      "empty_command",
 			input:     "",
@@ -224,3 +238,182 @@ func TestStartOptions_Structure(t *testing.T) {
 		assert.Equal(t, "http://localhost:3000/health", options.HealthCheck.Target)
 	})
 }
+
+func TestResolveStart(t *testing.T) {
+	cfg := &config.Config{
+		Projects: map[string]*config.ProjectConfig{
+			"web": {
+				Command:     "npm run dev",
+				Port:        3000,
+				WorkingDir:  "./web",
+				BindAddress: "127.0.0.1",
+				Environment: map[string]string{"NODE_ENV": "development"},
+				HealthCheck: &process.HealthCheck{Type: process.HealthCheckHTTP, Target: "http://localhost:3000/health"},
+			},
+			"worker": {
+				Command:     "go run ./cmd/worker",
+				HealthCheck: &process.HealthCheck{Type: process.HealthCheckNone},
+			},
+			"grpc-api": {
+				Command:     "go run ./cmd/api",
+				Port:        50051,
+				HealthCheck: &process.HealthCheck{Type: process.HealthCheckGRPC, Service: "api.Users"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		cfg            *config.Config
+		input          string
+		requireProject bool
+		portFlag       int
+		expectedErr    error
+		errContains    string
+		validate       func(t *testing.T, plan *startPlan)
+	}{
+		{
+			name:  "project",
+			cfg:   cfg,
+			input: "web",
+			validate: func(t *testing.T, plan *startPlan) {
+				t.Helper()
+				assert.Equal(t, "web", plan.Project)
+				assert.Equal(t, "npm", plan.Command)
+				assert.Equal(t, []string{"run", "dev"}, plan.Args)
+				assert.Equal(t, 3000, plan.Options.Port)
+				assert.Equal(t, "./web", plan.Options.WorkingDir)
+				assert.Equal(t, "127.0.0.1", plan.Options.BindAddress)
+				assert.Equal(t, "development", plan.Options.Environment["NODE_ENV"])
+				require.NotNil(t, plan.Options.HealthCheck)
+				assert.Equal(t, "http://localhost:3000/health", plan.Options.HealthCheck.Target)
+			},
+		},
+		{
+			name:  "project_grpc_check_on_project_port",
+			cfg:   cfg,
+			input: "grpc-api",
+			validate: func(t *testing.T, plan *startPlan) {
+				t.Helper()
+				require.NotNil(t, plan.Options.HealthCheck)
+				assert.Equal(t, process.HealthCheckGRPC, plan.Options.HealthCheck.Type)
+				assert.Empty(t, plan.Options.HealthCheck.Target)
+				assert.Equal(t, "api.Users", plan.Options.HealthCheck.Service)
+			},
+		},
+		{
+			name:     "port_flag_overrides_project",
+			cfg:      cfg,
+			input:    "web",
+			portFlag: 4000,
+			validate: func(t *testing.T, plan *startPlan) {
+				t.Helper()
+				assert.Equal(t, 4000, plan.Options.Port)
+			},
+		},
+		{
+			name:           "project_without_health_check",
+			cfg:            cfg,
+			input:          "worker",
+			requireProject: true,
+			validate: func(t *testing.T, plan *startPlan) {
+				t.Helper()
+				assert.Equal(t, "worker", plan.Project)
+				assert.Nil(t, plan.Options.HealthCheck)
+			},
+		},
+		{
+			name:  "direct_command",
+			cfg:   cfg,
+			input: "go run main.go",
+			validate: func(t *testing.T, plan *startPlan) {
+				t.Helper()
+				assert.Empty(t, plan.Project)
+				assert.Equal(t, "go", plan.Command)
+				assert.Equal(t, []string{"run", "main.go"}, plan.Args)
+			},
+		},
+		{
+			name:           "undefined_project",
+			cfg:            cfg,
+			input:          "api",
+			requireProject: true,
+			expectedErr:    config.ErrProjectNotFound,
+			errContains:    "configured projects: grpc-api, web, worker",
+		},
+		{
+			name:           "no_configuration",
+			input:          "web",
+			requireProject: true,
+			expectedErr:    config.ErrProjectNotFound,
+			errContains:    "no configuration loaded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			originalPort := port
+			port = tt.portFlag
+			defer func() { port = originalPort }()
+
+			plan, err := resolveStart(tt.cfg, tt.input, tt.requireProject)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+			tt.validate(t, plan)
+		})
+	}
+}
+
+func TestStartError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		sentinel error
+		contains string
+	}{
+		{
+			name:     "managed_conflict_suggests_stop",
+			err:      &process.PortConflictError{Port: 3000, PID: 1234, Managed: true, ProcessID: "npm-run-dev-1"},
+			sentinel: process.ErrPortAlreadyInUse,
+			contains: "portguard stop npm-run-dev-1",
+		},
+		{
+			name:     "external_conflict_suggests_check",
+			err:      &process.PortConflictError{Port: 3000, PID: 4242, ProcessName: "python3"},
+			sentinel: process.ErrPortAlreadyInUse,
+			contains: "held by python3, PID 4242); inspect it with 'portguard check 3000' or use --auto-port",
+		},
+		{
+			name:     "state_not_writable_suggests_no_persist",
+			err:      fmt.Errorf("%w: read-only file system", process.ErrStateNotWritable),
+			sentinel: process.ErrStateNotWritable,
+			contains: "--no-persist",
+		},
+		{
+			name:     "lock_not_writable_suggests_no_persist",
+			err:      fmt.Errorf("failed to acquire lock: %w: permission denied", lock.ErrLockNotWritable),
+			sentinel: lock.ErrLockNotWritable,
+			contains: "--no-persist",
+		},
+		{
+			name:     "other_errors_are_wrapped",
+			err:      errors.New("boom"),
+			contains: "failed to start process: boom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := startError(tt.err)
+			require.ErrorIs(t, err, tt.err)
+			if tt.sentinel != nil {
+				require.ErrorIs(t, err, tt.sentinel)
+			}
+			assert.Contains(t, err.Error(), tt.contains)
+		})
+	}
+}
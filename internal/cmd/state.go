@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/paveg/portguard/internal/lock"
+	"github.com/paveg/portguard/internal/state"
+	"github.com/spf13/cobra"
+)
+
+// stateImportMerge adds imported processes to the existing state instead of replacing it
+var stateImportMerge bool
+
+var stateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Export or import the managed-process state",
+	Long: `Snapshot the managed-process state to move it between machines or keep a baseline.
+
+Examples:
+  portguard state export > snapshot.json
+  portguard state import snapshot.json
+  portguard state import snapshot.json --merge`,
+}
+
+var stateExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write the state as JSON to stdout",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		store, _, err := openStateStore()
+		if err != nil {
+			return err
+		}
+		if err := store.Export(cmd.OutOrStdout()); err != nil {
+			return fmt.Errorf("failed to export state: %w", err)
+		}
+		return nil
+	},
+}
+
+var stateImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Load a state snapshot, backing up the current state first",
+	Long: `Load a snapshot written by "portguard state export". The snapshot is validated before anything
+is changed, and snapshots from a newer portguard schema are rejected. The current state file is
+backed up next to it, then replaced, or with --merge extended, by the snapshot's processes.
+When merging, processes already in the state are kept over snapshot entries with the same ID.
+Imported processes are recorded as stopped: their PIDs may belong to other programs by now.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		file, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to open snapshot: %w", err)
+		}
+		defer func() { _ = file.Close() }() //nolint:errcheck // Read-only file
+
+		snapshot, err := state.ReadSnapshot(file)
+		if err != nil {
+			return fmt.Errorf("invalid snapshot %s: %w", args[0], err)
+		}
+
+		store, lockManager, err := openStateStore()
+		if err != nil {
+			return err
+		}
+		if err := lockManager.Lock(); err != nil {
+			return fmt.Errorf("failed to acquire lock: %w", err)
+		}
+		defer func() {
+			_ = lockManager.Unlock() //nolint:errcheck // Best effort unlock
+		}()
+
+		if err := store.Import(snapshot, stateImportMerge); err != nil {
+			return fmt.Errorf("failed to import state: %w", err)
+		}
+
+		mode := "replaced"
+		if stateImportMerge {
+			mode = "merged"
+		}
+		fmt.Printf("✅ Imported %d process(es) from %s (%s)\n", len(snapshot.Processes), args[0], mode)
+		return nil
+	},
+}
+
+// openStateStore opens the default state file together with the lock guarding it
+func openStateStore() (*state.JSONStore, lock.Locker, error) {
+	portguardDir, err := getPortguardDir()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get portguard directory: %w", err)
+	}
+
+	store, err := state.NewJSONStore(filepath.Join(portguardDir, "state.json"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create state store: %w", err)
+	}
+	cfg, err := currentConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	lockManager, err := newLockManager(cfg, portguardDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	return store, lockManager, nil
+}
+
+func init() {
+	rootCmd.AddCommand(stateCmd)
+	stateCmd.AddCommand(stateExportCmd)
+	stateCmd.AddCommand(stateImportCmd)
+
+	stateImportCmd.Flags().BoolVar(&stateImportMerge, "merge", false, "merge into the existing state instead of replacing it")
+}
@@ -17,25 +17,50 @@ var statusCmd = &cobra.Command{
 	Long: `Show detailed status and health information for a specific process or all processes.
 Includes port information, health check results, and resource usage.
 
+With --summary, prints a one-line overview for dashboards and scripts: processes by
+status, total restarts, the uptime of the oldest running process and the number of
+port conflicts, i.e. ports of stopped processes now held by something else or ports
+claimed by several running processes.
+
 Examples:
   portguard status
-  portguard status abc123
-  portguard status --json`,
+  portguard status npm-dev-a1b2c3
+  portguard status --json
+  portguard status --summary --json`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(_ *cobra.Command, args []string) error {
+		cfg, err := currentConfig()
+		if err != nil {
+			return err
+		}
+
 		// Initialize process manager
-		pm, err := initializeProcessManager()
+		pmScanner, err := newPortScanner(cfg, 5*time.Second)
+		if err != nil {
+			return err
+		}
+		pm, err := initializeProcessManagerWithScanner(cfg, pmScanner)
 		if err != nil {
 			return fmt.Errorf("failed to initialize process manager: %w", err)
 		}
 
+		// Port scanner for additional port information
+		scanner, err := newPortScanner(cfg, 2*time.Second)
+		if err != nil {
+			return err
+		}
+
 		// Handle single process status
 		if len(args) == 1 {
-			return handleSingleProcessStatus(pm, args[0])
+			return handleSingleProcessStatus(pm, scanner, args[0])
+		}
+
+		if statusSummaryOnly {
+			return handleStatusSummary(pm, scanner)
 		}
 
 		// Handle system-wide status
-		return handleSystemStatus(pm)
+		return handleSystemStatus(pm, scanner)
 	},
 }
 
@@ -43,8 +68,12 @@ func init() {
 	rootCmd.AddCommand(statusCmd)
 
 	statusCmd.Flags().BoolVar(&jsonOutput, "json", false, "output in JSON format")
+	statusCmd.Flags().BoolVar(&statusSummaryOnly, "summary", false, "print a one-line summary of all processes")
 }
 
+// statusSummaryOnly limits status to the aggregate summary
+var statusSummaryOnly bool
+
 // ProcessStatus represents detailed status information for a process
 type ProcessStatus struct {
 	ID          string               `json:"id"`
@@ -61,6 +90,7 @@ type ProcessStatus struct {
 	Environment map[string]string    `json:"environment,omitempty"`
 	WorkingDir  string               `json:"working_dir,omitempty"`
 	LogFile     string               `json:"log_file,omitempty"`
+	LogError    string               `json:"log_attach_error,omitempty"`
 	HealthCheck *process.HealthCheck `json:"health_check,omitempty"`
 	PortInfo    *PortStatusInfo      `json:"port_info,omitempty"`
 }
@@ -85,10 +115,24 @@ type SystemStatus struct {
 	CheckedAt          time.Time              `json:"checked_at"`
 	Processes          []ProcessStatus        `json:"processes"`
 	PortSummary        map[string]interface{} `json:"port_summary"`
+	Summary            StatusSummary          `json:"summary"`
+}
+
+// StatusSummary aggregates the state of all managed processes
+type StatusSummary struct {
+	Total         int    `json:"total"`
+	Running       int    `json:"running"` // Includes processes still starting
+	Unhealthy     int    `json:"unhealthy"`
+	Stopped       int    `json:"stopped"`
+	Failed        int    `json:"failed"`
+	Restarts      int    `json:"restarts"`
+	OldestID      string `json:"oldest_running_id,omitempty"`
+	OldestUptime  string `json:"oldest_running_uptime,omitempty"`
+	PortConflicts int    `json:"port_conflicts"`
 }
 
 // handleSingleProcessStatus shows detailed status for a specific process
-func handleSingleProcessStatus(pm *process.ProcessManager, processID string) error {
+func handleSingleProcessStatus(pm *process.ProcessManager, scanner *portpkg.Scanner, processID string) error {
 	proc, exists := pm.GetProcess(processID)
 	if !exists {
 		return fmt.Errorf("process %s not found", processID)
@@ -96,9 +140,6 @@ func handleSingleProcessStatus(pm *process.ProcessManager, processID string) err
 
 	fmt.Printf("Getting detailed status for process %s...\n", processID)
 
-	// Create port scanner for additional port information
-	scanner := portpkg.NewScanner(2 * time.Second)
-
 	status := convertToProcessStatus(proc, scanner)
 
 	if jsonOutput {
@@ -140,6 +181,9 @@ func handleSingleProcessStatus(pm *process.ProcessManager, processID string) err
 	if status.LogFile != "" {
 		fmt.Printf("  Log File: %s\n", status.LogFile)
 	}
+	if status.LogError != "" {
+		fmt.Printf("  Log File: not captured (%s)\n", status.LogError)
+	}
 	if len(status.Environment) > 0 {
 		fmt.Printf("  Environment Variables: %d set\n", len(status.Environment))
 	}
@@ -151,7 +195,7 @@ func handleSingleProcessStatus(pm *process.ProcessManager, processID string) err
 }
 
 // handleSystemStatus shows overall system status
-func handleSystemStatus(pm *process.ProcessManager) error {
+func handleSystemStatus(pm *process.ProcessManager, scanner *portpkg.Scanner) error {
 	fmt.Println("Getting system-wide status...")
 
 	// Get all processes
@@ -162,9 +206,6 @@ func handleSystemStatus(pm *process.ProcessManager) error {
 	runningOptions := process.ProcessListOptions{IncludeStopped: false}
 	runningProcesses := pm.ListProcesses(runningOptions)
 
-	// Create port scanner
-	scanner := portpkg.NewScanner(2 * time.Second)
-
 	// Calculate statistics
 	var healthyCount, unhealthyCount, stoppedCount int
 	var portsInUse []int
@@ -207,6 +248,7 @@ func handleSystemStatus(pm *process.ProcessManager) error {
 		CheckedAt:          time.Now(),
 		Processes:          processStatuses,
 		PortSummary:        portSummary,
+		Summary:            summarizeProcesses(allProcesses, scanner.IsPortInUse, time.Now()),
 	}
 
 	if jsonOutput {
@@ -224,14 +266,16 @@ func handleSystemStatus(pm *process.ProcessManager) error {
 	fmt.Printf("  Total Processes: %d\n", systemStatus.TotalProcesses)
 	fmt.Printf("  Running: %d | Stopped: %d\n", systemStatus.RunningProcesses, systemStatus.StoppedProcesses)
 	fmt.Printf("  Healthy: %d | Unhealthy: %d\n", systemStatus.HealthyProcesses, systemStatus.UnhealthyProcesses)
+	fmt.Printf("  Failed: %d | Restarts: %d | Port Conflicts: %d\n",
+		systemStatus.Summary.Failed, systemStatus.Summary.Restarts, systemStatus.Summary.PortConflicts)
 	fmt.Printf("  Ports In Use: %d\n", systemStatus.PortsInUse)
 	fmt.Printf("  Checked At: %s\n", systemStatus.CheckedAt.Format(time.RFC3339))
 
 	// Show process summary if any exist
 	if len(processStatuses) > 0 {
 		fmt.Printf("\nProcess Summary:\n")
-		fmt.Printf("%-12s %-10s %-10s %-6s %-s\n", "PROCESS ID", "STATUS", "HEALTHY", "PORT", "COMMAND")
-		fmt.Println("────────────────────────────────────────────────────────────────────")
+		fmt.Printf("%-20s %-10s %-10s %-6s %-s\n", "PROCESS ID", "STATUS", "HEALTHY", "PORT", "COMMAND")
+		fmt.Println("────────────────────────────────────────────────────────────────────────────")
 
 		for i := range processStatuses {
 			status := &processStatuses[i]
@@ -245,8 +289,8 @@ func handleSystemStatus(pm *process.ProcessManager) error {
 				portStr = strconv.Itoa(status.Port)
 			}
 
-			fmt.Printf("%-12s %-10s %-10s %-6s %-s\n",
-				status.ID[:8]+"...",
+			fmt.Printf("%-20s %-10s %-10s %-6s %-s\n",
+				status.ID,
 				status.Status,
 				healthyStr,
 				portStr,
@@ -276,6 +320,7 @@ func convertToProcessStatus(proc *process.ManagedProcess, scanner *portpkg.Scann
 		Environment: proc.Environment,
 		WorkingDir:  proc.WorkingDir,
 		LogFile:     proc.LogFile,
+		LogError:    proc.LogAttachError,
 		HealthCheck: proc.HealthCheck,
 	}
 
@@ -298,3 +343,92 @@ func convertToProcessStatus(proc *process.ManagedProcess, scanner *portpkg.Scann
 
 	return status
 }
+
+// handleStatusSummary prints the aggregate summary of all processes
+func handleStatusSummary(pm *process.ProcessManager, scanner *portpkg.Scanner) error {
+	processes := pm.ListProcesses(process.ProcessListOptions{IncludeStopped: true})
+	summary := summarizeProcesses(processes, scanner.IsPortInUse, time.Now())
+
+	if jsonOutput {
+		output, err := json.Marshal(summary)
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(output))
+		return nil
+	}
+
+	fmt.Println(formatStatusSummary(summary))
+	return nil
+}
+
+// summarizeProcesses counts processes by status and detects port conflicts. A port conflicts
+// when it belongs to a process that is not running but is in use anyway, or when several
+// running processes claim it.
+func summarizeProcesses(processes []*process.ManagedProcess, portInUse func(int) bool, now time.Time) StatusSummary {
+	summary := StatusSummary{Total: len(processes)}
+	var oldestStart time.Time
+	claims := make(map[int]int)
+	idlePorts := make(map[int]bool)
+
+	for _, proc := range processes {
+		summary.Restarts += proc.RestartCount
+
+		switch proc.Status {
+		case process.StatusRunning, process.StatusPending:
+			summary.Running++
+		case process.StatusUnhealthy:
+			summary.Unhealthy++
+		case process.StatusFailed:
+			summary.Failed++
+		case process.StatusStopped:
+			summary.Stopped++
+		}
+
+		ports := proc.AllPorts()
+		if !proc.IsRunning() && proc.Status != process.StatusPending {
+			for _, portNum := range ports {
+				idlePorts[portNum] = true
+			}
+			continue
+		}
+		for _, portNum := range ports {
+			claims[portNum]++
+		}
+
+		started := proc.StartedAt
+		if started.IsZero() {
+			started = proc.CreatedAt
+		}
+		if summary.OldestID == "" || started.Before(oldestStart) {
+			summary.OldestID = proc.ID
+			oldestStart = started
+		}
+	}
+
+	if summary.OldestID != "" {
+		summary.OldestUptime = now.Sub(oldestStart).Round(time.Second).String()
+	}
+	for _, count := range claims {
+		if count > 1 {
+			summary.PortConflicts++
+		}
+	}
+	for portNum := range idlePorts {
+		if claims[portNum] == 0 && portInUse(portNum) {
+			summary.PortConflicts++
+		}
+	}
+	return summary
+}
+
+// formatStatusSummary renders the summary as a single line
+func formatStatusSummary(summary StatusSummary) string {
+	line := fmt.Sprintf("%d managed: %d running, %d unhealthy, %d stopped, %d failed | %d restarts | %d port conflicts",
+		summary.Total, summary.Running, summary.Unhealthy, summary.Stopped, summary.Failed,
+		summary.Restarts, summary.PortConflicts)
+	if summary.OldestID != "" {
+		line += fmt.Sprintf(" | oldest %s up %s", summary.OldestID, summary.OldestUptime)
+	}
+	return line
+}
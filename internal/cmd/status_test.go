@@ -23,7 +23,7 @@ func TestHandleSingleProcessStatus(t *testing.T) {
 	pm := createStatusTestProcessManager(t, tempDir)
 
 	// Test with non-existent process
-	err = handleSingleProcessStatus(pm, "nonexistent")
+	err = handleSingleProcessStatus(pm, portpkg.NewScanner(time.Second), "nonexistent")
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "not found")
 }
@@ -38,7 +38,7 @@ func TestHandleSystemStatus(t *testing.T) {
 	pm := createStatusTestProcessManager(t, tempDir)
 
 	// Test handleSystemStatus - should work even with no processes
-	err = handleSystemStatus(pm)
+	err = handleSystemStatus(pm, portpkg.NewScanner(time.Second))
 	assert.NoError(t, err)
 }
 
@@ -153,7 +153,7 @@ func TestStatusCommandWithJSONOutput(t *testing.T) {
 		defer func() { jsonOutput = false }()
 
 		// Test with non-existent process (will error but exercises the JSON path)
-		err = handleSingleProcessStatus(pm, "test-process")
+		err = handleSingleProcessStatus(pm, portpkg.NewScanner(time.Second), "test-process")
 		require.Error(t, err)
 	})
 
@@ -171,7 +171,7 @@ func TestStatusCommandWithJSONOutput(t *testing.T) {
 		defer func() { jsonOutput = false }()
 
 		// Test system status
-		err = handleSystemStatus(pm)
+		err = handleSystemStatus(pm, portpkg.NewScanner(time.Second))
 		assert.NoError(t, err)
 	})
 }
@@ -268,3 +268,41 @@ func (t *testStatusPortScanner) ScanRange(startPort, endPort int) ([]portpkg.Por
 func (t *testStatusPortScanner) FindAvailablePort(startPort int) (int, error) {
 	return startPort, nil
 }
+
+func TestSummarizeProcesses(t *testing.T) {
+	now := time.Date(2026, 1, 2, 15, 0, 0, 0, time.UTC)
+	processes := []*process.ManagedProcess{
+		{ID: "web", Status: process.StatusRunning, Port: 3000, Ports: []int{3000, 24678}, StartedAt: now.Add(-2 * time.Hour), RestartCount: 2},
+		{ID: "api", Status: process.StatusUnhealthy, Port: 8080, StartedAt: now.Add(-3 * time.Hour), RestartCount: 1},
+		{ID: "docs", Status: process.StatusPending, Port: 3000, CreatedAt: now.Add(-time.Minute)},
+		{ID: "db", Status: process.StatusStopped, Port: 5432},
+		{ID: "cache", Status: process.StatusStopped, Port: 6379},
+		{ID: "worker", Status: process.StatusFailed, RestartCount: 5},
+	}
+	inUse := map[int]bool{5432: true}
+
+	summary := summarizeProcesses(processes, func(port int) bool { return inUse[port] }, now)
+
+	assert.Equal(t, StatusSummary{
+		Total:         6,
+		Running:       2,
+		Unhealthy:     1,
+		Stopped:       2,
+		Failed:        1,
+		Restarts:      8,
+		OldestID:      "api",
+		OldestUptime:  "3h0m0s",
+		PortConflicts: 2, // 3000 is claimed twice, 5432 is held by something else
+	}, summary)
+	assert.Equal(t,
+		"6 managed: 2 running, 1 unhealthy, 2 stopped, 1 failed | 8 restarts | 2 port conflicts | oldest api up 3h0m0s",
+		formatStatusSummary(summary))
+}
+
+func TestSummarizeProcesses_Empty(t *testing.T) {
+	summary := summarizeProcesses(nil, func(int) bool { return true }, time.Now())
+
+	assert.Equal(t, StatusSummary{}, summary)
+	assert.Equal(t, "0 managed: 0 running, 0 unhealthy, 0 stopped, 0 failed | 0 restarts | 0 port conflicts",
+		formatStatusSummary(summary))
+}
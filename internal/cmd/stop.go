@@ -9,13 +9,19 @@ import (
 )
 
 var stopCmd = &cobra.Command{
-	Use:   "stop <id|port>",
+	Use:   "stop <id|prefix|:port|port>",
 	Short: "Stop a managed process",
-	Long: `Stop a managed process by ID or port number.
-Gracefully shuts down the process and cleans up resources.
+	Long: `Stop a managed process by ID, unique ID prefix, ":port" or port number.
+Gracefully shuts down the process and cleans up resources. A process that "start" reused for
+several callers keeps running until each of them has stopped it; --force stops it right away.
+
+A port, bare or as ":port", selects the running process bound to it; an ID prefix must
+match exactly one process.
 
 Examples:
-  portguard stop abc123
+  portguard stop npm-dev-a1b2c3
+  portguard stop npm-dev-a1
+  portguard stop :3000
   portguard stop 3000
   portguard stop 3001 --force`,
 	Args: cobra.ExactArgs(1),
@@ -32,27 +38,20 @@ Examples:
 		if port, err := strconv.Atoi(target); err == nil {
 			fmt.Printf("Stopping process on port: %d\n", port)
 
-			// Find processes by port
-			options := process.ProcessListOptions{
-				FilterByPort:   port,
-				IncludeStopped: false,
-			}
-
-			processes := pm.ListProcesses(options)
-			if len(processes) == 0 {
+			proc, found := pm.GetProcessByPort(port)
+			if !found {
 				fmt.Printf("No running processes found on port %d\n", port)
 				return nil
 			}
-
-			// Stop all processes on this port
-			for _, proc := range processes {
-				if err := pm.StopProcess(proc.ID, force); err != nil {
-					fmt.Printf("Failed to stop process %s: %v\n", proc.ID, err)
-				} else {
-					fmt.Printf("✅ Process %s stopped successfully\n", proc.ID)
-				}
+			if err := pm.StopProcess(proc.ID, force); err != nil {
+				return fmt.Errorf("failed to stop process %s: %w", proc.ID, err)
 			}
+			printStopResult(pm, proc.ID)
 		} else {
+			target, err = resolveProcessID(pm, target)
+			if err != nil {
+				return err
+			}
 			fmt.Printf("Stopping process with ID: %s\n", target)
 
 			if force {
@@ -64,13 +63,23 @@ Examples:
 				return fmt.Errorf("failed to stop process %s: %w", target, err)
 			}
 
-			fmt.Printf("✅ Process %s stopped successfully\n", target)
+			printStopResult(pm, target)
 		}
 
 		return nil
 	},
 }
 
+// printStopResult reports whether a stop terminated the process or only released one of its references
+func printStopResult(pm *process.ProcessManager, id string) {
+	if proc, exists := pm.GetProcess(id); exists && proc.IsRunning() {
+		fmt.Printf("Process %s is still used by %d other caller(s); released one reference (use --force to stop it now)\n",
+			id, proc.References())
+		return
+	}
+	fmt.Printf("✅ Process %s stopped successfully\n", id)
+}
+
 func init() {
 	rootCmd.AddCommand(stopCmd)
 
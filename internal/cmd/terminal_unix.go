@@ -0,0 +1,20 @@
+//go:build !windows
+// +build !windows
+
+package cmd
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// terminalWidth returns the column count of the terminal f is attached to, and false
+// when f is not a terminal
+func terminalWidth(f *os.File) (int, bool) {
+	size, err := unix.IoctlGetWinsize(int(f.Fd()), unix.TIOCGWINSZ) //nolint:gosec // File descriptors fit in an int
+	if err != nil {
+		return 0, false
+	}
+	return int(size.Col), true
+}
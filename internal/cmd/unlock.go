@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/paveg/portguard/internal/lock"
+	"github.com/spf13/cobra"
+)
+
+// ErrLockHolderAlive is returned when the lock holder still runs and --force was not given
+var ErrLockHolderAlive = errors.New("lock holder is still running")
+
+// unlockForce clears the lock even when its holder appears to be alive
+var unlockForce bool
+
+var unlockCmd = &cobra.Command{
+	Use:   "unlock",
+	Short: "Show and clear a stuck portguard lock",
+	Long: `Report which process holds the portguard lock and clear it if that process is gone.
+
+A portguard command that crashed mid-operation can leave the lock behind, making later
+commands time out. Without --force only locks whose holder PID no longer exists are cleared.
+With --force the lock is removed even if its holder looks alive, which can let two portguard
+commands modify the state at the same time.
+
+Examples:
+  portguard unlock
+  portguard unlock --force`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		portguardDir, err := getPortguardDir()
+		if err != nil {
+			return fmt.Errorf("failed to get portguard directory: %w", err)
+		}
+
+		cfg, err := currentConfig()
+		if err != nil {
+			return err
+		}
+		backend, err := lockBackend(cfg)
+		if err != nil {
+			return err
+		}
+		lockPath := lock.Path(portguardDir)
+		return runUnlock(cmd.OutOrStdout(), lock.New(backend, lockPath, effectiveLockTimeout(cfg, 5*time.Second)), lockPath, unlockForce)
+	},
+}
+
+// runUnlock reports the lock holder and clears the lock when it is stale or force is set
+func runUnlock(w io.Writer, locker lock.Locker, lockPath string, force bool) error {
+	info, err := locker.GetLockInfo()
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		fmt.Fprintf(w, "No lock is held (%s does not exist)\n", lockPath)
+		return nil
+	case errors.Is(err, lock.ErrNoLockHolder):
+		fmt.Fprintf(w, "No lock is held on %s\n", lockPath)
+		return nil
+	case err != nil && !force:
+		return fmt.Errorf("cannot read lock holder from %s (use --force to remove it): %w", lockPath, err)
+	case err != nil:
+		fmt.Fprintf(w, "⚠️  Lock file %s is unreadable: %v\n", lockPath, err)
+	default:
+		fmt.Fprintf(w, "Lock %s held by PID %d since %s (%s ago)\n",
+			lockPath, info.PID, info.Timestamp.Format(time.RFC3339), time.Since(info.Timestamp).Round(time.Second))
+
+		switch {
+		case info.IsStale:
+			fmt.Fprintf(w, "PID %d is no longer running; the lock is stale\n", info.PID)
+		case !force:
+			return fmt.Errorf("%w: PID %d (use --force to clear the lock anyway)", ErrLockHolderAlive, info.PID)
+		default:
+			fmt.Fprintf(w, "⚠️  WARNING: PID %d still appears to be running.\n", info.PID)
+			fmt.Fprintln(w, "⚠️  Clearing its lock lets another portguard command change the state while it works.")
+		}
+	}
+
+	if err := locker.ForceClearLock(); err != nil {
+		return err
+	}
+	fmt.Fprintln(w, "✅ Lock cleared")
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(unlockCmd)
+
+	unlockCmd.Flags().BoolVar(&unlockForce, "force", false, "clear the lock even if its holder appears to be running")
+}
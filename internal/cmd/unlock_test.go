@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/paveg/portguard/internal/lock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunUnlock(t *testing.T) {
+	// A PID far above any default pid_max so the holder is provably gone
+	const deadPID = 999999999
+
+	tests := []struct {
+		name        string
+		lockData    string // empty means no lock file
+		force       bool
+		expectedErr error
+		cleared     bool
+		contains    []string
+	}{
+		{
+			name:     "no_lock",
+			contains: []string{"No lock is held"},
+		},
+		{
+			name:     "stale_lock",
+			lockData: fmt.Sprintf("%d\n%d\n1\n", deadPID, time.Now().Unix()),
+			cleared:  true,
+			contains: []string{fmt.Sprintf("held by PID %d", deadPID), "stale", "Lock cleared"},
+		},
+		{
+			name:        "live_lock_without_force",
+			lockData:    fmt.Sprintf("%d\n%d\n1\n", os.Getpid(), time.Now().Unix()),
+			expectedErr: ErrLockHolderAlive,
+		},
+		{
+			name:     "live_lock_with_force",
+			lockData: fmt.Sprintf("%d\n%d\n1\n", os.Getpid(), time.Now().Unix()),
+			force:    true,
+			cleared:  true,
+			contains: []string{"WARNING", "Lock cleared"},
+		},
+		{
+			name:        "invalid_lock_without_force",
+			lockData:    "garbage",
+			expectedErr: lock.ErrInvalidLockFormat,
+		},
+		{
+			name:     "invalid_lock_with_force",
+			lockData: "garbage",
+			force:    true,
+			cleared:  true,
+			contains: []string{"unreadable", "Lock cleared"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lockPath := filepath.Join(t.TempDir(), "portguard.lock")
+			if tt.lockData != "" {
+				require.NoError(t, os.WriteFile(lockPath, []byte(tt.lockData), 0o600))
+			}
+
+			var buf bytes.Buffer
+			err := runUnlock(&buf, lock.NewFileLock(lockPath, time.Second), lockPath, tt.force)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			_, statErr := os.Stat(lockPath)
+			if tt.cleared {
+				assert.True(t, os.IsNotExist(statErr), "lock file should be removed")
+			} else if tt.lockData != "" {
+				assert.NoError(t, statErr, "lock file should be kept")
+			}
+			for _, want := range tt.contains {
+				assert.Contains(t, buf.String(), want)
+			}
+		})
+	}
+}
+
+func TestRunUnlock_Flock(t *testing.T) {
+	lockPath := lock.Path(t.TempDir())
+	holder := lock.NewFlockLock(lockPath, time.Second)
+	require.NoError(t, holder.Lock())
+
+	var buf bytes.Buffer
+	err := runUnlock(&buf, lock.NewFlockLock(lockPath, time.Second), lockPath, false)
+	require.ErrorIs(t, err, ErrLockHolderAlive)
+
+	// The kernel lock of a running holder cannot be broken, even with --force
+	err = runUnlock(&buf, lock.NewFlockLock(lockPath, time.Second), lockPath, true)
+	require.ErrorIs(t, err, lock.ErrLockInUse)
+
+	require.NoError(t, holder.Unlock())
+	buf.Reset()
+	require.NoError(t, runUnlock(&buf, lock.NewFlockLock(lockPath, time.Second), lockPath, false))
+	assert.Contains(t, buf.String(), "No lock is held")
+}
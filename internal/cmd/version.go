@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/spf13/cobra"
+)
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Show version and build information",
+	Long: `Show the portguard version, the git commit and date it was built from, and the Go
+toolchain used. Builds without release metadata report "dev" and "unknown".
+
+Examples:
+  portguard version
+  portguard version --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return runVersion(cmd.OutOrStdout(), currentVersionInfo(), jsonOutput)
+	},
+}
+
+// VersionInfo describes the running binary
+type VersionInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+}
+
+// currentVersionInfo collects the ldflags metadata, falling back to the VCS details the Go
+// toolchain embeds when the binary was built from a checkout without them
+func currentVersionInfo() VersionInfo {
+	info := VersionInfo{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	buildInfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	for _, setting := range buildInfo.Settings {
+		switch {
+		case setting.Key == "vcs.revision" && info.Commit == "unknown":
+			info.Commit = setting.Value
+		case setting.Key == "vcs.time" && info.BuildDate == "unknown":
+			info.BuildDate = setting.Value
+		}
+	}
+	return info
+}
+
+// runVersion writes the version information as text or JSON
+func runVersion(w io.Writer, info VersionInfo, asJSON bool) error {
+	if asJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(info); err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		return nil
+	}
+
+	fmt.Fprintf(w, "portguard %s\n", info.Version)
+	fmt.Fprintf(w, "  Commit:     %s\n", info.Commit)
+	fmt.Fprintf(w, "  Built:      %s\n", info.BuildDate)
+	fmt.Fprintf(w, "  Go version: %s\n", info.GoVersion)
+	fmt.Fprintf(w, "  Platform:   %s\n", info.Platform)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+
+	versionCmd.Flags().BoolVar(&jsonOutput, "json", false, "output in JSON format")
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunVersion(t *testing.T) {
+	info := VersionInfo{
+		Version:   "v1.2.3",
+		Commit:    "abc1234",
+		BuildDate: "2024-05-01T12:00:00Z",
+		GoVersion: "go1.24.0",
+		Platform:  "linux/amd64",
+	}
+
+	t.Run("json", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, runVersion(&out, info, true))
+
+		var decoded map[string]string
+		require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+		assert.Equal(t, map[string]string{
+			"version":    "v1.2.3",
+			"commit":     "abc1234",
+			"build_date": "2024-05-01T12:00:00Z",
+			"go_version": "go1.24.0",
+			"platform":   "linux/amd64",
+		}, decoded)
+	})
+
+	t.Run("text", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, runVersion(&out, info, false))
+
+		assert.Contains(t, out.String(), "portguard v1.2.3")
+		assert.Contains(t, out.String(), "Commit:     abc1234")
+		assert.Contains(t, out.String(), "Go version: go1.24.0")
+	})
+}
+
+func TestCurrentVersionInfo_Defaults(t *testing.T) {
+	info := currentVersionInfo()
+
+	// Test binaries carry no ldflags or VCS stamp, so the defaults show through
+	assert.Equal(t, "dev", info.Version)
+	assert.NotEmpty(t, info.Commit)
+	assert.NotEmpty(t, info.BuildDate)
+	assert.Equal(t, runtime.Version(), info.GoVersion)
+	assert.Equal(t, runtime.GOOS+"/"+runtime.GOARCH, info.Platform)
+}
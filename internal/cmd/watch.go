@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/paveg/portguard/internal/process"
+	"github.com/spf13/cobra"
+)
+
+// ErrInvalidInterval is returned when the watch refresh interval is not positive
+var ErrInvalidInterval = errors.New("refresh interval must be positive")
+
+// clearScreen moves the cursor home and clears the terminal
+const clearScreen = "\033[H\033[2J"
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Continuously refresh the process table",
+	Long: `Watch redraws the process table every interval, re-checking PIDs and health on each refresh.
+Processes whose status changed since the previous refresh are highlighted. Press Ctrl+C to exit.
+
+Examples:
+  portguard watch
+  portguard watch --interval 5s
+  portguard watch --all --port 3000`,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		if watchInterval <= 0 {
+			return fmt.Errorf("%w: %v", ErrInvalidInterval, watchInterval)
+		}
+
+		pm, err := initializeProcessManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize process manager: %w", err)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		return runWatch(ctx, os.Stdout, pm, watchInterval, process.ProcessListOptions{
+			IncludeStopped: showAll,
+			FilterByPort:   port,
+		})
+	},
+}
+
+// watchInterval is the delay between redraws of the watch table
+var watchInterval time.Duration
+
+// runWatch redraws the process table until ctx is cancelled
+func runWatch(ctx context.Context, w io.Writer, pm *process.ProcessManager, interval time.Duration,
+	options process.ProcessListOptions,
+) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var previous map[string]process.ProcessStatus
+	for {
+		// Reload on every tick so processes started by other commands show up
+		if err := pm.ReloadState(); err != nil {
+			return fmt.Errorf("failed to reload state: %w", err)
+		}
+		refreshErr := pm.RefreshStatuses()
+
+		fmt.Fprint(w, clearScreen)
+		previous = renderWatchFrame(w, pm.ListProcesses(options), previous, interval, time.Now())
+		if refreshErr != nil {
+			fmt.Fprintf(w, "\nWarning: some statuses could not be refreshed: %v\n", refreshErr)
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Fprintln(w)
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// renderWatchFrame writes one refresh of the watch table and returns the statuses it showed.
+// Processes whose status differs from previous are highlighted; nothing is highlighted on the first frame.
+func renderWatchFrame(w io.Writer, processes []*process.ManagedProcess, previous map[string]process.ProcessStatus,
+	interval time.Duration, now time.Time,
+) map[string]process.ProcessStatus {
+	sort.Slice(processes, func(i, j int) bool { return processes[i].ID < processes[j].ID })
+
+	current := make(map[string]process.ProcessStatus, len(processes))
+	changed := make(map[string]bool)
+	for _, proc := range processes {
+		current[proc.ID] = proc.Status
+		if previous != nil && previous[proc.ID] != proc.Status {
+			changed[proc.ID] = true
+		}
+	}
+
+	fmt.Fprintf(w, "Every %v: portguard watch    %s\n\n", interval, now.Format(time.TimeOnly))
+	if len(processes) == 0 {
+		fmt.Fprintln(w, "No processes found")
+		return current
+	}
+	printProcessTable(w, processes, changed)
+	return current
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+
+	watchCmd.Flags().DurationVarP(&watchInterval, "interval", "n", 2*time.Second, "delay between refreshes")
+	watchCmd.Flags().BoolVarP(&showAll, "all", "a", false, "show all processes including stopped ones")
+	watchCmd.Flags().IntVarP(&port, "port", "p", 0, "only show processes using this port")
+}
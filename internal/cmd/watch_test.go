@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/paveg/portguard/internal/process"
+	"github.com/paveg/portguard/internal/state"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderWatchFrame(t *testing.T) {
+	now := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	changing := &process.ManagedProcess{ID: "bbbbbbbb22", PID: 200, Status: process.StatusRunning, Command: "go run main.go", Port: 8080}
+	processes := []*process.ManagedProcess{
+		changing,
+		{ID: "aaaaaaaa11", PID: 100, Status: process.StatusRunning, Command: "npm run dev", Port: 3000},
+	}
+
+	var first bytes.Buffer
+	previous := renderWatchFrame(&first, processes, nil, 2*time.Second, now)
+
+	output := first.String()
+	assert.Contains(t, output, "Every 2s: portguard watch    15:04:05")
+	assert.NotContains(t, output, "\033[7m", "first frame has nothing to compare against")
+	assert.Less(t, strings.Index(output, "aaaaaaaa"), strings.Index(output, "bbbbbbbb"), "rows are sorted by ID")
+	assert.Equal(t, map[string]process.ProcessStatus{
+		"aaaaaaaa11": process.StatusRunning,
+		"bbbbbbbb22": process.StatusRunning,
+	}, previous)
+
+	changing.Status = process.StatusUnhealthy
+	var second bytes.Buffer
+	renderWatchFrame(&second, processes, previous, 2*time.Second, now)
+
+	for _, line := range strings.Split(second.String(), "\n") {
+		switch {
+		case strings.HasPrefix(line, "bbbbbbbb"):
+			assert.Contains(t, line, highlight("unhealthy "))
+		case strings.HasPrefix(line, "aaaaaaaa"):
+			assert.NotContains(t, line, "\033[7m")
+		}
+	}
+}
+
+func TestRenderWatchFrame_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	statuses := renderWatchFrame(&buf, nil, nil, time.Second, time.Now())
+
+	assert.Contains(t, buf.String(), "No processes found")
+	assert.Empty(t, statuses)
+}
+
+// frameWriter calls onFrame with the number of each frame as watch clears the screen
+type frameWriter struct {
+	bytes.Buffer
+	frames  int
+	onFrame func(frame int)
+}
+
+func (fw *frameWriter) Write(p []byte) (int, error) {
+	if string(p) == clearScreen {
+		fw.frames++
+		fw.onFrame(fw.frames)
+	}
+	return fw.Buffer.Write(p)
+}
+
+func TestRunWatch_ReloadsState(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	pm, err := initializeProcessManager()
+	require.NoError(t, err)
+	pm.SetMonitoringDisabled(true)
+
+	portguardDir, err := getPortguardDir()
+	require.NoError(t, err)
+	external, err := state.NewJSONStore(filepath.Join(portguardDir, "state.json"))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var out *frameWriter
+	out = &frameWriter{onFrame: func(frame int) {
+		switch frame {
+		case 1:
+			// Another portguard invocation records a process while watch is running
+			assert.NotContains(t, out.String(), "cccccccc33")
+			require.NoError(t, external.Save(map[string]*process.ManagedProcess{
+				"cccccccc33": {ID: "cccccccc33", Status: process.StatusStopped, Command: "npm run dev", Port: 3000},
+			}))
+		case 2:
+			cancel()
+		}
+	}}
+
+	require.NoError(t, runWatch(ctx, out, pm, 10*time.Millisecond, process.ProcessListOptions{IncludeStopped: true}))
+	assert.Contains(t, out.String(), "cccccccc33", "the same manager picks up state written by others")
+}
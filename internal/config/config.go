@@ -6,30 +6,64 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/paveg/portguard/internal/lock"
+	"github.com/paveg/portguard/internal/logging"
+	"github.com/paveg/portguard/internal/port"
 	"github.com/paveg/portguard/internal/process"
 	"github.com/spf13/viper"
 )
 
 // Static error variables to satisfy err113 linter
 var (
-	ErrInvalidPortRange    = errors.New("start port must be less than end port")
-	ErrInvalidStartPort    = errors.New("invalid start port")
-	ErrInvalidEndPort      = errors.New("invalid end port")
-	ErrHealthCheckTimeout  = errors.New("health check timeout must be positive")
-	ErrHealthCheckInterval = errors.New("health check interval must be positive")
-	ErrHealthCheckRetries  = errors.New("health check retries cannot be negative")
-	ErrProjectEmptyCommand = errors.New("project has empty command")
-	ErrProjectInvalidPort  = errors.New("project has invalid port")
+	ErrInvalidPortRange     = errors.New("start port must be less than end port")
+	ErrInvalidStartPort     = errors.New("invalid start port")
+	ErrInvalidEndPort       = errors.New("invalid end port")
+	ErrHealthCheckTimeout   = errors.New("health check timeout must be positive")
+	ErrHealthCheckInterval  = errors.New("health check interval must be positive")
+	ErrHealthCheckRetries   = errors.New("health check retries cannot be negative")
+	ErrProjectEmptyCommand  = errors.New("project has empty command")
+	ErrProjectInvalidPort   = errors.New("project has invalid port")
+	ErrInvalidServerPattern = errors.New("invalid server command pattern")
+	ErrInvalidPortPattern   = errors.New("invalid port pattern")
+	ErrInvalidInterceptRule = errors.New("invalid intercept allow or deny pattern")
+	ErrUnsupportedFormat    = errors.New("unsupported config file format")
+	ErrProjectNotFound      = errors.New("project not found")
+	ErrNoSourceFile         = errors.New("configuration was not loaded from a file")
+	ErrInvalidWebhookURL    = errors.New("notification webhook URL must be an absolute http or https URL")
+	ErrNotifyTimeout        = errors.New("notification timeout cannot be negative")
+	ErrInvalidReadyPattern  = errors.New("invalid ready log pattern")
+	ErrMonitorInterval      = errors.New("monitor interval cannot be negative")
+	ErrMaxProcesses         = errors.New("max processes cannot be negative")
+	ErrSaveInterval         = errors.New("save interval cannot be negative")
+	ErrCleanupInterval      = errors.New("cleanup interval cannot be negative")
+	ErrNegativeTimeout      = errors.New("timeout cannot be negative")
+	ErrInvalidCommonPort    = errors.New("common port must be between 1 and 65535")
+	ErrScanRateLimit        = errors.New("scan rate limit cannot be negative")
+	ErrRecommendedPort      = errors.New("recommended port must be between 1 and 65535")
 )
 
+// FileNames are the config file names searched for in each directory, in priority order.
+// The format is taken from the extension.
+var FileNames = []string{
+	".portguard.yml", ".portguard.yaml", ".portguard.toml", ".portguard.json",
+	"portguard.yml", "portguard.yaml", "portguard.toml", "portguard.json",
+}
+
 // Config represents the application configuration
 type Config struct {
 	Default  *DefaultConfig            `mapstructure:"default" yaml:"default"`
 	Projects map[string]*ProjectConfig `mapstructure:"projects" yaml:"projects"`
+
+	sourceFile string // File the configuration was loaded from, if any
 }
 
 // DefaultConfig contains default settings
@@ -40,6 +74,65 @@ type DefaultConfig struct {
 	StateFile   string             `mapstructure:"state_file" yaml:"state_file"`
 	LockFile    string             `mapstructure:"lock_file" yaml:"lock_file"`
 	LogLevel    string             `mapstructure:"log_level" yaml:"log_level"`
+	LogFormat   string             `mapstructure:"log_format" yaml:"log_format"` // "text" or "json"
+	// DisableMonitoring skips background monitors for started and adopted processes,
+	// e.g. in CI where portguard should exit right after starting a server.
+	DisableMonitoring bool `mapstructure:"disable_monitoring" yaml:"disable_monitoring"`
+	// MonitorInterval is how often background monitors check that processes are alive.
+	// Health checks run on their own interval.
+	MonitorInterval time.Duration `mapstructure:"monitor_interval" yaml:"monitor_interval"`
+	// MaxProcesses caps how many running or unhealthy processes portguard starts at once,
+	// e.g. on shared CI runners. Zero means no limit.
+	MaxProcesses int `mapstructure:"max_processes" yaml:"max_processes"`
+	// SaveInterval batches the state writes caused by status changes, writing at most once
+	// per interval. Zero writes every change immediately.
+	SaveInterval time.Duration `mapstructure:"save_interval" yaml:"save_interval"`
+	// HookTimeout is how long pre-start and post-stop hooks may run before they are
+	// killed. Started processes never time out. Zero keeps the default of 2 minutes.
+	HookTimeout time.Duration `mapstructure:"hook_timeout" yaml:"hook_timeout"`
+	// LockTimeout and ScanTimeout bound waiting for the state lock and port scans.
+	// Zero keeps each command's default; --lock-timeout and --scan-timeout override them.
+	LockTimeout time.Duration `mapstructure:"lock_timeout" yaml:"lock_timeout"`
+	ScanTimeout time.Duration `mapstructure:"scan_timeout" yaml:"scan_timeout"`
+	// ServerPatterns are extra regular expressions recognized as server commands
+	// in addition to the builtin list used by the intercept hook.
+	ServerPatterns []string `mapstructure:"server_patterns" yaml:"server_patterns"`
+	// PortPatterns assign default ports to commands that do not specify one explicitly.
+	// A list rather than a map, since config keys may not contain dots and are lowercased.
+	PortPatterns []PortPattern `mapstructure:"port_patterns" yaml:"port_patterns"`
+	// Intercept restricts which commands the intercept hook manages or registers
+	Intercept *InterceptConfig `mapstructure:"intercept" yaml:"intercept"`
+	// Notifications reports processes going unhealthy, stopping or recovering
+	Notifications *NotificationsConfig `mapstructure:"notifications" yaml:"notifications"`
+	// DiscoveryBackend selects how processes behind ports are found: "shell" runs
+	// lsof/netstat, "native" reads the Linux kernel socket tables and falls back to shell
+	// on other platforms.
+	DiscoveryBackend string `mapstructure:"discovery_backend" yaml:"discovery_backend"`
+	// LockBackend selects how portguard invocations exclude each other: "file" uses a lock
+	// file portguard manages itself, "flock" an OS-level lock the kernel releases on exit.
+	LockBackend string `mapstructure:"lock_backend" yaml:"lock_backend"`
+	// CommonPorts are extra ports checked when listing listening ports, e.g. 50051 for gRPC.
+	// They are added to the builtin development ports unless ReplaceCommonPorts is set.
+	CommonPorts        []int `mapstructure:"common_ports" yaml:"common_ports"`
+	ReplaceCommonPorts bool  `mapstructure:"replace_common_ports" yaml:"replace_common_ports"`
+	// ScanRateLimit caps port range scans at this many ports per second; zero means unlimited
+	ScanRateLimit float64 `mapstructure:"scan_rate_limit" yaml:"scan_rate_limit"`
+	// RecommendedPorts maps application types such as "web" or "api" to the port recommended
+	// for them, overriding the builtin conventions
+	RecommendedPorts map[string]int `mapstructure:"recommended_ports" yaml:"recommended_ports"`
+}
+
+// InterceptConfig holds regular expressions matched against the whole command line. A command
+// matching Deny is always left alone; when Allow is set, only commands matching it are handled.
+type InterceptConfig struct {
+	Allow []string `mapstructure:"allow" yaml:"allow"`
+	Deny  []string `mapstructure:"deny" yaml:"deny"`
+}
+
+// NotificationsConfig controls where status transitions of monitored processes are reported
+type NotificationsConfig struct {
+	WebhookURL string        `mapstructure:"webhook_url" yaml:"webhook_url"` // POST target for JSON events; empty disables notifications
+	Timeout    time.Duration `mapstructure:"timeout" yaml:"timeout"`         // Upper bound for a single delivery
 }
 
 // HealthCheckConfig contains default health check settings
@@ -61,6 +154,16 @@ type CleanupConfig struct {
 	AutoCleanup     bool          `mapstructure:"auto_cleanup" yaml:"auto_cleanup"`
 	MaxIdleTime     time.Duration `mapstructure:"max_idle_time" yaml:"max_idle_time"`
 	BackupRetention time.Duration `mapstructure:"backup_retention" yaml:"backup_retention"`
+	// Interval is how often long-running commands remove processes idle for MaxIdleTime
+	// when AutoCleanup is set; zero disables the background cleanup
+	Interval time.Duration `mapstructure:"interval" yaml:"interval"`
+}
+
+// PortPattern assigns Port to commands matching the regular expression Pattern.
+// The first matching entry wins.
+type PortPattern struct {
+	Pattern string `mapstructure:"pattern" yaml:"pattern"`
+	Port    int    `mapstructure:"port" yaml:"port"`
 }
 
 // ProjectConfig contains project-specific settings
@@ -69,8 +172,15 @@ type ProjectConfig struct {
 	Port        int                  `mapstructure:"port" yaml:"port"`
 	HealthCheck *process.HealthCheck `mapstructure:"health_check" yaml:"health_check"`
 	Environment map[string]string    `mapstructure:"environment" yaml:"environment"`
+	EnvFile     string               `mapstructure:"env_file" yaml:"env_file"` // Relative to WorkingDir when set
 	WorkingDir  string               `mapstructure:"working_dir" yaml:"working_dir"`
 	LogFile     string               `mapstructure:"log_file" yaml:"log_file"`
+	BindAddress string               `mapstructure:"bind_address" yaml:"bind_address"` // Interface to listen on; see process.StartOptions.BindAddress
+	// ReadyLogPattern makes start wait until new output in LogFile matches this regular expression
+	ReadyLogPattern string `mapstructure:"ready_log_pattern" yaml:"ready_log_pattern"`
+	// PreStart and PostStop are commands run before every start and after every stop
+	PreStart []string `mapstructure:"pre_start" yaml:"pre_start"`
+	PostStop []string `mapstructure:"post_stop" yaml:"post_stop"`
 }
 
 // Load loads configuration from file and environment
@@ -79,18 +189,22 @@ func Load() (*Config, error) {
 	setDefaults()
 
 	// Try to read config file
+	sourceFile := ""
 	if err := viper.ReadInConfig(); err != nil {
 		var configNotFoundError viper.ConfigFileNotFoundError
 		if !errors.As(err, &configNotFoundError) {
 			return nil, fmt.Errorf("error reading config file: %w", err)
 		}
 		// Config file not found is OK, we'll use defaults
+	} else {
+		sourceFile = viper.ConfigFileUsed()
 	}
 
 	var config Config
 	if err := viper.Unmarshal(&config); err != nil {
 		return nil, fmt.Errorf("unable to decode config: %w", err)
 	}
+	config.sourceFile = sourceFile
 
 	// Apply defaults if not set
 	if config.Default == nil {
@@ -106,6 +220,11 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("failed to expand paths: %w", err)
 	}
 
+	// An invalid discovery backend is an error rather than a silent switch to the shell tools
+	if _, err := port.ParseDiscoveryBackend(config.Default.DiscoveryBackend); err != nil {
+		return nil, &ValidationError{Field: "default.discovery_backend", Err: err}
+	}
+
 	return &config, nil
 }
 
@@ -131,6 +250,12 @@ func setDefaults() {
 	viper.SetDefault("default.state_file", filepath.Join(homeDir, ".portguard", "state.json"))
 	viper.SetDefault("default.lock_file", filepath.Join(homeDir, ".portguard", "portguard.lock"))
 	viper.SetDefault("default.log_level", "info")
+	viper.SetDefault("default.log_format", logging.FormatText)
+	viper.SetDefault("default.disable_monitoring", false)
+	viper.SetDefault("default.monitor_interval", "500ms")
+	viper.SetDefault("default.notifications.timeout", "5s")
+	viper.SetDefault("default.discovery_backend", string(port.DiscoveryShell))
+	viper.SetDefault("default.lock_backend", string(lock.BackendFile))
 }
 
 // getDefaultConfig returns the default configuration
@@ -152,10 +277,16 @@ func getDefaultConfig() *DefaultConfig {
 			AutoCleanup:     true,
 			MaxIdleTime:     time.Hour,
 			BackupRetention: 7 * 24 * time.Hour,
+			Interval:        10 * time.Minute,
 		},
 		StateFile: filepath.Join(homeDir, ".portguard", "state.json"),
 		LockFile:  filepath.Join(homeDir, ".portguard", "portguard.lock"),
 		LogLevel:  "info",
+		LogFormat: logging.FormatText,
+
+		MonitorInterval:  500 * time.Millisecond,
+		DiscoveryBackend: string(port.DiscoveryShell),
+		LockBackend:      string(lock.BackendFile),
 	}
 }
 
@@ -201,46 +332,205 @@ func expandPaths(config *Config) error {
 	return nil
 }
 
-// expandPath expands ~ to home directory and resolves relative paths
+// expandPath expands ~ to home directory and resolves relative paths, the same way
+// process working directories are resolved at start
 func expandPath(path string) (string, error) {
-	if path == "" {
-		return path, nil
-	}
+	return process.ExpandPath(path)
+}
 
-	// Expand ~ to home directory
-	if path[:1] == "~" {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return "", fmt.Errorf("failed to get home directory: %w", err)
+// FindConfigFile returns the first config file in dirs matching FileNames
+func FindConfigFile(dirs ...string) (string, bool) {
+	for _, dir := range dirs {
+		for _, name := range FileNames {
+			path := filepath.Join(dir, name)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path, true
+			}
 		}
-		path = filepath.Join(homeDir, path[1:])
 	}
+	return "", false
+}
 
-	// Convert to absolute path
-	abs, err := filepath.Abs(path)
-	if err != nil {
-		return "", fmt.Errorf("failed to get absolute path: %w", err)
+// SourceFile returns the file the configuration was loaded from, or "" if defaults were used
+func (c *Config) SourceFile() string {
+	return c.sourceFile
+}
+
+// Settings returns the configuration as plain maps keyed like the config file, with durations
+// as strings such as "30s". It is what Save writes, including values that came from defaults.
+func (c *Config) Settings() map[string]interface{} {
+	settings := map[string]interface{}{"default": toSettings(reflect.ValueOf(c.Default))}
+	if projects := toSettings(reflect.ValueOf(c.Projects)); projects != nil {
+		settings["projects"] = projects
 	}
-	return abs, nil
+	return settings
 }
 
-// Save saves the configuration to a file
+// Save saves the configuration to a file. The format follows the file extension
+// (.yml, .yaml, .toml or .json), falling back to the format the config was loaded from.
 func (c *Config) Save(filename string) error {
-	viper.Set("default", c.Default)
-	viper.Set("projects", c.Projects)
+	format, err := fileFormat(filename)
+	if err != nil && c.sourceFile != "" {
+		format, err = fileFormat(c.sourceFile)
+	}
+	if err != nil {
+		return err
+	}
+
+	// A separate instance keeps defaults and environment overrides out of the written file
+	writer := viper.New()
+	writer.SetConfigType(format)
+	for key, value := range c.Settings() {
+		writer.Set(key, value)
+	}
 
-	if err := viper.WriteConfigAs(filename); err != nil {
+	if err := writer.WriteConfigAs(filename); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 	return nil
 }
 
+// SaveToSource writes the configuration back to the file it was loaded from, in the same format
+func (c *Config) SaveToSource() error {
+	if c.sourceFile == "" {
+		return ErrNoSourceFile
+	}
+	return c.Save(c.sourceFile)
+}
+
+// fileFormat returns the viper config type for a config file name
+func fileFormat(filename string) (string, error) {
+	switch ext := strings.ToLower(filepath.Ext(filename)); ext {
+	case ".yml", ".yaml":
+		return "yaml", nil
+	case ".toml":
+		return "toml", nil
+	case ".json":
+		return "json", nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedFormat, filename)
+	}
+}
+
+// toSettings converts a config value into plain maps keyed by mapstructure tags, so every
+// output format decodes back through Load. Durations are written as strings such as "30s".
+func toSettings(value reflect.Value) interface{} {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return nil
+		}
+		return toSettings(value.Elem())
+	case reflect.Struct:
+		settings := make(map[string]interface{})
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			key, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+			if key == "-" {
+				continue
+			}
+			if key == "" {
+				key = strings.ToLower(field.Name)
+			}
+			if fieldValue := toSettings(value.Field(i)); fieldValue != nil {
+				settings[key] = fieldValue
+			}
+		}
+		return settings
+	case reflect.Map:
+		if value.IsNil() {
+			return nil
+		}
+		settings := make(map[string]interface{}, value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			if itemValue := toSettings(iter.Value()); itemValue != nil {
+				settings[fmt.Sprint(iter.Key().Interface())] = itemValue
+			}
+		}
+		return settings
+	case reflect.Slice:
+		if value.IsNil() {
+			return nil
+		}
+		items := make([]interface{}, value.Len())
+		for i := range items {
+			items[i] = toSettings(value.Index(i))
+		}
+		return items
+	case reflect.String:
+		return value.String() // Drops named types such as process.HealthCheckType
+	case reflect.Int64:
+		if duration, ok := value.Interface().(time.Duration); ok {
+			return duration.String()
+		}
+	}
+	return value.Interface()
+}
+
 // GetProject returns a project configuration by name
 func (c *Config) GetProject(name string) (*ProjectConfig, bool) {
 	project, exists := c.Projects[name]
 	return project, exists
 }
 
+// ResolveProject returns a copy of the named project with unset health check fields
+// (zero timeout, interval, retries and enabled) filled from Default.HealthCheck.
+// Use type "none" to opt a project out of health checks when defaults enable them.
+func (c *Config) ResolveProject(name string) (*ProjectConfig, error) {
+	project, exists := c.Projects[name]
+	if !exists || project == nil {
+		return nil, fmt.Errorf("%w: %s", ErrProjectNotFound, name)
+	}
+
+	resolved := *project
+	if project.HealthCheck != nil {
+		healthCheck := *project.HealthCheck
+		if c.Default != nil && c.Default.HealthCheck != nil {
+			defaults := c.Default.HealthCheck
+			if healthCheck.Timeout == 0 {
+				healthCheck.Timeout = defaults.Timeout
+			}
+			if healthCheck.Interval == 0 {
+				healthCheck.Interval = defaults.Interval
+			}
+			if healthCheck.Retries == 0 {
+				healthCheck.Retries = defaults.Retries
+			}
+			if !healthCheck.Enabled {
+				healthCheck.Enabled = defaults.Enabled
+			}
+		}
+
+		if err := validateProjectHealthCheck(&healthCheck); err != nil {
+			return nil, fmt.Errorf("project %s: %w", name, err)
+		}
+		resolved.HealthCheck = &healthCheck
+	}
+
+	return &resolved, nil
+}
+
+// validateProjectHealthCheck checks a resolved project health check
+func validateProjectHealthCheck(healthCheck *process.HealthCheck) error {
+	if healthCheck.Retries < 0 {
+		return ErrHealthCheckRetries
+	}
+	if !healthCheck.Enabled || healthCheck.Type == process.HealthCheckNone {
+		return nil
+	}
+	if healthCheck.Timeout <= 0 {
+		return ErrHealthCheckTimeout
+	}
+	if healthCheck.Interval <= 0 {
+		return ErrHealthCheckInterval
+	}
+	return nil
+}
+
 // AddProject adds or updates a project configuration
 func (c *Config) AddProject(name string, project *ProjectConfig) {
 	if c.Projects == nil {
@@ -263,45 +553,230 @@ func (c *Config) ListProjects() []string {
 	return names
 }
 
-// Validate validates the configuration
+// ValidationError ties a validation problem to the config field it was found in
+type ValidationError struct {
+	Field string // Dotted path such as "projects.web.port"
+	Err   error
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Field, e.Err)
+}
+
+// Unwrap exposes the underlying Err* sentinel to errors.Is
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Validate validates the configuration and returns the first problem found
 func (c *Config) Validate() error {
+	if problems := c.ValidateAll(); len(problems) > 0 {
+		return problems[0]
+	}
+	return nil
+}
+
+// ValidateAll validates the configuration and returns every problem found,
+// defaults first and then projects in name order
+func (c *Config) ValidateAll() []*ValidationError {
+	var problems []*ValidationError
+	report := func(field string, err error) {
+		problems = append(problems, &ValidationError{Field: field, Err: err})
+	}
+
 	if c.Default != nil {
 		// Validate port range
 		if c.Default.PortRange != nil {
 			if c.Default.PortRange.Start < 1 || c.Default.PortRange.Start > 65535 {
-				return fmt.Errorf("%w: %d", ErrInvalidStartPort, c.Default.PortRange.Start)
+				report("default.port_range.start", fmt.Errorf("%w: %d", ErrInvalidStartPort, c.Default.PortRange.Start))
 			}
 			if c.Default.PortRange.End < 1 || c.Default.PortRange.End > 65535 {
-				return fmt.Errorf("%w: %d", ErrInvalidEndPort, c.Default.PortRange.End)
+				report("default.port_range.end", fmt.Errorf("%w: %d", ErrInvalidEndPort, c.Default.PortRange.End))
 			}
 			if c.Default.PortRange.Start > c.Default.PortRange.End {
-				return ErrInvalidPortRange
+				report("default.port_range", ErrInvalidPortRange)
 			}
 		}
 
 		// Validate health check settings
 		if c.Default.HealthCheck != nil {
 			if c.Default.HealthCheck.Timeout <= 0 {
-				return ErrHealthCheckTimeout
+				report("default.health_check.timeout", ErrHealthCheckTimeout)
 			}
 			if c.Default.HealthCheck.Interval <= 0 {
-				return ErrHealthCheckInterval
+				report("default.health_check.interval", ErrHealthCheckInterval)
 			}
 			if c.Default.HealthCheck.Retries < 0 {
-				return ErrHealthCheckRetries
+				report("default.health_check.retries", ErrHealthCheckRetries)
+			}
+		}
+
+		// Validate logging settings
+		if _, err := logging.ParseLevel(c.Default.LogLevel); err != nil {
+			report("default.log_level", fmt.Errorf("invalid default log level: %w", err))
+		}
+		if err := logging.ValidateFormat(c.Default.LogFormat); err != nil {
+			report("default.log_format", fmt.Errorf("invalid default log format: %w", err))
+		}
+
+		if c.Default.MonitorInterval < 0 {
+			report("default.monitor_interval", ErrMonitorInterval)
+		}
+		if c.Default.MaxProcesses < 0 {
+			report("default.max_processes", ErrMaxProcesses)
+		}
+		if c.Default.SaveInterval < 0 {
+			report("default.save_interval", ErrSaveInterval)
+		}
+		if c.Default.HookTimeout < 0 {
+			report("default.hook_timeout", ErrNegativeTimeout)
+		}
+		if c.Default.Cleanup != nil && c.Default.Cleanup.Interval < 0 {
+			report("default.cleanup.interval", ErrCleanupInterval)
+		}
+		if c.Default.LockTimeout < 0 {
+			report("default.lock_timeout", ErrNegativeTimeout)
+		}
+		if c.Default.ScanTimeout < 0 {
+			report("default.scan_timeout", ErrNegativeTimeout)
+		}
+
+		// Validate port discovery settings
+		if _, err := port.ParseDiscoveryBackend(c.Default.DiscoveryBackend); err != nil {
+			report("default.discovery_backend", err)
+		}
+		if _, err := lock.ParseBackend(c.Default.LockBackend); err != nil {
+			report("default.lock_backend", err)
+		}
+		for i, portNum := range c.Default.CommonPorts {
+			if portNum < 1 || portNum > 65535 {
+				report(fmt.Sprintf("default.common_ports[%d]", i), fmt.Errorf("%w: %d", ErrInvalidCommonPort, portNum))
+			}
+		}
+		if c.Default.ScanRateLimit < 0 {
+			report("default.scan_rate_limit", ErrScanRateLimit)
+		}
+		appTypes := make([]string, 0, len(c.Default.RecommendedPorts))
+		for appType := range c.Default.RecommendedPorts {
+			appTypes = append(appTypes, appType)
+		}
+		sort.Strings(appTypes)
+		for _, appType := range appTypes {
+			if portNum := c.Default.RecommendedPorts[appType]; portNum < 1 || portNum > 65535 {
+				report("default.recommended_ports."+appType, fmt.Errorf("%w: %d", ErrRecommendedPort, portNum))
 			}
 		}
+
+		// Validate notification settings
+		if notifications := c.Default.Notifications; notifications != nil {
+			if notifications.WebhookURL != "" && !isHTTPURL(notifications.WebhookURL) {
+				report("default.notifications.webhook_url", fmt.Errorf("%w: %q", ErrInvalidWebhookURL, notifications.WebhookURL))
+			}
+			if notifications.Timeout < 0 {
+				report("default.notifications.timeout", ErrNotifyTimeout)
+			}
+		}
+
+		// Validate custom command patterns
+		problems = append(problems, validatePatterns(c.Default)...)
 	}
 
 	// Validate project configurations
-	for name, project := range c.Projects {
+	names := c.ListProjects()
+	sort.Strings(names)
+	for _, name := range names {
+		project := c.Projects[name]
+		field := "projects." + name
+		if project == nil {
+			report(field, fmt.Errorf("%w: %s", ErrProjectEmptyCommand, name))
+			continue
+		}
 		if project.Command == "" {
-			return fmt.Errorf("%w: %s", ErrProjectEmptyCommand, name)
+			report(field+".command", fmt.Errorf("%w: %s", ErrProjectEmptyCommand, name))
 		}
 		if project.Port != 0 && (project.Port < 1 || project.Port > 65535) {
-			return fmt.Errorf("%w: %s (port: %d)", ErrProjectInvalidPort, name, project.Port)
+			report(field+".port", fmt.Errorf("%w: %s (port: %d)", ErrProjectInvalidPort, name, project.Port))
+		}
+		if _, err := c.ResolveProject(name); err != nil {
+			report(field+".health_check", err)
+		}
+		if project.ReadyLogPattern != "" {
+			if project.LogFile == "" {
+				report(field+".ready_log_pattern", fmt.Errorf("%w: %s requires log_file", ErrInvalidReadyPattern, name))
+			} else if _, err := CompilePatterns([]string{project.ReadyLogPattern}); err != nil {
+				report(field+".ready_log_pattern", fmt.Errorf("%w: %w", ErrInvalidReadyPattern, err))
+			}
 		}
 	}
 
-	return nil
+	return problems
+}
+
+// isHTTPURL reports whether raw is an absolute http or https URL
+func isHTTPURL(raw string) bool {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+// validatePatterns checks the custom server and port patterns
+func validatePatterns(defaults *DefaultConfig) []*ValidationError {
+	var problems []*ValidationError
+
+	for i, pattern := range defaults.ServerPatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			problems = append(problems, &ValidationError{
+				Field: fmt.Sprintf("default.server_patterns[%d]", i),
+				Err:   fmt.Errorf("%w: failed to compile pattern %q: %w", ErrInvalidServerPattern, pattern, err),
+			})
+		}
+	}
+
+	if intercept := defaults.Intercept; intercept != nil {
+		problems = append(problems, validateInterceptRules("allow", intercept.Allow)...)
+		problems = append(problems, validateInterceptRules("deny", intercept.Deny)...)
+	}
+
+	for i, entry := range defaults.PortPatterns {
+		field := fmt.Sprintf("default.port_patterns[%d]", i)
+		if _, err := regexp.Compile(entry.Pattern); err != nil {
+			problems = append(problems, &ValidationError{Field: field, Err: fmt.Errorf("%w: %q: %w", ErrInvalidPortPattern, entry.Pattern, err)})
+			continue
+		}
+		if entry.Port < 1 || entry.Port > 65535 {
+			problems = append(problems, &ValidationError{Field: field, Err: fmt.Errorf("%w: %q (port: %d)", ErrInvalidPortPattern, entry.Pattern, entry.Port)})
+		}
+	}
+
+	return problems
+}
+
+// validateInterceptRules checks the patterns of one intercept allow or deny list
+func validateInterceptRules(list string, patterns []string) []*ValidationError {
+	var problems []*ValidationError
+	for i, pattern := range patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			problems = append(problems, &ValidationError{
+				Field: fmt.Sprintf("default.intercept.%s[%d]", list, i),
+				Err:   fmt.Errorf("%w: failed to compile pattern %q: %w", ErrInvalidInterceptRule, pattern, err),
+			})
+		}
+	}
+	return problems
+}
+
+// CompilePatterns compiles a list of regular expressions, failing on the first invalid one
+func CompilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
 }
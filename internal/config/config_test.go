@@ -10,6 +10,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/paveg/portguard/internal/lock"
+	"github.com/paveg/portguard/internal/logging"
+	"github.com/paveg/portguard/internal/port"
 	"github.com/paveg/portguard/internal/process"
 )
 
@@ -27,6 +30,20 @@ func TestConfigErrors(t *testing.T) {
 		{"ErrHealthCheckRetries", ErrHealthCheckRetries},
 		{"ErrProjectEmptyCommand", ErrProjectEmptyCommand},
 		{"ErrProjectInvalidPort", ErrProjectInvalidPort},
+		{"ErrInvalidServerPattern", ErrInvalidServerPattern},
+		{"ErrInvalidPortPattern", ErrInvalidPortPattern},
+		{"ErrInvalidWebhookURL", ErrInvalidWebhookURL},
+		{"ErrNotifyTimeout", ErrNotifyTimeout},
+		{"ErrInvalidReadyPattern", ErrInvalidReadyPattern},
+		{"ErrMonitorInterval", ErrMonitorInterval},
+		{"ErrMaxProcesses", ErrMaxProcesses},
+		{"ErrSaveInterval", ErrSaveInterval},
+		{"ErrCleanupInterval", ErrCleanupInterval},
+		{"ErrInvalidInterceptRule", ErrInvalidInterceptRule},
+		{"ErrNegativeTimeout", ErrNegativeTimeout},
+		{"ErrInvalidCommonPort", ErrInvalidCommonPort},
+		{"ErrScanRateLimit", ErrScanRateLimit},
+		{"ErrRecommendedPort", ErrRecommendedPort},
 	}
 
 	for _, tt := range tests {
@@ -58,6 +75,7 @@ func TestLoad(t *testing.T) {
 				assert.NotNil(t, cfg)
 				assert.NotNil(t, cfg.Default)
 				assert.NotNil(t, cfg.Projects)
+				assert.False(t, cfg.Default.DisableMonitoring)
 			},
 		},
 		{
@@ -83,6 +101,7 @@ default:
   state_file: "/tmp/portguard-test.json"
   lock_file: "/tmp/portguard-test.lock"
   log_level: "info"
+  disable_monitoring: true
 projects:
   webapp:
     command: "npm run dev"
@@ -115,6 +134,7 @@ projects:
 				assert.Equal(t, 9000, cfg.Default.PortRange.End)
 				assert.Equal(t, "/tmp/portguard-test.json", cfg.Default.StateFile)
 				assert.Equal(t, "info", cfg.Default.LogLevel)
+				assert.True(t, cfg.Default.DisableMonitoring)
 
 				// Validate project
 				assert.Len(t, cfg.Projects, 1)
@@ -124,6 +144,18 @@ projects:
 				assert.Equal(t, 3000, webapp.Port)
 			},
 		},
+		{
+			name: "invalid_discovery_backend",
+			setupConfig: func(t *testing.T) func() {
+				t.Helper()
+				configPath := filepath.Join(t.TempDir(), "test-config.yml")
+				require.NoError(t, os.WriteFile(configPath, []byte("default:\n  discovery_backend: gopsutil\n"), 0o600))
+				viper.Reset()
+				viper.SetConfigFile(configPath)
+				return func() { viper.Reset() }
+			},
+			expectError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -549,6 +581,249 @@ func TestConfigValidate(t *testing.T) {
 			expectError: true,
 			errorType:   ErrProjectInvalidPort,
 		},
+		{
+			name: "project_ready_pattern_without_log_file",
+			config: &Config{
+				Default: getDefaultConfig(),
+				Projects: map[string]*ProjectConfig{
+					"web": {Command: "npm run dev", ReadyLogPattern: "ready"},
+				},
+			},
+			expectError: true,
+			errorType:   ErrInvalidReadyPattern,
+		},
+		{
+			name: "project_invalid_ready_pattern",
+			config: &Config{
+				Default: getDefaultConfig(),
+				Projects: map[string]*ProjectConfig{
+					"web": {Command: "npm run dev", LogFile: "/tmp/web.log", ReadyLogPattern: "ready ("},
+				},
+			},
+			expectError: true,
+			errorType:   ErrInvalidReadyPattern,
+		},
+		{
+			name: "valid_custom_patterns",
+			config: &Config{
+				Default: &DefaultConfig{
+					ServerPatterns: []string{"mycli dev", `mycli serve --watch`},
+					PortPatterns:   []PortPattern{{Pattern: "mycli dev", Port: 4500}},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid_server_pattern",
+			config: &Config{
+				Default: &DefaultConfig{
+					ServerPatterns: []string{"mycli (dev"}, // Invalid: unbalanced paren
+				},
+			},
+			expectError: true,
+			errorType:   ErrInvalidServerPattern,
+		},
+		{
+			name: "invalid_intercept_deny_pattern",
+			config: &Config{
+				Default: &DefaultConfig{
+					Intercept: &InterceptConfig{Allow: []string{"^npm "}, Deny: []string{"kubectl ("}},
+				},
+			},
+			expectError: true,
+			errorType:   ErrInvalidInterceptRule,
+		},
+		{
+			name: "invalid_port_pattern_regex",
+			config: &Config{
+				Default: &DefaultConfig{
+					PortPatterns: []PortPattern{{Pattern: "mycli [dev", Port: 4500}},
+				},
+			},
+			expectError: true,
+			errorType:   ErrInvalidPortPattern,
+		},
+		{
+			name: "invalid_port_pattern_port",
+			config: &Config{
+				Default: &DefaultConfig{
+					PortPatterns: []PortPattern{{Pattern: "mycli dev", Port: 70000}},
+				},
+			},
+			expectError: true,
+			errorType:   ErrInvalidPortPattern,
+		},
+		{
+			name: "valid_notifications",
+			config: &Config{
+				Default: &DefaultConfig{
+					Notifications: &NotificationsConfig{WebhookURL: "https://hooks.example.com/portguard", Timeout: 5 * time.Second},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "relative_webhook_url",
+			config: &Config{
+				Default: &DefaultConfig{
+					Notifications: &NotificationsConfig{WebhookURL: "hooks.example.com/portguard"},
+				},
+			},
+			expectError: true,
+			errorType:   ErrInvalidWebhookURL,
+		},
+		{
+			name: "negative_notification_timeout",
+			config: &Config{
+				Default: &DefaultConfig{
+					Notifications: &NotificationsConfig{Timeout: -time.Second},
+				},
+			},
+			expectError: true,
+			errorType:   ErrNotifyTimeout,
+		},
+		{
+			name: "invalid_log_level",
+			config: &Config{
+				Default: &DefaultConfig{
+					LogLevel: "loud",
+				},
+			},
+			expectError: true,
+			errorType:   logging.ErrInvalidLevel,
+		},
+		{
+			name: "invalid_log_format",
+			config: &Config{
+				Default: &DefaultConfig{
+					LogLevel:  "debug",
+					LogFormat: "xml",
+				},
+			},
+			expectError: true,
+			errorType:   logging.ErrInvalidFormat,
+		},
+		{
+			name: "negative_monitor_interval",
+			config: &Config{
+				Default: &DefaultConfig{
+					LogLevel:        "info",
+					MonitorInterval: -time.Second,
+				},
+			},
+			expectError: true,
+			errorType:   ErrMonitorInterval,
+		},
+		{
+			name: "negative_max_processes",
+			config: &Config{
+				Default: &DefaultConfig{
+					LogLevel:     "info",
+					MaxProcesses: -1,
+				},
+			},
+			expectError: true,
+			errorType:   ErrMaxProcesses,
+		},
+		{
+			name: "negative_save_interval",
+			config: &Config{
+				Default: &DefaultConfig{
+					LogLevel:     "info",
+					SaveInterval: -time.Second,
+				},
+			},
+			expectError: true,
+			errorType:   ErrSaveInterval,
+		},
+		{
+			name: "negative_hook_timeout",
+			config: &Config{
+				Default: &DefaultConfig{
+					LogLevel:    "info",
+					HookTimeout: -time.Second,
+				},
+			},
+			expectError: true,
+			errorType:   ErrNegativeTimeout,
+		},
+		{
+			name: "negative_cleanup_interval",
+			config: &Config{
+				Default: &DefaultConfig{
+					LogLevel: "info",
+					Cleanup:  &CleanupConfig{AutoCleanup: true, Interval: -time.Minute},
+				},
+			},
+			expectError: true,
+			errorType:   ErrCleanupInterval,
+		},
+		{
+			name: "invalid_common_port",
+			config: &Config{
+				Default: &DefaultConfig{
+					LogLevel:    "info",
+					CommonPorts: []int{50051, 70000},
+				},
+			},
+			expectError: true,
+			errorType:   ErrInvalidCommonPort,
+		},
+		{
+			name: "negative_scan_rate_limit",
+			config: &Config{
+				Default: &DefaultConfig{
+					LogLevel:      "info",
+					ScanRateLimit: -1,
+				},
+			},
+			expectError: true,
+			errorType:   ErrScanRateLimit,
+		},
+		{
+			name: "invalid_recommended_port",
+			config: &Config{
+				Default: &DefaultConfig{
+					LogLevel:         "info",
+					RecommendedPorts: map[string]int{"api": 8080, "grpc": 0},
+				},
+			},
+			expectError: true,
+			errorType:   ErrRecommendedPort,
+		},
+		{
+			name: "negative_lock_timeout",
+			config: &Config{
+				Default: &DefaultConfig{
+					LogLevel:    "info",
+					LockTimeout: -time.Second,
+				},
+			},
+			expectError: true,
+			errorType:   ErrNegativeTimeout,
+		},
+		{
+			name: "invalid_discovery_backend",
+			config: &Config{
+				Default: &DefaultConfig{
+					LogLevel:         "info",
+					DiscoveryBackend: "gopsutil",
+				},
+			},
+			expectError: true,
+			errorType:   port.ErrUnknownDiscoveryBackend,
+		},
+		{
+			name: "invalid_lock_backend",
+			config: &Config{
+				Default: &DefaultConfig{
+					LogLevel:    "info",
+					LockBackend: "fcntl",
+				},
+			},
+			expectError: true,
+			errorType:   lock.ErrUnknownBackend,
+		},
 	}
 
 	for _, tt := range tests {
@@ -567,3 +842,268 @@ func TestConfigValidate(t *testing.T) {
 		})
 	}
 }
+
+// formatFixtures holds the same logical configuration in every supported format
+var formatFixtures = map[string]string{
+	"portguard.yml": `
+default:
+  health_check:
+    enabled: true
+    timeout: 10s
+    interval: 5s
+    retries: 2
+  port_range:
+    start: 4000
+    end: 5000
+  log_level: debug
+  server_patterns:
+    - "mycli dev"
+projects:
+  web:
+    command: "npm run dev"
+    port: 3000
+    health_check:
+      type: http
+      target: "http://localhost:3000/health"
+      timeout: 3s
+`,
+	"portguard.toml": `
+[default]
+log_level = "debug"
+server_patterns = ["mycli dev"]
+
+[default.health_check]
+enabled = true
+timeout = "10s"
+interval = "5s"
+retries = 2
+
+[default.port_range]
+start = 4000
+end = 5000
+
+[projects.web]
+command = "npm run dev"
+port = 3000
+
+[projects.web.health_check]
+type = "http"
+target = "http://localhost:3000/health"
+timeout = "3s"
+`,
+	"portguard.json": `{
+  "default": {
+    "health_check": {"enabled": true, "timeout": "10s", "interval": "5s", "retries": 2},
+    "port_range": {"start": 4000, "end": 5000},
+    "log_level": "debug",
+    "server_patterns": ["mycli dev"]
+  },
+  "projects": {
+    "web": {
+      "command": "npm run dev",
+      "port": 3000,
+      "health_check": {"type": "http", "target": "http://localhost:3000/health", "timeout": "3s"}
+    }
+  }
+}`,
+}
+
+// loadFile loads configuration from a single file with a clean viper state
+func loadFile(t *testing.T, path string) *Config {
+	t.Helper()
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.SetConfigFile(path)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, path, cfg.SourceFile())
+	return cfg
+}
+
+func TestLoadFormats(t *testing.T) {
+	tempDir := t.TempDir()
+
+	var expected *Config
+	for _, name := range []string{"portguard.yml", "portguard.toml", "portguard.json"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(tempDir, name)
+			require.NoError(t, os.WriteFile(path, []byte(formatFixtures[name]), 0o600))
+
+			cfg := loadFile(t, path)
+			assert.Equal(t, 10*time.Second, cfg.Default.HealthCheck.Timeout)
+			assert.Equal(t, 4000, cfg.Default.PortRange.Start)
+			assert.Equal(t, []string{"mycli dev"}, cfg.Default.ServerPatterns)
+			require.Contains(t, cfg.Projects, "web")
+			assert.Equal(t, process.HealthCheckHTTP, cfg.Projects["web"].HealthCheck.Type)
+			assert.Equal(t, 3*time.Second, cfg.Projects["web"].HealthCheck.Timeout)
+
+			// Every format must decode to the same logical configuration
+			cfg.sourceFile = ""
+			if expected == nil {
+				expected = cfg
+			} else {
+				assert.Equal(t, expected, cfg)
+			}
+		})
+	}
+}
+
+func TestLoadPortPatterns(t *testing.T) {
+	// Dots and uppercase letters would break map keys, which viper splits and lowercases
+	path := filepath.Join(t.TempDir(), "portguard.yml")
+	require.NoError(t, os.WriteFile(path, []byte(`default:
+  port_patterns:
+    - pattern: "node .*Server\\.js"
+      port: 8080
+    - pattern: "mycli dev"
+      port: 4500
+  server_patterns:
+    - "mycli dev"
+`), 0o600))
+
+	cfg := loadFile(t, path)
+	require.NoError(t, cfg.Validate())
+	assert.Equal(t, []PortPattern{
+		{Pattern: `node .*Server\.js`, Port: 8080},
+		{Pattern: "mycli dev", Port: 4500},
+	}, cfg.Default.PortPatterns)
+	assert.Equal(t, []string{"mycli dev"}, cfg.Default.ServerPatterns, "other settings load alongside the patterns")
+}
+
+func TestConfigSaveToSource(t *testing.T) {
+	for _, name := range []string{"portguard.yml", "portguard.toml", "portguard.json"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			require.NoError(t, os.WriteFile(path, []byte(formatFixtures[name]), 0o600))
+
+			cfg := loadFile(t, path)
+			cfg.Projects["web"].Port = 3100
+			require.NoError(t, cfg.SaveToSource())
+
+			reloaded := loadFile(t, path)
+			assert.Equal(t, 3100, reloaded.Projects["web"].Port)
+			assert.Equal(t, 10*time.Second, reloaded.Default.HealthCheck.Timeout)
+			assert.Equal(t, cfg.Projects["web"].HealthCheck, reloaded.Projects["web"].HealthCheck)
+		})
+	}
+
+	t.Run("without_source_file", func(t *testing.T) {
+		err := (&Config{}).SaveToSource()
+		assert.ErrorIs(t, err, ErrNoSourceFile)
+	})
+
+	t.Run("unsupported_extension", func(t *testing.T) {
+		err := (&Config{}).Save(filepath.Join(t.TempDir(), "portguard.ini"))
+		assert.ErrorIs(t, err, ErrUnsupportedFormat)
+	})
+}
+
+func TestFindConfigFile(t *testing.T) {
+	emptyDir := t.TempDir()
+	configDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "portguard.toml"), []byte(formatFixtures["portguard.toml"]), 0o600))
+
+	path, found := FindConfigFile(emptyDir, configDir)
+	assert.True(t, found)
+	assert.Equal(t, filepath.Join(configDir, "portguard.toml"), path)
+
+	_, found = FindConfigFile(emptyDir)
+	assert.False(t, found)
+}
+
+func TestConfigResolveProject(t *testing.T) {
+	defaults := &HealthCheckConfig{
+		Enabled:  true,
+		Timeout:  10 * time.Second,
+		Interval: 5 * time.Second,
+		Retries:  3,
+	}
+
+	tests := []struct {
+		name        string
+		defaults    *HealthCheckConfig
+		healthCheck *process.HealthCheck
+		expected    *process.HealthCheck
+		expectError error
+	}{
+		{
+			name:     "target_only_inherits_defaults",
+			defaults: defaults,
+			healthCheck: &process.HealthCheck{
+				Type:   process.HealthCheckHTTP,
+				Target: "http://localhost:3000/health",
+			},
+			expected: &process.HealthCheck{
+				Type:     process.HealthCheckHTTP,
+				Target:   "http://localhost:3000/health",
+				Timeout:  10 * time.Second,
+				Interval: 5 * time.Second,
+				Retries:  3,
+				Enabled:  true,
+			},
+		},
+		{
+			name:     "project_fields_take_precedence",
+			defaults: defaults,
+			healthCheck: &process.HealthCheck{
+				Type:    process.HealthCheckTCP,
+				Target:  "localhost:3000",
+				Timeout: 2 * time.Second,
+				Retries: 1,
+			},
+			expected: &process.HealthCheck{
+				Type:     process.HealthCheckTCP,
+				Target:   "localhost:3000",
+				Timeout:  2 * time.Second,
+				Interval: 5 * time.Second,
+				Retries:  1,
+				Enabled:  true,
+			},
+		},
+		{
+			name:     "no_health_check_stays_nil",
+			defaults: defaults,
+		},
+		{
+			name: "enabled_without_timeout_is_invalid",
+			healthCheck: &process.HealthCheck{
+				Type:    process.HealthCheckHTTP,
+				Target:  "http://localhost:3000/health",
+				Enabled: true,
+			},
+			expectError: ErrHealthCheckTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Default: &DefaultConfig{HealthCheck: tt.defaults},
+				Projects: map[string]*ProjectConfig{
+					"web": {Command: "npm run dev", Port: 3000, HealthCheck: tt.healthCheck},
+				},
+			}
+
+			resolved, err := cfg.ResolveProject("web")
+			if tt.expectError != nil {
+				require.ErrorIs(t, err, tt.expectError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "npm run dev", resolved.Command)
+			assert.Equal(t, tt.expected, resolved.HealthCheck)
+			// The stored project is left untouched
+			if tt.healthCheck != nil {
+				assert.NotSame(t, tt.healthCheck, resolved.HealthCheck)
+				assert.Zero(t, cfg.Projects["web"].HealthCheck.Interval)
+			}
+		})
+	}
+
+	t.Run("unknown_project", func(t *testing.T) {
+		_, err := (&Config{Projects: map[string]*ProjectConfig{}}).ResolveProject("missing")
+		assert.ErrorIs(t, err, ErrProjectNotFound)
+	})
+}
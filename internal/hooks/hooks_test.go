@@ -3,7 +3,9 @@ package hooks
 import (
 	"encoding/json"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"runtime"
 	"testing"
 	"time"
 
@@ -224,6 +226,85 @@ func TestStatusCheckerCheck(t *testing.T) {
 	})
 }
 
+func TestStatusCheckerCheckInstallation(t *testing.T) {
+	// writeInstallation lays out a basic-template installation under a fresh HOME
+	writeInstallation := func(t *testing.T, hookVersion string, scriptMode os.FileMode, scripts ...string) string {
+		t.Helper()
+		home := t.TempDir()
+		t.Setenv("HOME", home)
+		t.Setenv("USERPROFILE", home) // os.UserHomeDir on Windows
+		configPath := filepath.Join(home, ".claude")
+		require.NoError(t, os.MkdirAll(filepath.Join(configPath, "hooks"), 0o755))
+
+		pgConfig := PortguardConfig{
+			Version:   "1.0.0",
+			Template:  "basic",
+			Installed: time.Now(),
+			Hooks: map[string]HookConfig{
+				"preToolUse":  {Enabled: true, Version: hookVersion},
+				"postToolUse": {Enabled: true, Version: hookVersion},
+			},
+		}
+		data, err := json.Marshal(pgConfig)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(configPath, ".portguard-hooks.json"), data, 0o644))
+
+		for _, name := range scripts {
+			require.NoError(t, os.WriteFile(hookScriptPath(configPath, name), []byte("#!/bin/sh\n"), scriptMode))
+		}
+		return configPath
+	}
+
+	t.Run("complete_installation", func(t *testing.T) {
+		configPath := writeInstallation(t, "1.0.0", 0o755, "preToolUse", "postToolUse")
+
+		result, err := NewStatusChecker().Check()
+		require.NoError(t, err)
+		assert.True(t, result.Installed)
+		assert.Equal(t, configPath, result.ConfigPath)
+		assert.Equal(t, "basic", result.Template)
+		assert.Equal(t, []string{"postToolUse", "preToolUse"}, result.HooksActive)
+		assert.Empty(t, result.Messages)
+	})
+
+	t.Run("missing_script", func(t *testing.T) {
+		writeInstallation(t, "1.0.0", 0o755, "preToolUse")
+
+		result, err := NewStatusChecker().Check()
+		require.NoError(t, err)
+		assert.False(t, result.Installed)
+		assert.Equal(t, []string{"preToolUse"}, result.HooksActive)
+		require.Len(t, result.Messages, 1)
+		assert.Contains(t, result.Messages[0], "postToolUse.sh is missing")
+	})
+
+	t.Run("script_not_executable", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("Windows has no executable permission bit")
+		}
+		writeInstallation(t, "1.0.0", 0o644, "preToolUse", "postToolUse")
+
+		result, err := NewStatusChecker().Check()
+		require.NoError(t, err)
+		assert.False(t, result.Installed)
+		assert.Empty(t, result.HooksActive)
+		require.Len(t, result.Messages, 2)
+		assert.Contains(t, result.Messages[0], "is not executable")
+	})
+
+	t.Run("version_drift", func(t *testing.T) {
+		writeInstallation(t, "0.9.0", 0o755, "preToolUse", "postToolUse")
+
+		result, err := NewStatusChecker().Check()
+		require.NoError(t, err)
+		assert.True(t, result.Installed, "drift is reported but does not make hooks unusable")
+		require.Len(t, result.Messages, 2)
+		for _, message := range result.Messages {
+			assert.Contains(t, message, "version 0.9.0 but template 'basic' is 1.0.0")
+		}
+	})
+}
+
 func TestManagerGetClaudeConfigPaths(t *testing.T) {
 	manager := NewManager()
 
@@ -337,6 +418,29 @@ func TestInstallerCheckDependencies(t *testing.T) {
 		available := installer.isCommandAvailable("definitely-not-a-real-command-12345")
 		assert.False(t, available)
 	})
+
+	t.Run("finds_go_by_bare_name", func(t *testing.T) {
+		// The test binary is built by the go tool, but it may run without it on PATH
+		if _, err := exec.LookPath("go"); err != nil {
+			t.Skip("go is not on PATH")
+		}
+		// On Windows this resolves go.exe through PATHEXT
+		assert.True(t, installer.isCommandAvailable("go"))
+		assert.True(t, NewStatusChecker().isCommandAvailable("go"))
+	})
+
+	t.Run("finds_absolute_path", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("executable files need a PATHEXT extension on Windows")
+		}
+		script := filepath.Join(t.TempDir(), "tool")
+		require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\n"), 0o755))
+		assert.True(t, installer.isCommandAvailable(script))
+
+		plain := filepath.Join(t.TempDir(), "notes.txt")
+		require.NoError(t, os.WriteFile(plain, nil, 0o644))
+		assert.False(t, installer.isCommandAvailable(plain), "non-executable files are not commands")
+	})
 }
 
 func TestInstallerFindClaudeConfigPath(t *testing.T) {
@@ -420,3 +524,115 @@ func TestHookConfig(t *testing.T) {
 		assert.Contains(t, config.Environment, "PORTGUARD_ENV")
 	})
 }
+
+func TestInstallerInstall_Idempotent(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Dependency stubs are shell scripts")
+	}
+
+	// Satisfy the template dependencies with stubs
+	binDir := t.TempDir()
+	for _, dep := range []string{"jq", "portguard"} {
+		require.NoError(t, os.WriteFile(filepath.Join(binDir, dep), []byte("#!/bin/sh\n"), 0o755)) //nolint:gosec // Stub must be executable
+	}
+	t.Setenv("PATH", binDir)
+
+	configDir := t.TempDir()
+	settingsPath := filepath.Join(configDir, "settings.json")
+	pgConfigPath := filepath.Join(configDir, ".portguard-hooks.json")
+	require.NoError(t, os.WriteFile(settingsPath, []byte(`{
+  "model": "sonnet",
+  "permissions": {"allow": ["Bash(ls:*)"]},
+  "hooks": {"postSession": {"enabled": true, "command": "/usr/local/bin/notify"}}
+}`), 0o600))
+
+	installer := NewInstaller()
+	install := func(force bool) *InstallResult {
+		t.Helper()
+		result, err := installer.Install(&InstallConfig{Template: "basic", ClaudeConfig: configDir, Force: force})
+		require.NoError(t, err)
+		return result
+	}
+	readSettings := func() map[string]interface{} {
+		t.Helper()
+		data, err := os.ReadFile(settingsPath)
+		require.NoError(t, err)
+		var settings map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &settings))
+		return settings
+	}
+	changes := func(result *InstallResult) map[string]HookChange {
+		byName := make(map[string]HookChange)
+		for _, status := range result.Hooks {
+			byName[status.Name] = status.Change
+		}
+		return byName
+	}
+
+	first := install(false)
+	assert.ElementsMatch(t, []string{"preToolUse.sh", "postToolUse.sh"}, first.HooksCreated)
+	assert.True(t, first.ConfigUpdated)
+	assert.Equal(t, map[string]HookChange{"preToolUse": HookCreated, "postToolUse": HookCreated}, changes(first))
+
+	settings := readSettings()
+	assert.Equal(t, "sonnet", settings["model"], "unrelated settings are preserved")
+	assert.NotNil(t, settings["permissions"])
+	hooks := settings["hooks"].(map[string]interface{})
+	assert.Equal(t, "/usr/local/bin/notify", hooks["postSession"].(map[string]interface{})["command"])
+	assert.Equal(t, hookScriptPath(configDir, "preToolUse"), hooks["preToolUse"].(map[string]interface{})["command"])
+
+	settingsBefore, err := os.ReadFile(settingsPath)
+	require.NoError(t, err)
+	pgConfigBefore, err := os.ReadFile(pgConfigPath)
+	require.NoError(t, err)
+
+	t.Run("reinstall_is_stable", func(t *testing.T) {
+		second := install(false)
+		assert.Empty(t, second.HooksCreated)
+		assert.False(t, second.ConfigUpdated)
+		assert.Equal(t, map[string]HookChange{"preToolUse": HookUnchanged, "postToolUse": HookUnchanged}, changes(second))
+		assert.Contains(t, second.Messages, "Hooks already up to date")
+
+		settingsAfter, err := os.ReadFile(settingsPath)
+		require.NoError(t, err)
+		assert.Equal(t, string(settingsBefore), string(settingsAfter))
+		pgConfigAfter, err := os.ReadFile(pgConfigPath)
+		require.NoError(t, err)
+		assert.Equal(t, string(pgConfigBefore), string(pgConfigAfter))
+	})
+
+	t.Run("changed_entry_is_updated_keeping_user_fields", func(t *testing.T) {
+		settings := readSettings()
+		entry := settings["hooks"].(map[string]interface{})["preToolUse"].(map[string]interface{})
+		entry["timeout"] = 1
+		entry["matcher"] = "Bash"
+		data, err := json.Marshal(settings)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(settingsPath, data, 0o600))
+
+		result := install(false)
+		assert.Equal(t, HookUpdated, changes(result)["preToolUse"])
+		assert.Equal(t, HookUnchanged, changes(result)["postToolUse"])
+
+		entry = readSettings()["hooks"].(map[string]interface{})["preToolUse"].(map[string]interface{})
+		assert.Equal(t, "Bash", entry["matcher"])
+		assert.NotEqual(t, float64(1), entry["timeout"])
+	})
+
+	t.Run("foreign_hook_is_skipped_unless_forced", func(t *testing.T) {
+		settings := readSettings()
+		settings["hooks"].(map[string]interface{})["postToolUse"] = map[string]interface{}{"enabled": true, "command": "/opt/lint.sh"}
+		data, err := json.Marshal(settings)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(settingsPath, data, 0o600))
+
+		result := install(false)
+		assert.Equal(t, HookSkipped, changes(result)["postToolUse"])
+		assert.NotEmpty(t, result.Warnings)
+		assert.Equal(t, "/opt/lint.sh", readSettings()["hooks"].(map[string]interface{})["postToolUse"].(map[string]interface{})["command"])
+
+		result = install(true)
+		assert.Equal(t, HookUpdated, changes(result)["postToolUse"])
+		assert.Equal(t, hookScriptPath(configDir, "postToolUse"), readSettings()["hooks"].(map[string]interface{})["postToolUse"].(map[string]interface{})["command"])
+	})
+}
@@ -8,7 +8,12 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -174,6 +179,7 @@ func (i *Installer) Install(config *InstallConfig) (*InstallResult, error) {
 		InstalledAt:  time.Now(),
 		ConfigPath:   claudeConfigPath,
 		HooksCreated: []string{},
+		Hooks:        []HookInstallStatus{},
 		Messages:     []string{},
 	}
 
@@ -182,28 +188,40 @@ func (i *Installer) Install(config *InstallConfig) (*InstallResult, error) {
 		return result, nil
 	}
 
+	// Update Claude Code settings.json first: hooks whose event belongs to someone else are skipped
+	settingsChanges, settingsErr := i.updateClaudeCodeSettings(claudeConfigPath, template, config.Force) //nolint:govet // TODO: rename variables to avoid shadowing
+	if settingsErr != nil {
+		return nil, fmt.Errorf("failed to update Claude Code settings: %w", settingsErr)
+	}
+
 	// Create hook scripts
 	for _, hook := range template.Hooks {
-		scriptPath := filepath.Join(claudeConfigPath, "hooks", hook.Name+".sh")
+		change := settingsChanges[hook.Type]
+		if change == HookSkipped {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s already has a hook that is not portguard's; left it in place (use --force to replace it)", hook.Type))
+			result.Hooks = append(result.Hooks, HookInstallStatus{Name: hook.Name, Type: hook.Type, Change: change})
+			continue
+		}
 
+		scriptPath := hookScriptPath(claudeConfigPath, hook.Name)
 		if mkdirErr := os.MkdirAll(filepath.Dir(scriptPath), 0o755); mkdirErr != nil { //nolint:gocritic,govet // TODO: consider using constants for file permissions and avoid shadowing
 			return nil, fmt.Errorf("failed to create hooks directory: %w", mkdirErr)
 		}
 
-		if writeErr := os.WriteFile(scriptPath, []byte(hook.Script), 0o755); writeErr != nil { //nolint:gocritic,govet // TODO: consider using constants for file permissions and avoid shadowing
+		scriptChange, writeErr := writeFileIfChanged(scriptPath, []byte(hook.Script), 0o755)
+		if writeErr != nil {
 			return nil, fmt.Errorf("failed to write hook script: %w", writeErr)
 		}
+		if scriptChange == HookCreated {
+			result.HooksCreated = append(result.HooksCreated, hook.Name+".sh")
+		}
 
-		result.HooksCreated = append(result.HooksCreated, hook.Name+".sh")
-	}
-
-	// Update Claude Code settings.json
-	if settingsErr := i.updateClaudeCodeSettings(claudeConfigPath, template); settingsErr != nil { //nolint:govet // TODO: rename variables to avoid shadowing
-		return nil, fmt.Errorf("failed to update Claude Code settings: %w", settingsErr)
+		result.Hooks = append(result.Hooks, HookInstallStatus{Name: hook.Name, Type: hook.Type, Change: combineChanges(change, scriptChange)})
 	}
-	result.ConfigUpdated = true
+	result.ConfigUpdated = settingsChanged(settingsChanges)
 
-	// Create portguard hook config
+	// Create portguard hook config, keeping the original installation time on reinstalls
+	pgConfigPath := filepath.Join(claudeConfigPath, ".portguard-hooks.json")
 	pgConfig := PortguardConfig{
 		Version:   "1.0.0",
 		Template:  template.Name,
@@ -211,6 +229,12 @@ func (i *Installer) Install(config *InstallConfig) (*InstallResult, error) {
 		Hooks:     make(map[string]HookConfig),
 		Settings:  make(map[string]interface{}),
 	}
+	if data, readErr := os.ReadFile(pgConfigPath); readErr == nil { //nolint:govet // TODO: rename variables to avoid shadowing
+		var previous PortguardConfig
+		if json.Unmarshal(data, &previous) == nil && !previous.Installed.IsZero() {
+			pgConfig.Installed = previous.Installed
+		}
+	}
 
 	for _, hook := range template.Hooks {
 		pgConfig.Hooks[hook.Name] = HookConfig{
@@ -226,12 +250,15 @@ func (i *Installer) Install(config *InstallConfig) (*InstallResult, error) {
 		return nil, fmt.Errorf("failed to marshal portguard config: %w", err)
 	}
 
-	pgConfigPath := filepath.Join(claudeConfigPath, ".portguard-hooks.json")
-	if err := os.WriteFile(pgConfigPath, pgConfigData, 0o644); err != nil { //nolint:gocritic // TODO: consider using constants for file permissions
+	if _, err := writeFileIfChanged(pgConfigPath, pgConfigData, 0o644); err != nil {
 		return nil, fmt.Errorf("failed to write portguard config: %w", err)
 	}
 
-	result.Messages = append(result.Messages, "Hooks installed successfully", "Configuration: "+pgConfigPath) //nolint:gocritic,perfsprint // TODO: optimize message building
+	summary := "Hooks installed successfully"
+	if !result.ConfigUpdated && len(result.HooksCreated) == 0 && !anyChange(result.Hooks, HookUpdated) {
+		summary = "Hooks already up to date"
+	}
+	result.Messages = append(result.Messages, summary, "Configuration: "+pgConfigPath)
 
 	return result, nil
 }
@@ -275,63 +302,173 @@ func (i *Installer) checkDependencies(deps []string) error {
 
 // isCommandAvailable checks if a command is available in PATH
 func (i *Installer) isCommandAvailable(command string) bool {
-	_, err := os.Stat(command)
-	if err == nil {
-		return true
-	}
-
-	// Check PATH
-	pathEnv := os.Getenv("PATH")
-	for _, dir := range filepath.SplitList(pathEnv) {
-		fullPath := filepath.Join(dir, command)
-		if _, err := os.Stat(fullPath); err == nil {
-			return true
-		}
-	}
-
-	return false
+	return commandAvailable(command)
 }
 
-// updateClaudeCodeSettings updates the Claude Code settings.json file
-func (i *Installer) updateClaudeCodeSettings(configPath string, template *Template) error {
+// managedHookFields are the settings.json hook fields portguard owns; other fields are the user's
+var managedHookFields = []string{"enabled", "command", "timeout", "failureHandling", "environment", "description"}
+
+// updateClaudeCodeSettings installs the template's hooks into settings.json and reports the change
+// per hook type. Settings and hook fields portguard does not own are preserved, the file is only
+// rewritten when something changed, and events already hooked by something other than portguard
+// are skipped unless force is set.
+func (i *Installer) updateClaudeCodeSettings(configPath string, template *Template, force bool) (map[HookType]HookChange, error) {
 	settingsPath := filepath.Join(configPath, "settings.json")
 
-	// Load existing settings or create new
-	var settings ClaudeCodeSettings
+	// Load existing settings as raw JSON so unknown fields survive the rewrite
+	settings := make(map[string]json.RawMessage)
 	if data, err := os.ReadFile(settingsPath); err == nil {
-		_ = json.Unmarshal(data, &settings) //nolint:errcheck // TODO: handle unmarshal error properly instead of ignoring
+		if err := json.Unmarshal(data, &settings); err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidConfig, settingsPath, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read settings: %w", err)
 	}
 
-	// Initialize hooks map if needed
-	if settings.Hooks == nil {
-		settings.Hooks = make(map[string]ClaudeCodeHook)
+	hooks := make(map[string]map[string]interface{})
+	if raw, exists := settings["hooks"]; exists {
+		if err := json.Unmarshal(raw, &hooks); err != nil {
+			return nil, fmt.Errorf("%w: %s: hooks: %w", ErrInvalidConfig, settingsPath, err)
+		}
 	}
 
-	// Add hooks from template
+	changes := make(map[HookType]HookChange, len(template.Hooks))
 	for _, hook := range template.Hooks {
-		claudeHook := ClaudeCodeHook{
+		desired, err := hookSettingsEntry(ClaudeCodeHook{
 			Enabled:         hook.Enabled,
-			Command:         filepath.Join(configPath, "hooks", hook.Name+".sh"),
+			Command:         hookScriptPath(configPath, hook.Name),
 			Timeout:         int(hook.Timeout / time.Millisecond),
 			FailureHandling: string(hook.FailureMode),
 			Environment:     hook.Environment,
 			Description:     hook.Description,
+		})
+		if err != nil {
+			return nil, err
 		}
 
-		settings.Hooks[string(hook.Type)] = claudeHook
+		existing, exists := hooks[string(hook.Type)]
+		switch {
+		case !exists:
+			hooks[string(hook.Type)] = desired
+			changes[hook.Type] = HookCreated
+			continue
+		case !isPortguardHook(existing, configPath) && !force:
+			changes[hook.Type] = HookSkipped
+			continue
+		}
+
+		merged := make(map[string]interface{}, len(existing)+len(desired))
+		for key, value := range existing {
+			merged[key] = value
+		}
+		for _, key := range managedHookFields {
+			delete(merged, key)
+		}
+		for key, value := range desired {
+			merged[key] = value
+		}
+
+		if reflect.DeepEqual(merged, existing) {
+			changes[hook.Type] = HookUnchanged
+			continue
+		}
+		hooks[string(hook.Type)] = merged
+		changes[hook.Type] = HookUpdated
+	}
+
+	if !settingsChanged(changes) {
+		return changes, nil
+	}
+
+	hooksData, err := json.Marshal(hooks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal hooks: %w", err)
 	}
+	settings["hooks"] = hooksData
 
-	// Write updated settings
 	data, err := json.MarshalIndent(settings, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to marshal settings: %w", err)
+		return nil, fmt.Errorf("failed to marshal settings: %w", err)
 	}
 
 	if err := os.WriteFile(settingsPath, data, 0o644); err != nil { //nolint:gocritic // TODO: consider using constants for file permissions
-		return fmt.Errorf("failed to write settings: %w", err)
+		return nil, fmt.Errorf("failed to write settings: %w", err)
 	}
 
-	return nil
+	return changes, nil
+}
+
+// hookSettingsEntry converts a hook to the generic form it has in settings.json
+func hookSettingsEntry(hook ClaudeCodeHook) (map[string]interface{}, error) {
+	data, err := json.Marshal(hook)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal hook: %w", err)
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("failed to convert hook: %w", err)
+	}
+	return entry, nil
+}
+
+// isPortguardHook reports whether a settings.json hook entry runs a script portguard installed
+func isPortguardHook(entry map[string]interface{}, configPath string) bool {
+	command, _ := entry["command"].(string) //nolint:errcheck // A missing or non-string command is not portguard's
+	return command != "" && strings.HasPrefix(filepath.Clean(command), filepath.Join(configPath, "hooks")+string(filepath.Separator))
+}
+
+// settingsChanged reports whether any hook entry in settings.json was added or rewritten
+func settingsChanged(changes map[HookType]HookChange) bool {
+	for _, change := range changes {
+		if change == HookCreated || change == HookUpdated {
+			return true
+		}
+	}
+	return false
+}
+
+// anyChange reports whether any hook has the given change
+func anyChange(statuses []HookInstallStatus, change HookChange) bool {
+	for _, status := range statuses {
+		if status.Change == change {
+			return true
+		}
+	}
+	return false
+}
+
+// combineChanges merges the settings entry and script changes of one hook; created wins over
+// updated, which wins over unchanged
+func combineChanges(changes ...HookChange) HookChange {
+	combined := HookUnchanged
+	for _, change := range changes {
+		switch {
+		case change == HookCreated:
+			return HookCreated
+		case change == HookUpdated:
+			combined = HookUpdated
+		}
+	}
+	return combined
+}
+
+// writeFileIfChanged writes data to path unless the file already holds exactly that content
+func writeFileIfChanged(path string, data []byte, perm os.FileMode) (HookChange, error) {
+	change := HookCreated
+	existing, err := os.ReadFile(path) //nolint:gosec // Paths are inside the Claude Code config directory
+	switch {
+	case err == nil && string(existing) == string(data):
+		return HookUnchanged, nil
+	case err == nil:
+		change = HookUpdated
+	case !os.IsNotExist(err):
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if err := os.WriteFile(path, data, perm); err != nil {
+		return "", err
+	}
+	return change, nil
 }
 
 // Updater handles hook updates
@@ -388,7 +525,9 @@ func NewStatusChecker() *StatusChecker {
 	return &StatusChecker{}
 }
 
-// Check checks the current installation status
+// Check checks the current installation status. Hooks count as installed when the
+// portguard hooks configuration exists and every enabled hook script is present and
+// executable; missing scripts and drift from the builtin template are reported in Messages.
 func (s *StatusChecker) Check() (*StatusResult, error) {
 	result := &StatusResult{
 		Installed:      false,
@@ -406,44 +545,91 @@ func (s *StatusChecker) Check() (*StatusResult, error) {
 		}
 	}
 
-	// FIXED: Check actual installation status
-	configPaths := s.getClaudeConfigPaths()
-	for _, configPath := range configPaths {
+	for _, configPath := range NewManager().getClaudeConfigPaths() {
 		pgConfigPath := filepath.Join(configPath, ".portguard-hooks.json")
-		if _, err := os.Stat(pgConfigPath); err == nil {
-			// Found portguard hooks configuration file
-			result.Installed = true
-			result.ConfigPath = configPath
-
-			// Try to read the configuration to get more details
-			if pgConfig, err := s.readPortguardConfig(pgConfigPath); err == nil {
-				result.Template = pgConfig.Template
-				result.Version = pgConfig.Version
-			}
-			break
+		if _, err := os.Stat(pgConfigPath); err != nil {
+			continue
 		}
-	}
+		result.ConfigPath = configPath
 
-	if !result.Installed {
-		result.Messages = append(result.Messages, "No portguard hooks configuration found")
+		pgConfig, err := s.readPortguardConfig(pgConfigPath)
+		if err != nil {
+			result.Messages = append(result.Messages,
+				fmt.Sprintf("%s is unreadable (%v); reinstall with 'portguard hooks install'", pgConfigPath, err))
+			return result, nil
+		}
+		result.Template = pgConfig.Template
+		result.Version = pgConfig.Version
+
+		result.Installed = s.checkScripts(configPath, pgConfig, result)
+		s.checkTemplateDrift(pgConfig, result)
+		return result, nil
 	}
 
+	result.Messages = append(result.Messages, "No portguard hooks configuration found")
 	return result, nil
 }
 
-// getClaudeConfigPaths returns possible Claude Code configuration paths
-func (s *StatusChecker) getClaudeConfigPaths() []string {
-	homeDir, err := os.UserHomeDir()
+// checkScripts verifies each enabled hook's script, recording active hooks and problems.
+// It reports whether every enabled script is usable.
+func (s *StatusChecker) checkScripts(configPath string, pgConfig *PortguardConfig, result *StatusResult) bool {
+	names := make([]string, 0, len(pgConfig.Hooks))
+	for name := range pgConfig.Hooks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ok := true
+	for _, name := range names {
+		if !pgConfig.Hooks[name].Enabled {
+			continue
+		}
+		scriptPath := hookScriptPath(configPath, name)
+		info, err := os.Stat(scriptPath)
+		switch {
+		case err != nil:
+			ok = false
+			result.Messages = append(result.Messages, fmt.Sprintf(
+				"Hook script %s is missing; run 'portguard hooks install --template %s' to restore it", scriptPath, pgConfig.Template))
+		case runtime.GOOS != "windows" && info.Mode().Perm()&0o111 == 0:
+			ok = false
+			result.Messages = append(result.Messages, fmt.Sprintf(
+				"Hook script %s is not executable; run 'chmod +x %s'", scriptPath, scriptPath))
+		default:
+			result.HooksActive = append(result.HooksActive, name)
+		}
+	}
+	return ok
+}
+
+// checkTemplateDrift reports hooks installed from an older or newer version of the builtin template
+func (s *StatusChecker) checkTemplateDrift(pgConfig *PortguardConfig, result *StatusResult) {
+	template, err := GetTemplate(pgConfig.Template)
 	if err != nil {
-		return []string{}
+		result.Messages = append(result.Messages,
+			fmt.Sprintf("Template '%s' is not a builtin template; version drift cannot be checked", pgConfig.Template))
+		return
 	}
 
-	return []string{
-		filepath.Join(homeDir, ".config", "claude-code"),
-		filepath.Join(homeDir, ".claude"),
+	for _, hook := range template.Hooks {
+		installed, found := pgConfig.Hooks[hook.Name]
+		switch {
+		case !found:
+			result.Messages = append(result.Messages, fmt.Sprintf(
+				"Hook %s from template '%s' %s is not installed; run 'portguard hooks update'", hook.Name, template.Name, template.Version))
+		case installed.Version != template.Version:
+			result.Messages = append(result.Messages, fmt.Sprintf(
+				"Hook %s is version %s but template '%s' is %s; run 'portguard hooks update'",
+				hook.Name, installed.Version, template.Name, template.Version))
+		}
 	}
 }
 
+// hookScriptPath returns where the script for a named hook is installed
+func hookScriptPath(configPath, name string) string {
+	return filepath.Join(configPath, "hooks", name+".sh")
+}
+
 // readPortguardConfig reads and parses the portguard hooks configuration
 func (s *StatusChecker) readPortguardConfig(configPath string) (*PortguardConfig, error) {
 	data, err := os.ReadFile(configPath)
@@ -461,18 +647,12 @@ func (s *StatusChecker) readPortguardConfig(configPath string) (*PortguardConfig
 
 // isCommandAvailable checks if a command is available
 func (s *StatusChecker) isCommandAvailable(command string) bool {
-	_, err := os.Stat(command)
-	if err == nil {
-		return true
-	}
-
-	pathEnv := os.Getenv("PATH")
-	for _, dir := range filepath.SplitList(pathEnv) {
-		fullPath := filepath.Join(dir, command)
-		if _, err := os.Stat(fullPath); err == nil {
-			return true
-		}
-	}
+	return commandAvailable(command)
+}
 
-	return false
+// commandAvailable resolves a bare name through PATH, or checks a path directly. On Windows
+// exec.LookPath also tries the PATHEXT extensions, so "jq" finds jq.exe.
+func commandAvailable(command string) bool {
+	_, err := exec.LookPath(command)
+	return err == nil
 }
@@ -66,14 +66,32 @@ type InstallConfig struct {
 
 // InstallResult contains the result of hook installation
 type InstallResult struct {
-	Success       bool      `json:"success"`
-	Template      string    `json:"template"`
-	InstalledAt   time.Time `json:"installed_at"`
-	ConfigPath    string    `json:"config_path"`
-	HooksCreated  []string  `json:"hooks_created"`
-	ConfigUpdated bool      `json:"config_updated"`
-	Messages      []string  `json:"messages,omitempty"`
-	Warnings      []string  `json:"warnings,omitempty"`
+	Success       bool                `json:"success"`
+	Template      string              `json:"template"`
+	InstalledAt   time.Time           `json:"installed_at"`
+	ConfigPath    string              `json:"config_path"`
+	HooksCreated  []string            `json:"hooks_created"`  // Scripts written for the first time
+	ConfigUpdated bool                `json:"config_updated"` // Whether settings.json was rewritten
+	Hooks         []HookInstallStatus `json:"hooks"`
+	Messages      []string            `json:"messages,omitempty"`
+	Warnings      []string            `json:"warnings,omitempty"`
+}
+
+// HookChange is what an installation did to one hook
+type HookChange string
+
+const (
+	HookCreated   HookChange = "created"   // Script or settings entry was added
+	HookUpdated   HookChange = "updated"   // An earlier portguard installation was changed
+	HookUnchanged HookChange = "unchanged" // Already installed as the template describes
+	HookSkipped   HookChange = "skipped"   // The event has a hook that is not portguard's; use force to replace it
+)
+
+// HookInstallStatus reports the outcome of installing one hook
+type HookInstallStatus struct {
+	Name   string     `json:"name"`
+	Type   HookType   `json:"type"`
+	Change HookChange `json:"change"`
 }
 
 // UpdateConfig configures hook updates
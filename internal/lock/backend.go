@@ -0,0 +1,58 @@
+package lock
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrUnknownBackend is returned for lock backend names other than file and flock
+var ErrUnknownBackend = errors.New("unknown lock backend")
+
+// Backend selects how portguard invocations exclude each other
+type Backend string
+
+// Supported lock backends
+const (
+	BackendFile  Backend = "file"  // Lock file created exclusively and parsed by portguard
+	BackendFlock Backend = "flock" // OS lock via flock(2) or LockFileEx, released by the kernel on exit
+)
+
+// Locker is a lock manager that can also report and clear its holder
+type Locker interface {
+	Lock() error
+	Unlock() error
+	IsLocked() bool
+	GetLockInfo() (*Info, error)
+	ForceClearLock() error
+	Timeout() time.Duration
+}
+
+// ParseBackend parses a lock backend name; empty selects the file backend
+func ParseBackend(name string) (Backend, error) {
+	switch backend := Backend(strings.ToLower(strings.TrimSpace(name))); backend {
+	case "", BackendFile:
+		return BackendFile, nil
+	case BackendFlock:
+		return BackendFlock, nil
+	default:
+		return "", fmt.Errorf("%w: %q (expected file or flock)", ErrUnknownBackend, name)
+	}
+}
+
+// Path returns the lock file guarding the state in dir. Both backends use the same file and
+// respect each other's holders, so invocations that disagree on the backend, e.g. while the
+// configuration changes, still exclude each other.
+func Path(dir string) string {
+	return filepath.Join(dir, "portguard.lock")
+}
+
+// New creates a lock manager for the backend guarding lockFile
+func New(backend Backend, lockFile string, timeout time.Duration) Locker {
+	if backend == BackendFlock {
+		return NewFlockLock(lockFile, timeout)
+	}
+	return NewFileLock(lockFile, timeout)
+}
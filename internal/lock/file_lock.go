@@ -6,12 +6,16 @@ package lock
 import (
 	"errors"
 	"fmt"
+	"io/fs"
+	"math/rand/v2"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"syscall"
 	"time"
 )
 
@@ -20,11 +24,27 @@ var (
 	ErrLockTimeout       = errors.New("failed to acquire lock within timeout")
 	ErrNotOwner          = errors.New("cannot unlock: we don't own the lock")
 	ErrInvalidLockFormat = errors.New("invalid lock file format")
+	ErrLockNotHeld       = errors.New("cannot unlock: lock is not held")
+	ErrLockNotWritable   = errors.New("lock file is not writable")
 )
 
+// openLockFile opens lock files; tests replace it to simulate file system errors
+var openLockFile = os.OpenFile
+
+// notWritable reports whether err means the lock file can never be created, so waiting is pointless
+func notWritable(err error) bool {
+	return errors.Is(err, fs.ErrPermission) || errors.Is(err, syscall.EROFS)
+}
+
 // Global counter to ensure unique instance IDs
 var instanceCounter uint64
 
+// Backoff bounds between lock acquisition attempts
+const (
+	DefaultRetryInterval = 10 * time.Millisecond
+	maxRetryInterval     = 250 * time.Millisecond
+)
+
 // FileLock implements LockManager interface using file-based locking
 type FileLock struct {
 	lockFile    string
@@ -32,6 +52,10 @@ type FileLock struct {
 	locked      bool
 	instanceID  uint64     // Unique identifier for this instance
 	mu          sync.Mutex // Protects locked field
+
+	// RetryInterval is the base delay between acquisition attempts; it doubles
+	// (with jitter) after each failed attempt
+	RetryInterval time.Duration
 }
 
 // NewFileLock creates a new file-based lock manager
@@ -46,10 +70,11 @@ func NewFileLock(lockFile string, timeout time.Duration) *FileLock {
 	instanceID := (uint64(now) << 16) | (counter & 0xFFFF)
 
 	return &FileLock{
-		lockFile:    lockFile,
-		lockTimeout: timeout,
-		locked:      false,
-		instanceID:  instanceID,
+		lockFile:      lockFile,
+		lockTimeout:   timeout,
+		locked:        false,
+		instanceID:    instanceID,
+		RetryInterval: DefaultRetryInterval,
 	}
 }
 
@@ -71,43 +96,96 @@ func (fl *FileLock) Lock() error {
 	// Try to acquire lock with timeout
 	deadline := time.Now().Add(fl.lockTimeout)
 
-	for time.Now().Before(deadline) {
+	for attempt := 0; time.Now().Before(deadline); attempt++ {
 		// Try to create lock file exclusively
-		file, err := os.OpenFile(fl.lockFile, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		err := fl.create()
 		if err == nil {
-			// Successfully acquired lock
-			pid := os.Getpid()
-			timestamp := time.Now().Unix()
-
-			// Write PID, timestamp, and instance ID to lock file
-			lockData := fmt.Sprintf("%d\n%d\n%d\n", pid, timestamp, fl.instanceID)
-			if _, err := file.WriteString(lockData); err != nil {
-				_ = file.Close() // Best effort cleanup on error
-				return fmt.Errorf("failed to write lock data: %w", err)
-			}
-			_ = file.Close() // Close lock file after writing
-
 			// Set locked flag under mutex protection
 			fl.mu.Lock()
 			fl.locked = true
 			fl.mu.Unlock()
 			return nil
 		}
+		if errors.Is(err, errLockData) {
+			return err
+		}
 
-		// Check if existing lock is stale
-		if fl.isStale() {
-			// Remove stale lock and try again
-			_ = os.Remove(fl.lockFile) //nolint:errcheck // Best effort cleanup of stale lock
-			continue
+		switch {
+		case errors.Is(err, fs.ErrExist):
+			// Remove a stale lock and try again at once; if it cannot be removed, wait like for a live one
+			if fl.removeIfStale() {
+				continue
+			}
+		case notWritable(err):
+			return fmt.Errorf("%w: %w", ErrLockNotWritable, err)
 		}
 
-		// Wait a bit before retrying
-		time.Sleep(100 * time.Millisecond)
+		// Back off before retrying, without sleeping past the deadline
+		delay := fl.backoff(attempt)
+		if remaining := time.Until(deadline); delay > remaining {
+			delay = remaining
+		}
+		time.Sleep(delay)
 	}
 
 	return fmt.Errorf("%w: %v", ErrLockTimeout, fl.lockTimeout)
 }
 
+// errLockData marks a failure to write the holder info, which retrying does not fix
+var errLockData = errors.New("failed to write lock data")
+
+// create writes the PID, timestamp and instance ID to a temporary file and links it into
+// place, failing like an exclusive create if the lock file exists. The lock file thus never
+// exists without its holder info, which a FlockLock would take for a free lock.
+func (fl *FileLock) create() error {
+	tmpFile := fmt.Sprintf("%s.%d-%d.tmp", fl.lockFile, os.Getpid(), fl.instanceID)
+	file, err := openLockFile(tmpFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmpFile) }() //nolint:errcheck // Only the linked name is the lock
+
+	lockData := fmt.Sprintf("%d\n%d\n%d\n", os.Getpid(), time.Now().Unix(), fl.instanceID)
+	if _, err := file.WriteString(lockData); err != nil {
+		_ = file.Close() // Best effort cleanup on error
+		return fmt.Errorf("%w: %w", errLockData, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("%w: %w", errLockData, err)
+	}
+	return os.Link(tmpFile, fl.lockFile)
+}
+
+// Timeout returns how long Lock waits for the lock
+func (fl *FileLock) Timeout() time.Duration {
+	return fl.lockTimeout
+}
+
+// backoff returns the jittered exponential delay before the next acquisition attempt
+func (fl *FileLock) backoff(attempt int) time.Duration {
+	return retryDelay(fl.RetryInterval, attempt)
+}
+
+// retryDelay doubles base for each failed attempt up to maxRetryInterval, with jitter
+func retryDelay(base time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		base = DefaultRetryInterval
+	}
+
+	delay := base
+	for i := 0; i < attempt && delay < maxRetryInterval; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryInterval {
+		delay = maxRetryInterval
+	}
+
+	// Jitter in [delay/2, delay] spreads out contending waiters
+	half := delay / 2
+	//nolint:gosec // Jitter does not need a cryptographic source
+	return half + time.Duration(rand.Int64N(int64(half)+1))
+}
+
 // Unlock releases the file lock
 func (fl *FileLock) Unlock() error {
 	fl.mu.Lock()
@@ -120,7 +198,7 @@ func (fl *FileLock) Unlock() error {
 			// Our state is out of sync, reset it
 			fl.locked = false
 		}
-		return errors.New("cannot unlock: lock is not held")
+		return ErrLockNotHeld
 	}
 
 	// Check ownership first - if we don't own the lock, return ErrNotOwner regardless of internal state
@@ -130,7 +208,7 @@ func (fl *FileLock) Unlock() error {
 
 	// If we own the lock but our internal state says we're not locked, this is a state inconsistency
 	if !fl.locked {
-		return errors.New("cannot unlock: lock is not held")
+		return ErrLockNotHeld
 	}
 
 	// Remove the lock file
@@ -183,6 +261,46 @@ func (fl *FileLock) isStale() bool {
 	return !processExists(pid)
 }
 
+// removeIfStale removes the lock file if its holder is gone and reports whether it did.
+// A flock-backend holder keeps the file OS-locked and is never stale. The OS lock is held
+// across the removal so such a holder cannot take the file in between; Windows cannot
+// remove an open file, so there it is released first and an open holder makes the
+// removal fail instead.
+func (fl *FileLock) removeIfStale() bool {
+	file, err := os.Open(fl.lockFile)
+	if err != nil {
+		return os.IsNotExist(err)
+	}
+	osLocked := false
+	release := func() {
+		if osLocked {
+			_ = unlockFile(file) //nolint:errcheck // Closing the descriptor releases it anyway
+		}
+		_ = file.Close() //nolint:errcheck // Probe descriptor
+	}
+
+	switch err := tryLockFile(file); {
+	case err == nil:
+		osLocked = true
+	case errors.Is(err, errWouldBlock):
+		release()
+		return false
+	}
+	if !fl.isStale() {
+		release()
+		return false
+	}
+
+	if runtime.GOOS == "windows" {
+		release()
+		err = os.Remove(fl.lockFile)
+	} else {
+		err = os.Remove(fl.lockFile)
+		release()
+	}
+	return err == nil || os.IsNotExist(err)
+}
+
 // ownsLock checks if the current process owns the lock
 func (fl *FileLock) ownsLock() bool {
 	data, err := os.ReadFile(fl.lockFile)
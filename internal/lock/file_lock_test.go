@@ -3,9 +3,11 @@ package lock
 import (
 	"encoding/json"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"sync"
+	"syscall"
 	"testing"
 	"time"
 
@@ -624,3 +626,82 @@ func TestFileLock_DirectoryCreation(t *testing.T) {
 	//nolint:errcheck // Test cleanup can fail
 	_ = fileLock.Unlock()
 }
+
+func TestFileLock_Backoff(t *testing.T) {
+	fileLock := NewFileLock(filepath.Join(t.TempDir(), "backoff.lock"), testLockTimeout)
+	assert.Equal(t, DefaultRetryInterval, fileLock.RetryInterval)
+
+	fileLock.RetryInterval = 4 * time.Millisecond
+
+	tests := []struct {
+		name    string
+		attempt int
+		min     time.Duration
+		max     time.Duration
+	}{
+		{"first attempt", 0, 2 * time.Millisecond, 4 * time.Millisecond},
+		{"doubles", 2, 8 * time.Millisecond, 16 * time.Millisecond},
+		{"capped", 20, maxRetryInterval / 2, maxRetryInterval},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				delay := fileLock.backoff(tt.attempt)
+				assert.GreaterOrEqual(t, delay, tt.min)
+				assert.LessOrEqual(t, delay, tt.max)
+			}
+		})
+	}
+}
+
+func TestFileLock_LockRespectsTimeoutWithBackoff(t *testing.T) {
+	fileLock1, lockFile, cleanup := setupTestFileLock(t, testLockTimeout)
+	defer cleanup()
+	require.NoError(t, fileLock1.Lock())
+
+	fileLock2 := NewFileLock(lockFile, 120*time.Millisecond)
+	start := time.Now()
+	err := fileLock2.Lock()
+	elapsed := time.Since(start)
+
+	require.ErrorIs(t, err, ErrLockTimeout)
+	assert.Less(t, elapsed, 120*time.Millisecond+50*time.Millisecond, "backoff must not overshoot the timeout")
+}
+
+func TestFileLock_OpenErrors(t *testing.T) {
+	stubOpen := func(t *testing.T, err error) *int {
+		t.Helper()
+		calls := 0
+		original := openLockFile
+		openLockFile = func(string, int, os.FileMode) (*os.File, error) {
+			calls++
+			return nil, &fs.PathError{Op: "open", Path: "test.lock", Err: err}
+		}
+		t.Cleanup(func() { openLockFile = original })
+		return &calls
+	}
+
+	t.Run("not_writable_fails_at_once", func(t *testing.T) {
+		for _, errno := range []syscall.Errno{syscall.EACCES, syscall.EROFS} {
+			calls := stubOpen(t, errno)
+			fileLock, _, cleanup := setupTestFileLock(t, testLockTimeout)
+			defer cleanup()
+
+			err := fileLock.Lock()
+			require.ErrorIs(t, err, ErrLockNotWritable)
+			require.ErrorIs(t, err, errno)
+			assert.Equal(t, 1, *calls, "waiting cannot make the lock file writable")
+		}
+	})
+
+	t.Run("other_errors_back_off", func(t *testing.T) {
+		calls := stubOpen(t, syscall.EIO)
+		fileLock, _, cleanup := setupTestFileLock(t, shortTimeout)
+		defer cleanup()
+		fileLock.RetryInterval = 10 * time.Millisecond
+
+		require.ErrorIs(t, fileLock.Lock(), ErrLockTimeout)
+		assert.Less(t, *calls, 20, "failed attempts must sleep between retries")
+	})
+}
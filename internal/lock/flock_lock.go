@@ -0,0 +1,258 @@
+package lock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Errors specific to OS-level locks
+var (
+	ErrNoLockHolder = errors.New("no process holds the lock")
+	ErrLockInUse    = errors.New("an OS lock cannot be cleared while its holder runs")
+)
+
+// errWouldBlock is returned by tryLockFile when another open file holds the lock
+var errWouldBlock = errors.New("lock is held elsewhere")
+
+// FlockLock implements LockManager with an OS-level lock on an open file: flock(2) on
+// Unix and LockFileEx on Windows. The kernel releases the lock when its holder exits,
+// so a crash never leaves a stale lock behind. The holder's PID is written into the
+// file for GetLockInfo. A FileLock holding the same file, which takes no OS lock, is
+// recognized by its live PID in the file.
+type FlockLock struct {
+	lockFile    string
+	lockTimeout time.Duration
+	file        *os.File   // Open while the lock is held
+	mu          sync.Mutex // Protects file
+
+	// RetryInterval is the base delay between acquisition attempts; it doubles
+	// (with jitter) after each failed attempt
+	RetryInterval time.Duration
+}
+
+// NewFlockLock creates a lock manager using an OS-level lock on lockFile
+func NewFlockLock(lockFile string, timeout time.Duration) *FlockLock {
+	return &FlockLock{
+		lockFile:      lockFile,
+		lockTimeout:   timeout,
+		RetryInterval: DefaultRetryInterval,
+	}
+}
+
+// Timeout returns how long Lock waits for the lock
+func (fl *FlockLock) Timeout() time.Duration {
+	return fl.lockTimeout
+}
+
+// Lock acquires the lock, waiting up to the timeout. It is re-entrant per instance.
+func (fl *FlockLock) Lock() error {
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+	if fl.file != nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fl.lockFile), 0o750); err != nil {
+		return fmt.Errorf("failed to create lock directory: %w", err)
+	}
+
+	deadline := time.Now().Add(fl.lockTimeout)
+	var file *os.File
+	for attempt := 0; ; attempt++ {
+		var err error
+		file, err = fl.tryAcquire()
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, errWouldBlock) {
+			return err
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return fmt.Errorf("%w: %v", ErrLockTimeout, fl.lockTimeout)
+		}
+		time.Sleep(min(retryDelay(fl.RetryInterval, attempt), remaining))
+	}
+
+	// Record the holder in the same format as FileLock; the lock itself does not depend on it
+	lockData := fmt.Sprintf("%d\n%d\n", os.Getpid(), time.Now().Unix())
+	if err := file.Truncate(0); err == nil {
+		_, _ = file.WriteAt([]byte(lockData), 0) //nolint:errcheck // Holder info is informational
+	}
+
+	fl.file = file
+	return nil
+}
+
+// tryAcquire opens the lock file and takes the OS lock without blocking. It returns
+// errWouldBlock while the lock is held, by another FlockLock or by a FileLock.
+func (fl *FlockLock) tryAcquire() (*os.File, error) {
+	file, err := openLockFile(fl.lockFile, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		if notWritable(err) {
+			return nil, fmt.Errorf("%w: %w", ErrLockNotWritable, err)
+		}
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	if err := tryLockFile(file); err != nil {
+		_ = file.Close() //nolint:errcheck // The lock error is what matters
+		if errors.Is(err, errWouldBlock) {
+			return nil, errWouldBlock
+		}
+		return nil, fmt.Errorf("failed to lock %s: %w", fl.lockFile, err)
+	}
+
+	// A FileLock may have removed the file after we opened it, or hold it without an OS lock
+	if !isCurrentFile(file, fl.lockFile) || fileLockHolderAlive(file) {
+		_ = unlockFile(file) //nolint:errcheck // Closing the descriptor releases it anyway
+		_ = file.Close()     //nolint:errcheck // Retried after backing off
+		return nil, errWouldBlock
+	}
+	return file, nil
+}
+
+// isCurrentFile reports whether file is still the one at path
+func isCurrentFile(file *os.File, path string) bool {
+	opened, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	current, err := os.Stat(path)
+	return err == nil && os.SameFile(opened, current)
+}
+
+// fileLockHolderAlive reports whether file holds the PID, timestamp and instance ID a
+// FileLock writes, for a process that is still running
+func fileLockHolderAlive(file *os.File) bool {
+	data := make([]byte, 128)
+	n, _ := file.ReadAt(data, 0) //nolint:errcheck // A short or empty file has no holder
+	lines := strings.Split(strings.TrimSpace(string(data[:n])), "\n")
+	if len(lines) < 3 {
+		return false
+	}
+	pid, err := strconv.Atoi(lines[0])
+	return err == nil && processExists(pid)
+}
+
+// Unlock releases the lock
+func (fl *FlockLock) Unlock() error {
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+	if fl.file == nil {
+		return ErrLockNotHeld
+	}
+
+	// Clear the holder info while still holding the lock so no one reads it as ours afterwards
+	_ = fl.file.Truncate(0) //nolint:errcheck // Holder info is informational
+	err := unlockFile(fl.file)
+	closeErr := fl.file.Close()
+	fl.file = nil
+	if err != nil {
+		return fmt.Errorf("failed to unlock %s: %w", fl.lockFile, err)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("failed to close lock file: %w", closeErr)
+	}
+	return nil
+}
+
+// IsLocked checks if this instance holds the lock
+func (fl *FlockLock) IsLocked() bool {
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+	return fl.file != nil
+}
+
+// GetLockInfo returns information about the current lock holder. Holder info left behind
+// by a process that died without unlocking is reported as stale, although the kernel
+// already released its lock.
+func (fl *FlockLock) GetLockInfo() (*Info, error) {
+	data, err := os.ReadFile(fl.lockFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lock file: %w", err)
+	}
+
+	held, err := fl.heldElsewhere()
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) < 2 {
+		if !held {
+			return nil, fmt.Errorf("%w: %s", ErrNoLockHolder, fl.lockFile)
+		}
+		return nil, ErrInvalidLockFormat
+	}
+
+	pid, err := strconv.Atoi(lines[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid PID in lock file: %w", err)
+	}
+	timestamp, err := strconv.ParseInt(lines[1], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp in lock file: %w", err)
+	}
+
+	// Three lines are a FileLock holder, which takes no OS lock
+	fileLockHolder := len(lines) >= 3 && processExists(pid)
+	return &Info{
+		PID:       pid,
+		Timestamp: time.Unix(timestamp, 0),
+		IsStale:   !held && !fileLockHolder,
+	}, nil
+}
+
+// heldElsewhere reports whether another open file holds the lock, by probing it on a
+// separate descriptor. A lock held by this instance counts as held.
+func (fl *FlockLock) heldElsewhere() (bool, error) {
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+	if fl.file != nil {
+		return true, nil
+	}
+
+	file, err := os.OpenFile(fl.lockFile, os.O_RDWR, 0)
+	if err != nil {
+		return false, fmt.Errorf("failed to open lock file: %w", err)
+	}
+	defer func() { _ = file.Close() }() //nolint:errcheck // Probe descriptor
+
+	switch err := tryLockFile(file); {
+	case err == nil:
+		_ = unlockFile(file) //nolint:errcheck // Closing the descriptor releases it anyway
+		return false, nil
+	case errors.Is(err, errWouldBlock):
+		return true, nil
+	default:
+		return false, fmt.Errorf("failed to probe lock %s: %w", fl.lockFile, err)
+	}
+}
+
+// ForceClearLock removes stale holder info. The OS lock of a running holder cannot be
+// broken, so ErrLockInUse is returned while one exists.
+func (fl *FlockLock) ForceClearLock() error {
+	held, err := fl.heldElsewhere()
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if held {
+		return ErrLockInUse
+	}
+
+	if err := os.Truncate(fl.lockFile, 0); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to force clear lock: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+package lock
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlockLock_MutualExclusion(t *testing.T) {
+	lockFile := filepath.Join(t.TempDir(), "portguard.lock")
+	first := NewFlockLock(lockFile, shortTimeout)
+	second := NewFlockLock(lockFile, shortTimeout)
+
+	require.NoError(t, first.Lock())
+	assert.True(t, first.IsLocked())
+	require.NoError(t, first.Lock(), "locking is re-entrant per instance")
+
+	err := second.Lock()
+	require.ErrorIs(t, err, ErrLockTimeout)
+	assert.False(t, second.IsLocked())
+
+	require.NoError(t, first.Unlock())
+	assert.False(t, first.IsLocked())
+	require.ErrorIs(t, first.Unlock(), ErrLockNotHeld)
+
+	require.NoError(t, second.Lock())
+	require.NoError(t, second.Unlock())
+}
+
+func TestFlockLock_ConcurrentInstances(t *testing.T) {
+	lockFile := filepath.Join(t.TempDir(), "portguard.lock")
+
+	var holders, maxHolders atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fl := NewFlockLock(lockFile, testLockTimeout)
+			if !assert.NoError(t, fl.Lock()) {
+				return
+			}
+			current := holders.Add(1)
+			for {
+				seen := maxHolders.Load()
+				if current <= seen || maxHolders.CompareAndSwap(seen, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			holders.Add(-1)
+			assert.NoError(t, fl.Unlock())
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), maxHolders.Load(), "only one instance may hold the lock at a time")
+}
+
+func TestFlockLock_ReleasedWhenHolderDies(t *testing.T) {
+	lockFile := filepath.Join(t.TempDir(), "portguard.lock")
+	holder := NewFlockLock(lockFile, shortTimeout)
+	require.NoError(t, holder.Lock())
+
+	// Closing the descriptor without unlocking is what the kernel does when a process dies
+	require.NoError(t, holder.file.Close())
+
+	info, err := NewFlockLock(lockFile, shortTimeout).GetLockInfo()
+	require.NoError(t, err)
+	assert.Equal(t, os.Getpid(), info.PID)
+	assert.True(t, info.IsStale, "holder info without a lock is left over from a dead holder")
+
+	next := NewFlockLock(lockFile, shortTimeout)
+	require.NoError(t, next.Lock(), "no stale lock detection is needed")
+	require.NoError(t, next.Unlock())
+}
+
+func TestFlockLock_GetLockInfo(t *testing.T) {
+	t.Run("held", func(t *testing.T) {
+		lockFile := filepath.Join(t.TempDir(), "portguard.lock")
+		holder := NewFlockLock(lockFile, shortTimeout)
+		require.NoError(t, holder.Lock())
+		defer func() { _ = holder.Unlock() }() //nolint:errcheck // Test cleanup
+
+		info, err := NewFlockLock(lockFile, shortTimeout).GetLockInfo()
+		require.NoError(t, err)
+		assert.Equal(t, os.Getpid(), info.PID)
+		assert.False(t, info.IsStale)
+		assert.WithinDuration(t, time.Now(), info.Timestamp, 5*time.Second)
+	})
+
+	t.Run("released", func(t *testing.T) {
+		lockFile := filepath.Join(t.TempDir(), "portguard.lock")
+		holder := NewFlockLock(lockFile, shortTimeout)
+		require.NoError(t, holder.Lock())
+		require.NoError(t, holder.Unlock())
+
+		_, err := holder.GetLockInfo()
+		require.ErrorIs(t, err, ErrNoLockHolder)
+	})
+
+	t.Run("missing_file", func(t *testing.T) {
+		_, err := NewFlockLock(filepath.Join(t.TempDir(), "missing.flock"), shortTimeout).GetLockInfo()
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestFlockLock_ForceClearLock(t *testing.T) {
+	lockFile := filepath.Join(t.TempDir(), "portguard.lock")
+	holder := NewFlockLock(lockFile, shortTimeout)
+	require.NoError(t, holder.Lock())
+
+	other := NewFlockLock(lockFile, shortTimeout)
+	require.ErrorIs(t, other.ForceClearLock(), ErrLockInUse, "a live holder's lock cannot be broken")
+
+	require.NoError(t, holder.file.Close())
+	require.NoError(t, other.ForceClearLock())
+	_, err := other.GetLockInfo()
+	require.ErrorIs(t, err, ErrNoLockHolder)
+}
+
+func TestParseBackend(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Backend
+		wantErr  bool
+	}{
+		{input: "", expected: BackendFile},
+		{input: "file", expected: BackendFile},
+		{input: " FLOCK ", expected: BackendFlock},
+		{input: "fcntl", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			backend, err := ParseBackend(tt.input)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrUnknownBackend)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, backend)
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	dir := t.TempDir()
+
+	assert.IsType(t, &FileLock{}, New(BackendFile, Path(dir), shortTimeout))
+	assert.IsType(t, &FlockLock{}, New(BackendFlock, Path(dir), shortTimeout))
+}
+
+func TestMixedBackends_MutualExclusion(t *testing.T) {
+	t.Run("file_lock_holder_blocks_flock", func(t *testing.T) {
+		lockFile := Path(t.TempDir())
+		holder := NewFileLock(lockFile, shortTimeout)
+		require.NoError(t, holder.Lock())
+
+		waiter := NewFlockLock(lockFile, shortTimeout)
+		require.ErrorIs(t, waiter.Lock(), ErrLockTimeout)
+
+		info, err := waiter.GetLockInfo()
+		require.NoError(t, err)
+		assert.Equal(t, os.Getpid(), info.PID)
+		assert.False(t, info.IsStale, "a live FileLock holder is not stale")
+
+		require.NoError(t, holder.Unlock())
+		require.NoError(t, waiter.Lock())
+		require.NoError(t, waiter.Unlock())
+	})
+
+	t.Run("flock_holder_blocks_file_lock", func(t *testing.T) {
+		lockFile := Path(t.TempDir())
+		holder := NewFlockLock(lockFile, shortTimeout)
+		require.NoError(t, holder.Lock())
+
+		// Even holder info that looks stale must not let a FileLock remove an OS-locked file
+		require.NoError(t, os.WriteFile(lockFile, []byte("999999999\n0\n"), 0o600))
+		waiter := NewFileLock(lockFile, shortTimeout)
+		require.ErrorIs(t, waiter.Lock(), ErrLockTimeout)
+
+		require.NoError(t, holder.Unlock())
+		require.NoError(t, waiter.Lock())
+		require.NoError(t, waiter.Unlock())
+	})
+
+	t.Run("interleaved_backends", func(t *testing.T) {
+		lockFile := Path(t.TempDir())
+
+		// A FileLock must never expose its file before the holder info is in it, or a
+		// FlockLock takes the seemingly free lock at the same time. Writes are delayed to
+		// widen that window.
+		original := openLockFile
+		openLockFile = func(name string, flag int, perm os.FileMode) (*os.File, error) {
+			file, err := original(name, flag, perm)
+			if err == nil && flag&os.O_WRONLY != 0 {
+				time.Sleep(2 * time.Millisecond)
+			}
+			return file, err
+		}
+		t.Cleanup(func() { openLockFile = original })
+
+		var holders, maxHolders atomic.Int32
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				var lm interface {
+					Lock() error
+					Unlock() error
+				} = NewFileLock(lockFile, testLockTimeout)
+				if i%2 == 1 {
+					lm = NewFlockLock(lockFile, testLockTimeout)
+				}
+				for round := 0; round < 20; round++ {
+					if !assert.NoError(t, lm.Lock()) {
+						return
+					}
+					current := holders.Add(1)
+					for {
+						seen := maxHolders.Load()
+						if current <= seen || maxHolders.CompareAndSwap(seen, current) {
+							break
+						}
+					}
+					time.Sleep(time.Millisecond)
+					holders.Add(-1)
+					if !assert.NoError(t, lm.Unlock()) {
+						return
+					}
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), maxHolders.Load(), "only one holder of either backend at a time")
+		tmpFiles, err := filepath.Glob(lockFile + ".*.tmp")
+		require.NoError(t, err)
+		assert.Empty(t, tmpFiles, "temporary lock files are removed")
+	})
+}
@@ -0,0 +1,42 @@
+package lock
+
+import "sync"
+
+// MemoryLock implements LockManager for a single invocation whose state is not shared
+// with other processes, e.g. when persistence is disabled. Like FileLock it is re-entrant
+// per instance.
+type MemoryLock struct {
+	mu     sync.Mutex
+	locked bool
+}
+
+// NewMemoryLock creates an in-process lock manager
+func NewMemoryLock() *MemoryLock {
+	return &MemoryLock{}
+}
+
+// Lock acquires the lock
+func (ml *MemoryLock) Lock() error {
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
+	ml.locked = true
+	return nil
+}
+
+// Unlock releases the lock
+func (ml *MemoryLock) Unlock() error {
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
+	if !ml.locked {
+		return ErrLockNotHeld
+	}
+	ml.locked = false
+	return nil
+}
+
+// IsLocked checks if the lock is currently held
+func (ml *MemoryLock) IsLocked() bool {
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
+	return ml.locked
+}
@@ -0,0 +1,21 @@
+package lock
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryLock(t *testing.T) {
+	memoryLock := NewMemoryLock()
+	assert.False(t, memoryLock.IsLocked())
+
+	require.NoError(t, memoryLock.Lock())
+	assert.True(t, memoryLock.IsLocked())
+	require.NoError(t, memoryLock.Lock(), "re-entrant like FileLock")
+
+	require.NoError(t, memoryLock.Unlock())
+	assert.False(t, memoryLock.IsLocked())
+	require.ErrorIs(t, memoryLock.Unlock(), ErrLockNotHeld)
+}
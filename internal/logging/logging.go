@@ -0,0 +1,71 @@
+// Package logging provides leveled, structured logging for Portguard.
+// It wraps log/slog so the level and output format can be driven by configuration.
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Static error variables to satisfy err113 linter
+var (
+	ErrInvalidLevel  = errors.New("invalid log level")
+	ErrInvalidFormat = errors.New("invalid log format")
+)
+
+// Supported output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// ParseLevel converts a configured level name into a slog.Level. An empty name means info.
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("%w: %q", ErrInvalidLevel, level)
+	}
+}
+
+// ValidateFormat checks that the format is one of the supported output formats. An empty format means text.
+func ValidateFormat(format string) error {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", FormatText, FormatJSON:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidFormat, format)
+	}
+}
+
+// New creates a logger writing to w at the given level and format
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+	slogLevel, err := ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateFormat(format); err != nil {
+		return nil, err
+	}
+
+	options := &slog.HandlerOptions{Level: slogLevel}
+	if strings.EqualFold(strings.TrimSpace(format), FormatJSON) {
+		return slog.New(slog.NewJSONHandler(w, options)), nil
+	}
+	return slog.New(slog.NewTextHandler(w, options)), nil
+}
+
+// Discard returns a logger that drops every record
+func Discard() *slog.Logger {
+	return slog.New(slog.DiscardHandler)
+}
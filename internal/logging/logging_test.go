@@ -0,0 +1,73 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		input     string
+		expected  slog.Level
+		expectErr bool
+	}{
+		{input: "", expected: slog.LevelInfo},
+		{input: "debug", expected: slog.LevelDebug},
+		{input: "INFO", expected: slog.LevelInfo},
+		{input: "warn", expected: slog.LevelWarn},
+		{input: "warning", expected: slog.LevelWarn},
+		{input: " error ", expected: slog.LevelError},
+		{input: "verbose", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			level, err := ParseLevel(tt.input)
+			if tt.expectErr {
+				require.ErrorIs(t, err, ErrInvalidLevel)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, level)
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Run("json_format_filters_by_level", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, err := New(&buf, "warn", FormatJSON)
+		require.NoError(t, err)
+
+		logger.Info("hidden")
+		logger.Warn("status update failed", "process_id", "abc")
+
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		assert.Equal(t, "WARN", record["level"])
+		assert.Equal(t, "status update failed", record["msg"])
+		assert.Equal(t, "abc", record["process_id"])
+	})
+
+	t.Run("text_format_by_default", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, err := New(&buf, "debug", "")
+		require.NoError(t, err)
+
+		logger.Debug("health check passed")
+		assert.Contains(t, buf.String(), "msg=\"health check passed\"")
+	})
+
+	t.Run("invalid_settings", func(t *testing.T) {
+		_, err := New(&bytes.Buffer{}, "loud", FormatText)
+		require.ErrorIs(t, err, ErrInvalidLevel)
+
+		_, err = New(&bytes.Buffer{}, "info", "xml")
+		require.ErrorIs(t, err, ErrInvalidFormat)
+	})
+}
@@ -0,0 +1,194 @@
+// Package metrics exposes portguard state in the Prometheus text exposition format.
+// It implements the small subset of the format portguard needs, so no Prometheus client
+// library is required.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ContentType is the media type of the text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Metric types written in # TYPE lines
+const (
+	TypeCounter = "counter"
+	TypeGauge   = "gauge"
+	TypeSummary = "summary"
+)
+
+// Label is a metric label name and value
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample is one value of a metric family. Suffix is appended to the family name,
+// e.g. "_sum" or "_count" for summaries.
+type Sample struct {
+	Suffix string
+	Labels []Label
+	Value  float64
+}
+
+// Family is a named group of samples sharing help text and type
+type Family struct {
+	Name    string
+	Help    string
+	Type    string
+	Samples []Sample
+}
+
+// Collector produces metric families on every scrape
+type Collector interface {
+	Collect() []Family
+}
+
+// CollectorFunc adapts a function to the Collector interface
+type CollectorFunc func() []Family
+
+// Collect implements Collector
+func (f CollectorFunc) Collect() []Family {
+	return f()
+}
+
+// Registry gathers metric families from its collectors
+type Registry struct {
+	mutex      sync.Mutex
+	collectors []Collector
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a collector to the registry
+func (r *Registry) Register(collector Collector) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.collectors = append(r.collectors, collector)
+}
+
+// Gather collects every family, sorted by name
+func (r *Registry) Gather() []Family {
+	r.mutex.Lock()
+	collectors := append([]Collector(nil), r.collectors...)
+	r.mutex.Unlock()
+
+	var families []Family
+	for _, collector := range collectors {
+		families = append(families, collector.Collect()...)
+	}
+	sort.SliceStable(families, func(i, j int) bool { return families[i].Name < families[j].Name })
+	return families
+}
+
+// WriteText writes families in the Prometheus text exposition format
+func WriteText(w io.Writer, families []Family) error {
+	buf := bufio.NewWriter(w)
+	for _, family := range families {
+		fmt.Fprintf(buf, "# HELP %s %s\n", family.Name, escapeHelp(family.Help))
+		fmt.Fprintf(buf, "# TYPE %s %s\n", family.Name, family.Type)
+		for _, sample := range family.Samples {
+			buf.WriteString(family.Name + sample.Suffix)
+			writeLabels(buf, sample.Labels)
+			buf.WriteByte(' ')
+			buf.WriteString(formatValue(sample.Value))
+			buf.WriteByte('\n')
+		}
+	}
+	if err := buf.Flush(); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	return nil
+}
+
+// Handler serves the registry's metrics over HTTP
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		_ = WriteText(w, r.Gather()) //nolint:errcheck // The client went away, nothing to report to
+	})
+}
+
+func writeLabels(buf *bufio.Writer, labels []Label) {
+	if len(labels) == 0 {
+		return
+	}
+	buf.WriteByte('{')
+	for i, label := range labels {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(label.Name)
+		buf.WriteString(`="`)
+		buf.WriteString(escapeLabelValue(label.Value))
+		buf.WriteByte('"')
+	}
+	buf.WriteByte('}')
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
+
+func escapeLabelValue(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// Summary tracks the count and total of observed durations, exposed without quantiles
+type Summary struct {
+	mutex sync.Mutex
+	count uint64
+	sum   float64
+}
+
+// Observe records one duration
+func (s *Summary) Observe(d time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.count++
+	s.sum += d.Seconds()
+}
+
+// Family returns the summary as a family with _sum and _count samples
+func (s *Summary) Family(name, help string) Family {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return Family{
+		Name: name,
+		Help: help,
+		Type: TypeSummary,
+		Samples: []Sample{
+			{Suffix: "_sum", Value: s.sum},
+			{Suffix: "_count", Value: float64(s.count)},
+		},
+	}
+}
@@ -0,0 +1,128 @@
+package metrics
+
+import (
+	"bytes"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/paveg/portguard/internal/port"
+	"github.com/paveg/portguard/internal/process"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteText(t *testing.T) {
+	families := []Family{
+		{
+			Name: "portguard_processes",
+			Help: "Managed processes\nby status.",
+			Type: TypeGauge,
+			Samples: []Sample{
+				{Labels: []Label{{Name: "status", Value: "running"}}, Value: 2},
+				{Labels: []Label{{Name: "status", Value: `say "hi"\`}}, Value: 0.5},
+			},
+		},
+		{
+			Name:    "portguard_up",
+			Help:    "Always one.",
+			Type:    TypeGauge,
+			Samples: []Sample{{Value: math.Inf(1)}},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteText(&buf, families))
+
+	expected := `# HELP portguard_processes Managed processes\nby status.
+# TYPE portguard_processes gauge
+portguard_processes{status="running"} 2
+portguard_processes{status="say \"hi\"\\"} 0.5
+# HELP portguard_up Always one.
+# TYPE portguard_up gauge
+portguard_up +Inf
+`
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(CollectorFunc(func() []Family {
+		return []Family{{Name: "b_metric", Type: TypeGauge, Samples: []Sample{{Value: 1}}}}
+	}))
+	registry.Register(CollectorFunc(func() []Family {
+		return []Family{{Name: "a_metric", Type: TypeCounter, Samples: []Sample{{Value: 2}}}}
+	}))
+
+	families := registry.Gather()
+	require.Len(t, families, 2)
+	assert.Equal(t, "a_metric", families[0].Name)
+	assert.Equal(t, "b_metric", families[1].Name)
+
+	recorder := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, ContentType, recorder.Header().Get("Content-Type"))
+	assert.Contains(t, recorder.Body.String(), "a_metric 2\n")
+}
+
+func TestSummary(t *testing.T) {
+	var summary Summary
+	summary.Observe(250 * time.Millisecond)
+	summary.Observe(750 * time.Millisecond)
+
+	family := summary.Family("scan_seconds", "Scan time.")
+	assert.Equal(t, TypeSummary, family.Type)
+	assert.Equal(t, []Sample{{Suffix: "_sum", Value: 1}, {Suffix: "_count", Value: 2}}, family.Samples)
+}
+
+type staticStats process.Stats
+
+func (s staticStats) Stats() process.Stats {
+	return process.Stats(s)
+}
+
+func TestProcessCollector(t *testing.T) {
+	collector := NewProcessCollector(staticStats{
+		ProcessesByStatus:   map[process.ProcessStatus]int{process.StatusRunning: 2, process.StatusFailed: 1},
+		RestartsByProcess:   map[string]int{"web-a1b2c3": 3, "api-d4e5f6": 0},
+		HealthCheckFailures: 7,
+	})
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteText(&buf, collector.Collect()))
+	output := buf.String()
+
+	assert.Contains(t, output, `portguard_processes{status="running"} 2`)
+	assert.Contains(t, output, `portguard_processes{status="failed"} 1`)
+	assert.Contains(t, output, `portguard_processes{status="stopped"} 0`, "known statuses are exported even when empty")
+	assert.Contains(t, output, "portguard_process_restarts_total{process_id=\"api-d4e5f6\"} 0\n"+
+		"portguard_process_restarts_total{process_id=\"web-a1b2c3\"} 3\n", "restart series are sorted by process ID")
+	assert.Contains(t, output, "portguard_health_check_failures_total 7\n")
+}
+
+type stubScanner struct{}
+
+func (stubScanner) IsPortInUse(int) bool                         { return true }
+func (stubScanner) GetPortInfo(int) (*port.PortInfo, error)      { return &port.PortInfo{}, nil }
+func (stubScanner) ScanRange(int, int) ([]port.PortInfo, error)  { return nil, nil }
+func (stubScanner) FindAvailablePort(startPort int) (int, error) { return startPort, nil }
+
+func TestInstrumentedScanner(t *testing.T) {
+	scanner := NewInstrumentedScanner(stubScanner{})
+
+	assert.True(t, scanner.IsPortInUse(3000))
+	_, err := scanner.GetPortInfo(3000)
+	require.NoError(t, err)
+	_, err = scanner.ScanRange(3000, 3010)
+	require.NoError(t, err)
+	available, err := scanner.FindAvailablePort(3000)
+	require.NoError(t, err)
+	assert.Equal(t, 3000, available)
+
+	families := scanner.Collect()
+	require.Len(t, families, 1)
+	assert.Equal(t, "portguard_port_scan_duration_seconds", families[0].Name)
+	assert.Equal(t, Sample{Suffix: "_count", Value: 4}, families[0].Samples[1])
+}
@@ -0,0 +1,120 @@
+package metrics
+
+import (
+	"sort"
+	"time"
+
+	"github.com/paveg/portguard/internal/port"
+	"github.com/paveg/portguard/internal/process"
+)
+
+// StatsSource provides process statistics, typically a *process.ProcessManager
+type StatsSource interface {
+	Stats() process.Stats
+}
+
+// knownStatuses are always exported so dashboards see zeros instead of missing series
+var knownStatuses = []process.ProcessStatus{
+	process.StatusPending,
+	process.StatusRunning,
+	process.StatusStopped,
+	process.StatusFailed,
+	process.StatusUnhealthy,
+}
+
+// NewProcessCollector exports process counts by status, restart counts and health check failures
+func NewProcessCollector(source StatsSource) Collector {
+	return CollectorFunc(func() []Family {
+		stats := source.Stats()
+
+		statuses := Family{
+			Name: "portguard_processes",
+			Help: "Number of managed processes by status.",
+			Type: TypeGauge,
+		}
+		seen := make(map[process.ProcessStatus]bool, len(knownStatuses))
+		for _, status := range knownStatuses {
+			seen[status] = true
+			statuses.Samples = append(statuses.Samples, statusSample(status, stats.ProcessesByStatus[status]))
+		}
+		for status, count := range stats.ProcessesByStatus {
+			if !seen[status] {
+				statuses.Samples = append(statuses.Samples, statusSample(status, count))
+			}
+		}
+
+		restarts := Family{
+			Name: "portguard_process_restarts_total",
+			Help: "Restarts performed by restart policies, by process.",
+			Type: TypeCounter,
+		}
+		ids := make([]string, 0, len(stats.RestartsByProcess))
+		for id := range stats.RestartsByProcess {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			restarts.Samples = append(restarts.Samples, Sample{
+				Labels: []Label{{Name: "process_id", Value: id}},
+				Value:  float64(stats.RestartsByProcess[id]),
+			})
+		}
+
+		failures := Family{
+			Name:    "portguard_health_check_failures_total",
+			Help:    "Failed health checks.",
+			Type:    TypeCounter,
+			Samples: []Sample{{Value: float64(stats.HealthCheckFailures)}},
+		}
+
+		return []Family{statuses, restarts, failures}
+	})
+}
+
+func statusSample(status process.ProcessStatus, count int) Sample {
+	return Sample{Labels: []Label{{Name: "status", Value: string(status)}}, Value: float64(count)}
+}
+
+// InstrumentedScanner wraps a port scanner and records how long each scan takes
+type InstrumentedScanner struct {
+	process.PortScanner
+	durations Summary
+}
+
+// NewInstrumentedScanner wraps scanner with duration tracking
+func NewInstrumentedScanner(scanner process.PortScanner) *InstrumentedScanner {
+	return &InstrumentedScanner{PortScanner: scanner}
+}
+
+// IsPortInUse implements process.PortScanner
+func (s *InstrumentedScanner) IsPortInUse(portNum int) bool {
+	defer s.observe(time.Now())
+	return s.PortScanner.IsPortInUse(portNum)
+}
+
+// GetPortInfo implements process.PortScanner
+func (s *InstrumentedScanner) GetPortInfo(portNum int) (*port.PortInfo, error) {
+	defer s.observe(time.Now())
+	return s.PortScanner.GetPortInfo(portNum)
+}
+
+// ScanRange implements process.PortScanner
+func (s *InstrumentedScanner) ScanRange(startPort, endPort int) ([]port.PortInfo, error) {
+	defer s.observe(time.Now())
+	return s.PortScanner.ScanRange(startPort, endPort)
+}
+
+// FindAvailablePort implements process.PortScanner
+func (s *InstrumentedScanner) FindAvailablePort(startPort int) (int, error) {
+	defer s.observe(time.Now())
+	return s.PortScanner.FindAvailablePort(startPort)
+}
+
+func (s *InstrumentedScanner) observe(start time.Time) {
+	s.durations.Observe(time.Since(start))
+}
+
+// Collect implements Collector
+func (s *InstrumentedScanner) Collect() []Family {
+	return []Family{s.durations.Family("portguard_port_scan_duration_seconds", "Time spent scanning ports.")}
+}
@@ -0,0 +1,191 @@
+package port
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Wildcard bind addresses
+const (
+	WildcardIPv4 = "0.0.0.0"
+	WildcardIPv6 = "::"
+)
+
+// BindingsConflict reports whether two listeners on the same port would collide.
+// A wildcard binding shadows every specific address of its family; "::" also covers
+// IPv4 because dual-stack sockets are the default. An empty or unparsable address is
+// treated as unknown and assumed to conflict.
+func BindingsConflict(a, b string) bool {
+	ipA := net.ParseIP(normalizeBindAddress(a))
+	ipB := net.ParseIP(normalizeBindAddress(b))
+	if ipA == nil || ipB == nil {
+		return true
+	}
+	if ipA.Equal(ipB) {
+		return true
+	}
+	return wildcardCovers(ipA, ipB) || wildcardCovers(ipB, ipA)
+}
+
+// ConflictsWith reports whether binding address on this port would collide with its current listener
+func (p *PortInfo) ConflictsWith(address string) bool {
+	return BindingsConflict(p.BindAddress, address)
+}
+
+// wildcardCovers reports whether wildcard is an unspecified address covering other
+func wildcardCovers(wildcard, other net.IP) bool {
+	if !wildcard.IsUnspecified() {
+		return false
+	}
+	if wildcard.To4() == nil {
+		return true // "::" accepts IPv4 as well on dual-stack systems
+	}
+	return other.To4() != nil
+}
+
+// normalizeBindAddress strips brackets and zones and maps "*" to the IPv6 wildcard
+func normalizeBindAddress(address string) string {
+	address = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(address), "["), "]")
+	if zone := strings.IndexByte(address, '%'); zone >= 0 {
+		address = address[:zone]
+	}
+	if address == "*" {
+		return WildcardIPv6
+	}
+	return address
+}
+
+// widestBindAddress picks the address that shadows the most others, preferring wildcards
+func widestBindAddress(addresses []string) string {
+	best := ""
+	for _, address := range addresses {
+		switch {
+		case address == WildcardIPv6:
+			return address
+		case address == WildcardIPv4, best == "":
+			best = address
+		}
+	}
+	return best
+}
+
+// getBindAddress finds the local address a listener on the port is bound to, or "" when unknown
+func (s *Scanner) getBindAddress(port int) string {
+	switch runtime.GOOS {
+	case OSLinux:
+		if address := procNetBindAddress(port); address != "" {
+			return address
+		}
+		return s.lsofBindAddress(port)
+	case OSWindows:
+		ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+		defer cancel()
+		output, err := exec.CommandContext(ctx, "netstat", "-ano").Output()
+		if err != nil {
+			return ""
+		}
+		return parseNetstatBindAddressWindows(string(output), port)
+	default:
+		return s.lsofBindAddress(port)
+	}
+}
+
+// lsofBindAddress asks lsof for the listening address of the port
+func (s *Scanner) lsofBindAddress(port int) string {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "lsof", "-nP", fmt.Sprintf("-iTCP:%d", port), "-sTCP:LISTEN", "-Ftn").Output()
+	if err != nil {
+		return ""
+	}
+	return parseLsofBindAddress(string(output))
+}
+
+// parseLsofBindAddress parses lsof -Ftn output, where "t" lines carry the
+// address family and "n" lines the local address, e.g. "n*:3000" or "n[::1]:3000"
+func parseLsofBindAddress(output string) string {
+	var addresses []string
+	family := ""
+	for _, line := range strings.Split(output, "\n") {
+		if len(line) < 2 {
+			continue
+		}
+		switch line[0] {
+		case 't':
+			family = line[1:]
+		case 'n':
+			host, _, err := net.SplitHostPort(strings.SplitN(line[1:], "->", 2)[0])
+			if err != nil {
+				continue
+			}
+			if host == "*" {
+				host = WildcardIPv6
+				if family == "IPv4" {
+					host = WildcardIPv4
+				}
+			}
+			addresses = append(addresses, normalizeBindAddress(host))
+		}
+	}
+	return widestBindAddress(addresses)
+}
+
+// parseNetstatBindAddressWindows finds the local address of a listener in Windows netstat -ano output
+func parseNetstatBindAddressWindows(output string, targetPort int) string {
+	var addresses []string
+	for _, line := range strings.Split(output, "\n") {
+		// TCP    0.0.0.0:3000    0.0.0.0:0    LISTENING    12345
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[0] != "TCP" || fields[3] != "LISTENING" {
+			continue
+		}
+		host, portStr, err := net.SplitHostPort(fields[1])
+		if err != nil || portStr != strconv.Itoa(targetPort) {
+			continue
+		}
+		addresses = append(addresses, normalizeBindAddress(host))
+	}
+	return widestBindAddress(addresses)
+}
+
+// procNetBindAddress reads the listening TCP sockets from /proc, falling back to bound UDP sockets
+func procNetBindAddress(port int) string {
+	// Only consult UDP when no TCP listener was found
+	for _, tables := range [][]procSocketTable{procTCPTables, procUDPTables} {
+		sockets, err := readProcSockets(tables...)
+		if err != nil {
+			continue
+		}
+		if addresses := socketAddresses(sockets, port); len(addresses) > 0 {
+			return widestBindAddress(addresses)
+		}
+	}
+	return ""
+}
+
+// tcpListenState is the socket state of a listening socket in /proc/net/tcp
+const tcpListenState = "0A"
+
+// decodeProcNetAddress decodes a /proc/net address, which the kernel prints
+// as 32-bit words in host (little-endian) byte order
+func decodeProcNetAddress(addrHex string) (string, bool) {
+	raw, err := hex.DecodeString(addrHex)
+	if err != nil || (len(raw) != net.IPv4len && len(raw) != net.IPv6len) {
+		return "", false
+	}
+
+	ip := make(net.IP, len(raw))
+	for word := 0; word < len(raw); word += 4 {
+		for i := 0; i < 4; i++ {
+			ip[word+i] = raw[word+3-i]
+		}
+	}
+	return ip.String(), true
+}
@@ -0,0 +1,119 @@
+package port
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBindingsConflict(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		wanted   string
+		conflict bool
+	}{
+		{name: "ipv4 wildcard vs loopback", existing: "0.0.0.0", wanted: "127.0.0.1", conflict: true},
+		{name: "loopback vs ipv4 wildcard", existing: "127.0.0.1", wanted: "0.0.0.0", conflict: true},
+		{name: "ipv4 wildcard vs interface", existing: "0.0.0.0", wanted: "192.168.1.10", conflict: true},
+		{name: "ipv4 wildcard vs ipv4 wildcard", existing: "0.0.0.0", wanted: "0.0.0.0", conflict: true},
+		{name: "ipv4 wildcard vs ipv6 loopback", existing: "0.0.0.0", wanted: "::1", conflict: false},
+		{name: "ipv6 wildcard vs ipv4 loopback", existing: "::", wanted: "127.0.0.1", conflict: true},
+		{name: "ipv6 wildcard vs ipv6 loopback", existing: "::", wanted: "::1", conflict: true},
+		{name: "ipv6 wildcard vs ipv4 wildcard", existing: "::", wanted: "0.0.0.0", conflict: true},
+		{name: "bracketed ipv6 wildcard", existing: "[::]", wanted: "127.0.0.1", conflict: true},
+		{name: "lsof star", existing: "*", wanted: "10.0.0.5", conflict: true},
+		{name: "same loopback", existing: "127.0.0.1", wanted: "127.0.0.1", conflict: true},
+		{name: "different loopback addresses", existing: "127.0.0.1", wanted: "127.0.0.2", conflict: false},
+		{name: "loopback vs interface", existing: "127.0.0.1", wanted: "192.168.1.10", conflict: false},
+		{name: "ipv4 vs ipv6 loopback", existing: "127.0.0.1", wanted: "::1", conflict: false},
+		{name: "mapped ipv4 vs ipv4", existing: "::ffff:127.0.0.1", wanted: "127.0.0.1", conflict: true},
+		{name: "unknown existing", existing: "", wanted: "127.0.0.1", conflict: true},
+		{name: "unparsable wanted", existing: "127.0.0.1", wanted: "localhost", conflict: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.conflict, BindingsConflict(tt.existing, tt.wanted))
+			assert.Equal(t, tt.conflict, BindingsConflict(tt.wanted, tt.existing), "conflicts are symmetric")
+
+			info := &PortInfo{Port: 3000, BindAddress: tt.existing}
+			assert.Equal(t, tt.conflict, info.ConflictsWith(tt.wanted))
+		})
+	}
+}
+
+func TestDecodeProcNetAddress(t *testing.T) {
+	tests := []struct {
+		hex  string
+		want string
+		ok   bool
+	}{
+		{hex: "0100007F", want: "127.0.0.1", ok: true},
+		{hex: "00000000", want: "0.0.0.0", ok: true},
+		{hex: "0A01A8C0", want: "192.168.1.10", ok: true},
+		{hex: "00000000000000000000000000000000", want: "::", ok: true},
+		{hex: "00000000000000000000000001000000", want: "::1", ok: true},
+		{hex: "0000000000000000FFFF00000100007F", want: "127.0.0.1", ok: true},
+		{hex: "ZZ", ok: false},
+		{hex: "0100", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.hex, func(t *testing.T) {
+			got, ok := decodeProcNetAddress(tt.hex)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSocketAddresses(t *testing.T) {
+	table := `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 0100007F:0BB8 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 101
+   1: 00000000:0BB8 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 102
+   2: 0100007F:0BB9 0100007F:D431 01 00000000:00000000 00:00000000 00000000  1000        0 103
+`
+
+	listeners := parseProcNetSockets(table, "tcp", true)
+	assert.Equal(t, []string{"127.0.0.1", "0.0.0.0"}, socketAddresses(listeners, 3000))
+	assert.Empty(t, socketAddresses(listeners, 3001), "established sockets are not listeners")
+	assert.Equal(t, []string{"127.0.0.1"}, socketAddresses(parseProcNetSockets(table, "tcp", false), 3001))
+	assert.Equal(t, "0.0.0.0", widestBindAddress(socketAddresses(listeners, 3000)))
+}
+
+func TestParseLsofBindAddress(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{name: "ipv4 loopback", output: "p123\nf10\ntIPv4\nn127.0.0.1:3000\n", want: "127.0.0.1"},
+		{name: "ipv4 wildcard", output: "p123\nf10\ntIPv4\nn*:3000\n", want: "0.0.0.0"},
+		{name: "ipv6 wildcard wins", output: "p123\nf10\ntIPv4\nn127.0.0.1:3000\nf11\ntIPv6\nn*:3000\n", want: "::"},
+		{name: "ipv6 loopback", output: "p123\nf10\ntIPv6\nn[::1]:3000\n", want: "::1"},
+		{name: "empty", output: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseLsofBindAddress(tt.output))
+		})
+	}
+}
+
+func TestParseNetstatBindAddressWindows(t *testing.T) {
+	output := `
+Active Connections
+
+  Proto  Local Address          Foreign Address        State           PID
+  TCP    127.0.0.1:3000         0.0.0.0:0              LISTENING       1234
+  TCP    0.0.0.0:8080           0.0.0.0:0              LISTENING       5678
+  TCP    [::]:8080              [::]:0                 LISTENING       5678
+  TCP    127.0.0.1:3001         127.0.0.1:50000        ESTABLISHED     1234
+`
+
+	assert.Equal(t, "127.0.0.1", parseNetstatBindAddressWindows(output, 3000))
+	assert.Equal(t, "::", parseNetstatBindAddressWindows(output, 8080))
+	assert.Empty(t, parseNetstatBindAddressWindows(output, 3001))
+}
@@ -0,0 +1,268 @@
+package port
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DiscoveryBackend selects how the scanner finds the process behind a port
+type DiscoveryBackend string
+
+// Discovery backends
+const (
+	DiscoveryShell  DiscoveryBackend = "shell"  // lsof, netstat, ps and tasklist
+	DiscoveryNative DiscoveryBackend = "native" // Kernel socket tables read in-process, Linux only
+)
+
+// Errors returned by discovery backend selection
+var (
+	ErrUnknownDiscoveryBackend    = errors.New("unknown discovery backend")
+	ErrNativeDiscoveryUnavailable = errors.New("native discovery is not available on this platform")
+)
+
+// ParseDiscoveryBackend parses a discovery backend name; empty selects the shell backend
+func ParseDiscoveryBackend(name string) (DiscoveryBackend, error) {
+	switch backend := DiscoveryBackend(strings.ToLower(strings.TrimSpace(name))); backend {
+	case "", DiscoveryShell:
+		return DiscoveryShell, nil
+	case DiscoveryNative:
+		return DiscoveryNative, nil
+	default:
+		return "", fmt.Errorf("%w: %q (expected shell or native)", ErrUnknownDiscoveryBackend, name)
+	}
+}
+
+// SetDiscoveryBackend selects how process information is looked up. The native backend
+// falls back to the shell tools wherever it cannot answer, e.g. on macOS and Windows.
+func (s *Scanner) SetDiscoveryBackend(backend DiscoveryBackend) {
+	s.backend = backend
+}
+
+// NativeDiscoveryAvailable reports whether the native backend can answer on this platform.
+// It reads /proc, so everywhere but Linux it always falls back to the shell tools.
+func NativeDiscoveryAvailable() bool {
+	return runtime.GOOS == OSLinux
+}
+
+// procRoot is the procfs mount point, replaced in tests
+var procRoot = "/proc"
+
+// procSocket is a TCP listener or bound UDP socket from the kernel socket tables
+type procSocket struct {
+	port     int
+	address  string
+	inode    uint64
+	protocol string
+}
+
+// procSocketTable is a kernel socket table in /proc/net
+type procSocketTable struct {
+	name      string
+	protocol  string
+	listening bool // Only listening sockets count, which applies to TCP
+}
+
+// The kernel socket tables for each protocol
+var (
+	procTCPTables = []procSocketTable{{"tcp", "tcp", true}, {"tcp6", "tcp", true}}
+	procUDPTables = []procSocketTable{{"udp", "udp", false}, {"udp6", "udp", false}}
+)
+
+// readProcSockets lists the sockets in the given /proc/net tables, TCP ones only while listening.
+// It fails only when none of the tables can be read.
+func readProcSockets(tables ...procSocketTable) ([]procSocket, error) {
+	if !NativeDiscoveryAvailable() {
+		return nil, fmt.Errorf("%w: %s", ErrNativeDiscoveryUnavailable, runtime.GOOS)
+	}
+
+	var sockets []procSocket
+	readAny := false
+	var readErr error
+	for _, table := range tables {
+		data, err := os.ReadFile(filepath.Join(procRoot, "net", table.name))
+		if err != nil {
+			readErr = err
+			continue
+		}
+		readAny = true
+		sockets = append(sockets, parseProcNetSockets(string(data), table.protocol, table.listening)...)
+	}
+	if !readAny {
+		return nil, fmt.Errorf("failed to read socket tables: %w", readErr)
+	}
+	return sockets, nil
+}
+
+// parseProcNetSockets parses a /proc/net/{tcp,udp}{,6} table. Addresses look like
+// "0100007F:0BB8", with the port in hex after the colon, and the inode is the tenth column.
+func parseProcNetSockets(table, protocol string, listeningOnly bool) []procSocket {
+	var sockets []procSocket
+	lines := strings.Split(table, "\n")
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) < 10 {
+			continue
+		}
+		if listeningOnly && fields[3] != tcpListenState {
+			continue
+		}
+		addrHex, portHex, found := strings.Cut(fields[1], ":")
+		if !found {
+			continue
+		}
+		localPort, err := strconv.ParseInt(portHex, 16, 32)
+		if err != nil || localPort == 0 {
+			continue
+		}
+		address, ok := decodeProcNetAddress(addrHex)
+		if !ok {
+			continue
+		}
+		inode, err := strconv.ParseUint(fields[9], 10, 64)
+		if err != nil {
+			continue
+		}
+		sockets = append(sockets, procSocket{port: int(localPort), address: address, inode: inode, protocol: protocol})
+	}
+	return sockets
+}
+
+// socketAddresses returns the local addresses of the sockets on the port
+func socketAddresses(sockets []procSocket, port int) []string {
+	var addresses []string
+	for _, socket := range sockets {
+		if socket.port == port {
+			addresses = append(addresses, socket.address)
+		}
+	}
+	return addresses
+}
+
+// socketOwners maps socket inodes to the PID holding them by walking /proc/<pid>/fd.
+// Sockets of processes we may not inspect, e.g. other users' without root, stay unmapped.
+func socketOwners(inodes map[uint64]bool) map[uint64]int {
+	owners := make(map[uint64]int, len(inodes))
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return owners
+	}
+
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+		fdDir := filepath.Join(procRoot, entry.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			link, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err != nil {
+				continue
+			}
+			inode, ok := parseSocketLink(link)
+			if !ok || !inodes[inode] {
+				continue
+			}
+			if _, seen := owners[inode]; !seen {
+				owners[inode] = pid
+			}
+			if len(owners) == len(inodes) {
+				return owners
+			}
+		}
+	}
+	return owners
+}
+
+// parseSocketLink extracts the inode from a file descriptor link such as "socket:[12345]"
+func parseSocketLink(link string) (uint64, bool) {
+	value, found := strings.CutPrefix(link, "socket:[")
+	if !found || !strings.HasSuffix(value, "]") {
+		return 0, false
+	}
+	inode, err := strconv.ParseUint(strings.TrimSuffix(value, "]"), 10, 64)
+	return inode, err == nil
+}
+
+// nativePortInfo builds port information for each requested port that has a socket,
+// resolving owners with a single walk of /proc
+func nativePortInfo(ports map[int]bool) ([]PortInfo, error) {
+	sockets, err := readProcSockets(slices.Concat(procTCPTables, procUDPTables)...)
+	if err != nil {
+		return nil, err
+	}
+
+	byPort := make(map[int][]procSocket)
+	inodes := make(map[uint64]bool)
+	for _, socket := range sockets {
+		if !ports[socket.port] {
+			continue
+		}
+		byPort[socket.port] = append(byPort[socket.port], socket)
+		if socket.inode != 0 {
+			inodes[socket.inode] = true
+		}
+	}
+	owners := socketOwners(inodes)
+
+	result := make([]PortInfo, 0, len(byPort))
+	for portNum, portSockets := range byPort {
+		result = append(result, buildNativePortInfo(portNum, portSockets, owners))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Port < result[j].Port })
+	return result, nil
+}
+
+// buildNativePortInfo describes a port the way the shell backend does: TCP listeners take
+// precedence over UDP sockets for the owner and bind address, and the protocol is always tcp
+func buildNativePortInfo(portNum int, sockets []procSocket, owners map[uint64]int) PortInfo {
+	info := PortInfo{Port: portNum, PID: -1, ProcessName: UnknownProcessName, Protocol: "tcp"}
+
+	for _, protocol := range []string{"tcp", "udp"} {
+		var addresses []string
+		for _, socket := range sockets {
+			if socket.protocol != protocol {
+				continue
+			}
+			addresses = append(addresses, socket.address)
+			if pid, ok := owners[socket.inode]; ok && info.PID <= 0 {
+				info.PID = pid
+			}
+		}
+		if len(addresses) == 0 {
+			continue
+		}
+		if info.BindAddress == "" {
+			info.BindAddress = widestBindAddress(addresses)
+		}
+	}
+
+	if info.PID > 0 {
+		if processName, _, err := readProcProcessInfo(info.PID); err == nil {
+			info.ProcessName = processName
+		}
+	}
+	return info
+}
+
+// nativeProcessInfoForPort looks up the process holding a port without external tools
+func nativeProcessInfoForPort(port int) (int, string, error) {
+	infos, err := nativePortInfo(map[int]bool{port: true})
+	if err != nil {
+		return -1, "", err
+	}
+	if len(infos) == 0 {
+		return -1, "", fmt.Errorf("no socket found on port %d", port)
+	}
+	return infos[0].PID, infos[0].ProcessName, nil
+}
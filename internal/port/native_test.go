@@ -0,0 +1,108 @@
+package port
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDiscoveryBackend(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    DiscoveryBackend
+		wantErr bool
+	}{
+		{name: "empty_defaults_to_shell", input: "", want: DiscoveryShell},
+		{name: "shell", input: "shell", want: DiscoveryShell},
+		{name: "native_case_insensitive", input: " Native ", want: DiscoveryNative},
+		{name: "unknown", input: "gopsutil", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend, err := ParseDiscoveryBackend(tt.input)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrUnknownDiscoveryBackend)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, backend)
+		})
+	}
+}
+
+func TestParseProcNetSockets(t *testing.T) {
+	tcp := `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 0100007F:0BB8 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 4242 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:0BB8 0100007F:D431 01 00000000:00000000 00:00000000 00000000  1000        0 4343 1 0000000000000000 20 4 30 10 -1
+   2: 00000000:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 5151 1 0000000000000000 100 0 0 10 0
+`
+	sockets := parseProcNetSockets(tcp, "tcp", true)
+	assert.Equal(t, []procSocket{
+		{port: 3000, address: "127.0.0.1", inode: 4242, protocol: "tcp"},
+		{port: 8080, address: "0.0.0.0", inode: 5151, protocol: "tcp"},
+	}, sockets, "established connections are not listeners")
+
+	udp6 := `  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops
+  10: 00000000000000000000000000000000:14E9 00000000000000000000000000000000:0000 07 00000000:00000000 00:00000000 00000000   100        0 777 2 0000000000000000 0
+`
+	assert.Equal(t, []procSocket{{port: 5353, address: "::", inode: 777, protocol: "udp"}},
+		parseProcNetSockets(udp6, "udp", false))
+}
+
+func TestParseSocketLink(t *testing.T) {
+	inode, ok := parseSocketLink("socket:[12345]")
+	assert.True(t, ok)
+	assert.Equal(t, uint64(12345), inode)
+
+	for _, link := range []string{"pipe:[12345]", "/dev/null", "socket:[abc]", "socket:[12"} {
+		_, ok := parseSocketLink(link)
+		assert.False(t, ok, link)
+	}
+}
+
+func TestBuildNativePortInfo(t *testing.T) {
+	sockets := []procSocket{
+		{port: 3000, address: "127.0.0.1", inode: 1, protocol: "tcp"},
+		{port: 3000, address: "::", inode: 2, protocol: "tcp"},
+		{port: 3000, address: "0.0.0.0", inode: 3, protocol: "udp"},
+	}
+
+	t.Run("tcp_listener_wins", func(t *testing.T) {
+		info := buildNativePortInfo(3000, sockets, map[uint64]int{})
+		assert.Equal(t, PortInfo{Port: 3000, PID: -1, ProcessName: UnknownProcessName, Protocol: "tcp", BindAddress: "::"}, info)
+	})
+
+	t.Run("udp_only", func(t *testing.T) {
+		info := buildNativePortInfo(3000, sockets[2:], map[uint64]int{})
+		assert.Equal(t, "0.0.0.0", info.BindAddress)
+		assert.Equal(t, "tcp", info.Protocol, "matches the shell backend, which always reports tcp")
+	})
+}
+
+func TestSocketOwners(t *testing.T) {
+	root := t.TempDir()
+	for pid, links := range map[string][]string{
+		"123":  {"pipe:[7]", "socket:[42]"},
+		"456":  {"socket:[43]"},
+		"self": {"socket:[44]"}, // Not a PID directory
+	} {
+		fdDir := filepath.Join(root, pid, "fd")
+		require.NoError(t, os.MkdirAll(fdDir, 0o755))
+		for i, link := range links {
+			require.NoError(t, os.Symlink(link, filepath.Join(fdDir, strconv.Itoa(i))))
+		}
+	}
+
+	original := procRoot
+	procRoot = root
+	t.Cleanup(func() { procRoot = original })
+
+	owners := socketOwners(map[uint64]bool{42: true, 43: true, 44: true, 99: true})
+	assert.Equal(t, map[uint64]int{42: 123, 43: 456}, owners)
+}
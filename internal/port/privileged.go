@@ -0,0 +1,49 @@
+package port
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// currentEUID returns the effective user ID, replaced in tests
+var currentEUID = os.Geteuid
+
+// NeedsPrivileges reports whether the current user is likely unable to bind port: a privileged
+// port on a system that restricts them, without root. Linux honors the
+// net.ipv4.ip_unprivileged_port_start sysctl; macOS and Windows do not restrict these ports.
+// File capabilities such as CAP_NET_BIND_SERVICE on the started binary are not detected.
+func (s *Scanner) NeedsPrivileges(port int) bool {
+	if !s.IsPrivilegedPort(port) {
+		return false
+	}
+
+	switch runtime.GOOS {
+	case OSWindows, OSDarwin:
+		return false
+	}
+	if currentEUID() == 0 {
+		return false
+	}
+	if runtime.GOOS == OSLinux {
+		if start, ok := unprivilegedPortStart(); ok {
+			return port < start
+		}
+	}
+	return true
+}
+
+// unprivilegedPortStart reads the lowest port Linux lets unprivileged users bind
+func unprivilegedPortStart() (int, bool) {
+	data, err := os.ReadFile(filepath.Join(procRoot, "sys", "net", "ipv4", "ip_unprivileged_port_start"))
+	if err != nil {
+		return 0, false
+	}
+	start, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, false
+	}
+	return start, true
+}
@@ -0,0 +1,57 @@
+package port
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanner_NeedsPrivileges(t *testing.T) {
+	if runtime.GOOS != OSLinux {
+		t.Skip("privileged port rules are simulated through Linux procfs")
+	}
+	scanner := NewScanner(defaultTimeout)
+
+	// setup fakes the effective user and the ip_unprivileged_port_start sysctl ("" for none)
+	setup := func(t *testing.T, euid int, portStart string) {
+		t.Helper()
+		root := t.TempDir()
+		if portStart != "" {
+			sysctlDir := filepath.Join(root, "sys", "net", "ipv4")
+			require.NoError(t, os.MkdirAll(sysctlDir, 0o755))
+			require.NoError(t, os.WriteFile(filepath.Join(sysctlDir, "ip_unprivileged_port_start"), []byte(portStart+"\n"), 0o600))
+		}
+
+		originalRoot, originalEUID := procRoot, currentEUID
+		procRoot = root
+		currentEUID = func() int { return euid }
+		t.Cleanup(func() { procRoot, currentEUID = originalRoot, originalEUID })
+	}
+
+	tests := []struct {
+		name      string
+		euid      int
+		portStart string
+		port      int
+		expected  bool
+	}{
+		{name: "unprivileged_port", euid: 1000, portStart: "1024", port: 8080, expected: false},
+		{name: "privileged_port_as_user", euid: 1000, portStart: "1024", port: 80, expected: true},
+		{name: "privileged_port_as_root", euid: 0, portStart: "1024", port: 80, expected: false},
+		{name: "lowered_sysctl", euid: 1000, portStart: "80", port: 80, expected: false},
+		{name: "below_lowered_sysctl", euid: 1000, portStart: "80", port: 22, expected: true},
+		{name: "sysctl_unreadable", euid: 1000, port: 443, expected: true},
+		{name: "invalid_port", euid: 1000, portStart: "1024", port: 0, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setup(t, tt.euid, tt.portStart)
+			assert.Equal(t, tt.expected, scanner.NeedsPrivileges(tt.port))
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package port
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// tokenBucket paces port probes: tokens refill at rate per second up to burst, and each
+// probe takes one, waiting for the next refill when the bucket is empty
+type tokenBucket struct {
+	mutex  sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket creates a full bucket refilling at rate tokens per second
+func newTokenBucket(rate, burst float64) *tokenBucket {
+	return &tokenBucket{rate: rate, burst: burst, tokens: burst, last: time.Now()}
+}
+
+// wait takes a token, blocking until one is available or ctx is done. A caller that gives up
+// returns its token so the callers queued behind it do not wait for it.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	b.mutex.Lock()
+	now := time.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	// Take the token now, even if it has yet to refill, so concurrent callers queue up
+	b.tokens--
+	delay := time.Duration(-b.tokens / b.rate * float64(time.Second))
+	b.mutex.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.mutex.Lock()
+		b.tokens = min(b.burst, b.tokens+1)
+		b.mutex.Unlock()
+		return ctx.Err()
+	}
+}
@@ -6,11 +6,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"net"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -33,7 +37,46 @@ const (
 
 // Scanner implements PortScanner interface for cross-platform port scanning
 type Scanner struct {
-	timeout time.Duration
+	timeout     time.Duration
+	backend     DiscoveryBackend
+	commonPorts []int        // Ports checked by GetListeningPorts; nil uses commonDevPorts
+	limiter     *tokenBucket // Paces range scans; nil scans as fast as possible
+
+	recommendedPorts map[string]int // App type to port for GetRecommendedPort; nil uses defaultRecommendedPorts
+}
+
+// Timeout returns the upper bound for a single scan
+func (s *Scanner) Timeout() time.Duration {
+	return s.timeout
+}
+
+// SetRateLimit caps range scans at portsPerSecond probed ports; zero or less removes the cap.
+// Each probe binds TCP and UDP sockets, so a fast sweep over thousands of ports can look like
+// a port scan to host intrusion detection or exhaust file descriptors. A limit trades that for
+// scan time: the 5536-port ephemeral sweep of GetListeningPorts takes about 55s at 100 ports/s.
+func (s *Scanner) SetRateLimit(portsPerSecond float64) {
+	if portsPerSecond <= 0 {
+		s.limiter = nil
+		return
+	}
+	// Allow a short burst so small scans are not slowed down
+	s.limiter = newTokenBucket(portsPerSecond, max(1, portsPerSecond/10))
+}
+
+// RateLimit returns the range scan limit in ports per second; zero means unlimited
+func (s *Scanner) RateLimit() float64 {
+	if s.limiter == nil {
+		return 0
+	}
+	return s.limiter.rate
+}
+
+// throttle waits until the rate limit allows probing another port
+func (s *Scanner) throttle(ctx context.Context) error {
+	if s.limiter == nil {
+		return nil
+	}
+	return s.limiter.wait(ctx)
 }
 
 // PortInfo represents information about a port
@@ -43,6 +86,8 @@ type PortInfo struct {
 	ProcessName string `json:"process_name"` // Name of the process
 	IsManaged   bool   `json:"is_managed"`   // Whether this port is managed by portguard
 	Protocol    string `json:"protocol"`     // TCP or UDP
+	BindAddress string `json:"bind_address"` // Local address the listener is bound to, e.g. 0.0.0.0, 127.0.0.1 or ::; empty if unknown
+	InUse       bool   `json:"in_use"`       // Whether something holds the port
 }
 
 // NewScanner creates a new port scanner
@@ -52,27 +97,204 @@ func NewScanner(timeout time.Duration) *Scanner {
 	}
 }
 
-// IsPortInUse checks if a specific port is currently in use
+// IsPortInUse checks if a specific port is currently in use.
+//
+// A successful UDP bind is not conclusive: servers bound to another local address, or with
+// SO_REUSEADDR/SO_REUSEPORT, can share the port with our probe. After the bind succeeds the
+// socket table is consulted as well. Linux reads /proc/net/udp{,6} (falling back to lsof),
+// macOS and other Unix systems shell out to lsof -iUDP, and Windows is not covered, so shared
+// UDP ports can still be reported as available there. Range scans read the UDP sockets once
+// per scan instead of once per port; see snapshotUDPPorts.
 func (s *Scanner) IsPortInUse(port int) bool {
+	return bindProbeInUse(port) || s.hasUDPOwner(port)
+}
+
+// inUseWith is IsPortInUse for scans over many ports, with UDP owners from a snapshot
+func (s *Scanner) inUseWith(port int, udpPorts udpPortSet) bool {
+	return bindProbeInUse(port) || udpPorts[port]
+}
+
+// bindProbeInUse reports whether test binds on the port fail over TCP or UDP
+func bindProbeInUse(port int) bool {
 	// Try to bind to the port - if we can't, it's in use
 	// Use localhost to match common development server binding
 	address := fmt.Sprintf("127.0.0.1:%d", port)
 
-	// Check TCP
-	if listener, err := net.Listen("tcp", address); err == nil { //nolint:noctx // TODO: Add context support for port scanning operations
-		_ = listener.Close() //nolint:errcheck // Best effort cleanup during port scan
-	} else {
-		return true // Port is in use
+	// Check TCP on loopback, then on the wildcard address so listeners bound to
+	// another interface are caught too; which probe fails depends on the platform
+	for _, probe := range []string{address, fmt.Sprintf(":%d", port)} {
+		if listener, err := net.Listen("tcp", probe); err == nil { //nolint:noctx // TODO: Add context support for port scanning operations
+			_ = listener.Close() //nolint:errcheck // Best effort cleanup during port scan
+		} else {
+			return true // Port is in use
+		}
 	}
 
-	// Check UDP
+	// Check UDP; the bind can still succeed next to a socket that shares the port
 	if conn, err := net.ListenPacket("udp", address); err == nil { //nolint:noctx // TODO: Add context support for port scanning operations
 		_ = conn.Close() //nolint:errcheck // Best effort cleanup during port scan
-	} else {
-		return true // Port is in use
+		return false
 	}
+	return true // Port is in use
+}
 
-	return false
+// maxConcurrentProbes bounds the number of ports AreInUse probes at once
+const maxConcurrentProbes = 16
+
+// AreInUse checks many ports concurrently with the same probes as IsPortInUse.
+// Ports that were not probed before ctx was canceled are missing from the result.
+// The rate limit does not apply: callers check the few ports they are about to use.
+func (s *Scanner) AreInUse(ctx context.Context, ports []int) map[int]bool {
+	return s.areInUse(ctx, ports, false)
+}
+
+// areInUse implements AreInUse; throttled paces the probes by the rate limit, as for range scans
+func (s *Scanner) areInUse(ctx context.Context, ports []int, throttled bool) map[int]bool {
+	result := make(map[int]bool, len(ports))
+	var mutex sync.Mutex
+	udpPorts := s.snapshotUDPPorts()
+
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < min(maxConcurrentProbes, len(ports)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for port := range work {
+				inUse := s.inUseWith(port, udpPorts)
+				mutex.Lock()
+				result[port] = inUse
+				mutex.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, port := range ports {
+		if ctx.Err() != nil {
+			break
+		}
+		if throttled && s.throttle(ctx) != nil {
+			break
+		}
+		select {
+		case work <- port:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	return result
+}
+
+// hasUDPOwner reports whether some process holds a UDP socket on the port
+func (s *Scanner) hasUDPOwner(port int) bool {
+	switch runtime.GOOS {
+	case OSWindows:
+		return false
+	case OSLinux:
+		if bound, err := procNetUDPHasPort(port); err == nil {
+			return bound
+		}
+	}
+
+	pid, err := s.getUDPProcessID(port)
+	return err == nil && pid > 0
+}
+
+// udpPortSet holds the ports with a UDP socket; a nil set means the owners are unknown
+type udpPortSet map[int]bool
+
+// snapshotUDPPorts lists the ports held by UDP sockets with one read of the socket tables,
+// or one lsof run, so range scans do not spawn a process per port
+func (s *Scanner) snapshotUDPPorts() udpPortSet {
+	switch runtime.GOOS {
+	case OSWindows:
+		return nil
+	case OSLinux:
+		if ports, err := procNetUDPPorts(); err == nil {
+			return ports
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, "lsof", "-nP", "-iUDP", "-F", "n").Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(output) == 0 {
+			return udpPortSet{} // lsof exits non-zero when no socket matches
+		}
+		return nil
+	}
+	return parseLsofUDPPorts(string(output))
+}
+
+// parseLsofUDPPorts extracts local ports from lsof -F n output, whose name lines look like
+// "n*:5353", "n[::1]:5353" or "n10.0.0.2:123->10.0.0.1:123"
+func parseLsofUDPPorts(output string) udpPortSet {
+	ports := make(udpPortSet)
+	for _, line := range strings.Split(output, "\n") {
+		name, isName := strings.CutPrefix(line, "n")
+		if !isName {
+			continue
+		}
+		local, _, _ := strings.Cut(name, "->")
+		separator := strings.LastIndex(local, ":")
+		if separator < 0 {
+			continue
+		}
+		if port, err := strconv.Atoi(local[separator+1:]); err == nil && port > 0 {
+			ports[port] = true
+		}
+	}
+	return ports
+}
+
+// getUDPProcessID looks up the process holding a UDP socket on the port using lsof
+func (s *Scanner) getUDPProcessID(port int) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	// lsof exits non-zero when nothing matches
+	output, err := exec.CommandContext(ctx, "lsof", "-nP", "-t", fmt.Sprintf("-iUDP:%d", port)).Output()
+	if err != nil {
+		return -1, fmt.Errorf("failed to run lsof for UDP port %d: %w", port, err)
+	}
+
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		return -1, fmt.Errorf("no UDP socket found on port %d", port)
+	}
+	pid, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return -1, fmt.Errorf("invalid PID in lsof output %q: %w", fields[0], err)
+	}
+	return pid, nil
+}
+
+// procNetUDPHasPort checks the kernel UDP socket tables for a socket bound to the port
+func procNetUDPHasPort(port int) (bool, error) {
+	ports, err := procNetUDPPorts()
+	if err != nil {
+		return false, err
+	}
+	return ports[port], nil
+}
+
+// procNetUDPPorts lists the ports of the sockets in the kernel UDP socket tables
+func procNetUDPPorts() (udpPortSet, error) {
+	sockets, err := readProcSockets(procUDPTables...)
+	if err != nil {
+		return nil, err
+	}
+	ports := make(udpPortSet, len(sockets))
+	for _, socket := range sockets {
+		ports[socket.port] = true
+	}
+	return ports, nil
 }
 
 // GetPortInfo retrieves detailed information about a specific port
@@ -89,33 +311,36 @@ func (s *Scanner) GetPortInfo(port int) (*PortInfo, error) {
 	if !s.IsPortInUse(port) {
 		return portInfo, nil // Port is available
 	}
+	return s.describeUsedPort(port), nil
+}
+
+// describeUsedPort looks up the owner and bind address of a port known to be in use
+func (s *Scanner) describeUsedPort(port int) *PortInfo {
+	portInfo := &PortInfo{Port: port, PID: -1, Protocol: "tcp", InUse: true}
 
 	// Try to get process information using platform-specific methods
 	if pid, processName, err := s.getProcessInfoForPort(port); err == nil {
 		portInfo.PID = pid
 		portInfo.ProcessName = processName
 	}
+	portInfo.BindAddress = s.getBindAddress(port)
 
-	return portInfo, nil
+	return portInfo
 }
 
 // ScanRange scans a range of ports and returns information about ports in use
 func (s *Scanner) ScanRange(startPort, endPort int) ([]PortInfo, error) {
-	// Validate port range
-	if startPort > endPort {
-		return nil, fmt.Errorf("%w: start port must be less than end port", ErrPortRangeOrder)
-	}
-	if startPort <= 0 || endPort <= 0 || startPort > 65535 || endPort > 65535 {
-		return nil, fmt.Errorf("%w: invalid port range format", ErrInvalidPortRange)
+	if err := validateRange(startPort, endPort); err != nil {
+		return nil, err
 	}
 
 	var result []PortInfo
+	udpPorts := s.snapshotUDPPorts()
 
 	for port := startPort; port <= endPort; port++ {
-		if s.IsPortInUse(port) {
-			if portInfo, err := s.GetPortInfo(port); err == nil {
-				result = append(result, *portInfo)
-			}
+		_ = s.throttle(context.Background()) //nolint:errcheck // Background is never canceled
+		if s.inUseWith(port, udpPorts) {
+			result = append(result, *s.describeUsedPort(port))
 		}
 		// FIXED: Only add ports that are actually in use
 		// Removed the else block that was adding unused ports
@@ -124,9 +349,51 @@ func (s *Scanner) ScanRange(startPort, endPort int) ([]PortInfo, error) {
 	return result, nil
 }
 
+// ScanRangeDetailed scans a range of ports and returns one entry per port in order,
+// free ports included, with InUse telling them apart
+func (s *Scanner) ScanRangeDetailed(startPort, endPort int) ([]PortInfo, error) {
+	if err := validateRange(startPort, endPort); err != nil {
+		return nil, err
+	}
+
+	ports := make([]int, 0, endPort-startPort+1)
+	for port := startPort; port <= endPort; port++ {
+		ports = append(ports, port)
+	}
+	inUse := s.areInUse(context.Background(), ports, true)
+
+	result := make([]PortInfo, 0, len(ports))
+	for _, port := range ports {
+		portInfo := PortInfo{Port: port, PID: -1, Protocol: "tcp"}
+		if inUse[port] {
+			portInfo.InUse = true
+			if pid, processName, err := s.getProcessInfoForPort(port); err == nil {
+				portInfo.PID = pid
+				portInfo.ProcessName = processName
+			}
+			portInfo.BindAddress = s.getBindAddress(port)
+		}
+		result = append(result, portInfo)
+	}
+
+	return result, nil
+}
+
+// validateRange checks that startPort..endPort is an ordered range of valid ports
+func validateRange(startPort, endPort int) error {
+	if startPort > endPort {
+		return fmt.Errorf("%w: start port must be less than end port", ErrPortRangeOrder)
+	}
+	if startPort <= 0 || endPort <= 0 || startPort > 65535 || endPort > 65535 {
+		return fmt.Errorf("%w: invalid port range format", ErrInvalidPortRange)
+	}
+	return nil
+}
+
 // FindAvailablePort finds the first available port starting from the given port
 func (s *Scanner) FindAvailablePort(startPort int) (int, error) {
 	maxAttempts := 1000 // Prevent infinite loops
+	udpPorts := s.snapshotUDPPorts()
 
 	for i := 0; i < maxAttempts; i++ {
 		port := startPort + i
@@ -134,7 +401,7 @@ func (s *Scanner) FindAvailablePort(startPort int) (int, error) {
 			break // Exceeded valid port range
 		}
 
-		if !s.IsPortInUse(port) {
+		if !s.inUseWith(port, udpPorts) {
 			return port, nil
 		}
 	}
@@ -145,6 +412,12 @@ func (s *Scanner) FindAvailablePort(startPort int) (int, error) {
 // getProcessInfoForPort attempts to get process information for a port
 // This is platform-specific and may not work on all systems
 func (s *Scanner) getProcessInfoForPort(port int) (int, string, error) {
+	if s.backend == DiscoveryNative {
+		if pid, processName, err := nativeProcessInfoForPort(port); err == nil {
+			return pid, processName, nil
+		}
+	}
+
 	switch runtime.GOOS {
 	case OSDarwin, OSLinux:
 		return s.getProcessInfoUnix(port)
@@ -187,7 +460,14 @@ func (s *Scanner) getProcessInfoUnix(port int) (int, string, error) {
 		return s.parseNetstatOutput(string(netstatOutput), port)
 	}
 
-	// If both methods fail, check if port is actually in use
+	// Minimal containers often ship neither tool; on Linux read the socket tables directly
+	if runtime.GOOS == OSLinux {
+		if pid, processName, err := nativeProcessInfoForPort(port); err == nil && pid > 0 {
+			return pid, processName, nil
+		}
+	}
+
+	// If all methods fail, check if port is actually in use
 	if s.IsPortInUse(port) {
 		return -1, UnknownProcessName, nil // Port in use but can't identify process
 	}
@@ -300,30 +580,75 @@ func (s *Scanner) parseTasklistOutput(output string) string {
 	return ""
 }
 
+// commonDevPorts are the development ports checked by GetListeningPorts
+var commonDevPorts = []int{3000, 3001, 3002, 3003, 4000, 4001, 5000, 5001, 8000, 8001, 8080, 8081, 9000, 9001}
+
+// Part of the ephemeral range (49152-65535) where most dynamically assigned ports land
+const (
+	ephemeralScanStart = 60000
+	ephemeralScanEnd   = 65535
+)
+
+// SetCommonPorts changes the ports GetListeningPorts checks besides the ephemeral range.
+// The ports are added to the builtin development ports, or used instead of them when replace is set.
+func (s *Scanner) SetCommonPorts(ports []int, replace bool) {
+	merged := make([]int, 0, len(commonDevPorts)+len(ports))
+	if !replace {
+		merged = append(merged, commonDevPorts...)
+	}
+	merged = append(merged, ports...)
+
+	seen := make(map[int]bool, len(merged))
+	s.commonPorts = merged[:0]
+	for _, port := range merged {
+		if !seen[port] && s.IsPortInRange(port) {
+			seen[port] = true
+			s.commonPorts = append(s.commonPorts, port)
+		}
+	}
+}
+
+// CommonPorts returns the ports GetListeningPorts checks besides the ephemeral range
+func (s *Scanner) CommonPorts() []int {
+	if s.commonPorts == nil {
+		return commonDevPorts
+	}
+	return s.commonPorts
+}
+
 // GetListeningPorts returns all ports currently being listened on
 func (s *Scanner) GetListeningPorts() ([]PortInfo, error) {
+	commonPorts := s.CommonPorts()
+	if s.backend == DiscoveryNative {
+		candidates := make(map[int]bool, len(commonPorts)+ephemeralScanEnd-ephemeralScanStart+1)
+		for _, port := range commonPorts {
+			candidates[port] = true
+		}
+		for port := ephemeralScanStart; port <= ephemeralScanEnd; port++ {
+			candidates[port] = true
+		}
+		if result, err := nativePortInfo(candidates); err == nil {
+			return result, nil
+		}
+	}
+
 	// Initialize result slice (never return nil)
 	result := make([]PortInfo, 0)
-
-	// Scan common development ports
-	commonPorts := []int{3000, 3001, 3002, 3003, 4000, 4001, 5000, 5001, 8000, 8001, 8080, 8081, 9000, 9001}
+	udpPorts := s.snapshotUDPPorts()
 
 	// Check common ports
 	for _, port := range commonPorts {
-		if s.IsPortInUse(port) {
-			if portInfo, err := s.GetPortInfo(port); err == nil {
-				result = append(result, *portInfo)
-			}
+		_ = s.throttle(context.Background()) //nolint:errcheck // Background is never canceled
+		if s.inUseWith(port, udpPorts) {
+			result = append(result, *s.describeUsedPort(port))
 		}
 	}
 
-	// Scan ephemeral port range (system-assigned ports) - common range is 49152-65535
-	// For efficiency, scan a smaller range where most dynamic ports are assigned
-	for port := 60000; port <= 65535; port++ {
-		if s.IsPortInUse(port) {
-			if portInfo, err := s.GetPortInfo(port); err == nil {
-				result = append(result, *portInfo)
-			}
+	// Scan ephemeral port range (system-assigned ports)
+	for port := ephemeralScanStart; port <= ephemeralScanEnd; port++ {
+		_ = s.throttle(context.Background()) //nolint:errcheck // Background is never canceled
+		if s.inUseWith(port, udpPorts) {
+			result = append(result, *s.describeUsedPort(port))
 		}
 	}
 
@@ -340,31 +665,54 @@ func (s *Scanner) IsPrivilegedPort(port int) bool {
 	return port > 0 && port < 1024
 }
 
-// GetRecommendedPort suggests a good port based on the application type
-func (s *Scanner) GetRecommendedPort(appType string) int {
-	recommendations := map[string]int{
-		"web":        3000,
-		"api":        3001,
-		"websocket":  3002,
-		"database":   5432,
-		"cache":      6379,
-		"monitoring": 9090,
-		"metrics":    9091,
-	}
+// defaultRecommendedPorts maps application types to the port GetRecommendedPort starts from
+var defaultRecommendedPorts = map[string]int{
+	"web":        3000,
+	"dev":        3000,
+	"api":        8080,
+	"websocket":  3002,
+	"database":   5432,
+	"cache":      6379,
+	"monitoring": 9090,
+	"metrics":    9091,
+}
 
-	if port, exists := recommendations[strings.ToLower(appType)]; exists {
-		// Try to find available port starting from recommendation
-		if available, err := s.FindAvailablePort(port); err == nil {
-			return available
+// fallbackRecommendedPort is where GetRecommendedPort starts for application types without a mapping
+const fallbackRecommendedPort = 8080
+
+// SetRecommendedPorts adds application type to port mappings for GetRecommendedPort, replacing
+// builtin ones with the same type. Types are case-insensitive; invalid ports are ignored.
+func (s *Scanner) SetRecommendedPorts(recommendations map[string]int) {
+	s.recommendedPorts = make(map[string]int, len(defaultRecommendedPorts)+len(recommendations))
+	maps.Copy(s.recommendedPorts, defaultRecommendedPorts)
+	for appType, port := range recommendations {
+		if s.IsPortInRange(port) {
+			s.recommendedPorts[strings.ToLower(appType)] = port
 		}
 	}
+}
 
-	// Default to finding available port from 3000
-	if available, err := s.FindAvailablePort(3000); err == nil {
-		return available
+// RecommendedPortFor returns the port GetRecommendedPort starts searching from for the
+// application type, without checking availability
+func (s *Scanner) RecommendedPortFor(appType string) int {
+	recommendations := s.recommendedPorts
+	if recommendations == nil {
+		recommendations = defaultRecommendedPorts
 	}
+	if port, exists := recommendations[strings.ToLower(appType)]; exists {
+		return port
+	}
+	return fallbackRecommendedPort
+}
 
-	return 0
+// GetRecommendedPort returns the first available port at or above the recommendation for the
+// application type. It fails with ErrNoAvailablePort when no port near the recommendation is free.
+func (s *Scanner) GetRecommendedPort(appType string) (int, error) {
+	port, err := s.FindAvailablePort(s.RecommendedPortFor(appType))
+	if err != nil {
+		return 0, fmt.Errorf("no recommended port for %q: %w", appType, err)
+	}
+	return port, nil
 }
 
 // ParsePortRange parses a port range string like "3000-3010"
@@ -432,12 +780,14 @@ func (s *Scanner) DiscoverDevelopmentServers(startPort, endPort int) ([]PortInfo
 	for _, portInfo := range portsInUse {
 		if portInfo.PID > 0 && portInfo.ProcessName != "" && portInfo.ProcessName != UnknownProcessName {
 			// Check if process name matches development server patterns
-			processNameLower := strings.ToLower(portInfo.ProcessName)
-			for _, pattern := range devPatterns {
-				if strings.Contains(processNameLower, pattern) {
-					developmentServers = append(developmentServers, portInfo)
-					break
-				}
+			if containsAnyPattern(portInfo.ProcessName, devPatterns) {
+				developmentServers = append(developmentServers, portInfo)
+				continue
+			}
+
+			// Process names can be truncated or belong to a wrapper; fall back to the full command line
+			if _, command, err := s.GetProcessInfoByPID(portInfo.PID); err == nil && containsAnyPattern(command, devPatterns) {
+				developmentServers = append(developmentServers, portInfo)
 			}
 		}
 	}
@@ -445,29 +795,48 @@ func (s *Scanner) DiscoverDevelopmentServers(startPort, endPort int) ([]PortInfo
 	return developmentServers, nil
 }
 
-// GetProcessInfoByPID retrieves process information by PID
+// containsAnyPattern reports whether value contains any of the patterns, case-insensitively
+func containsAnyPattern(value string, patterns []string) bool {
+	valueLower := strings.ToLower(value)
+	for _, pattern := range patterns {
+		if strings.Contains(valueLower, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetProcessInfoByPID retrieves the process name and full command line by PID.
+// The name may be truncated by the OS (15 characters on Linux); the command line is not.
 func (s *Scanner) GetProcessInfoByPID(pid int) (string, string, error) {
+	if runtime.GOOS == OSLinux {
+		if processName, command, err := readProcProcessInfo(pid); err == nil {
+			return processName, command, nil
+		}
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
 	defer cancel()
 
 	switch runtime.GOOS {
 	case OSDarwin, OSLinux:
-		// Use ps to get process info
-		cmd := exec.CommandContext(ctx, "ps", "-p", strconv.Itoa(pid), "-o", "comm=,args=")
-		output, err := cmd.Output()
+		// Query name and arguments separately so the command line is not split on comm's spaces
+		nameOutput, err := exec.CommandContext(ctx, "ps", "-p", strconv.Itoa(pid), "-o", "comm=").Output()
 		if err != nil {
 			return "", "", fmt.Errorf("failed to get process info for PID %d: %w", pid, err)
 		}
+		processName := strings.TrimSpace(string(nameOutput))
+		if processName == "" {
+			break
+		}
 
-		lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-		if len(lines) > 0 {
-			fields := strings.Fields(lines[0])
-			if len(fields) >= 1 {
-				processName := fields[0]
-				command := strings.Join(fields, " ")
-				return processName, command, nil
+		command := processName
+		if argsOutput, err := exec.CommandContext(ctx, "ps", "-p", strconv.Itoa(pid), "-o", "args=").Output(); err == nil {
+			if args := strings.TrimSpace(string(argsOutput)); args != "" {
+				command = args
 			}
 		}
+		return processName, command, nil
 
 	case OSWindows:
 		// Use tasklist for Windows
@@ -477,10 +846,48 @@ func (s *Scanner) GetProcessInfoByPID(pid int) (string, string, error) {
 			return "", "", fmt.Errorf("failed to get process info for PID %d: %w", pid, err)
 		}
 
-		if processName := s.parseTasklistOutput(string(output)); processName != "" {
-			return processName, processName, nil
+		processName := s.parseTasklistOutput(string(output))
+		if processName == "" {
+			break
 		}
+
+		// tasklist only reports the image name; ask CIM for the full command line
+		command := processName
+		query := fmt.Sprintf("(Get-CimInstance Win32_Process -Filter 'ProcessId=%d').CommandLine", pid)
+		if cmdlineOutput, err := exec.CommandContext(ctx, "powershell", "-NoProfile", "-Command", query).Output(); err == nil {
+			if cmdline := strings.TrimSpace(string(cmdlineOutput)); cmdline != "" {
+				command = cmdline
+			}
+		}
+		return processName, command, nil
 	}
 
 	return "", "", fmt.Errorf("could not retrieve process info for PID %d", pid)
 }
+
+// readProcProcessInfo reads the process name and full command line from /proc
+func readProcProcessInfo(pid int) (string, string, error) {
+	procDir := filepath.Join("/proc", strconv.Itoa(pid))
+
+	comm, err := os.ReadFile(filepath.Join(procDir, "comm"))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read process name for PID %d: %w", pid, err)
+	}
+	processName := strings.TrimSpace(string(comm))
+
+	command := processName
+	if cmdline, err := os.ReadFile(filepath.Join(procDir, "cmdline")); err == nil {
+		// Kernel threads and zombies have an empty cmdline
+		if parsed := parseProcCmdline(cmdline); parsed != "" {
+			command = parsed
+		}
+	}
+
+	return processName, command, nil
+}
+
+// parseProcCmdline converts the NUL-separated contents of /proc/<pid>/cmdline into a command string
+func parseProcCmdline(data []byte) string {
+	args := strings.Split(strings.TrimRight(string(data), "\x00"), "\x00")
+	return strings.TrimSpace(strings.Join(args, " "))
+}
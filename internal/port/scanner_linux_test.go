@@ -0,0 +1,192 @@
+//go:build linux
+
+package port
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listenUDPReuse binds a UDP socket with SO_REUSEADDR and SO_REUSEPORT set
+func listenUDPReuse(t *testing.T, address string) net.PacketConn {
+	t.Helper()
+
+	lc := net.ListenConfig{
+		Control: func(_, _ string, c syscall.RawConn) error {
+			var sockErr error
+			if err := c.Control(func(fd uintptr) {
+				if sockErr = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEADDR, 1); sockErr != nil {
+					return
+				}
+				sockErr = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1)
+			}); err != nil {
+				return err
+			}
+			return sockErr
+		},
+	}
+
+	conn, err := lc.ListenPacket(context.Background(), "udp", address)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close() //nolint:errcheck // Test cleanup can fail
+	})
+	return conn
+}
+
+func TestScanner_IsPortInUse_UDPReuseAddr(t *testing.T) {
+	scanner := NewScanner(defaultTimeout)
+	port := findTestPort(t)
+
+	// A server on another loopback address with reuse flags lets our probe bind succeed
+	listenUDPReuse(t, fmt.Sprintf("127.0.0.2:%d", port))
+
+	probe, err := net.ListenPacket("udp", fmt.Sprintf("127.0.0.1:%d", port)) //nolint:noctx // Test precondition
+	if err != nil {
+		t.Skipf("UDP bind on shared port failed, reuse case not reproducible: %v", err)
+	}
+	_ = probe.Close() //nolint:errcheck // Test cleanup can fail
+
+	assert.True(t, scanner.IsPortInUse(port), "shared UDP port should be reported in use")
+
+	bound, err := procNetUDPHasPort(port)
+	require.NoError(t, err)
+	assert.True(t, bound)
+}
+
+func TestScanner_AreInUse_UDPReuseAddr(t *testing.T) {
+	scanner := NewScanner(defaultTimeout)
+	port := findTestPort(t)
+
+	listenUDPReuse(t, fmt.Sprintf("127.0.0.2:%d", port))
+
+	// Range scans take the UDP owners from one snapshot instead of a lookup per port
+	assert.True(t, scanner.snapshotUDPPorts()[port])
+	assert.True(t, scanner.AreInUse(context.Background(), []int{port})[port])
+}
+
+func TestProcNetUDPPorts(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "net"), 0o755))
+	table := `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops
+  123: 0100007F:0BB8 00000000:0000 07 00000000:00000000 00:00000000 00000000  1000        0 4242 2 0000000000000000 0
+  124: 00000000:14E9 00000000:0000 07 00000000:00000000 00:00000000 00000000     0        0 4243 2 0000000000000000 0
+`
+	require.NoError(t, os.WriteFile(filepath.Join(root, "net", "udp"), []byte(table), 0o600))
+
+	original := procRoot
+	procRoot = root
+	t.Cleanup(func() { procRoot = original })
+
+	// udp6 is missing, which is fine as long as one table can be read
+	ports, err := procNetUDPPorts()
+	require.NoError(t, err)
+	assert.Equal(t, udpPortSet{3000: true, 5353: true}, ports)
+
+	procRoot = t.TempDir()
+	_, err = procNetUDPPorts()
+	assert.Error(t, err)
+}
+
+func TestScanner_IsPortInUse_UDPReleased(t *testing.T) {
+	scanner := NewScanner(defaultTimeout)
+	port := findTestPort(t)
+
+	conn := listenUDPReuse(t, fmt.Sprintf("127.0.0.2:%d", port))
+	require.NoError(t, conn.Close())
+
+	assert.False(t, scanner.IsPortInUse(port))
+}
+
+func TestScanner_GetPortInfo_BindAddress(t *testing.T) {
+	scanner := NewScanner(defaultTimeout)
+
+	tests := []struct {
+		name    string
+		address string
+		want    []string
+	}{
+		{name: "loopback", address: "127.0.0.1", want: []string{"127.0.0.1"}},
+		{name: "ipv4 wildcard", address: "0.0.0.0", want: []string{"0.0.0.0"}},
+		{name: "other interface", address: "127.0.0.2", want: []string{"127.0.0.2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port := findTestPort(t)
+			listener, err := net.Listen("tcp4", fmt.Sprintf("%s:%d", tt.address, port)) //nolint:noctx // Test listener
+			require.NoError(t, err)
+			defer func() {
+				_ = listener.Close() //nolint:errcheck // Test cleanup can fail
+			}()
+
+			// Listeners on another interface are only caught by the wildcard probe
+			assert.True(t, scanner.IsPortInUse(port))
+
+			info, err := scanner.GetPortInfo(port)
+			require.NoError(t, err)
+			assert.Contains(t, tt.want, info.BindAddress)
+			assert.True(t, info.ConflictsWith("0.0.0.0"), "wildcard binds always conflict")
+			assert.Equal(t, tt.address != "127.0.0.2", info.ConflictsWith("127.0.0.1"))
+		})
+	}
+}
+
+func TestScanner_NativeDiscoveryMatchesShell(t *testing.T) {
+	port := findTestPort(t)
+	listener, err := net.Listen("tcp4", fmt.Sprintf("127.0.0.1:%d", port)) //nolint:noctx // Test listener
+	require.NoError(t, err)
+	defer func() {
+		_ = listener.Close() //nolint:errcheck // Test cleanup can fail
+	}()
+
+	native := NewScanner(defaultTimeout)
+	native.SetDiscoveryBackend(DiscoveryNative)
+	nativeInfo, err := native.GetPortInfo(port)
+	require.NoError(t, err)
+	assert.Equal(t, os.Getpid(), nativeInfo.PID)
+	assert.Equal(t, "127.0.0.1", nativeInfo.BindAddress)
+
+	pid, _, err := nativeProcessInfoForPort(port)
+	require.NoError(t, err, "the native lookup must answer on its own, without falling back")
+	assert.Equal(t, os.Getpid(), pid)
+
+	if _, err := exec.LookPath("lsof"); err != nil {
+		t.Skip("lsof not available, cannot compare with the shell backend")
+	}
+	shellInfo, err := NewScanner(defaultTimeout).GetPortInfo(port)
+	require.NoError(t, err)
+	assert.Equal(t, shellInfo, nativeInfo)
+}
+
+func TestScanner_GetPortInfo_WithoutShellTools(t *testing.T) {
+	port := findTestPort(t)
+	listener, err := net.Listen("tcp4", fmt.Sprintf("127.0.0.1:%d", port)) //nolint:noctx // Test listener
+	require.NoError(t, err)
+	defer func() {
+		_ = listener.Close() //nolint:errcheck // Test cleanup can fail
+	}()
+
+	// Like a minimal container: no lsof, netstat or ps on PATH
+	t.Setenv("PATH", t.TempDir())
+
+	comm, err := os.ReadFile("/proc/self/comm")
+	require.NoError(t, err)
+
+	info, err := NewScanner(defaultTimeout).GetPortInfo(port)
+	require.NoError(t, err)
+	assert.Equal(t, os.Getpid(), info.PID)
+	assert.Equal(t, strings.TrimSpace(string(comm)), info.ProcessName)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"os"
 	"runtime"
 	"sync"
 	"testing"
@@ -100,6 +101,45 @@ func TestNewScanner(t *testing.T) {
 	}
 }
 
+func TestScanner_AreInUse(t *testing.T) {
+	scanner := NewScanner(defaultTimeout)
+
+	boundPort := findTestPort(t)
+	_, cleanup := createTestServer(t, boundPort)
+	defer cleanup()
+
+	ports := []int{boundPort}
+	for seen := map[int]bool{boundPort: true}; len(ports) < 40; {
+		if candidate := findTestPort(t); !seen[candidate] {
+			seen[candidate] = true
+			ports = append(ports, candidate)
+		}
+	}
+
+	result := scanner.AreInUse(context.Background(), ports)
+	assert.Len(t, result, len(ports))
+	assert.True(t, result[boundPort], "bound port should be reported in use")
+
+	t.Run("canceled_context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.Empty(t, scanner.AreInUse(ctx, ports))
+	})
+
+	t.Run("no_ports", func(t *testing.T) {
+		assert.Empty(t, scanner.AreInUse(context.Background(), nil))
+	})
+
+	t.Run("rate_limit_does_not_apply", func(t *testing.T) {
+		limited := NewScanner(defaultTimeout)
+		limited.SetRateLimit(1) // Pacing 40 ports would take about 39s
+
+		start := time.Now()
+		assert.Len(t, limited.AreInUse(context.Background(), ports), len(ports))
+		assert.Less(t, time.Since(start), 10*time.Second)
+	})
+}
+
 func TestScanner_IsPortInUse(t *testing.T) {
 	scanner := NewScanner(defaultTimeout)
 
@@ -368,6 +408,40 @@ func TestScanner_ScanRange(t *testing.T) {
 	}
 }
 
+func TestScanner_ScanRangeDetailed(t *testing.T) {
+	scanner := NewScanner(defaultTimeout)
+
+	startPort := testPortStart + 500
+	endPort := startPort + 3
+	usedPort := startPort + 1
+	_, cleanup := createTestServer(t, usedPort)
+	defer cleanup()
+
+	portInfos, err := scanner.ScanRangeDetailed(startPort, endPort)
+	require.NoError(t, err)
+	require.Len(t, portInfos, 4, "every port in the range is reported")
+
+	for i, portInfo := range portInfos {
+		assert.Equal(t, startPort+i, portInfo.Port, "ports are reported in order")
+		assert.Equal(t, portInfo.Port == usedPort, portInfo.InUse, "port %d", portInfo.Port)
+		if !portInfo.InUse {
+			assert.Equal(t, -1, portInfo.PID)
+		}
+	}
+
+	// The original variant still lists only the used port
+	used, err := scanner.ScanRange(startPort, endPort)
+	require.NoError(t, err)
+	require.Len(t, used, 1)
+	assert.Equal(t, usedPort, used[0].Port)
+	assert.True(t, used[0].InUse)
+
+	_, err = scanner.ScanRangeDetailed(endPort, startPort)
+	require.ErrorIs(t, err, ErrPortRangeOrder)
+	_, err = scanner.ScanRangeDetailed(0, endPort)
+	require.ErrorIs(t, err, ErrInvalidPortRange)
+}
+
 func TestScanner_GetListeningPorts(t *testing.T) {
 	scanner := NewScanner(defaultTimeout)
 
@@ -542,13 +616,44 @@ func TestScanner_GetRecommendedPort(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			recommended := scanner.GetRecommendedPort(tt.appType)
-			assert.Positive(t, recommended)
+			assert.Equal(t, tt.expected, scanner.RecommendedPortFor(tt.appType))
+
+			// The recommendation is the first free port from the mapped one
+			recommended, err := scanner.GetRecommendedPort(tt.appType)
+			require.NoError(t, err)
+			assert.GreaterOrEqual(t, recommended, tt.expected)
 			assert.LessOrEqual(t, recommended, 65535)
 		})
 	}
 }
 
+func TestScanner_SetRecommendedPorts(t *testing.T) {
+	scanner := NewScanner(defaultTimeout)
+	scanner.SetRecommendedPorts(map[string]int{"API": 4000, "grpc": 50051, "broken": 70000})
+
+	assert.Equal(t, 4000, scanner.RecommendedPortFor("api"), "overrides are case-insensitive")
+	assert.Equal(t, 50051, scanner.RecommendedPortFor("grpc"))
+	assert.Equal(t, 3000, scanner.RecommendedPortFor("web"), "builtin mappings are kept")
+	assert.Equal(t, fallbackRecommendedPort, scanner.RecommendedPortFor("broken"), "invalid ports are ignored")
+	assert.Equal(t, 8080, defaultRecommendedPorts["api"], "the builtin mapping is not modified")
+}
+
+func TestScanner_GetRecommendedPort_NoAvailablePort(t *testing.T) {
+	// Nothing is searched above the last port, so holding it leaves no candidate
+	listener, err := net.Listen("tcp", ":65535")
+	if err != nil {
+		t.Skipf("cannot hold port 65535: %v", err)
+	}
+	defer func() { _ = listener.Close() }() //nolint:errcheck // Test cleanup
+
+	scanner := NewScanner(defaultTimeout)
+	scanner.SetRecommendedPorts(map[string]int{"edge": 65535})
+
+	port, err := scanner.GetRecommendedPort("edge")
+	require.ErrorIs(t, err, ErrNoAvailablePort)
+	assert.Zero(t, port)
+}
+
 func TestScanner_ParsePortRange(t *testing.T) {
 	scanner := NewScanner(defaultTimeout)
 
@@ -928,3 +1033,146 @@ func TestScanner_ParseNetstatOutputWindows(t *testing.T) {
 		})
 	}
 }
+
+func TestParseLsofUDPPorts(t *testing.T) {
+	output := "p412\ncmDNSResponder\nf7\nn*:5353\nf8\nn[::1]:5354\np913\nf12\nn10.0.0.2:123->10.0.0.1:124\nnbogus\n"
+
+	ports := parseLsofUDPPorts(output)
+	assert.Equal(t, udpPortSet{5353: true, 5354: true, 123: true}, ports)
+	assert.Empty(t, parseLsofUDPPorts(""))
+}
+
+func TestParseProcCmdline(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		expected string
+	}{
+		{"nul_separated_args", []byte("node\x00/usr/lib/node_modules/npm/bin/npm-cli.js\x00run\x00dev\x00"), "node /usr/lib/node_modules/npm/bin/npm-cli.js run dev"},
+		{"single_arg", []byte("vite\x00"), "vite"},
+		{"empty", []byte{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseProcCmdline(tt.data))
+		})
+	}
+}
+
+func TestScanner_GetProcessInfoByPID_FullCommandLine(t *testing.T) {
+	if runtime.GOOS != OSLinux {
+		t.Skip("Linux-specific test")
+	}
+
+	scanner := NewScanner(defaultTimeout)
+	processName, command, err := scanner.GetProcessInfoByPID(os.Getpid())
+	require.NoError(t, err)
+
+	// comm is capped at 15 characters, the command line carries every argument
+	assert.LessOrEqual(t, len(processName), 15)
+	assert.Contains(t, command, os.Args[0])
+	for _, arg := range os.Args[1:] {
+		assert.Contains(t, command, arg)
+	}
+}
+
+func TestScanner_SetCommonPorts(t *testing.T) {
+	tests := []struct {
+		name     string
+		ports    []int
+		replace  bool
+		expected []int
+	}{
+		{"extend", []int{50051, 3000, 5432}, false, append(append([]int{}, commonDevPorts...), 50051, 5432)},
+		{"replace", []int{50051, 5432, 50051}, true, []int{50051, 5432}},
+		{"replace_with_nothing", nil, true, []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := NewScanner(defaultTimeout)
+			assert.Equal(t, commonDevPorts, scanner.CommonPorts())
+
+			scanner.SetCommonPorts(tt.ports, tt.replace)
+			assert.Equal(t, tt.expected, scanner.CommonPorts())
+		})
+	}
+}
+
+func TestScanner_GetListeningPorts_CustomCommonPort(t *testing.T) {
+	scanner := NewScanner(defaultTimeout)
+
+	// 50051 (gRPC) is outside both the builtin list and the scanned ephemeral range
+	testPort := 50051
+	for scanner.IsPortInUse(testPort) && testPort < 50061 {
+		testPort++
+	}
+	if scanner.IsPortInUse(testPort) {
+		t.Skip("No available ports near 50051 for testing")
+	}
+
+	_, cleanup := createTestServer(t, testPort)
+	defer cleanup()
+
+	findPort := func() bool {
+		ports, err := scanner.GetListeningPorts()
+		require.NoError(t, err)
+		for _, portInfo := range ports {
+			if portInfo.Port == testPort {
+				return true
+			}
+		}
+		return false
+	}
+
+	assert.False(t, findPort(), "port %d should not be scanned by default", testPort)
+
+	scanner.SetCommonPorts([]int{testPort}, true)
+	assert.True(t, findPort(), "configured common port %d should be scanned", testPort)
+}
+
+func TestScanner_SetRateLimit(t *testing.T) {
+	scanner := NewScanner(defaultTimeout)
+	assert.Zero(t, scanner.RateLimit())
+
+	const startPort, endPort = 45000, 45009
+
+	start := time.Now()
+	_, err := scanner.ScanRange(startPort, endPort)
+	require.NoError(t, err)
+	unlimited := time.Since(start)
+
+	// 10 ports at 20/s with a burst of 2: the remaining 8 probes wait 50ms each
+	scanner.SetRateLimit(20)
+	assert.InDelta(t, 20, scanner.RateLimit(), 0)
+
+	start = time.Now()
+	_, err = scanner.ScanRange(startPort, endPort)
+	require.NoError(t, err)
+	limited := time.Since(start)
+
+	assert.GreaterOrEqual(t, limited, 350*time.Millisecond)
+	assert.Greater(t, limited, unlimited+300*time.Millisecond)
+
+	scanner.SetRateLimit(0)
+	assert.Zero(t, scanner.RateLimit())
+}
+
+func TestTokenBucket_WaitHonorsContext(t *testing.T) {
+	bucket := newTokenBucket(1, 1)
+	require.NoError(t, bucket.wait(context.Background()))
+
+	// The bucket is empty and refills in a second; the context ends first
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	require.ErrorIs(t, bucket.wait(ctx), context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+
+	// The canceled wait returned its token, so the next caller is not delayed by it
+	bucket.mutex.Lock()
+	tokens := bucket.tokens
+	bucket.mutex.Unlock()
+	assert.Greater(t, tokens, -0.5)
+}
@@ -23,6 +23,7 @@ var (
 	ErrSystemProcess      = errors.New("cannot adopt system process")
 	ErrInsufficientPerms  = errors.New("insufficient permissions to adopt process")
 	ErrProcessAlreadyDead = errors.New("process is no longer running")
+	ErrInvalidPIDFile     = errors.New("PID file does not contain a valid PID")
 )
 
 // AdoptionInfo contains information about a process that can be adopted
@@ -34,22 +35,62 @@ type AdoptionInfo struct {
 	WorkingDir  string `json:"working_dir,omitempty"`
 	IsSuitable  bool   `json:"is_suitable"`
 	Reason      string `json:"reason,omitempty"`
+
+	// Environment of the process, nil when it could not be read. It is never serialized
+	// because it commonly holds secrets.
+	Environment    map[string]string `json:"-"`
+	environmentErr error             // Why Environment could not be read
+}
+
+// NotSuitableError reports a process that was evaluated but not adopted. It matches
+// ErrProcessNotSuitable with errors.Is and carries the evaluation for callers that report it.
+type NotSuitableError struct {
+	Info *AdoptionInfo
+}
+
+// Error gives the reason the process was rejected
+func (e *NotSuitableError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrProcessNotSuitable, e.Info.Reason)
+}
+
+// Unwrap makes errors.Is(err, ErrProcessNotSuitable) hold
+func (e *NotSuitableError) Unwrap() error {
+	return ErrProcessNotSuitable
 }
 
 // ProcessAdopter handles adoption of external processes
 type ProcessAdopter struct {
 	scanner *port.Scanner
 	timeout time.Duration
+
+	// lookupProcessInfo returns the process name and full command line for a PID
+	lookupProcessInfo func(pid int) (string, string, error)
+	// lookupEnvironment returns the environment of a running process
+	lookupEnvironment func(pid int) (map[string]string, error)
+
+	envFilters []EnvPredicate // Conditions a process environment must meet to be suitable
 }
 
 // NewProcessAdopter creates a new process adopter
 func NewProcessAdopter(timeout time.Duration) *ProcessAdopter {
+	scanner := port.NewScanner(timeout)
 	return &ProcessAdopter{
-		scanner: port.NewScanner(timeout),
-		timeout: timeout,
+		scanner:           scanner,
+		timeout:           timeout,
+		lookupProcessInfo: scanner.GetProcessInfoByPID,
+		lookupEnvironment: func(pid int) (map[string]string, error) {
+			return readProcessEnvironment(pid, timeout)
+		},
 	}
 }
 
+// SetEnvFilters makes only processes whose environment meets every predicate suitable for
+// adoption, e.g. NODE_ENV=development to tell a dev server from an identical production one.
+// A process whose environment cannot be read, typically one owned by another user, is unsuitable.
+func (pa *ProcessAdopter) SetEnvFilters(filters []EnvPredicate) {
+	pa.envFilters = filters
+}
+
 // AdoptProcessByPID adopts an existing process by PID
 func (pa *ProcessAdopter) AdoptProcessByPID(pid int) (*ManagedProcess, error) {
 	// Validate PID
@@ -70,13 +111,44 @@ func (pa *ProcessAdopter) AdoptProcessByPID(pid int) (*ManagedProcess, error) {
 
 	// Check if process is suitable for adoption
 	if !adoptionInfo.IsSuitable {
-		return nil, fmt.Errorf("%w: %s", ErrProcessNotSuitable, adoptionInfo.Reason)
+		return nil, &NotSuitableError{Info: adoptionInfo}
 	}
 
 	// Create ManagedProcess from adoption info
 	return pa.createManagedProcessFromAdoption(adoptionInfo)
 }
 
+// AdoptProcessByPIDFile adopts the process whose PID a daemon wrote to a PID file.
+// A PID file naming a process that has exited is reported as ErrProcessAlreadyDead.
+func (pa *ProcessAdopter) AdoptProcessByPIDFile(path string) (*ManagedProcess, error) {
+	pid, err := ReadPIDFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !pa.isProcessRunning(pid) {
+		return nil, fmt.Errorf("%w: PID %d from %s (stale PID file?)", ErrProcessAlreadyDead, pid, path)
+	}
+	return pa.AdoptProcessByPID(pid)
+}
+
+// ReadPIDFile parses the PID on the first line of a PID file, ignoring surrounding whitespace.
+// Some daemons write further lines after the PID, which are ignored too.
+func ReadPIDFile(path string) (int, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // Reading the PID file the user asked for is the point
+	if err != nil {
+		return 0, fmt.Errorf("failed to read PID file: %w", err)
+	}
+
+	firstLine, _, _ := strings.Cut(strings.TrimSpace(string(data)), "\n")
+	firstLine = strings.TrimSpace(firstLine)
+	pid, err := strconv.Atoi(firstLine)
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("%w: %s contains %q", ErrInvalidPIDFile, path, firstLine)
+	}
+	return pid, nil
+}
+
 // AdoptProcessByPort adopts a process running on a specific port
 func (pa *ProcessAdopter) AdoptProcessByPort(portNum int) (*ManagedProcess, error) {
 	// Get port information
@@ -93,6 +165,10 @@ func (pa *ProcessAdopter) AdoptProcessByPort(portNum int) (*ManagedProcess, erro
 	// Adopt the process by PID
 	managedProcess, err := pa.AdoptProcessByPID(portInfo.PID)
 	if err != nil {
+		var notSuitable *NotSuitableError
+		if errors.As(err, &notSuitable) {
+			notSuitable.Info.Port = portNum
+		}
 		return nil, err
 	}
 
@@ -100,6 +176,9 @@ func (pa *ProcessAdopter) AdoptProcessByPort(portNum int) (*ManagedProcess, erro
 	if managedProcess.Config.Port == 0 {
 		managedProcess.Config.Port = portNum
 	}
+	if managedProcess.Port == 0 {
+		managedProcess.Port = portNum
+	}
 
 	return managedProcess, nil
 }
@@ -139,8 +218,8 @@ func (pa *ProcessAdopter) GetProcessInfo(pid int) (*AdoptionInfo, error) {
 		IsSuitable: false,
 	}
 
-	// Get process name and command
-	processName, command, err := pa.scanner.GetProcessInfoByPID(pid)
+	// Get process name and full command line; the name alone may be truncated by the OS
+	processName, command, err := pa.lookupProcessInfo(pid)
 	if err != nil {
 		info.Reason = fmt.Sprintf("failed to get process info: %v", err)
 		return info, nil
@@ -154,6 +233,9 @@ func (pa *ProcessAdopter) GetProcessInfo(pid int) (*AdoptionInfo, error) {
 		info.WorkingDir = workingDir
 	}
 
+	// Without permission to read the environment only env filters are affected
+	info.Environment, info.environmentErr = pa.lookupEnvironment(pid)
+
 	// Evaluate if process is suitable for adoption
 	info.IsSuitable, info.Reason = pa.evaluateProcessSuitability(info)
 
@@ -240,6 +322,17 @@ func (pa *ProcessAdopter) evaluateProcessSuitability(info *AdoptionInfo) (bool,
 		return false, "system process (low PID)"
 	}
 
+	if len(pa.envFilters) > 0 {
+		if info.Environment == nil {
+			return false, fmt.Sprintf("environment not readable: %v", info.environmentErr)
+		}
+		for _, filter := range pa.envFilters {
+			if !filter.Matches(info.Environment) {
+				return false, "environment does not match " + filter.String()
+			}
+		}
+	}
+
 	// Check process name against development server patterns
 	devPatterns := []string{
 		"node", "npm", "yarn", "pnpm", "webpack", "vite", "next",
@@ -304,6 +397,8 @@ func (pa *ProcessAdopter) createManagedProcessFromAdoption(info *AdoptionInfo) (
 	// Create managed process
 	managedProcess := &ManagedProcess{
 		Config:     config,
+		Command:    info.Command,
+		Port:       info.Port,
 		PID:        info.PID,
 		Status:     StatusRunning,
 		StartedAt:  time.Now(), // We don't know the actual start time, use adoption time
@@ -3,7 +3,9 @@ package process
 import (
 	"encoding/json"
 	"os"
+	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -493,3 +495,144 @@ func TestAdoptionInfoStructure(t *testing.T) {
 		assert.NotContains(t, jsonString, "reason")
 	})
 }
+
+func TestGetProcessInfo_TruncatedProcessName(t *testing.T) {
+	testCases := []struct {
+		name             string
+		processName      string
+		command          string
+		expectedSuitable bool
+	}{
+		{
+			// /proc/<pid>/comm keeps only 15 characters of a wrapper's name
+			name:             "full_cmdline_reveals_dev_server",
+			processName:      "launchd-wrapper",
+			command:          "/opt/tools/launchd-wrapper-service npm run dev",
+			expectedSuitable: true,
+		},
+		{
+			name:             "truncated_name_alone_is_not_enough",
+			processName:      "launchd-wrapper",
+			command:          "launchd-wrapper",
+			expectedSuitable: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			adopter := NewProcessAdopter(2 * time.Second)
+			adopter.lookupProcessInfo = func(int) (string, string, error) {
+				return tc.processName, tc.command, nil
+			}
+
+			info, err := adopter.GetProcessInfo(5000)
+			require.NoError(t, err)
+			assert.Equal(t, tc.processName, info.ProcessName)
+			assert.Equal(t, tc.command, info.Command)
+			assert.Equal(t, tc.expectedSuitable, info.IsSuitable)
+		})
+	}
+}
+
+func TestAdoptProcessByPIDFile(t *testing.T) {
+	writePIDFile := func(t *testing.T, contents string) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "app.pid")
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+		return path
+	}
+
+	newAdopter := func() *ProcessAdopter {
+		adopter := NewProcessAdopter(2 * time.Second)
+		adopter.lookupProcessInfo = func(int) (string, string, error) {
+			return "node", "node server.js", nil
+		}
+		return adopter
+	}
+
+	t.Run("current_process", func(t *testing.T) {
+		pid := os.Getpid()
+		if pid < 1000 {
+			t.Skip("low PIDs are refused as system processes")
+		}
+		path := writePIDFile(t, strconv.Itoa(pid)+"\n")
+
+		managed, err := newAdopter().AdoptProcessByPIDFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, pid, managed.PID)
+		assert.True(t, managed.IsExternal)
+	})
+
+	t.Run("missing_file", func(t *testing.T) {
+		_, err := newAdopter().AdoptProcessByPIDFile(filepath.Join(t.TempDir(), "missing.pid"))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("non_numeric", func(t *testing.T) {
+		_, err := newAdopter().AdoptProcessByPIDFile(writePIDFile(t, "not-a-pid\n"))
+		require.ErrorIs(t, err, ErrInvalidPIDFile)
+		assert.Contains(t, err.Error(), "not-a-pid")
+	})
+
+	t.Run("dead_pid", func(t *testing.T) {
+		_, err := newAdopter().AdoptProcessByPIDFile(writePIDFile(t, "999999\n"))
+		require.ErrorIs(t, err, ErrProcessAlreadyDead)
+		assert.Contains(t, err.Error(), "999999")
+	})
+}
+
+func TestReadPIDFile(t *testing.T) {
+	testCases := []struct {
+		name     string
+		contents string
+		expected int
+		wantErr  bool
+	}{
+		{name: "plain", contents: "4242", expected: 4242},
+		{name: "trailing_newline", contents: "4242\n", expected: 4242},
+		{name: "surrounding_whitespace", contents: "  4242 \r\n", expected: 4242},
+		{name: "extra_lines", contents: "4242\n/var/lib/app\n", expected: 4242},
+		{name: "empty", contents: "", wantErr: true},
+		{name: "zero", contents: "0\n", wantErr: true},
+		{name: "negative", contents: "-1\n", wantErr: true},
+		{name: "text", contents: "pid=4242\n", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.pid")
+			require.NoError(t, os.WriteFile(path, []byte(tc.contents), 0o600))
+
+			pid, err := ReadPIDFile(path)
+			if tc.wantErr {
+				require.ErrorIs(t, err, ErrInvalidPIDFile)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, pid)
+		})
+	}
+}
+
+func TestAdoptProcessByPID_NotSuitableError(t *testing.T) {
+	pid := os.Getpid()
+	if pid < 1000 {
+		t.Skip("low PIDs are refused before the name is checked")
+	}
+
+	adopter := NewProcessAdopter(2 * time.Second)
+	adopter.lookupProcessInfo = func(int) (string, string, error) {
+		return "postgres", "/usr/lib/postgresql/bin/postgres", nil
+	}
+
+	_, err := adopter.AdoptProcessByPID(pid)
+	require.ErrorIs(t, err, ErrProcessNotSuitable)
+
+	var notSuitable *NotSuitableError
+	require.ErrorAs(t, err, &notSuitable)
+	assert.Equal(t, pid, notSuitable.Info.PID)
+	assert.Equal(t, "postgres", notSuitable.Info.ProcessName)
+	assert.False(t, notSuitable.Info.IsSuitable)
+	assert.Equal(t, "not a recognized development server", notSuitable.Info.Reason)
+	assert.Contains(t, err.Error(), "not a recognized development server")
+}
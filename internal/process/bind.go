@@ -0,0 +1,116 @@
+package process
+
+import (
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// HostPlaceholder is substituted with StartOptions.BindAddress in commands, environment
+// values and health check targets
+const HostPlaceholder = "{host}"
+
+// bindHostFlags maps dev servers to the flag that selects their listen address
+var bindHostFlags = map[string]string{
+	"astro":   "--host",
+	"flask":   "--host",
+	"hugo":    "--bind",
+	"next":    "--hostname",
+	"rails":   "--binding",
+	"uvicorn": "--host",
+	"vite":    "--host",
+}
+
+// packageRunners run the tool named by their first argument
+var packageRunners = map[string]bool{"npx": true, "bunx": true, "pnpx": true}
+
+// applyBindAddress returns copies of the command, args and options that make the process
+// listen on options.BindAddress. HostPlaceholder is replaced wherever it appears; without a
+// placeholder in the command line, known dev servers get their host flag appended.
+// An empty BindAddress leaves everything to the server's own default.
+func applyBindAddress(command string, args []string, options StartOptions) (string, []string, StartOptions, error) {
+	host := options.BindAddress
+	if host == "" {
+		return command, args, options, nil
+	}
+
+	placeholder := strings.Contains(command, HostPlaceholder)
+	expandedArgs := make([]string, 0, len(args)+2)
+	for _, arg := range args {
+		placeholder = placeholder || strings.Contains(arg, HostPlaceholder)
+		expandedArgs = append(expandedArgs, strings.ReplaceAll(arg, HostPlaceholder, host))
+	}
+	command = strings.ReplaceAll(command, HostPlaceholder, host)
+
+	if options.Environment != nil {
+		env := make(map[string]string, len(options.Environment))
+		for key, value := range options.Environment {
+			env[key] = strings.ReplaceAll(value, HostPlaceholder, host)
+		}
+		options.Environment = env
+	}
+	if options.HealthCheck != nil {
+		healthCheck := *options.HealthCheck
+		healthCheck.Target = strings.ReplaceAll(healthCheck.Target, HostPlaceholder, host)
+		options.HealthCheck = &healthCheck
+	}
+
+	if placeholder {
+		return command, expandedArgs, options, nil
+	}
+
+	// The flag goes after the whole command line, so split a command given as one string
+	if len(expandedArgs) == 0 {
+		parts, err := SplitCommandLine(command)
+		if err != nil {
+			return "", nil, options, err
+		}
+		if len(parts) > 0 {
+			command, expandedArgs = parts[0], parts[1:]
+		}
+	}
+	if flag := bindHostFlag(command, expandedArgs); flag != "" && !hasFlag(expandedArgs, flag) {
+		expandedArgs = append(expandedArgs, flag, host)
+	}
+	if len(expandedArgs) == 0 {
+		expandedArgs = args
+	}
+	return command, expandedArgs, options, nil
+}
+
+// bindHostFlag returns the host flag of the dev server run by the command, or "" if unknown
+func bindHostFlag(command string, args []string) string {
+	tool := filepath.Base(command)
+	if packageRunners[tool] && len(args) > 0 {
+		tool = filepath.Base(args[0])
+	}
+	return bindHostFlags[tool]
+}
+
+// hasFlag reports whether args already set flag, as "--flag value" or "--flag=value"
+func hasFlag(args []string, flag string) bool {
+	for _, arg := range args {
+		if arg == flag || strings.HasPrefix(arg, flag+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultTCPTarget is the address a TCP health check without a target dials: the bind
+// address, or loopback when the process listens on all interfaces or its default
+func defaultTCPTarget(bindAddress string, portNum int) string {
+	host := bindAddress
+	switch ip := net.ParseIP(strings.Trim(bindAddress, "[]")); {
+	case bindAddress == "":
+		host = "localhost"
+	case ip != nil && ip.IsUnspecified() && ip.To4() != nil:
+		host = "127.0.0.1"
+	case ip != nil && ip.IsUnspecified():
+		host = "::1"
+	case ip != nil:
+		host = ip.String()
+	}
+	return net.JoinHostPort(host, strconv.Itoa(portNum))
+}
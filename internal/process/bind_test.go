@@ -0,0 +1,190 @@
+package process
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/paveg/portguard/internal/port"
+)
+
+func TestApplyBindAddress(t *testing.T) {
+	tests := []struct {
+		name         string
+		command      string
+		args         []string
+		bindAddress  string
+		expectedCmd  string
+		expectedArgs []string
+	}{
+		{
+			name:         "unset_keeps_framework_default",
+			command:      "vite",
+			args:         []string{"--port", "5173"},
+			expectedCmd:  "vite",
+			expectedArgs: []string{"--port", "5173"},
+		},
+		{
+			name:         "placeholder",
+			command:      "python3",
+			args:         []string{"-m", "http.server", "--bind", "{host}"},
+			bindAddress:  "127.0.0.1",
+			expectedCmd:  "python3",
+			expectedArgs: []string{"-m", "http.server", "--bind", "127.0.0.1"},
+		},
+		{
+			name:         "known_framework_gets_flag",
+			command:      "vite",
+			args:         []string{"--port", "5173"},
+			bindAddress:  "0.0.0.0",
+			expectedCmd:  "vite",
+			expectedArgs: []string{"--port", "5173", "--host", "0.0.0.0"},
+		},
+		{
+			name:         "command_line_in_one_string",
+			command:      "next dev",
+			bindAddress:  "127.0.0.1",
+			expectedCmd:  "next",
+			expectedArgs: []string{"dev", "--hostname", "127.0.0.1"},
+		},
+		{
+			name:         "package_runner",
+			command:      "npx",
+			args:         []string{"astro", "dev"},
+			bindAddress:  "::1",
+			expectedCmd:  "npx",
+			expectedArgs: []string{"astro", "dev", "--host", "::1"},
+		},
+		{
+			name:         "explicit_flag_wins",
+			command:      "uvicorn",
+			args:         []string{"app:app", "--host=10.0.0.5"},
+			bindAddress:  "127.0.0.1",
+			expectedCmd:  "uvicorn",
+			expectedArgs: []string{"app:app", "--host=10.0.0.5"},
+		},
+		{
+			name:         "unknown_command_untouched",
+			command:      "npm",
+			args:         []string{"run", "dev"},
+			bindAddress:  "127.0.0.1",
+			expectedCmd:  "npm",
+			expectedArgs: []string{"run", "dev"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, args, _, err := applyBindAddress(tt.command, tt.args, StartOptions{BindAddress: tt.bindAddress})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedCmd, command)
+			assert.Equal(t, tt.expectedArgs, args)
+		})
+	}
+
+	t.Run("expands_environment_and_health_check", func(t *testing.T) {
+		_, _, options, err := applyBindAddress("server", nil, StartOptions{
+			BindAddress: "127.0.0.2",
+			Environment: map[string]string{"HOST": "{host}"},
+			HealthCheck: &HealthCheck{Type: HealthCheckHTTP, Target: "http://{host}:3000/health"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "127.0.0.2", options.Environment["HOST"])
+		assert.Equal(t, "http://127.0.0.2:3000/health", options.HealthCheck.Target)
+	})
+}
+
+func TestDefaultTCPTarget(t *testing.T) {
+	tests := []struct {
+		bindAddress string
+		expected    string
+	}{
+		{bindAddress: "", expected: "localhost:3000"},
+		{bindAddress: "127.0.0.2", expected: "127.0.0.2:3000"},
+		{bindAddress: "0.0.0.0", expected: "127.0.0.1:3000"},
+		{bindAddress: "::", expected: "[::1]:3000"},
+		{bindAddress: "[fe80::1]", expected: "[fe80::1]:3000"},
+		{bindAddress: "devbox.local", expected: "devbox.local:3000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.bindAddress, func(t *testing.T) {
+			assert.Equal(t, tt.expected, defaultTCPTarget(tt.bindAddress, 3000))
+		})
+	}
+}
+
+func TestProcessManager_StartProcess_BindAddress(t *testing.T) {
+	pm, executor := setupFakeExecutorManager(t)
+	pm.SetMonitoringDisabled(true)
+
+	proc, err := pm.StartProcess("vite", nil, StartOptions{BindAddress: "127.0.0.1"})
+	require.NoError(t, err)
+
+	require.Equal(t, 1, executor.startCount())
+	assert.Equal(t, []string{"--host", "127.0.0.1"}, executor.starts[0].Args)
+	assert.Equal(t, "127.0.0.1", proc.BindAddress)
+}
+
+func TestProcessManager_DecideStart_BindAddress(t *testing.T) {
+	tests := []struct {
+		name        string
+		bindAddress string
+		expected    StartDecisionKind
+	}{
+		{name: "other_interface_is_free", bindAddress: "127.0.0.2", expected: DecisionStartNew},
+		{name: "same_interface_conflicts", bindAddress: "127.0.0.1", expected: DecisionConflictExternal},
+		{name: "wildcard_conflicts", bindAddress: "0.0.0.0", expected: DecisionConflictExternal},
+		{name: "unset_conflicts", expected: DecisionConflictExternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pm, _, _, mockPortScanner := setupTestProcessManager(t)
+			mockPortScanner.On("IsPortInUse", 3000).Return(true)
+			mockPortScanner.On("GetPortInfo", 3000).
+				Return(&port.PortInfo{Port: 3000, BindAddress: "127.0.0.1"}, nil).Maybe()
+
+			decision := pm.decideStart("server", "", tt.bindAddress, []int{3000})
+			assert.Equal(t, tt.expected, decision.Kind)
+		})
+	}
+}
+
+func TestProcessManager_PerformTCPHealthCheck_DefaultTarget(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+	portNum := listener.Addr().(*net.TCPAddr).Port
+
+	pm, _, _, _ := setupTestProcessManager(t)
+	for _, bindAddress := range []string{"127.0.0.1", "0.0.0.0"} {
+		t.Run(bindAddress, func(t *testing.T) {
+			proc := &ManagedProcess{
+				ID:          "bound",
+				Port:        portNum,
+				BindAddress: bindAddress,
+				HealthCheck: &HealthCheck{Type: HealthCheckTCP, Enabled: true},
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			require.NoError(t, pm.performTCPHealthCheck(ctx, proc))
+		})
+	}
+
+	t.Run("other_interface_fails", func(t *testing.T) {
+		proc := &ManagedProcess{
+			ID:          "bound",
+			Port:        portNum,
+			BindAddress: "127.0.0.2",
+			HealthCheck: &HealthCheck{Type: HealthCheckTCP, Enabled: true},
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		require.Error(t, pm.performTCPHealthCheck(ctx, proc))
+	})
+}
@@ -0,0 +1,66 @@
+package process
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// PortConflictError reports the process holding a port that a start needed. It matches
+// ErrPortAlreadyInUse with errors.Is; callers use errors.As to build precise remediation.
+type PortConflictError struct {
+	Port        int    `json:"port"`
+	PID         int    `json:"pid,omitempty"`          // Holder's PID; 0 if the scanner could not tell
+	ProcessName string `json:"process_name,omitempty"` // Holder's executable name; empty if unknown
+	Managed     bool   `json:"managed"`                // Whether the holder is a portguard managed process
+	ProcessID   string `json:"process_id,omitempty"`   // Managed process ID, set when Managed
+}
+
+// Error describes the conflict, naming the holder when it is known
+func (e *PortConflictError) Error() string {
+	switch {
+	case e.Managed:
+		return fmt.Sprintf("%v: %d (held by managed process %s)", ErrPortAlreadyInUse, e.Port, e.ProcessID)
+	case e.PID > 0 && e.ProcessName != "":
+		return fmt.Sprintf("%v: %d (held by %s, PID %d)", ErrPortAlreadyInUse, e.Port, e.ProcessName, e.PID)
+	case e.PID > 0:
+		return fmt.Sprintf("%v: %d (held by PID %d)", ErrPortAlreadyInUse, e.Port, e.PID)
+	default:
+		return fmt.Sprintf("%v: %d", ErrPortAlreadyInUse, e.Port)
+	}
+}
+
+// Unwrap makes errors.Is(err, ErrPortAlreadyInUse) hold
+func (e *PortConflictError) Unwrap() error {
+	return ErrPortAlreadyInUse
+}
+
+// PortConflict describes the holder of the port in a conflict decision. External holders are
+// looked up with the port scanner; nil is returned for decisions that are not conflicts.
+func (pm *ProcessManager) PortConflict(decision StartDecision) *PortConflictError {
+	switch decision.Kind {
+	case DecisionConflictManaged:
+		pm.mutex.RLock()
+		defer pm.mutex.RUnlock()
+		conflict := &PortConflictError{
+			Port:      decision.Port,
+			PID:       decision.Process.PID,
+			Managed:   true,
+			ProcessID: decision.Process.ID,
+		}
+		if fields := strings.Fields(decision.Process.Command); len(fields) > 0 {
+			conflict.ProcessName = filepath.Base(fields[0])
+		}
+		return conflict
+	case DecisionConflictExternal:
+		conflict := &PortConflictError{Port: decision.Port}
+		if info, err := pm.portScanner.GetPortInfo(decision.Port); err == nil && info != nil {
+			conflict.PID = info.PID
+			conflict.ProcessName = info.ProcessName
+		}
+		return conflict
+	case DecisionStartNew, DecisionReuse:
+		return nil
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package process
 import (
 	"context"
 	"fmt"
+	"os"
 	"testing"
 	"time"
 
@@ -90,12 +91,37 @@ func TestProcessManager_ExecuteProcess(t *testing.T) {
 	}
 }
 
+// TestProcessManager_ExecuteProcess_BackgroundSurvivesReturn guards against tying the child
+// to a context cancelled when executeProcess returns, which used to kill servers after 30s
+func TestProcessManager_ExecuteProcess_BackgroundSurvivesReturn(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping long-running background process test in short mode")
+	}
+	t.Parallel()
+
+	pm, _, _, _ := setupTestProcessManager(t)
+	proc, err := pm.executeProcess("sleep", []string{"60"}, StartOptions{Background: true})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = pm.terminateProcess(proc, true) }) //nolint:errcheck // Best effort cleanup
+
+	select {
+	case exitErr := <-proc.runtime.exited:
+		t.Fatalf("background process exited early: %v", exitErr)
+	case <-time.After(35 * time.Second):
+	}
+
+	osProcess, err := os.FindProcess(proc.PID)
+	require.NoError(t, err)
+	assert.True(t, isProcessAlive(osProcess), "PID %d should still be alive 35s after start", proc.PID)
+}
+
 // TestProcessManager_FindSimilarProcess tests enhanced duplicate detection
 func TestProcessManager_FindSimilarProcess(t *testing.T) {
 	tests := []struct {
 		name           string
 		existingProcs  []*ManagedProcess
 		searchCommand  string
+		searchDir      string
 		expectMatch    bool
 		expectedProcID string
 	}{
@@ -134,6 +160,26 @@ func TestProcessManager_FindSimilarProcess(t *testing.T) {
 			searchCommand: "npm run dev",
 			expectMatch:   false, // Stopped process should not be reused
 		},
+		{
+			name: "same_command_other_directory_no_match",
+			existingProcs: []*ManagedProcess{
+				withWorkingDir(createTestProcess("proc1", "npm run dev", 3000, StatusRunning), "/work/project-a"),
+			},
+			searchCommand: "npm run dev",
+			searchDir:     "/work/project-b",
+			expectMatch:   false, // Would serve another project's app
+		},
+		{
+			name: "same_command_same_directory",
+			existingProcs: []*ManagedProcess{
+				withWorkingDir(createTestProcess("proc1", "npm run dev", 3000, StatusRunning), "/work/project-a"),
+				withWorkingDir(createTestProcess("proc2", "npm run dev", 3001, StatusRunning), "/work/project-b"),
+			},
+			searchCommand:  "npm run dev",
+			searchDir:      "/work/project-b/",
+			expectMatch:    true,
+			expectedProcID: "proc2",
+		},
 		{
 			name: "multiple_matches_return_newest",
 			existingProcs: []*ManagedProcess{
@@ -167,7 +213,7 @@ func TestProcessManager_FindSimilarProcess(t *testing.T) {
 				pm.processes[proc.ID] = proc
 			}
 
-			matchedProcess, found := pm.findSimilarProcess(tt.searchCommand)
+			matchedProcess, found := pm.findSimilarProcess(tt.searchCommand, tt.searchDir)
 
 			assert.Equal(t, tt.expectMatch, found)
 			if tt.expectMatch {
@@ -259,6 +305,120 @@ func TestProcessManager_MonitorProcess(t *testing.T) {
 	}
 }
 
+// TestProcessManager_RestartPolicy tests crash restarts driven by the background monitor
+func TestProcessManager_RestartPolicy(t *testing.T) {
+	tests := []struct {
+		name          string
+		command       string
+		policy        RestartPolicy
+		maxRestarts   int
+		expectRestart int
+		expectStatus  ProcessStatus
+	}{
+		{
+			name:          "on_failure_stops_at_max_restarts",
+			command:       "false",
+			policy:        RestartOnFailure,
+			maxRestarts:   2,
+			expectRestart: 2,
+			expectStatus:  StatusFailed,
+		},
+		{
+			name:          "on_failure_ignores_clean_exit",
+			command:       "true",
+			policy:        RestartOnFailure,
+			maxRestarts:   2,
+			expectRestart: 0,
+			expectStatus:  StatusStopped,
+		},
+		{
+			name:          "always_restarts_clean_exit",
+			command:       "true",
+			policy:        RestartAlways,
+			maxRestarts:   1,
+			expectRestart: 1,
+			expectStatus:  StatusFailed,
+		},
+		{
+			name:          "never_restarts",
+			command:       "false",
+			policy:        RestartNever,
+			expectRestart: 0,
+			expectStatus:  StatusStopped,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pm, mockStateStore, mockLockManager, _ := setupTestProcessManager(t)
+			mockLockManager.On("Lock").Return(nil)
+			mockLockManager.On("Unlock").Return(nil)
+			mockStateStore.On("Save", mock.AnythingOfType("map[string]*process.ManagedProcess")).Return(nil)
+
+			proc, err := pm.StartProcess(tt.command, nil, StartOptions{
+				RestartPolicy: tt.policy,
+				MaxRestarts:   tt.maxRestarts,
+			})
+			require.NoError(t, err)
+
+			require.Eventually(t, func() bool {
+				pm.mutex.RLock()
+				defer pm.mutex.RUnlock()
+				return proc.Status == tt.expectStatus && proc.RestartCount == tt.expectRestart
+			}, 5*time.Second, 20*time.Millisecond)
+		})
+	}
+
+	t.Run("stop_does_not_trigger_restart", func(t *testing.T) {
+		pm, mockStateStore, mockLockManager, _ := setupTestProcessManager(t)
+		mockLockManager.On("Lock").Return(nil)
+		mockLockManager.On("Unlock").Return(nil)
+		mockStateStore.On("Save", mock.AnythingOfType("map[string]*process.ManagedProcess")).Return(nil)
+		mockStateStore.On("Load").Return(nil, os.ErrNotExist)
+
+		proc, err := pm.StartProcess("sleep", []string{"5"}, StartOptions{RestartPolicy: RestartAlways})
+		require.NoError(t, err)
+		require.NoError(t, pm.StopProcess(proc.ID, true))
+
+		// Give the monitor time to observe the exit
+		time.Sleep(200 * time.Millisecond)
+
+		pm.mutex.RLock()
+		defer pm.mutex.RUnlock()
+		assert.Equal(t, StatusStopped, proc.Status)
+		assert.Equal(t, 0, proc.RestartCount)
+	})
+
+	t.Run("records_exit_code", func(t *testing.T) {
+		pm, mockStateStore, mockLockManager, _ := setupTestProcessManager(t)
+		mockLockManager.On("Lock").Return(nil)
+		mockLockManager.On("Unlock").Return(nil)
+		mockStateStore.On("Save", mock.AnythingOfType("map[string]*process.ManagedProcess")).Return(nil)
+
+		proc, err := pm.StartProcess("sh", []string{"-c", "exit 3"}, StartOptions{RestartPolicy: RestartNever})
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			pm.mutex.RLock()
+			defer pm.mutex.RUnlock()
+			return proc.Status == StatusStopped
+		}, 5*time.Second, 20*time.Millisecond)
+
+		pm.mutex.RLock()
+		defer pm.mutex.RUnlock()
+		require.NotNil(t, proc.ExitCode)
+		assert.Equal(t, 3, *proc.ExitCode)
+		assert.Equal(t, "exit status 3", proc.ExitReason)
+	})
+
+	t.Run("rejects_unknown_policy", func(t *testing.T) {
+		pm, _, _, _ := setupTestProcessManager(t)
+
+		_, err := pm.StartProcess("true", nil, StartOptions{RestartPolicy: "sometimes"})
+		require.ErrorIs(t, err, ErrInvalidRestart)
+	})
+}
+
 // TestProcessManager_TerminateProcess tests process termination
 func TestProcessManager_TerminateProcess(t *testing.T) {
 	if testing.Short() {
@@ -380,43 +540,70 @@ func TestProcessManager_UpdateProcessStatus(t *testing.T) {
 
 // TestProcessManager_CleanupStaleProcesses tests cleanup of stale processes
 func TestProcessManager_CleanupStaleProcesses(t *testing.T) {
-	pm, mockStateStore, _, _ := setupTestProcessManager(t)
+	pm, mockStateStore, mockLockManager, _ := setupTestProcessManager(t)
+	executor := newFakeExecutor()
+	pm.SetExecutor(executor)
 
 	// Create processes with different states
 	runningProcess := createTestProcess("running", "npm start", 3000, StatusRunning)
 	runningProcess.LastSeen = time.Now() // Recent
 
-	staleProcess := createTestProcess("stale", "old process", 3001, StatusRunning)
-	staleProcess.LastSeen = time.Now().Add(-10 * time.Minute) // Stale
+	// Stale, but still running: only monitors advance LastSeen, so it must stay
+	idlePID, err := executor.Start(ExecSpec{Command: "npm", Args: []string{"run", "dev"}})
+	require.NoError(t, err)
+	idleProcess := createTestProcess("idle", "npm run dev", 3003, StatusRunning)
+	idleProcess.PID = idlePID
+	idleProcess.LastSeen = time.Now().Add(-10 * time.Minute)
+
+	deadProcess := createTestProcess("dead", "old process", 3001, StatusRunning)
+	deadProcess.PID = idlePID + 1 // Never started, so not alive
+	deadProcess.LastSeen = time.Now().Add(-10 * time.Minute)
 
 	stoppedProcess := createTestProcess("stopped", "finished", 3002, StatusStopped)
 
-	pm.processes["running"] = runningProcess
-	pm.processes["stale"] = staleProcess
-	pm.processes["stopped"] = stoppedProcess
+	oldStoppedProcess := createTestProcess("old-stopped", "finished long ago", 3004, StatusStopped)
+	oldStoppedProcess.LastSeen = time.Now().Add(-10 * time.Minute)
+
+	protectedProcess := createTestProcess("protected", "postgres -D data", 5432, StatusStopped)
+	protectedProcess.LastSeen = time.Now().Add(-10 * time.Minute) // Stale, but protected
+	protectedProcess.Protected = true
+
+	// The entries only exist in the stored state, so cleanup must reload it first
+	stored := map[string]*ManagedProcess{
+		"running":     runningProcess,
+		"idle":        idleProcess,
+		"dead":        deadProcess,
+		"stopped":     stoppedProcess,
+		"old-stopped": oldStoppedProcess,
+		"protected":   protectedProcess,
+	}
 
 	// Setup mock
+	mockLockManager.On("Lock").Return(nil).Once()
+	mockLockManager.On("Unlock").Return(nil).Once()
+	mockStateStore.On("Load").Return(stored, nil)
+	mockStateStore.On("BackupState").Return(nil)
 	mockStateStore.On("Save", mock.AnythingOfType("map[string]*process.ManagedProcess")).Return(nil)
 
 	// Cleanup stale processes
 	cleaned, err := pm.cleanupStaleProcesses(5 * time.Minute)
 	require.NoError(t, err)
 
-	// Should have cleaned up the stale process
-	assert.Equal(t, 1, cleaned)
-	assert.Len(t, pm.processes, 2) // running and stopped should remain
-
-	// Verify the stale process was removed
-	_, exists := pm.GetProcess("stale")
-	assert.False(t, exists)
+	// Should have cleaned up the dead and the old stopped process
+	assert.Equal(t, 2, cleaned)
+	assert.Len(t, pm.processes, 4)
 
-	// Verify other processes remain
-	_, exists = pm.GetProcess("running")
-	assert.True(t, exists)
-	_, exists = pm.GetProcess("stopped")
-	assert.True(t, exists)
+	for _, id := range []string{"dead", "old-stopped"} {
+		_, exists := pm.GetProcess(id)
+		assert.False(t, exists, "%s should be removed", id)
+	}
+	for _, id := range []string{"running", "idle", "stopped", "protected"} {
+		_, exists := pm.GetProcess(id)
+		assert.True(t, exists, "%s should remain", id)
+	}
 
 	mockStateStore.AssertExpectations(t)
+	mockLockManager.AssertExpectations(t)
 }
 
 // TestProcessManager_EnhancedPortConflictResolution tests enhanced port management
@@ -504,3 +691,9 @@ func TestProcessManager_EnhancedPortConflictResolution(t *testing.T) {
 		})
 	}
 }
+
+// withWorkingDir sets the working directory of a test process
+func withWorkingDir(process *ManagedProcess, dir string) *ManagedProcess {
+	process.WorkingDir = dir
+	return process
+}
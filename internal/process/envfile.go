@@ -0,0 +1,114 @@
+package process
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ErrEnvFileSyntax is returned when a .env file line cannot be parsed
+var ErrEnvFileSyntax = errors.New("invalid env file syntax")
+
+// LoadEnvFile reads KEY=VALUE pairs from a .env file
+func LoadEnvFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open env file %s: %w", path, err)
+	}
+	defer func() { _ = file.Close() }() //nolint:errcheck // Read-only file
+
+	env, err := parseEnvFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("env file %s: %w", path, err)
+	}
+	return env, nil
+}
+
+// parseEnvFile parses .env content: blank lines and # comments are skipped, an optional
+// "export " prefix is allowed, and values may be single-quoted (literal), double-quoted
+// (with \n, \t, \" and \\ escapes) or bare (trimmed, with trailing " #" comments removed).
+func parseEnvFile(r io.Reader) (map[string]string, error) {
+	env := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, rawValue, found := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !found || !isEnvKey(key) {
+			return nil, fmt.Errorf("%w: line %d: expected KEY=VALUE", ErrEnvFileSyntax, lineNum)
+		}
+
+		value, err := parseEnvValue(strings.TrimSpace(rawValue))
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrEnvFileSyntax, lineNum, err)
+		}
+		env[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read env file: %w", err)
+	}
+	return env, nil
+}
+
+// parseEnvValue decodes a single value, handling quotes and inline comments
+func parseEnvValue(raw string) (string, error) {
+	if raw == "" {
+		return "", nil
+	}
+
+	quote := raw[0]
+	if quote != '"' && quote != '\'' {
+		if idx := strings.Index(raw, " #"); idx >= 0 {
+			raw = raw[:idx]
+		}
+		return strings.TrimSpace(raw), nil
+	}
+
+	var value strings.Builder
+	for i := 1; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case c == quote:
+			rest := strings.TrimSpace(raw[i+1:])
+			if rest != "" && !strings.HasPrefix(rest, "#") {
+				return "", fmt.Errorf("unexpected text after closing quote: %q", rest)
+			}
+			return value.String(), nil
+		case c == '\\' && quote == '"' && i+1 < len(raw):
+			i++
+			switch raw[i] {
+			case 'n':
+				value.WriteByte('\n')
+			case 't':
+				value.WriteByte('\t')
+			default:
+				value.WriteByte(raw[i])
+			}
+		default:
+			value.WriteByte(c)
+		}
+	}
+	return "", fmt.Errorf("unterminated %c quote", quote)
+}
+
+// isEnvKey reports whether key is a valid environment variable name
+func isEnvKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i, r := range key {
+		isLetter := (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') || r == '_'
+		if !isLetter && (i == 0 || r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,137 @@
+package process
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEnvFile(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		expected    map[string]string
+		expectError bool
+	}{
+		{
+			name: "comments_and_blank_lines",
+			content: `# database settings
+
+DB_HOST=localhost
+  # indented comment
+DB_PORT=5432 # inline comment
+`,
+			expected: map[string]string{"DB_HOST": "localhost", "DB_PORT": "5432"},
+		},
+		{
+			name: "double_quoted_values",
+			content: `GREETING="hello world"
+MULTILINE="line1\nline2"
+ESCAPED="say \"hi\" # not a comment"
+`,
+			expected: map[string]string{
+				"GREETING":  "hello world",
+				"MULTILINE": "line1\nline2",
+				"ESCAPED":   `say "hi" # not a comment`,
+			},
+		},
+		{
+			name:     "single_quoted_values_are_literal",
+			content:  `PATTERN='a\nb $HOME' # comment`,
+			expected: map[string]string{"PATTERN": `a\nb $HOME`},
+		},
+		{
+			name:     "export_prefix_and_empty_value",
+			content:  "export API_URL=http://localhost:3000/api?x=1\nEMPTY=\n",
+			expected: map[string]string{"API_URL": "http://localhost:3000/api?x=1", "EMPTY": ""},
+		},
+		{
+			name:        "missing_equals",
+			content:     "JUST_A_KEY\n",
+			expectError: true,
+		},
+		{
+			name:        "invalid_key",
+			content:     "1BAD=value\n",
+			expectError: true,
+		},
+		{
+			name:        "unterminated_quote",
+			content:     `TOKEN="abc`,
+			expectError: true,
+		},
+		{
+			name:        "text_after_closing_quote",
+			content:     `TOKEN="abc" def`,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := parseEnvFile(strings.NewReader(tt.content))
+			if tt.expectError {
+				require.ErrorIs(t, err, ErrEnvFileSyntax)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, env)
+		})
+	}
+}
+
+func TestLoadEnvFile(t *testing.T) {
+	t.Run("missing_file", func(t *testing.T) {
+		_, err := LoadEnvFile(filepath.Join(t.TempDir(), ".env"))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("reports_line_number", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), ".env")
+		require.NoError(t, os.WriteFile(path, []byte("OK=1\nbroken line\n"), 0o600))
+
+		_, err := LoadEnvFile(path)
+		require.ErrorIs(t, err, ErrEnvFileSyntax)
+		assert.Contains(t, err.Error(), "line 2")
+	})
+}
+
+func TestResolveEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".env"), []byte("PORT=3000\nNODE_ENV=development\n"), 0o600))
+
+	env, err := resolveEnvironment(StartOptions{
+		EnvFile:     ".env",
+		WorkingDir:  dir,
+		Environment: map[string]string{"NODE_ENV": "test"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"PORT": "3000", "NODE_ENV": "test"}, env, "explicit entries take precedence")
+
+	_, err = resolveEnvironment(StartOptions{EnvFile: "missing.env", WorkingDir: dir})
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestProcessManager_ExecuteProcess_EnvFile(t *testing.T) {
+	pm, _, _, _ := setupTestProcessManager(t)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".env"), []byte(`GREETING="from env file"`+"\n"), 0o600))
+	output := filepath.Join(dir, "out.txt")
+
+	proc, err := pm.executeProcess("sh", []string{"-c", `printf '%s' "$GREETING" > out.txt`}, StartOptions{
+		EnvFile:    ".env",
+		WorkingDir: dir,
+	})
+	require.NoError(t, err)
+	require.NoError(t, <-proc.runtime.exited)
+
+	data, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Equal(t, "from env file", string(data))
+	assert.Empty(t, proc.Environment, "env file values are not persisted with the process")
+	assert.Equal(t, ".env", proc.EnvFile)
+}
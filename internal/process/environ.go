@@ -0,0 +1,127 @@
+package process
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/paveg/portguard/internal/port"
+)
+
+// ErrInvalidEnvPredicate is returned by ParseEnvPredicate for malformed predicates
+var ErrInvalidEnvPredicate = errors.New("invalid environment predicate")
+
+// envKeyPattern matches environment variable names
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// EnvPredicate is a condition on one environment variable of an adoption candidate
+type EnvPredicate struct {
+	Key    string
+	Value  string
+	Negate bool // Require the variable to differ from Value, or to be unset
+	AnySet bool // Only require the variable to be set; Value is ignored
+}
+
+// ParseEnvPredicate parses "KEY=value" (equal), "KEY!=value" (different or unset) or
+// "KEY" (set to anything), e.g. NODE_ENV=development
+func ParseEnvPredicate(expr string) (EnvPredicate, error) {
+	var predicate EnvPredicate
+	switch key, value, found := strings.Cut(expr, "="); {
+	case !found:
+		predicate = EnvPredicate{Key: expr, AnySet: true}
+	case strings.HasSuffix(key, "!"):
+		predicate = EnvPredicate{Key: strings.TrimSuffix(key, "!"), Value: value, Negate: true}
+	default:
+		predicate = EnvPredicate{Key: key, Value: value}
+	}
+	if !envKeyPattern.MatchString(predicate.Key) {
+		return EnvPredicate{}, fmt.Errorf("%w: %q", ErrInvalidEnvPredicate, expr)
+	}
+	return predicate, nil
+}
+
+// Matches reports whether the environment satisfies the predicate
+func (p EnvPredicate) Matches(env map[string]string) bool {
+	value, set := env[p.Key]
+	switch {
+	case p.AnySet:
+		return set
+	case p.Negate:
+		return !set || value != p.Value
+	default:
+		return set && value == p.Value
+	}
+}
+
+// String formats the predicate the way ParseEnvPredicate reads it
+func (p EnvPredicate) String() string {
+	switch {
+	case p.AnySet:
+		return p.Key
+	case p.Negate:
+		return p.Key + "!=" + p.Value
+	default:
+		return p.Key + "=" + p.Value
+	}
+}
+
+// readProcessEnvironment reads the environment of a running process. Linux reads
+// /proc/<pid>/environ; macOS parses ps eww, which is best effort because values containing
+// spaces cannot always be told apart from the command line. Processes of other users
+// usually fail with a permission error.
+func readProcessEnvironment(pid int, timeout time.Duration) (map[string]string, error) {
+	switch runtime.GOOS {
+	case port.OSLinux:
+		data, err := os.ReadFile(fmt.Sprintf("/proc/%d/environ", pid))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read environment of PID %d: %w", pid, err)
+		}
+		return parseEnviron(strings.Split(string(data), "\x00")), nil
+	case port.OSDarwin:
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		output, err := exec.CommandContext(ctx, "ps", "eww", "-o", "command=", "-p", strconv.Itoa(pid)).Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read environment of PID %d: %w", pid, err)
+		}
+		return parsePSEnvironment(strings.TrimSpace(string(output))), nil
+	default:
+		return nil, fmt.Errorf("reading the environment of PID %d is not supported on %s", pid, runtime.GOOS)
+	}
+}
+
+// parseEnviron converts KEY=VALUE entries into a map, skipping entries without a key
+func parseEnviron(entries []string) map[string]string {
+	env := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if key, value, found := strings.Cut(entry, "="); found && key != "" {
+			env[key] = value
+		}
+	}
+	return env
+}
+
+// parsePSEnvironment extracts the environment ps eww appends to a command line. The first
+// word that looks like KEY=VALUE after the command starts the environment; words that do not
+// continue the previous value, since ps joins everything with spaces.
+func parsePSEnvironment(line string) map[string]string {
+	words := strings.Fields(line)
+	var entries []string
+	for _, word := range words[min(1, len(words)):] {
+		key, _, found := strings.Cut(word, "=")
+		switch {
+		case found && envKeyPattern.MatchString(key):
+			entries = append(entries, word)
+		case len(entries) > 0:
+			entries[len(entries)-1] += " " + word
+		}
+	}
+	return parseEnviron(entries)
+}
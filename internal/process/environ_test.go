@@ -0,0 +1,149 @@
+package process
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEnvPredicate(t *testing.T) {
+	env := map[string]string{"NODE_ENV": "development", "DEBUG": ""}
+
+	tests := []struct {
+		expr    string
+		matches bool
+		wantErr bool
+	}{
+		{expr: "NODE_ENV=development", matches: true},
+		{expr: "NODE_ENV=production", matches: false},
+		{expr: "NODE_ENV!=production", matches: true},
+		{expr: "NODE_ENV!=development", matches: false},
+		{expr: "PORT!=3000", matches: true},
+		{expr: "DEBUG", matches: true},
+		{expr: "DEBUG=", matches: true},
+		{expr: "PORT", matches: false},
+		{expr: "=development", wantErr: true},
+		{expr: "NODE ENV=development", wantErr: true},
+		{expr: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			predicate, err := ParseEnvPredicate(tt.expr)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidEnvPredicate)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.matches, predicate.Matches(env))
+			assert.Equal(t, tt.expr, predicate.String())
+		})
+	}
+}
+
+func TestParsePSEnvironment(t *testing.T) {
+	line := "node server.js --port 3000 NODE_ENV=development GREETING=hello world PATH=/usr/bin:/bin"
+	assert.Equal(t, map[string]string{
+		"NODE_ENV": "development",
+		"GREETING": "hello world",
+		"PATH":     "/usr/bin:/bin",
+	}, parsePSEnvironment(line))
+
+	assert.Empty(t, parsePSEnvironment("node server.js"))
+	assert.Empty(t, parsePSEnvironment(""))
+}
+
+func TestReadProcessEnvironment(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("reading another process's environment is only supported on Linux and macOS")
+	}
+
+	t.Run("current_process", func(t *testing.T) {
+		// The kernel copy reflects the environment at exec time, which PATH is part of
+		env, err := readProcessEnvironment(os.Getpid(), 2*time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, os.Getenv("PATH"), env["PATH"])
+	})
+
+	t.Run("child_process", func(t *testing.T) {
+		child := exec.Command("sleep", "5")
+		child.Env = []string{"NODE_ENV=development", "PATH=" + os.Getenv("PATH")}
+		require.NoError(t, child.Start())
+		defer func() {
+			_ = child.Process.Kill() //nolint:errcheck // Test cleanup
+			_ = child.Wait()         //nolint:errcheck // Test cleanup
+		}()
+
+		// Until the child has exec'd, the kernel still reports the environment inherited from the fork
+		require.Eventually(t, func() bool {
+			env, err := readProcessEnvironment(child.Process.Pid, 2*time.Second)
+			return err == nil && env["NODE_ENV"] == "development"
+		}, 2*time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestProcessAdopter_EnvFilters(t *testing.T) {
+	environments := map[int]map[string]string{
+		5001: {"NODE_ENV": "development"},
+		5002: {"NODE_ENV": "production"},
+	}
+
+	adopter := NewProcessAdopter(2 * time.Second)
+	adopter.lookupProcessInfo = func(int) (string, string, error) {
+		return "node", "node server.js", nil
+	}
+	adopter.lookupEnvironment = func(pid int) (map[string]string, error) {
+		if env, ok := environments[pid]; ok {
+			return env, nil
+		}
+		return nil, os.ErrPermission
+	}
+
+	t.Run("no_filters", func(t *testing.T) {
+		for pid := range environments {
+			info, err := adopter.GetProcessInfo(pid)
+			require.NoError(t, err)
+			assert.True(t, info.IsSuitable)
+			assert.Equal(t, environments[pid], info.Environment)
+
+			// The environment may hold secrets and stays out of JSON output
+			data, err := json.Marshal(info)
+			require.NoError(t, err)
+			assert.NotContains(t, string(data), "NODE_ENV")
+		}
+
+		// An unreadable environment does not matter without filters
+		info, err := adopter.GetProcessInfo(5003)
+		require.NoError(t, err)
+		assert.True(t, info.IsSuitable)
+		assert.Nil(t, info.Environment)
+	})
+
+	t.Run("development_only", func(t *testing.T) {
+		predicate, err := ParseEnvPredicate("NODE_ENV=development")
+		require.NoError(t, err)
+		adopter.SetEnvFilters([]EnvPredicate{predicate})
+		defer adopter.SetEnvFilters(nil)
+
+		info, err := adopter.GetProcessInfo(5001)
+		require.NoError(t, err)
+		assert.True(t, info.IsSuitable)
+
+		info, err = adopter.GetProcessInfo(5002)
+		require.NoError(t, err)
+		assert.False(t, info.IsSuitable)
+		assert.Equal(t, "environment does not match NODE_ENV=development", info.Reason)
+
+		info, err = adopter.GetProcessInfo(5003)
+		require.NoError(t, err)
+		assert.False(t, info.IsSuitable)
+		assert.Contains(t, info.Reason, "environment not readable")
+		assert.Contains(t, info.Reason, "permission denied")
+	})
+}
@@ -0,0 +1,108 @@
+package process
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+)
+
+// ErrNotStarted is returned by Executor.Wait for PIDs the executor did not start
+var ErrNotStarted = errors.New("process was not started by this executor")
+
+// ExecSpec describes a process for an Executor to start
+type ExecSpec struct {
+	Command string
+	Args    []string
+	Dir     string    // Working directory; empty uses the current one
+	Env     []string  // Full environment in KEY=VALUE form; nil inherits the current one
+	Output  io.Writer // Receives stdout and stderr; nil discards them
+	// Detached starts the process in a new session (Unix) or without a console (Windows),
+	// decoupled from portguard's terminal. Stdin is always the null device.
+	Detached bool
+}
+
+// Executor starts and controls OS processes. ProcessManager uses it for every process
+// operation so tests can drive crashes, restarts and monitoring without real processes.
+type Executor interface {
+	// Start launches the process in its own process group and returns its PID
+	Start(spec ExecSpec) (int, error)
+	// Wait blocks until a process returned by Start exits and returns its exit result
+	Wait(pid int) error
+	// Signal delivers sig to the process, or to its whole process group when group is set
+	Signal(pid int, group bool, sig os.Signal) error
+	// Kill forcibly terminates the process, or its whole process tree when group is set
+	Kill(pid int, group bool) error
+	// IsAlive reports whether a process with the PID is running
+	IsAlive(pid int) bool
+}
+
+// OSExecutor is the Executor backed by the operating system
+type OSExecutor struct {
+	mutex   sync.Mutex
+	started map[int]*exec.Cmd // Children awaiting Wait
+}
+
+// NewOSExecutor creates an Executor that runs real processes
+func NewOSExecutor() *OSExecutor {
+	return &OSExecutor{started: make(map[int]*exec.Cmd)}
+}
+
+// Start implements Executor
+func (e *OSExecutor) Start(spec ExecSpec) (int, error) {
+	// The process outlives this call, so it must not be tied to a context that is cancelled on return
+	cmd := exec.Command(spec.Command, spec.Args...) //nolint:noctx // Long-running child process, lifetime managed by monitor
+	cmd.Dir = spec.Dir
+	cmd.Env = spec.Env
+	if spec.Output != nil {
+		cmd.Stdout = spec.Output
+		cmd.Stderr = spec.Output
+	}
+
+	// Set up process group for signal management (platform-specific)
+	cmd.SysProcAttr = setSysProcAttr(nil, spec.Detached)
+
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+	trackProcessTree(cmd.Process.Pid)
+
+	e.mutex.Lock()
+	e.started[cmd.Process.Pid] = cmd
+	e.mutex.Unlock()
+	return cmd.Process.Pid, nil
+}
+
+// Wait implements Executor
+func (e *OSExecutor) Wait(pid int) error {
+	e.mutex.Lock()
+	cmd, exists := e.started[pid]
+	delete(e.started, pid)
+	e.mutex.Unlock()
+
+	if !exists {
+		return fmt.Errorf("%w: %d", ErrNotStarted, pid)
+	}
+	return cmd.Wait()
+}
+
+// Signal implements Executor
+func (e *OSExecutor) Signal(pid int, group bool, sig os.Signal) error {
+	return sendSignal(pid, group, sig)
+}
+
+// Kill implements Executor
+func (e *OSExecutor) Kill(pid int, group bool) error {
+	return killProcess(pid, group)
+}
+
+// IsAlive implements Executor
+func (e *OSExecutor) IsAlive(pid int) bool {
+	osProcess, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return isProcessAlive(osProcess)
+}
@@ -0,0 +1,329 @@
+package process
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/paveg/portguard/internal/lock"
+)
+
+// fakeProcess is a process simulated by fakeExecutor
+type fakeProcess struct {
+	spec    ExecSpec
+	alive   bool
+	exit    chan error
+	signals []os.Signal
+}
+
+// fakeExecutor simulates processes so lifecycle tests do not depend on the OS
+type fakeExecutor struct {
+	mutex   sync.Mutex
+	nextPID int
+	procs   map[int]*fakeProcess
+	starts  []ExecSpec
+	killed  []int
+}
+
+func newFakeExecutor() *fakeExecutor {
+	return &fakeExecutor{nextPID: 1000, procs: make(map[int]*fakeProcess)}
+}
+
+func (f *fakeExecutor) Start(spec ExecSpec) (int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.nextPID++
+	f.procs[f.nextPID] = &fakeProcess{spec: spec, alive: true, exit: make(chan error, 1)}
+	f.starts = append(f.starts, spec)
+	return f.nextPID, nil
+}
+
+func (f *fakeExecutor) Wait(pid int) error {
+	f.mutex.Lock()
+	proc, exists := f.procs[pid]
+	f.mutex.Unlock()
+	if !exists {
+		return ErrNotStarted
+	}
+	return <-proc.exit
+}
+
+func (f *fakeExecutor) Signal(pid int, _ bool, sig os.Signal) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	proc, exists := f.procs[pid]
+	if !exists || !proc.alive {
+		return os.ErrProcessDone
+	}
+	proc.signals = append(proc.signals, sig)
+	return nil
+}
+
+func (f *fakeExecutor) Kill(pid int, _ bool) error {
+	f.mutex.Lock()
+	f.killed = append(f.killed, pid)
+	f.mutex.Unlock()
+	f.exitProcess(pid, errors.New("signal: killed"))
+	return nil
+}
+
+func (f *fakeExecutor) IsAlive(pid int) bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	proc, exists := f.procs[pid]
+	return exists && proc.alive
+}
+
+// exitProcess makes a simulated process exit with the given wait result
+func (f *fakeExecutor) exitProcess(pid int, result error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if proc, exists := f.procs[pid]; exists && proc.alive {
+		proc.alive = false
+		proc.exit <- result
+	}
+}
+
+func (f *fakeExecutor) startCount() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return len(f.starts)
+}
+
+// setupFakeExecutorManager returns a manager whose processes are simulated by a fake executor
+func setupFakeExecutorManager(t *testing.T) (*ProcessManager, *fakeExecutor) {
+	t.Helper()
+
+	pm, mockStateStore, mockLockManager, _ := setupTestProcessManager(t)
+	mockLockManager.On("Lock").Return(nil)
+	mockLockManager.On("Unlock").Return(nil)
+	mockStateStore.On("Save", mock.AnythingOfType("map[string]*process.ManagedProcess")).Return(nil)
+	mockStateStore.On("Load").Return(nil, os.ErrNotExist)
+
+	executor := newFakeExecutor()
+	pm.SetExecutor(executor)
+	return pm, executor
+}
+
+func TestOSExecutor(t *testing.T) {
+	executor := NewOSExecutor()
+
+	pid, err := executor.Start(ExecSpec{Command: "sh", Args: []string{"-c", "exit 3"}})
+	require.NoError(t, err)
+	assert.Positive(t, pid)
+
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, executor.Wait(pid), &exitErr)
+	assert.Equal(t, 3, exitErr.ExitCode())
+	assert.False(t, executor.IsAlive(pid))
+
+	require.ErrorIs(t, executor.Wait(pid), ErrNotStarted, "a process can only be waited for once")
+
+	_, err = executor.Start(ExecSpec{Command: "portguard-no-such-command"})
+	require.Error(t, err)
+}
+
+func TestProcessManager_FakeExecutor_RestartOnCrash(t *testing.T) {
+	pm, executor := setupFakeExecutorManager(t)
+
+	proc, err := pm.StartProcess("server", []string{"--port", "3000"}, StartOptions{
+		RestartPolicy: RestartOnFailure,
+		MaxRestarts:   1,
+		WorkingDir:    t.TempDir(),
+	})
+	require.NoError(t, err)
+	firstPID := proc.PID
+
+	executor.exitProcess(firstPID, errors.New("exit status 1"))
+	require.Eventually(t, func() bool {
+		pm.mutex.RLock()
+		defer pm.mutex.RUnlock()
+		return proc.RestartCount == 1 && proc.PID != firstPID
+	}, 2*time.Second, 10*time.Millisecond)
+
+	require.Equal(t, 2, executor.startCount())
+	assert.Equal(t, executor.starts[0], executor.starts[1], "restarts reuse the original command and options")
+
+	pm.mutex.RLock()
+	secondPID := proc.PID
+	pm.mutex.RUnlock()
+
+	// The restart limit is reached on the next crash
+	executor.exitProcess(secondPID, errors.New("exit status 1"))
+	require.Eventually(t, func() bool {
+		pm.mutex.RLock()
+		defer pm.mutex.RUnlock()
+		return proc.Status == StatusFailed
+	}, 2*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, 2, executor.startCount())
+	pm.mutex.RLock()
+	defer pm.mutex.RUnlock()
+	assert.Equal(t, "exit status 1", proc.ExitReason)
+}
+
+func TestProcessManager_FakeExecutor_CrashRestartTakesLock(t *testing.T) {
+	pm, mockStateStore, mockLockManager, _ := setupTestProcessManager(t)
+	mockLockManager.On("Lock").Return(nil).Once()
+	mockLockManager.On("Lock").Return(errors.New("lock held by another portguard"))
+	mockLockManager.On("Unlock").Return(nil)
+	mockStateStore.On("Save", mock.AnythingOfType("map[string]*process.ManagedProcess")).Return(nil)
+	executor := newFakeExecutor()
+	pm.SetExecutor(executor)
+
+	proc, err := pm.StartProcess("server", nil, StartOptions{RestartPolicy: RestartOnFailure})
+	require.NoError(t, err)
+	pm.mutex.RLock()
+	pid := proc.PID
+	pm.mutex.RUnlock()
+
+	executor.exitProcess(pid, errors.New("exit status 1"))
+	require.Eventually(t, func() bool {
+		pm.mutex.RLock()
+		defer pm.mutex.RUnlock()
+		return proc.Status == StatusFailed
+	}, 2*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, 1, executor.startCount(), "the process is not respawned without the state lock")
+	mockLockManager.AssertNumberOfCalls(t, "Lock", 2)
+}
+
+func TestProcessManager_FakeExecutor_CrashRestartWaitsForStateLock(t *testing.T) {
+	pm, executor := setupFakeExecutorManager(t)
+	fileLock := lock.NewFileLock(filepath.Join(t.TempDir(), "portguard.lock"), 5*time.Second)
+	pm.lockManager = fileLock
+
+	proc, err := pm.StartProcess("server", nil, StartOptions{RestartPolicy: RestartOnFailure})
+	require.NoError(t, err)
+	pm.mutex.RLock()
+	pid := proc.PID
+	pm.mutex.RUnlock()
+
+	// A foreground operation of the same manager holds the lock while the process crashes
+	require.NoError(t, pm.lockState())
+	executor.exitProcess(pid, errors.New("exit status 1"))
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, 1, executor.startCount(), "the monitor waits for the state lock")
+	assert.True(t, fileLock.IsLocked())
+
+	pm.unlockState()
+	require.Eventually(t, func() bool { return executor.startCount() == 2 }, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestProcessManager_FakeExecutor_ManualRestartKeepsCrashBudget(t *testing.T) {
+	pm, executor := setupFakeExecutorManager(t)
+
+	proc, err := pm.StartProcess("server", nil, StartOptions{RestartPolicy: RestartOnFailure, MaxRestarts: 1})
+	require.NoError(t, err)
+
+	crash := func() {
+		t.Helper()
+		pm.mutex.RLock()
+		pid := proc.PID
+		pm.mutex.RUnlock()
+		executor.exitProcess(pid, errors.New("exit status 1"))
+	}
+
+	// Manual restarts beyond MaxRestarts do not use up the crash budget
+	require.NoError(t, pm.RestartProcess(proc.ID, false))
+	require.NoError(t, pm.RestartProcess(proc.ID, false))
+	require.Equal(t, 3, executor.startCount())
+
+	crash()
+	require.Eventually(t, func() bool { return executor.startCount() == 4 }, 2*time.Second, 10*time.Millisecond)
+	pm.mutex.RLock()
+	assert.Equal(t, StatusRunning, proc.Status)
+	assert.Equal(t, 3, proc.RestartCount, "manual and crash restarts are both counted")
+	assert.Equal(t, 1, proc.CrashRestarts)
+	pm.mutex.RUnlock()
+
+	crash()
+	require.Eventually(t, func() bool {
+		pm.mutex.RLock()
+		defer pm.mutex.RUnlock()
+		return proc.Status == StatusFailed
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// A manual restart of the failed process starts with a fresh budget
+	require.NoError(t, pm.RestartProcess(proc.ID, false))
+	pm.mutex.RLock()
+	defer pm.mutex.RUnlock()
+	assert.Equal(t, StatusRunning, proc.Status)
+	assert.Equal(t, 0, proc.CrashRestarts)
+}
+
+func TestProcessManager_FakeExecutor_ManualRestartKeepsNoMonitor(t *testing.T) {
+	pm, executor := setupFakeExecutorManager(t)
+
+	proc, err := pm.StartProcess("server", nil, StartOptions{RestartPolicy: RestartAlways, NoMonitor: true})
+	require.NoError(t, err)
+	require.Zero(t, pm.ActiveMonitors())
+
+	require.NoError(t, pm.RestartProcess(proc.ID, false))
+	require.Equal(t, 2, executor.startCount())
+	assert.Zero(t, pm.ActiveMonitors(), "a restart does not add a monitor the start skipped")
+
+	// Without a monitor the restart policy never kicks in
+	pm.mutex.RLock()
+	pid := proc.PID
+	assert.True(t, proc.NoMonitor)
+	pm.mutex.RUnlock()
+	executor.exitProcess(pid, errors.New("exit status 1"))
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, 2, executor.startCount())
+}
+
+func TestProcessManager_FakeExecutor_StopAndRefresh(t *testing.T) {
+	t.Run("force_stop_kills_without_restart", func(t *testing.T) {
+		pm, executor := setupFakeExecutorManager(t)
+
+		proc, err := pm.StartProcess("server", nil, StartOptions{RestartPolicy: RestartAlways})
+		require.NoError(t, err)
+		require.NoError(t, pm.StopProcess(proc.ID, true))
+
+		assert.Equal(t, []int{proc.PID}, executor.killed)
+		require.Eventually(t, func() bool {
+			pm.mutex.RLock()
+			defer pm.mutex.RUnlock()
+			return proc.ExitReason != ""
+		}, 2*time.Second, 10*time.Millisecond)
+		assert.Equal(t, 1, executor.startCount(), "a requested stop must not trigger a restart")
+	})
+
+	t.Run("refresh_marks_dead_process_stopped", func(t *testing.T) {
+		pm, executor := setupFakeExecutorManager(t)
+		pm.SetMonitoringDisabled(true)
+
+		proc, err := pm.StartProcess("server", nil, StartOptions{})
+		require.NoError(t, err)
+
+		executor.exitProcess(proc.PID, nil)
+		require.NoError(t, pm.RefreshStatuses())
+
+		pm.mutex.RLock()
+		defer pm.mutex.RUnlock()
+		assert.Equal(t, StatusStopped, proc.Status)
+	})
+
+	t.Run("signal_goes_through_executor", func(t *testing.T) {
+		pm, executor := setupFakeExecutorManager(t)
+		pm.SetMonitoringDisabled(true)
+
+		proc, err := pm.StartProcess("server", nil, StartOptions{})
+		require.NoError(t, err)
+		require.NoError(t, pm.SignalProcess(proc.ID, syscall.SIGHUP))
+
+		executor.mutex.Lock()
+		defer executor.mutex.Unlock()
+		assert.Equal(t, []os.Signal{syscall.SIGHUP}, executor.procs[proc.PID].signals)
+	})
+}
@@ -0,0 +1,39 @@
+package process
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// performGRPCHealthCheck calls the standard grpc.health.v1 Health/Check RPC and treats
+// SERVING as healthy. The connection is plaintext, as development servers usually are.
+func (pm *ProcessManager) performGRPCHealthCheck(ctx context.Context, process *ManagedProcess) error {
+	// Without a target, dial the process port on the interface it was bound to
+	target := process.HealthCheck.Target
+	if target == "" && process.Port > 0 {
+		target = defaultTCPTarget(process.BindAddress, process.Port)
+	}
+	if target == "" {
+		return errors.New("gRPC health check target address not specified")
+	}
+
+	conn, err := grpc.NewClient("passthrough:///"+target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return fmt.Errorf("gRPC health check failed: %w", err)
+	}
+	defer func() { _ = conn.Close() }() //nolint:errcheck // Cleanup operation
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: process.HealthCheck.Service})
+	if err != nil {
+		return fmt.Errorf("gRPC health check failed: %w", err)
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("gRPC health check failed: status %s", resp.GetStatus())
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package process
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestProcessManager_PerformGRPCHealthCheck(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("api.Users", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus("api.Billing", healthpb.HealthCheckResponse_NOT_SERVING)
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	go func() { _ = server.Serve(listener) }() //nolint:errcheck // Serve returns when the test stops the server
+	defer server.Stop()
+
+	// A listener that accepts connections but never speaks HTTP/2
+	silent, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = silent.Close() }() //nolint:errcheck // Test cleanup
+
+	target := listener.Addr().String()
+	_, portStr, err := net.SplitHostPort(target)
+	require.NoError(t, err)
+	portNum, err := strconv.Atoi(portStr)
+	require.NoError(t, err)
+
+	pm, _, _, _ := setupTestProcessManager(t)
+
+	tests := []struct {
+		name        string
+		target      string
+		service     string
+		port        int
+		expectError string
+	}{
+		{name: "server_serving", target: target},
+		{name: "service_serving", target: target, service: "api.Users"},
+		{name: "service_not_serving", target: target, service: "api.Billing", expectError: "NOT_SERVING"},
+		{name: "unknown_service", target: target, service: "api.Missing", expectError: "NotFound"},
+		{name: "default_target_uses_port", port: portNum},
+		{name: "no_target", expectError: "target address not specified"},
+		{name: "times_out", target: silent.Addr().String(), expectError: "gRPC health check failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			process := &ManagedProcess{
+				ID:          "test-grpc",
+				Port:        tt.port,
+				BindAddress: "127.0.0.1",
+				HealthCheck: &HealthCheck{Type: HealthCheckGRPC, Target: tt.target, Service: tt.service, Enabled: true},
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+			defer cancel()
+
+			start := time.Now()
+			err := pm.performGRPCHealthCheck(ctx, process)
+			assert.Less(t, time.Since(start), 2*time.Second, "check must respect the context deadline")
+
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
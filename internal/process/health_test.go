@@ -1,13 +1,19 @@
 package process
 
 import (
+	"bufio"
 	"context"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestProcessManager_PerformHTTPHealthCheck(t *testing.T) {
@@ -80,6 +86,98 @@ func TestProcessManager_PerformHTTPHealthCheck(t *testing.T) {
 	}
 }
 
+func TestProcessManager_PerformHTTPHealthCheck_BodyExpectations(t *testing.T) {
+	const degradedBody = `{"status":"degraded","checks":{"db":{"ok":false}},"replicas":[{"ready":true}]}`
+	const healthyBody = `{"status":"ok","checks":{"db":{"ok":true}},"replicas":[{"ready":true}]}`
+
+	tests := []struct {
+		name        string
+		body        string
+		healthCheck HealthCheck
+		expectError error
+	}{
+		{
+			name:        "substring_matches",
+			body:        healthyBody,
+			healthCheck: HealthCheck{ExpectBody: `"status":"ok"`},
+		},
+		{
+			name:        "substring_missing",
+			body:        degradedBody,
+			healthCheck: HealthCheck{ExpectBody: `"status":"ok"`},
+			expectError: ErrUnexpectedBody,
+		},
+		{
+			name:        "json_path_value_matches",
+			body:        healthyBody,
+			healthCheck: HealthCheck{ExpectJSONPath: "checks.db.ok", ExpectJSONValue: "true"},
+		},
+		{
+			name:        "json_path_value_mismatch",
+			body:        degradedBody,
+			healthCheck: HealthCheck{ExpectJSONPath: "status", ExpectJSONValue: "ok"},
+			expectError: ErrUnexpectedBody,
+		},
+		{
+			name:        "json_path_with_array_index",
+			body:        healthyBody,
+			healthCheck: HealthCheck{ExpectJSONPath: "replicas.0.ready", ExpectJSONValue: "true"},
+		},
+		{
+			name:        "json_path_presence_only",
+			body:        healthyBody,
+			healthCheck: HealthCheck{ExpectJSONPath: "checks.db"},
+		},
+		{
+			name:        "json_path_missing",
+			body:        healthyBody,
+			healthCheck: HealthCheck{ExpectJSONPath: "checks.cache.ok"},
+			expectError: ErrUnexpectedBody,
+		},
+		{
+			name:        "json_path_on_non_json_body",
+			body:        "OK",
+			healthCheck: HealthCheck{ExpectJSONPath: "status"},
+			expectError: ErrUnexpectedBody,
+		},
+		{
+			name:        "body_too_large",
+			body:        strings.Repeat("a", maxHealthCheckBodySize+1),
+			healthCheck: HealthCheck{ExpectBody: "ok"},
+			expectError: ErrBodyTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(tt.body)) // Write error surfaces as a failed check
+			}))
+			defer server.Close()
+
+			pm, _, _, _ := setupTestProcessManager(t)
+
+			healthCheck := tt.healthCheck
+			healthCheck.Type = HealthCheckHTTP
+			healthCheck.Target = server.URL
+			healthCheck.Enabled = true
+			healthCheck.Timeout = 2 * time.Second
+			process := &ManagedProcess{ID: "test-body", HealthCheck: &healthCheck}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			err := pm.performHTTPHealthCheck(ctx, process)
+			if tt.expectError != nil {
+				require.ErrorIs(t, err, tt.expectError)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestProcessManager_PerformHTTPHealthCheck_Errors(t *testing.T) {
 	pm, _, _, _ := setupTestProcessManager(t)
 
@@ -124,6 +222,109 @@ func TestProcessManager_PerformHTTPHealthCheck_Errors(t *testing.T) {
 	}
 }
 
+func TestProcessManager_PerformHTTPHealthCheck_RequestOptions(t *testing.T) {
+	pm, _, _, _ := setupTestProcessManager(t)
+
+	// The server only answers requests carrying every configured option
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		switch {
+		case r.Method != http.MethodHead:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		case r.Header.Get("X-Api-Key") != "secret":
+			w.WriteHeader(http.StatusForbidden)
+		case r.Host != "app.localhost":
+			w.WriteHeader(http.StatusMisdirectedRequest)
+		case !ok || user != "admin" || password != "hunter2":
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	healthCheck := func(mutate func(*HealthCheck)) *ManagedProcess {
+		hc := &HealthCheck{
+			Type:              HealthCheckHTTP,
+			Target:            server.URL,
+			Method:            http.MethodHead,
+			Headers:           map[string]string{"x-api-key": "secret", "host": "app.localhost"},
+			BasicAuthUser:     "admin",
+			BasicAuthPassword: "hunter2",
+			Timeout:           2 * time.Second,
+			Enabled:           true,
+		}
+		if mutate != nil {
+			mutate(hc)
+		}
+		return &ManagedProcess{ID: "test-options", HealthCheck: hc}
+	}
+
+	tests := []struct {
+		name        string
+		mutate      func(*HealthCheck)
+		expectError bool
+	}{
+		{name: "all_options_applied"},
+		{name: "default_method_is_get", mutate: func(hc *HealthCheck) { hc.Method = "" }, expectError: true},
+		{name: "missing_header", mutate: func(hc *HealthCheck) { delete(hc.Headers, "x-api-key") }, expectError: true},
+		{name: "missing_host_override", mutate: func(hc *HealthCheck) { delete(hc.Headers, "host") }, expectError: true},
+		{name: "wrong_password", mutate: func(hc *HealthCheck) { hc.BasicAuthPassword = "guess" }, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := pm.performHTTPHealthCheck(context.Background(), healthCheck(tt.mutate))
+			if tt.expectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestProcessManager_PerformHTTPHealthCheck_Transport(t *testing.T) {
+	pm, _, _, _ := setupTestProcessManager(t)
+
+	t.Run("explicit_proxy", func(t *testing.T) {
+		var proxiedURL string
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			proxiedURL = r.URL.String() // Proxies receive the absolute target URL
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer proxy.Close()
+
+		process := &ManagedProcess{ID: "test-proxy", HealthCheck: &HealthCheck{
+			Type:    HealthCheckHTTP,
+			Target:  "http://api.internal:8080/health",
+			Proxy:   proxy.URL,
+			Timeout: 2 * time.Second,
+			Enabled: true,
+		}}
+		require.NoError(t, pm.performHTTPHealthCheck(context.Background(), process))
+		assert.Equal(t, "http://api.internal:8080/health", proxiedURL)
+	})
+
+	t.Run("self_signed_certificate", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		process := &ManagedProcess{ID: "test-tls", HealthCheck: &HealthCheck{
+			Type:    HealthCheckHTTP,
+			Target:  server.URL,
+			Timeout: 2 * time.Second,
+			Enabled: true,
+		}}
+		require.Error(t, pm.performHTTPHealthCheck(context.Background(), process), "certificates are verified by default")
+
+		process.HealthCheck.InsecureSkipVerify = true
+		require.NoError(t, pm.performHTTPHealthCheck(context.Background(), process))
+	})
+}
+
 func TestProcessManager_PerformTCPHealthCheck(t *testing.T) {
 	// Create a test TCP server
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -189,6 +390,115 @@ func TestProcessManager_PerformTCPHealthCheck(t *testing.T) {
 	}
 }
 
+func TestProcessManager_PerformTCPHealthCheck_Banner(t *testing.T) {
+	// scriptedServer runs script for every accepted connection
+	scriptedServer := func(t *testing.T, script func(conn net.Conn)) string {
+		t.Helper()
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = listener.Close() })
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				go func() {
+					defer func() { _ = conn.Close() }()
+					script(conn)
+				}()
+			}
+		}()
+		return listener.Addr().String()
+	}
+
+	tests := []struct {
+		name        string
+		script      func(conn net.Conn)
+		send        string
+		expect      string
+		expectedErr error
+	}{
+		{
+			name:   "smtp_greeting",
+			script: func(conn net.Conn) { _, _ = io.WriteString(conn, "220 mail.example.com ESMTP ready\r\n") },
+			expect: "220",
+		},
+		{
+			name: "redis_ping",
+			script: func(conn net.Conn) {
+				line, err := bufio.NewReader(conn).ReadString('\n')
+				if err == nil && line == "PING\r\n" {
+					_, _ = io.WriteString(conn, "+PONG\r\n")
+				}
+			},
+			send:   "PING\r\n",
+			expect: "+PONG",
+		},
+		{
+			name: "reply_split_across_reads",
+			script: func(conn net.Conn) {
+				_, _ = io.WriteString(conn, "+PO")
+				time.Sleep(20 * time.Millisecond)
+				_, _ = io.WriteString(conn, "NG\r\n")
+			},
+			expect: "+PONG",
+		},
+		{
+			name:   "send_only",
+			script: func(conn net.Conn) { _, _ = io.Copy(io.Discard, conn) },
+			send:   "hello\n",
+		},
+		{
+			name:        "different_service",
+			script:      func(conn net.Conn) { _, _ = io.WriteString(conn, "SSH-2.0-OpenSSH_9.6\r\n") },
+			expect:      "220",
+			expectedErr: ErrUnexpectedBanner,
+		},
+		{
+			name:        "silent_server",
+			script:      func(conn net.Conn) { _, _ = io.Copy(io.Discard, conn) },
+			expect:      "220",
+			expectedErr: ErrUnexpectedBanner,
+		},
+		{
+			name: "reply_too_long",
+			script: func(conn net.Conn) {
+				_, _ = io.WriteString(conn, strings.Repeat("x", 2*maxHealthCheckBannerSize))
+				_, _ = io.Copy(io.Discard, conn)
+			},
+			expect:      "220",
+			expectedErr: ErrUnexpectedBanner,
+		},
+	}
+
+	pm, _, _, _ := setupTestProcessManager(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			process := &ManagedProcess{
+				ID: "test-banner",
+				HealthCheck: &HealthCheck{
+					Type:          HealthCheckTCP,
+					Target:        scriptedServer(t, tt.script),
+					Enabled:       true,
+					SendOnConnect: tt.send,
+					ExpectBanner:  tt.expect,
+				},
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+			defer cancel()
+
+			err := pm.performTCPHealthCheck(ctx, process)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
 func TestProcessManager_PerformCommandHealthCheck(t *testing.T) {
 	pm, _, _, _ := setupTestProcessManager(t)
 
@@ -212,6 +522,21 @@ func TestProcessManager_PerformCommandHealthCheck(t *testing.T) {
 			target:      "echo hello world",
 			expectError: false,
 		},
+		{
+			name:        "quoted_argument",
+			target:      `sh -c "exit 0"`,
+			expectError: false,
+		},
+		{
+			name:        "quoted_argument_failure",
+			target:      `sh -c "exit 1"`,
+			expectError: true,
+		},
+		{
+			name:        "unterminated_quote",
+			target:      `sh -c "exit 0`,
+			expectError: true,
+		},
 		{
 			name:        "empty_target",
 			target:      "",
@@ -367,3 +692,285 @@ func TestProcessManager_RunHealthCheck_BasicFallback(t *testing.T) {
 	// but this exercises the fallback code path for coverage
 	_ = err
 }
+
+func TestProcessManager_SetHealthCheck(t *testing.T) {
+	// adoptFake registers a running process that has no monitor, like one adopted by an earlier command
+	adoptFake := func(t *testing.T, pm *ProcessManager, executor *fakeExecutor) *ManagedProcess {
+		t.Helper()
+		pid, err := executor.Start(ExecSpec{Command: "server"})
+		require.NoError(t, err)
+		proc := &ManagedProcess{ID: "server-a1b2c3", Command: "server", PID: pid, Status: StatusRunning, IsExternal: true}
+		pm.processes[proc.ID] = proc
+		return proc
+	}
+
+	t.Run("starts_monitoring_adopted_process", func(t *testing.T) {
+		pm, executor := setupFakeExecutorManager(t)
+		proc := adoptFake(t, pm, executor)
+
+		// Nothing listens on a closed listener's address, so the check fails
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		target := listener.Addr().String()
+		require.NoError(t, listener.Close())
+
+		healthCheck := &HealthCheck{Type: HealthCheckTCP, Target: target, Timeout: time.Second, Enabled: true}
+		require.NoError(t, pm.SetHealthCheck(proc.ID, healthCheck))
+
+		healthCheck.Target = "changed-after-the-call:1"
+		pm.mutex.RLock()
+		assert.Equal(t, target, proc.HealthCheck.Target, "the manager keeps its own copy")
+		pm.mutex.RUnlock()
+
+		require.Eventually(t, func() bool {
+			pm.mutex.RLock()
+			defer pm.mutex.RUnlock()
+			return proc.Status == StatusUnhealthy
+		}, 3*time.Second, 20*time.Millisecond)
+	})
+
+	t.Run("removes_health_check", func(t *testing.T) {
+		pm, executor := setupFakeExecutorManager(t)
+		pm.SetMonitoringDisabled(true)
+		proc := adoptFake(t, pm, executor)
+		proc.HealthCheck = &HealthCheck{Type: HealthCheckProcess, Enabled: true}
+
+		require.NoError(t, pm.SetHealthCheck(proc.ID, nil))
+		assert.Nil(t, proc.HealthCheck)
+	})
+
+	t.Run("rejects_invalid_health_check", func(t *testing.T) {
+		pm, executor := setupFakeExecutorManager(t)
+		proc := adoptFake(t, pm, executor)
+
+		err := pm.SetHealthCheck(proc.ID, &HealthCheck{Type: HealthCheckHTTP, Target: "not a url"})
+		require.ErrorIs(t, err, ErrInvalidHealthCheck)
+		assert.Nil(t, proc.HealthCheck)
+	})
+
+	t.Run("unknown_process", func(t *testing.T) {
+		pm, _ := setupFakeExecutorManager(t)
+
+		err := pm.SetHealthCheck("missing", &HealthCheck{Type: HealthCheckProcess})
+		require.ErrorIs(t, err, ErrProcessNotFound)
+	})
+}
+
+func TestProcessManager_HealthCheckSchedule(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	pm, executor := setupFakeExecutorManager(t)
+	pm.SetMonitorInterval(10 * time.Millisecond)
+
+	proc, err := pm.StartProcess("server", nil, StartOptions{
+		HealthCheck: &HealthCheck{Type: HealthCheckHTTP, Target: server.URL, Interval: 150 * time.Millisecond, Enabled: true},
+	})
+	require.NoError(t, err)
+
+	time.Sleep(800 * time.Millisecond)
+	executor.exitProcess(proc.PID, nil)
+
+	// About 80 liveness ticks elapsed; the health endpoint follows its own 150ms interval
+	checks := hits.Load()
+	assert.GreaterOrEqual(t, checks, int32(3))
+	assert.LessOrEqual(t, checks, int32(8))
+
+	pm.mutex.RLock()
+	defer pm.mutex.RUnlock()
+	assert.False(t, proc.LastHealthCheck.IsZero())
+}
+
+func TestHealthCheckDue(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		process  *ManagedProcess
+		expected bool
+	}{
+		{name: "no_health_check", process: &ManagedProcess{}, expected: false},
+		{name: "never_checked", process: &ManagedProcess{HealthCheck: &HealthCheck{Interval: time.Minute}}, expected: true},
+		{
+			name:     "interval_not_elapsed",
+			process:  &ManagedProcess{HealthCheck: &HealthCheck{Interval: time.Minute}, LastHealthCheck: now.Add(-30 * time.Second)},
+			expected: false,
+		},
+		{
+			name:     "interval_elapsed",
+			process:  &ManagedProcess{HealthCheck: &HealthCheck{Interval: time.Minute}, LastHealthCheck: now.Add(-time.Minute)},
+			expected: true,
+		},
+		{
+			name:     "default_interval",
+			process:  &ManagedProcess{HealthCheck: &HealthCheck{}, LastHealthCheck: now.Add(-time.Second)},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, healthCheckDue(tt.process, now))
+		})
+	}
+}
+
+func TestProcessManager_CheckHealth(t *testing.T) {
+	var healthy atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	pm, executor := setupFakeExecutorManager(t)
+	pm.SetMonitoringDisabled(true)
+
+	proc, err := pm.StartProcess("server", nil, StartOptions{
+		HealthCheck: &HealthCheck{Type: HealthCheckHTTP, Target: server.URL, Enabled: true},
+	})
+	require.NoError(t, err)
+	status := func() ProcessStatus {
+		pm.mutex.RLock()
+		defer pm.mutex.RUnlock()
+		return proc.Status
+	}
+
+	ok, err := pm.CheckHealth(proc.ID)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, StatusUnhealthy, status(), "the fresh result is stored")
+
+	healthy.Store(true)
+	ok, err = pm.CheckHealth(proc.ID)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, StatusRunning, status())
+
+	executor.exitProcess(proc.PID, nil)
+	ok, err = pm.CheckHealth(proc.ID)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, StatusStopped, status())
+
+	t.Run("errors", func(t *testing.T) {
+		pm, _ := setupFakeExecutorManager(t)
+		pm.SetMonitoringDisabled(true)
+
+		_, err := pm.CheckHealth("missing")
+		require.ErrorIs(t, err, ErrProcessNotFound)
+
+		withoutCheck, err := pm.StartProcess("plain", nil, StartOptions{})
+		require.NoError(t, err)
+		_, err = pm.CheckHealth(withoutCheck.ID)
+		require.ErrorIs(t, err, ErrNoHealthCheck)
+
+		disabled, err := pm.StartProcess("disabled", nil, StartOptions{
+			HealthCheck: &HealthCheck{Type: HealthCheckHTTP, Target: server.URL},
+		})
+		require.NoError(t, err)
+		_, err = pm.CheckHealth(disabled.ID)
+		require.ErrorIs(t, err, ErrNoHealthCheck)
+	})
+}
+
+func TestProcessManager_StatusCompareAndSwap(t *testing.T) {
+	t.Run("update_requires_expected_status", func(t *testing.T) {
+		pm, _ := setupFakeExecutorManager(t)
+		pm.SetMonitoringDisabled(true)
+
+		proc, err := pm.StartProcess("server", nil, StartOptions{})
+		require.NoError(t, err)
+		require.NoError(t, pm.updateProcessStatus(proc.ID, StatusStopped))
+
+		err = pm.updateProcessStatus(proc.ID, StatusRunning, activeStatuses...)
+		require.ErrorIs(t, err, ErrStatusChanged)
+		require.NoError(t, pm.updateProcessStatus(proc.ID, StatusFailed, StatusStopped))
+
+		current, exists := pm.GetProcess(proc.ID)
+		require.True(t, exists)
+		assert.Equal(t, StatusFailed, current.Status)
+	})
+
+	t.Run("lagging_health_check_does_not_resurrect_stopped_process", func(t *testing.T) {
+		var hits atomic.Int32
+		entered := make(chan struct{})
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			if hits.Add(1) == 1 {
+				close(entered)
+				<-release
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		pm, executor := setupFakeExecutorManager(t)
+		pm.SetMonitorInterval(10 * time.Millisecond)
+
+		proc, err := pm.StartProcess("server", nil, StartOptions{
+			HealthCheck: &HealthCheck{Type: HealthCheckHTTP, Target: server.URL, Interval: 20 * time.Millisecond, Timeout: 5 * time.Second, Enabled: true},
+		})
+		require.NoError(t, err)
+		defer executor.exitProcess(proc.PID, nil)
+
+		// The monitor read the process as running and is waiting for its health check
+		<-entered
+		require.NoError(t, pm.updateProcessStatus(proc.ID, StatusStopped))
+		close(release)
+
+		// A second check only starts once the monitor handled the first, stale result
+		require.Eventually(t, func() bool { return hits.Load() >= 2 }, 2*time.Second, 10*time.Millisecond)
+
+		pm.mutex.RLock()
+		defer pm.mutex.RUnlock()
+		assert.Equal(t, StatusStopped, proc.Status, "the stale healthy result must not overwrite the stop")
+	})
+
+	t.Run("health_check_of_earlier_run_is_discarded", func(t *testing.T) {
+		entered := make(chan struct{})
+		release := make(chan struct{})
+		var hits atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			if hits.Add(1) == 1 {
+				close(entered)
+				<-release
+			}
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		pm, _ := setupFakeExecutorManager(t)
+		pm.SetMonitoringDisabled(true)
+
+		proc, err := pm.StartProcess("server", nil, StartOptions{
+			HealthCheck: &HealthCheck{Type: HealthCheckHTTP, Target: server.URL, Timeout: 5 * time.Second, Enabled: true},
+		})
+		require.NoError(t, err)
+		firstPID := proc.PID
+
+		checkErr := make(chan error, 1)
+		go func() {
+			_, err := pm.CheckHealth(proc.ID)
+			checkErr <- err
+		}()
+
+		// The process is restarted, and running again, while the check of the old run is in flight
+		<-entered
+		require.NoError(t, pm.RestartProcess(proc.ID, true))
+		close(release)
+
+		require.ErrorIs(t, <-checkErr, ErrStatusChanged)
+		current, exists := pm.GetProcess(proc.ID)
+		require.True(t, exists)
+		assert.NotEqual(t, firstPID, current.PID)
+		assert.Equal(t, StatusRunning, current.Status, "the failed check of the old run must not mark the new one unhealthy")
+	})
+}
@@ -0,0 +1,103 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// hookCommandContext creates the commands run as hooks; tests replace it to observe when hooks run
+var hookCommandContext = exec.CommandContext
+
+// runHook runs a single hook command in dir with env, killing it after timeout and reporting
+// its output on failure
+func runHook(hook, dir string, env []string, timeout time.Duration) error {
+	parts, err := SplitCommandLine(hook)
+	if err != nil {
+		return fmt.Errorf("failed to parse hook %q: %w", hook, err)
+	}
+	if len(parts) == 0 {
+		return fmt.Errorf("hook %q: %w", hook, ErrEmptyHook)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := hookCommandContext(ctx, parts[0], parts[1:]...)
+	cmd.Dir = dir
+	cmd.Env = env
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if message := strings.TrimSpace(string(output)); message != "" {
+			return fmt.Errorf("hook %q: %w: %s", hook, err, message)
+		}
+		return fmt.Errorf("hook %q: %w", hook, err)
+	}
+	return nil
+}
+
+// runPreStartHooks runs the pre-start hooks of options in order, in the directory and
+// environment the process gets, and stops at the first failure
+func (pm *ProcessManager) runPreStartHooks(options StartOptions) error {
+	if len(options.PreStart) == 0 {
+		return nil
+	}
+	workingDir, err := resolveWorkingDir(options.WorkingDir)
+	if err != nil {
+		return err
+	}
+	env, err := hookEnvironment(options)
+	if err != nil {
+		return err
+	}
+
+	timeout := pm.hookLimit()
+	for _, hook := range options.PreStart {
+		if err := runHook(hook, effectiveWorkingDir(workingDir), env, timeout); err != nil {
+			return fmt.Errorf("%w: %w", ErrPreStartFailed, err)
+		}
+	}
+	return nil
+}
+
+// runPostStopHooks runs the post-stop hooks of a stopped process. They are best-effort:
+// failures are logged and never fail the stop.
+func (pm *ProcessManager) runPostStopHooks(process *ManagedProcess) {
+	pm.mutex.RLock()
+	options := storedStartOptions(process)
+	pm.mutex.RUnlock()
+	if len(options.PostStop) == 0 {
+		return
+	}
+
+	env, err := hookEnvironment(options)
+	if err != nil {
+		pm.log().Warn("post-stop hooks use the inherited environment", "process_id", process.ID, "error", err)
+		env = nil
+	}
+	timeout := pm.hookLimit()
+	for _, hook := range options.PostStop {
+		if err := runHook(hook, options.WorkingDir, env, timeout); err != nil {
+			pm.log().Warn("post-stop hook failed", "process_id", process.ID, "error", err)
+		}
+	}
+}
+
+// hookEnvironment builds the environment for a process and its hooks; nil inherits portguard's
+func hookEnvironment(options StartOptions) ([]string, error) {
+	environment, err := resolveEnvironment(options)
+	if err != nil {
+		return nil, err
+	}
+	if len(environment) == 0 {
+		return nil, nil
+	}
+	env := os.Environ()
+	for key, value := range environment {
+		env = append(env, fmt.Sprintf("%s=%s", key, value))
+	}
+	return env, nil
+}
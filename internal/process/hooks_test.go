@@ -0,0 +1,157 @@
+package process
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessManager_StartHooks(t *testing.T) {
+	readLog := func(t *testing.T, dir string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(dir, "hooks.log"))
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	t.Run("failing_pre_start_aborts_start", func(t *testing.T) {
+		pm, executor := setupFakeExecutorManager(t)
+		pm.SetMonitoringDisabled(true)
+
+		_, err := pm.StartProcess("server", nil, StartOptions{
+			WorkingDir: t.TempDir(),
+			PreStart:   []string{"true", "sh -c 'echo migration failed >&2; exit 3'", "touch never-run"},
+		})
+		require.ErrorIs(t, err, ErrPreStartFailed)
+		assert.Contains(t, err.Error(), "migration failed")
+		assert.Equal(t, 0, executor.startCount(), "the process must not start after a failed hook")
+		assert.Empty(t, pm.ListProcesses(ProcessListOptions{IncludeStopped: true}))
+	})
+
+	t.Run("pre_start_uses_process_dir_and_env", func(t *testing.T) {
+		pm, executor := setupFakeExecutorManager(t)
+		pm.SetMonitoringDisabled(true)
+		dir := t.TempDir()
+
+		_, err := pm.StartProcess("server", nil, StartOptions{
+			WorkingDir:  dir,
+			Environment: map[string]string{"HOOK_STAGE": "migrate"},
+			PreStart:    []string{`sh -c 'echo "pre $HOOK_STAGE" >> hooks.log'`},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "pre migrate\n", readLog(t, dir))
+		assert.Equal(t, 1, executor.startCount())
+	})
+
+	t.Run("pre_start_is_killed_after_hook_timeout", func(t *testing.T) {
+		pm, executor := setupFakeExecutorManager(t)
+		pm.SetMonitoringDisabled(true)
+		pm.SetHookTimeout(100 * time.Millisecond)
+
+		start := time.Now()
+		_, err := pm.StartProcess("server", nil, StartOptions{WorkingDir: t.TempDir(), PreStart: []string{"sleep 60"}})
+		require.ErrorIs(t, err, ErrPreStartFailed)
+		assert.Less(t, time.Since(start), 10*time.Second)
+		assert.Equal(t, 0, executor.startCount())
+	})
+
+	t.Run("post_stop_runs_on_stop_and_restart", func(t *testing.T) {
+		pm, executor := setupFakeExecutorManager(t)
+		pm.SetMonitoringDisabled(true)
+		dir := t.TempDir()
+
+		proc, err := pm.StartProcess("server", nil, StartOptions{
+			WorkingDir: dir,
+			PreStart:   []string{`sh -c 'echo pre >> hooks.log'`},
+			PostStop:   []string{"false", `sh -c 'echo post >> hooks.log'`},
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, pm.RestartProcess(proc.ID, true), "post-stop failures are best-effort")
+		require.NoError(t, pm.StopProcess(proc.ID, true))
+
+		assert.Equal(t, "pre\npost\npre\npost\n", readLog(t, dir), "restarts run both hooks again")
+		assert.Equal(t, 2, executor.startCount())
+	})
+
+	t.Run("pre_start_runs_without_state_lock", func(t *testing.T) {
+		pm, stateStore, lockManager, _ := setupTestProcessManager(t)
+		var locked atomic.Bool
+		lockManager.On("Lock").Run(func(mock.Arguments) { locked.Store(true) }).Return(nil)
+		lockManager.On("Unlock").Run(func(mock.Arguments) { locked.Store(false) }).Return(nil)
+		stateStore.On("Save", mock.AnythingOfType("map[string]*process.ManagedProcess")).Return(nil)
+		executor := newFakeExecutor()
+		pm.SetExecutor(executor)
+		pm.SetMonitoringDisabled(true)
+
+		var lockedDuringHook atomic.Bool
+		stubHookCommand(t, func() { lockedDuringHook.Store(locked.Load()) })
+
+		proc, err := pm.StartProcess("server", nil, StartOptions{WorkingDir: t.TempDir(), PreStart: []string{"migrate"}})
+		require.NoError(t, err)
+		assert.False(t, lockedDuringHook.Load(), "other invocations must not wait for hooks")
+		assert.Equal(t, 1, executor.startCount())
+
+		// Restarts, including EnsureRunning replacing an unhealthy process, run them the same way
+		lockedDuringHook.Store(true)
+		require.NoError(t, pm.RestartProcess(proc.ID, true))
+		assert.False(t, lockedDuringHook.Load(), "restarts must not hold the lock during hooks")
+		assert.Equal(t, 2, executor.startCount())
+	})
+
+	t.Run("restart_is_skipped_when_removed_during_hooks", func(t *testing.T) {
+		pm, executor := setupFakeExecutorManager(t)
+		pm.SetMonitoringDisabled(true)
+
+		proc, err := pm.StartProcess("server", nil, StartOptions{WorkingDir: t.TempDir(), PreStart: []string{"true"}})
+		require.NoError(t, err)
+
+		// Another caller removes the process while the restart's hooks run
+		stubHookCommand(t, func() {
+			pm.mutex.Lock()
+			delete(pm.processes, proc.ID)
+			pm.mutex.Unlock()
+		})
+		require.ErrorIs(t, pm.RestartProcess(proc.ID, true), ErrProcessNotFound)
+		assert.Equal(t, 1, executor.startCount(), "a removed process must not be started again")
+	})
+
+	t.Run("start_is_decided_again_after_hooks", func(t *testing.T) {
+		pm, executor := setupFakeExecutorManager(t)
+		pm.SetMonitoringDisabled(true)
+		dir := t.TempDir()
+
+		// Another invocation starts the same server while the hooks run
+		other := createTestProcess("other12345", "server", 0, StatusRunning)
+		other.WorkingDir = dir
+		stubHookCommand(t, func() {
+			pm.mutex.Lock()
+			pm.processes[other.ID] = other
+			pm.mutex.Unlock()
+		})
+
+		proc, err := pm.StartProcess("server", nil, StartOptions{WorkingDir: dir, PreStart: []string{"migrate"}})
+		require.NoError(t, err)
+		assert.Equal(t, other.ID, proc.ID, "the process started meanwhile is reused")
+		assert.Equal(t, 0, executor.startCount())
+	})
+}
+
+// stubHookCommand makes hooks run true instead of their command, calling onRun as each one starts
+func stubHookCommand(t *testing.T, onRun func()) {
+	t.Helper()
+	original := hookCommandContext
+	hookCommandContext = func(ctx context.Context, _ string, _ ...string) *exec.Cmd {
+		onRun()
+		return original(ctx, "true")
+	}
+	t.Cleanup(func() { hookCommandContext = original })
+}
@@ -1,8 +1,16 @@
 package process
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -160,6 +168,8 @@ func TestProcessManager_CleanupProcessResources_Integration(t *testing.T) {
 		Status:     StatusStopped,
 		LogFile:    logFile,
 		WorkingDir: workingDir,
+
+		CleanupWorkingDir: true,
 	}
 
 	// Test cleanup
@@ -170,11 +180,54 @@ func TestProcessManager_CleanupProcessResources_Integration(t *testing.T) {
 	_, err = os.Stat(logFile)
 	assert.True(t, os.IsNotExist(err), "Log file should be cleaned up")
 
-	// Verify working directory was cleaned up (contains "temp")
+	// Verify working directory was cleaned up (created for the process)
 	_, err = os.Stat(workingDir)
 	assert.True(t, os.IsNotExist(err), "Temp working directory should be cleaned up")
 }
 
+func TestProcessManager_CleanupProcessResources_KeepsUserDirectories(t *testing.T) {
+	pm, _, _, _ := setupTestProcessManager(t)
+
+	tests := []struct {
+		name              string
+		dirName           string
+		cleanupWorkingDir bool
+	}{
+		{name: "templates_project_is_never_deleted", dirName: "templates/app"},
+		{name: "temp_named_project_without_flag", dirName: "temp-project"},
+		{name: "contemporary_project_without_flag", dirName: "contemporary"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workingDir := filepath.Join(t.TempDir(), tt.dirName)
+			require.NoError(t, os.MkdirAll(workingDir, 0o755))
+			sourceFile := filepath.Join(workingDir, "main.go")
+			require.NoError(t, os.WriteFile(sourceFile, []byte("package main"), 0o644))
+
+			process := &ManagedProcess{
+				ID:         "keep-dir",
+				Command:    "echo test",
+				PID:        12345, // Fake PID for testing
+				Status:     StatusStopped,
+				WorkingDir: workingDir,
+			}
+
+			require.NoError(t, pm.cleanupProcessResources(process, true))
+			assert.FileExists(t, sourceFile)
+		})
+	}
+}
+
+func TestCleanupTempDirectory_RefusesUnsafePaths(t *testing.T) {
+	homeDir, err := os.UserHomeDir()
+	require.NoError(t, err)
+
+	for _, dir := range []string{"/", "relative/dir", homeDir, homeDir + "/"} {
+		require.ErrorIs(t, cleanupTempDirectory(dir), ErrUnsafeCleanupPath, dir)
+	}
+}
+
 func TestProcessManager_TimeSinceLastSeen_Coverage(t *testing.T) {
 	// Test the TimeSinceLastSeen method for coverage
 	now := time.Now()
@@ -263,3 +316,73 @@ func TestProcessManager_StartProcessWithDuplicate_Integration(t *testing.T) {
 	// In a real scenario, this might return the same process
 	// The behavior depends on the actual ShouldStartNew logic
 }
+
+func TestProcessManager_TerminateProcess_KillsProcessTree(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping process tree test in short mode")
+	}
+	if runtime.GOOS == "windows" {
+		t.Skip("Test uses a POSIX shell")
+	}
+
+	pm, _, _, _ := setupTestProcessManager(t)
+	childPIDFile := filepath.Join(t.TempDir(), "child.pid")
+
+	// The shell forks a child that would keep running if only the shell were killed
+	process, err := pm.executeProcess("sh", []string{"-c", "sleep 60 & echo $! > " + childPIDFile + "; wait"}, StartOptions{})
+	require.NoError(t, err)
+
+	var childPID int
+	require.Eventually(t, func() bool {
+		pid, readErr := ReadPIDFile(childPIDFile)
+		childPID = pid
+		return readErr == nil
+	}, 5*time.Second, 20*time.Millisecond)
+
+	require.NoError(t, pm.terminateProcess(process, true))
+
+	assert.Eventually(t, func() bool {
+		return !NewOSExecutor().IsAlive(childPID)
+	}, 5*time.Second, 20*time.Millisecond, "forked child should die with its parent")
+}
+
+// TestDetachedStartHelper is run as a separate process by TestOSExecutor_DetachedSurvivesParent:
+// it starts a detached child, reports its PID and exits like a short-lived portguard command.
+func TestDetachedStartHelper(t *testing.T) {
+	if os.Getenv("PORTGUARD_DETACH_HELPER") != "1" {
+		t.Skip("Helper process for TestOSExecutor_DetachedSurvivesParent")
+	}
+
+	pid, err := NewOSExecutor().Start(ExecSpec{Command: "sleep", Args: []string{"30"}, Detached: true})
+	require.NoError(t, err)
+	fmt.Printf("detached-pid=%d\n", pid)
+}
+
+func TestOSExecutor_DetachedSurvivesParent(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping detached start test in short mode")
+	}
+	if runtime.GOOS != "linux" {
+		t.Skip("Test reads /proc")
+	}
+
+	helper := exec.Command(os.Args[0], "-test.run=^TestDetachedStartHelper$")
+	helper.Env = append(os.Environ(), "PORTGUARD_DETACH_HELPER=1")
+	output, err := helper.Output()
+	require.NoError(t, err, string(output))
+
+	match := regexp.MustCompile(`detached-pid=(\d+)`).FindSubmatch(output)
+	require.NotNil(t, match, string(output))
+	pid, err := strconv.Atoi(string(match[1]))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = killProcess(pid, true) }) //nolint:errcheck // Test cleanup can fail
+
+	// Fields after the command name: state, ppid, pgrp, session
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	require.NoError(t, err, "detached child should outlive the helper")
+	fields := strings.Fields(string(stat[bytes.LastIndexByte(stat, ')')+1:]))
+	require.GreaterOrEqual(t, len(fields), 4)
+
+	assert.Equal(t, "1", fields[1], "child should be reparented to init once the helper exited")
+	assert.Equal(t, strconv.Itoa(pid), fields[3], "child should lead its own session")
+}
@@ -0,0 +1,64 @@
+package process
+
+import (
+	"context"
+	"time"
+)
+
+// SetAutoCleanup starts a background janitor that removes processes not seen for maxIdle
+// that are stopped or whose PID is gone, checking every interval. Running and protected
+// processes are kept. Calling it again replaces the
+// running janitor; a zero interval or maxIdle only stops it. Close also stops the janitor.
+func (pm *ProcessManager) SetAutoCleanup(interval, maxIdle time.Duration) {
+	pm.stopJanitor()
+	if interval <= 0 || maxIdle <= 0 {
+		return
+	}
+
+	pm.mutex.Lock()
+	ctx, cancel := context.WithCancel(pm.backgroundContextLocked())
+	done := make(chan struct{})
+	pm.janitorCancel = cancel
+	pm.janitorDone = done
+	pm.mutex.Unlock()
+
+	go pm.runJanitor(ctx, done, interval, maxIdle)
+}
+
+// runJanitor periodically removes stale processes until ctx is cancelled
+func (pm *ProcessManager) runJanitor(ctx context.Context, done chan<- struct{}, interval, maxIdle time.Duration) {
+	defer close(done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			removed, err := pm.cleanupStaleProcesses(maxIdle)
+			if err != nil {
+				pm.log().Warn("failed to clean up stale processes", "error", err)
+				continue
+			}
+			if removed > 0 {
+				pm.log().Info("removed stale processes", "count", removed, "max_idle_time", maxIdle)
+			}
+		}
+	}
+}
+
+// stopJanitor cancels the background janitor, if any, and waits for it to return
+func (pm *ProcessManager) stopJanitor() {
+	pm.mutex.Lock()
+	cancel, done := pm.janitorCancel, pm.janitorDone
+	pm.janitorCancel, pm.janitorDone = nil, nil
+	pm.mutex.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
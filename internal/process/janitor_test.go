@@ -0,0 +1,145 @@
+package process
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/paveg/portguard/internal/lock"
+)
+
+func TestProcessManager_SetAutoCleanup(t *testing.T) {
+	pm, mockStateStore, mockLockManager, _ := setupTestProcessManager(t)
+	pm.SetExecutor(newFakeExecutor())
+
+	fresh := createTestProcess("fresh", "npm start", 3000, StatusRunning)
+	fresh.LastSeen = time.Now()
+	stale := createTestProcess("stale", "old process", 3001, StatusStopped)
+	stale.LastSeen = time.Now().Add(-time.Hour)
+	protected := createTestProcess("protected", "postgres -D data", 5432, StatusStopped)
+	protected.LastSeen = time.Now().Add(-time.Hour)
+	protected.Protected = true
+
+	stored := map[string]*ManagedProcess{fresh.ID: fresh, stale.ID: stale, protected.ID: protected}
+	mockLockManager.On("Lock").Return(nil)
+	mockLockManager.On("Unlock").Return(nil)
+	mockStateStore.On("Load").Return(stored, nil)
+	mockStateStore.On("BackupState").Return(nil)
+	mockStateStore.On("Save", mock.AnythingOfType("map[string]*process.ManagedProcess")).Return(nil)
+
+	pm.SetAutoCleanup(10*time.Millisecond, time.Minute)
+	t.Cleanup(func() { _ = pm.Close() }) //nolint:errcheck // Closed again below
+
+	// The janitor reloads the stored state, so the entries appear once it has run
+	assert.Eventually(t, func() bool {
+		_, loaded := pm.GetProcess("fresh")
+		_, exists := pm.GetProcess("stale")
+		return loaded && !exists
+	}, 2*time.Second, 10*time.Millisecond, "stale process should be removed by the janitor")
+
+	_, exists := pm.GetProcess("protected")
+	assert.True(t, exists)
+
+	require.NoError(t, pm.Close())
+	pm.mutex.RLock()
+	defer pm.mutex.RUnlock()
+	assert.Nil(t, pm.janitorCancel, "Close should stop the janitor")
+}
+
+// TestProcessManager_StateLockExcludesGoroutines shares one FileLock between a foreground
+// operation and the janitor of the same manager. FileLock is re-entrant per instance, so
+// without lockState the janitor would run under the foreground's lock and release it for
+// everyone on its Unlock.
+func TestProcessManager_StateLockExcludesGoroutines(t *testing.T) {
+	pm, mockStateStore, _, _ := setupTestProcessManager(t)
+	fileLock := lock.NewFileLock(filepath.Join(t.TempDir(), "portguard.lock"), 5*time.Second)
+	pm.lockManager = fileLock
+	pm.SetExecutor(newFakeExecutor())
+
+	proc := createTestProcess("proc", "npm run dev", 3000, StatusRunning)
+	pm.processes[proc.ID] = proc
+	mockStateStore.On("Load").Return(map[string]*ManagedProcess{proc.ID: proc.Clone()}, nil)
+	mockStateStore.On("Save", mock.AnythingOfType("map[string]*process.ManagedProcess")).Return(nil)
+
+	require.NoError(t, pm.lockState())
+	done := make(chan error, 1)
+	go func() {
+		_, err := pm.cleanupStaleProcesses(time.Hour)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		t.Fatalf("the janitor ran under the foreground's lock: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	assert.True(t, fileLock.IsLocked(), "the foreground still holds the lock")
+
+	pm.unlockState()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the janitor did not get the lock after it was released")
+	}
+	assert.False(t, fileLock.IsLocked())
+}
+
+func TestProcessManager_SetAutoCleanupDisabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval time.Duration
+		maxIdle  time.Duration
+	}{
+		{"zero_interval", 0, time.Minute},
+		{"zero_max_idle", 10 * time.Millisecond, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pm, _, _, _ := setupTestProcessManager(t)
+			stale := createTestProcess("stale", "old process", 3001, StatusRunning)
+			stale.LastSeen = time.Now().Add(-time.Hour)
+			pm.processes[stale.ID] = stale
+
+			pm.SetAutoCleanup(tt.interval, tt.maxIdle)
+			time.Sleep(50 * time.Millisecond)
+
+			_, exists := pm.GetProcess("stale")
+			assert.True(t, exists)
+			require.NoError(t, pm.Close())
+		})
+	}
+}
+
+func TestProcessManager_CloseStopsMonitors(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	pm, executor := setupFakeExecutorManager(t)
+	pm.SetMonitorInterval(10 * time.Millisecond)
+	pm.SetAutoCleanup(10*time.Millisecond, time.Hour)
+
+	proc, err := pm.StartProcess("server", []string{"--port", "3000"}, StartOptions{WorkingDir: t.TempDir()})
+	require.NoError(t, err)
+	require.Equal(t, 1, pm.ActiveMonitors())
+
+	require.NoError(t, pm.Close())
+	assert.Zero(t, pm.ActiveMonitors())
+	current, exists := pm.GetProcess(proc.ID)
+	require.True(t, exists)
+	assert.Equal(t, StatusRunning, current.Status, "closing the manager must not mark its processes failed")
+	assert.True(t, executor.IsAlive(proc.PID), "closing the manager must not stop its processes")
+
+	// A closed manager no longer starts monitors
+	adopted := createTestProcess("adopted", "npm run dev", 3001, StatusRunning)
+	require.NoError(t, pm.AdoptProcess(adopted))
+	assert.Zero(t, pm.ActiveMonitors())
+
+	// Let the simulated child exit so its reaper returns; VerifyNone retries until it has
+	executor.exitProcess(proc.PID, nil)
+}
@@ -0,0 +1,37 @@
+package process
+
+import "fmt"
+
+// SetMaxProcesses caps how many running or unhealthy processes StartProcess allows at once.
+// Zero or a negative value removes the limit.
+func (pm *ProcessManager) SetMaxProcesses(limit int) {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+	pm.maxProcesses = max(limit, 0)
+}
+
+// reserveProcessSlot checks the process limit and holds a slot until release is called, so
+// concurrent starts cannot all pass the check before any of them is stored
+func (pm *ProcessManager) reserveProcessSlot() (func(), error) {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+
+	if pm.maxProcesses > 0 {
+		active := pm.pendingStarts
+		for _, process := range pm.processes {
+			if process.IsRunning() {
+				active++
+			}
+		}
+		if active >= pm.maxProcesses {
+			return nil, fmt.Errorf("%w: %d of %d allowed", ErrTooManyProcesses, active, pm.maxProcesses)
+		}
+	}
+
+	pm.pendingStarts++
+	return func() {
+		pm.mutex.Lock()
+		defer pm.mutex.Unlock()
+		pm.pendingStarts--
+	}, nil
+}
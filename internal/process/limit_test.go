@@ -0,0 +1,92 @@
+package process
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessManager_MaxProcesses(t *testing.T) {
+	t.Run("rejects_start_over_limit", func(t *testing.T) {
+		pm, executor := setupFakeExecutorManager(t)
+		pm.SetMonitoringDisabled(true)
+		pm.SetMaxProcesses(2)
+
+		first, err := pm.StartProcess("server-a", nil, StartOptions{})
+		require.NoError(t, err)
+		_, err = pm.StartProcess("server-b", nil, StartOptions{})
+		require.NoError(t, err)
+
+		_, err = pm.StartProcess("server-c", nil, StartOptions{})
+		require.ErrorIs(t, err, ErrTooManyProcesses)
+		assert.Equal(t, 2, executor.startCount(), "the rejected start must not spawn anything")
+
+		// Reusing a running process does not need a new slot
+		reused, err := pm.StartProcess("server-a", nil, StartOptions{})
+		require.NoError(t, err)
+		assert.Same(t, first, reused)
+
+		// Stopped processes no longer count toward the limit
+		require.NoError(t, pm.StopProcess(first.ID, true))
+		_, err = pm.StartProcess("server-c", nil, StartOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, 3, executor.startCount())
+	})
+
+	t.Run("unhealthy_counts", func(t *testing.T) {
+		pm, _ := setupFakeExecutorManager(t)
+		pm.SetMonitoringDisabled(true)
+		pm.SetMaxProcesses(1)
+
+		proc, err := pm.StartProcess("server-a", nil, StartOptions{})
+		require.NoError(t, err)
+		pm.mutex.Lock()
+		proc.Status = StatusUnhealthy
+		pm.mutex.Unlock()
+
+		_, err = pm.StartProcess("server-b", nil, StartOptions{})
+		require.ErrorIs(t, err, ErrTooManyProcesses)
+	})
+
+	t.Run("concurrent_starts", func(t *testing.T) {
+		pm, executor := setupFakeExecutorManager(t)
+		pm.SetMonitoringDisabled(true)
+		pm.SetMaxProcesses(3)
+
+		var wg sync.WaitGroup
+		var rejected atomic.Int32
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := pm.StartProcess(fmt.Sprintf("server-%d", i), nil, StartOptions{})
+				if errors.Is(err, ErrTooManyProcesses) {
+					rejected.Add(1)
+				} else {
+					assert.NoError(t, err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, 3, executor.startCount())
+		assert.Equal(t, int32(7), rejected.Load())
+	})
+
+	t.Run("zero_means_unlimited", func(t *testing.T) {
+		pm, executor := setupFakeExecutorManager(t)
+		pm.SetMonitoringDisabled(true)
+		pm.SetMaxProcesses(0)
+
+		for i := 0; i < 5; i++ {
+			_, err := pm.StartProcess(fmt.Sprintf("server-%d", i), nil, StartOptions{})
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 5, executor.startCount())
+	})
+}
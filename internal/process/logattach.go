@@ -0,0 +1,82 @@
+package process
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// ErrOutputNotAttachable is returned when the output of a running process cannot be captured
+var ErrOutputNotAttachable = errors.New("process output cannot be attached")
+
+// TailOutput appends everything the process with the PID writes to sources, as returned by
+// OutputFiles, to logFile. It polls every interval and returns once the process has exited
+// and its last output was copied, or when ctx is canceled.
+func TailOutput(ctx context.Context, pid int, sources []string, logFile string, interval time.Duration) error {
+	dst, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file %s: %w", logFile, err)
+	}
+	defer func() { _ = dst.Close() }() //nolint:errcheck // Cleanup operation
+
+	// Start at the current end so output written before the attach is not duplicated
+	tails := make([]*os.File, 0, len(sources))
+	defer func() {
+		for _, src := range tails {
+			_ = src.Close() //nolint:errcheck // Cleanup operation
+		}
+	}()
+	for _, source := range sources {
+		src, err := os.Open(source)
+		if err != nil {
+			return fmt.Errorf("failed to open output of PID %d: %w", pid, err)
+		}
+		tails = append(tails, src)
+		if _, err := src.Seek(0, io.SeekEnd); err != nil {
+			return fmt.Errorf("failed to seek output of PID %d: %w", pid, err)
+		}
+	}
+
+	executor := NewOSExecutor()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		// Check liveness before copying so output written just before the exit is not lost
+		alive := executor.IsAlive(pid)
+		for _, src := range tails {
+			if err := copyAppended(dst, src); err != nil {
+				return err
+			}
+		}
+		if !alive {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// copyAppended copies what was written to src since the last call, starting over when the
+// file was truncated, e.g. by log rotation
+func copyAppended(dst io.Writer, src *os.File) error {
+	offset, err := src.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("failed to read process output: %w", err)
+	}
+	if info, err := src.Stat(); err == nil && info.Size() < offset {
+		if _, err := src.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to read process output: %w", err)
+		}
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		return fmt.Errorf("failed to copy process output: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package process
+
+import (
+	"fmt"
+	"os"
+)
+
+// OutputFiles returns paths that read what the process with the PID writes to stdout and
+// stderr. Only output redirected to regular files can be attached: reading a pipe would steal
+// the output from its reader, and a terminal cannot be read back. The paths go through
+// /proc/<pid>/fd, so files that were renamed or deleted since the process opened them work too.
+func OutputFiles(pid int) ([]string, error) {
+	var sources []string
+	var sourceInfos []os.FileInfo
+	for _, stream := range []struct {
+		fd   int
+		name string
+	}{{1, "stdout"}, {2, "stderr"}} {
+		source := fmt.Sprintf("/proc/%d/fd/%d", pid, stream.fd)
+		target, err := os.Readlink(source)
+		if err != nil {
+			if stream.fd == 1 {
+				return nil, fmt.Errorf("%w: cannot inspect stdout of PID %d: %w", ErrOutputNotAttachable, pid, err)
+			}
+			continue // Stderr that is closed or unreadable still leaves stdout to capture
+		}
+		info, err := os.Stat(source)
+		if err != nil || !info.Mode().IsRegular() {
+			if stream.fd == 1 {
+				return nil, fmt.Errorf("%w: stdout of PID %d is %s, not a file", ErrOutputNotAttachable, pid, target)
+			}
+			continue
+		}
+
+		// 2>&1 points both descriptors at the same file
+		duplicate := false
+		for _, seen := range sourceInfos {
+			duplicate = duplicate || os.SameFile(seen, info)
+		}
+		if !duplicate {
+			sources = append(sources, source)
+			sourceInfos = append(sourceInfos, info)
+		}
+	}
+	return sources, nil
+}
@@ -0,0 +1,136 @@
+package process
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startWithOutput starts a shell script with stdout and stderr wired as given and reaps it on exit
+func startWithOutput(t *testing.T, script string, stdin io.Reader, stdout, stderr *os.File) (*exec.Cmd, <-chan struct{}) {
+	t.Helper()
+	cmd := exec.Command("sh", "-c", script)
+	cmd.Stdin = stdin
+	if stdout != nil {
+		cmd.Stdout = stdout
+	}
+	if stderr != nil {
+		cmd.Stderr = stderr
+	}
+	require.NoError(t, cmd.Start())
+
+	exited := make(chan struct{})
+	go func() {
+		_ = cmd.Wait() //nolint:errcheck // Only reaping matters
+		close(exited)
+	}()
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill() //nolint:errcheck // Test cleanup
+		<-exited
+	})
+	return cmd, exited
+}
+
+func TestOutputFiles_Attachable(t *testing.T) {
+	dir := t.TempDir()
+	output, err := os.Create(filepath.Join(dir, "app.out"))
+	require.NoError(t, err)
+	defer func() { _ = output.Close() }() //nolint:errcheck // Test cleanup
+	errOutput, err := os.Create(filepath.Join(dir, "app.err"))
+	require.NoError(t, err)
+	defer func() { _ = errOutput.Close() }() //nolint:errcheck // Test cleanup
+
+	t.Run("stdout_and_stderr_to_one_file", func(t *testing.T) {
+		cmd, _ := startWithOutput(t, "sleep 5", nil, output, output)
+		sources, err := OutputFiles(cmd.Process.Pid)
+		require.NoError(t, err)
+		assert.Len(t, sources, 1)
+	})
+
+	t.Run("separate_files", func(t *testing.T) {
+		cmd, _ := startWithOutput(t, "sleep 5", nil, output, errOutput)
+		sources, err := OutputFiles(cmd.Process.Pid)
+		require.NoError(t, err)
+		assert.Len(t, sources, 2)
+	})
+
+	t.Run("tails_new_output_until_exit", func(t *testing.T) {
+		outPath := filepath.Join(dir, "tail.out")
+		out, err := os.Create(outPath)
+		require.NoError(t, err)
+		defer func() { _ = out.Close() }() //nolint:errcheck // Test cleanup
+
+		// The script waits for a line on stdin before writing more
+		stdinReader, stdin, err := os.Pipe()
+		require.NoError(t, err)
+		defer func() { _ = stdin.Close() }() //nolint:errcheck // Test cleanup
+		cmd, exited := startWithOutput(t, "echo before; read line; echo after; echo oops >&2", stdinReader, out, out)
+		_ = stdinReader.Close() //nolint:errcheck // The child holds its own copy
+
+		require.Eventually(t, func() bool {
+			data, _ := os.ReadFile(outPath) //nolint:errcheck // Retried until it succeeds
+			return strings.Contains(string(data), "before")
+		}, 5*time.Second, 10*time.Millisecond)
+
+		sources, err := OutputFiles(cmd.Process.Pid)
+		require.NoError(t, err)
+
+		logFile := filepath.Join(dir, "adopted.log")
+		done := make(chan error, 1)
+		go func() {
+			done <- TailOutput(context.Background(), cmd.Process.Pid, sources, logFile, 20*time.Millisecond)
+		}()
+
+		// Let the tailer seek to the end before the process writes more
+		time.Sleep(100 * time.Millisecond)
+		_, err = stdin.WriteString("\n")
+		require.NoError(t, err)
+		<-exited
+
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("TailOutput did not return after the process exited")
+		}
+
+		data, err := os.ReadFile(logFile)
+		require.NoError(t, err)
+		assert.Equal(t, "after\noops\n", string(data), "only output written after attaching is captured")
+	})
+}
+
+func TestOutputFiles_NotAttachable(t *testing.T) {
+	t.Run("pipe", func(t *testing.T) {
+		reader, writer, err := os.Pipe()
+		require.NoError(t, err)
+		defer func() { _ = reader.Close() }() //nolint:errcheck // Test cleanup
+		cmd, _ := startWithOutput(t, "sleep 5", nil, writer, nil)
+		_ = writer.Close() //nolint:errcheck // The child holds its own copy
+
+		_, err = OutputFiles(cmd.Process.Pid)
+		require.ErrorIs(t, err, ErrOutputNotAttachable)
+		assert.Contains(t, err.Error(), "pipe:")
+	})
+
+	t.Run("null_device", func(t *testing.T) {
+		cmd, _ := startWithOutput(t, "sleep 5", nil, nil, nil)
+
+		_, err := OutputFiles(cmd.Process.Pid)
+		require.ErrorIs(t, err, ErrOutputNotAttachable)
+		assert.Contains(t, err.Error(), "/dev/null")
+	})
+
+	t.Run("no_such_process", func(t *testing.T) {
+		_, err := OutputFiles(999999999)
+		require.ErrorIs(t, err, ErrOutputNotAttachable)
+	})
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+// +build !linux
+
+package process
+
+import "fmt"
+
+// OutputFiles returns paths that read what the process with the PID writes. Only Linux
+// exposes the output of another running process, through /proc/<pid>/fd.
+func OutputFiles(pid int) ([]string, error) {
+	return nil, fmt.Errorf("%w: attaching to the output of PID %d is only supported on Linux; "+
+		"restart it with portguard start --log-file to capture its output", ErrOutputNotAttachable, pid)
+}
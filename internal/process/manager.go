@@ -6,39 +6,142 @@ package process
 import (
 	"context"
 	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
+	"unicode"
 
 	"github.com/paveg/portguard/internal/port"
 )
 
 // Static error variables to satisfy err113 linter
 var (
-	ErrPortAlreadyInUse = errors.New("cannot start process: port is already in use")
-	ErrProcessNotFound  = errors.New("process not found")
+	ErrPortAlreadyInUse  = errors.New("cannot start process: port is already in use")
+	ErrProcessNotFound   = errors.New("process not found")
+	ErrHealthWaitTimeout = errors.New("process did not become healthy before timeout")
+	ErrUnexpectedBody    = errors.New("HTTP health check response did not match expectation")
+	ErrBodyTooLarge      = errors.New("HTTP health check response body too large")
+	ErrUnexpectedBanner  = errors.New("TCP health check reply did not match expectation")
+	ErrInvalidRestart    = errors.New("invalid restart policy")
+	ErrUnsafeCleanupPath = errors.New("refusing to remove unsafe working directory")
+	ErrNoAvailablePort   = errors.New("no available port in range")
+	ErrUnknownSignal     = errors.New("unknown signal")
+	ErrReadyLogTimeout   = errors.New("process did not log its ready pattern before timeout")
+	ErrExitedBeforeReady = errors.New("process exited before logging its ready pattern")
+	ErrInvalidReadyLog   = errors.New("invalid ready log pattern")
+	ErrStartFailed       = errors.New("failed to start process")
+	ErrStateNotWritable  = errors.New("process state cannot be persisted")
+	ErrPrivilegedPort    = errors.New("port requires elevated privileges")
+	ErrWorkingDirMissing = errors.New("working directory does not exist")
+	ErrWorkingDirNotDir  = errors.New("working directory is not a directory")
+	ErrNoHealthCheck     = errors.New("no health check configured")
+	ErrTooManyProcesses  = errors.New("too many managed processes running")
+	ErrCloseTimeout      = errors.New("background monitors did not stop before timeout")
+	ErrNamespace         = errors.New("cannot run health check in the process network namespace")
+	ErrPreStartFailed    = errors.New("pre-start hook failed")
+	ErrEmptyHook         = errors.New("empty hook command")
+	ErrStatusChanged     = errors.New("process status changed concurrently")
+	ErrCleanupNeedsDir   = errors.New("cleanup of the working directory requires an explicit working directory")
 )
 
+// Defaults used when waiting for a freshly started process to become healthy
+const (
+	defaultWaitTimeout        = 30 * time.Second
+	defaultWaitPollInterval   = 500 * time.Millisecond
+	defaultHealthCheckTimeout = 5 * time.Second
+
+	// defaultMonitorInterval is how often background monitors check that a process is alive
+	defaultMonitorInterval = 500 * time.Millisecond
+	// defaultHealthCheckInterval spaces health checks that do not set an interval
+	defaultHealthCheckInterval = 10 * time.Second
+	// closeTimeout bounds how long Close waits for background monitors to return
+	closeTimeout = 5 * time.Second
+)
+
+// defaultMaxRestarts is the restart limit used when a restart policy is set without MaxRestarts
+const defaultMaxRestarts = 3
+
+// maxHealthCheckBodySize bounds how much of an HTTP health check response is read
+const maxHealthCheckBodySize = 1 << 20
+
+// maxHealthCheckBannerSize bounds how much of a TCP health check reply is read
+const maxHealthCheckBannerSize = 4 << 10
+
 // ProcessManager manages all processes for portguard
 type ProcessManager struct {
 	processes   map[string]*ManagedProcess
+	portIndex   map[int]string // Port to ID of the running process bound to it
 	mutex       sync.RWMutex
 	stateStore  StateStore
 	lockManager LockManager
 	portScanner PortScanner
+	logger      *slog.Logger
+
+	monitoringDisabled  bool               // Skip background monitors for new and adopted processes
+	activeMonitors      atomic.Int32       // Number of running background monitors
+	monitors            map[string]int     // Running background monitors per process ID, guarded by mutex
+	healthCheckFailures atomic.Uint64      // Failed health checks since the manager was created
+	backupRetention     time.Duration      // How long state backups are kept; zero keeps them all
+	notifier            Notifier           // Receives status transitions from background monitors; nil disables
+	notifyTimeout       time.Duration      // Upper bound for a single notification
+	executor            Executor           // Starts and controls processes; nil uses defaultExecutor
+	monitorInterval     time.Duration      // Liveness polling interval of background monitors; zero uses the default
+	hookTimeout         time.Duration      // Run time limit of hook commands; zero uses DefaultHookTimeout
+	maxProcesses        int                // Cap on running and unhealthy processes; zero means no limit
+	pendingStarts       int                // Starts that passed the limit check but are not stored yet, guarded by mutex
+	saveInterval        time.Duration      // Batching window for status change saves; zero saves immediately
+	pendingSaves        map[string]bool    // IDs of processes with batched status changes, guarded by mutex
+	saveTimer           *time.Timer        // Fires the batched save, guarded by mutex
+	saveMutex           sync.Mutex         // Serializes flushes so batched changes are written in order
+	stateMutex          sync.Mutex         // Serializes holders of lockManager within this process; see lockState
+	janitorCancel       func()             // Stops the stale process janitor, guarded by mutex
+	janitorDone         chan struct{}      // Closed when the janitor returns, guarded by mutex
+	background          context.Context    // Parent of all background monitors, guarded by mutex; Close cancels it
+	cancelBackground    context.CancelFunc // Cancels background, guarded by mutex
+	monitorWG           sync.WaitGroup     // Tracks background monitor goroutines so Close can wait for them
 }
 
+// defaultExecutor runs real processes for managers without an explicit executor
+var defaultExecutor Executor = NewOSExecutor()
+
 // StateStore interface for persisting process state
 type StateStore interface {
 	Save(processes map[string]*ManagedProcess) error
 	Load() (map[string]*ManagedProcess, error)
 	Delete(id string) error
+	BackupState() error
+	CleanupOldBackups(maxAge time.Duration) error
+}
+
+// WritableChecker is implemented by state stores that can verify up front that Save will succeed.
+// StartProcess uses it to fail before spawning a process it could not keep track of.
+type WritableChecker interface {
+	CheckWritable() error
+}
+
+// PrivilegeChecker is implemented by port scanners that can tell whether binding a port needs
+// privileges the current user lacks. StartProcess uses it to fail before the child hits a bind error.
+type PrivilegeChecker interface {
+	NeedsPrivileges(port int) bool
 }
 
 // LockManager interface for managing concurrent access
@@ -56,116 +159,887 @@ type PortScanner interface {
 	FindAvailablePort(startPort int) (int, error)
 }
 
+// DefaultBackupRetention is how long state backups are kept unless configured otherwise
+const DefaultBackupRetention = 7 * 24 * time.Hour
+
+// DefaultHookTimeout is how long a pre-start or post-stop hook may run unless configured otherwise
+const DefaultHookTimeout = 2 * time.Minute
+
 // NewProcessManager creates a new ProcessManager instance
 func NewProcessManager(stateStore StateStore, lockManager LockManager, portScanner PortScanner) *ProcessManager {
 	pm := &ProcessManager{
-		processes:   make(map[string]*ManagedProcess),
-		stateStore:  stateStore,
-		lockManager: lockManager,
-		portScanner: portScanner,
+		processes:       make(map[string]*ManagedProcess),
+		stateStore:      stateStore,
+		lockManager:     lockManager,
+		portScanner:     portScanner,
+		backupRetention: DefaultBackupRetention,
+		executor:        defaultExecutor,
 	}
 
 	// Load existing processes from storage
 	if loadedProcesses, err := stateStore.Load(); err == nil {
 		pm.processes = loadedProcesses
 	}
+	pm.rebuildPortIndex()
 
 	return pm
 }
 
-// generateID generates a unique ID for a process based on command and timestamp
+// SetLogger sets the logger used for background monitoring and status changes.
+// Passing nil discards log output.
+func (pm *ProcessManager) SetLogger(logger *slog.Logger) {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+	pm.logger = logger
+}
+
+// SetExecutor replaces the executor used to start and control processes.
+// It must be called before any process is started.
+func (pm *ProcessManager) SetExecutor(executor Executor) {
+	pm.executor = executor
+}
+
+// processExecutor returns the configured executor, or the OS executor when none is set
+func (pm *ProcessManager) processExecutor() Executor {
+	if pm.executor == nil {
+		return defaultExecutor
+	}
+	return pm.executor
+}
+
+// SetMonitoringDisabled turns off background monitoring for processes started or adopted afterwards.
+// Without a monitor, status and restart policies are not updated until an explicit refresh.
+func (pm *ProcessManager) SetMonitoringDisabled(disabled bool) {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+	pm.monitoringDisabled = disabled
+}
+
+// SetMonitorInterval sets how often background monitors started afterwards check that their
+// process is alive. Health checks follow their own interval. Zero restores the default.
+func (pm *ProcessManager) SetMonitorInterval(interval time.Duration) {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+	pm.monitorInterval = interval
+}
+
+// SetHookTimeout sets how long the pre-start and post-stop hooks portguard waits on may
+// run before they are killed. Managed processes themselves never time out. Zero restores the default.
+func (pm *ProcessManager) SetHookTimeout(timeout time.Duration) {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+	pm.hookTimeout = timeout
+}
+
+// hookLimit returns the run time limit of a single hook command
+func (pm *ProcessManager) hookLimit() time.Duration {
+	pm.mutex.RLock()
+	defer pm.mutex.RUnlock()
+	if pm.hookTimeout > 0 {
+		return pm.hookTimeout
+	}
+	return DefaultHookTimeout
+}
+
+// SetBackupRetention sets how long state backups taken before destructive operations are kept.
+// Zero keeps every backup.
+func (pm *ProcessManager) SetBackupRetention(retention time.Duration) {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+	pm.backupRetention = retention
+}
+
+// backupState snapshots the persisted state before a destructive save and prunes old backups.
+// It is best effort: failures are logged and never block the caller. Callers may hold pm.mutex,
+// so the logger and retention are passed in.
+func (pm *ProcessManager) backupState(logger *slog.Logger, retention time.Duration) {
+	if err := pm.stateStore.BackupState(); err != nil {
+		logger.Warn("failed to back up state", "error", err)
+		return
+	}
+	if retention <= 0 {
+		return
+	}
+	if err := pm.stateStore.CleanupOldBackups(retention); err != nil {
+		logger.Warn("failed to prune old state backups", "error", err)
+	}
+}
+
+// SetNotifier sets the notifier for status transitions detected by background monitors.
+// Each notification runs in its own goroutine bounded by timeout (DefaultNotifyTimeout if zero),
+// so a slow endpoint never stalls monitoring. Passing nil disables notifications.
+func (pm *ProcessManager) SetNotifier(notifier Notifier, timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultNotifyTimeout
+	}
+
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+	pm.notifier = notifier
+	pm.notifyTimeout = timeout
+}
+
+// notifyTransition fires a notification for notable status changes without blocking the caller
+func (pm *ProcessManager) notifyTransition(process *ManagedProcess, from, to ProcessStatus) {
+	kind, notable := transitionEvent(from, to)
+	if !notable {
+		return
+	}
+
+	pm.mutex.RLock()
+	notifier, timeout := pm.notifier, pm.notifyTimeout
+	event := Event{
+		Kind:      kind,
+		ProcessID: process.ID,
+		Command:   process.Command,
+		PID:       process.PID,
+		Port:      process.Port,
+		From:      from,
+		To:        to,
+		Time:      time.Now(),
+	}
+	pm.mutex.RUnlock()
+	if notifier == nil {
+		return
+	}
+
+	logger := pm.log()
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if err := notifier.Notify(ctx, event); err != nil {
+			logger.Warn("failed to send notification", "process_id", event.ProcessID, "kind", event.Kind, "error", err)
+		}
+	}()
+}
+
+// Close stops background monitors and the stale process janitor, waiting up to closeTimeout for
+// the monitors to return, and then writes batched state changes. Managed processes keep running.
+// Processes started or adopted afterwards are not monitored.
+func (pm *ProcessManager) Close() error {
+	pm.stopJanitor()
+
+	pm.mutex.Lock()
+	pm.backgroundContextLocked()
+	pm.cancelBackground()
+	pm.mutex.Unlock()
+
+	stopped := make(chan struct{})
+	go func() {
+		pm.monitorWG.Wait()
+		close(stopped)
+	}()
+
+	var waitErr error
+	select {
+	case <-stopped:
+	case <-time.After(closeTimeout):
+		waitErr = fmt.Errorf("%w: %d still running after %s", ErrCloseTimeout, pm.ActiveMonitors(), closeTimeout)
+	}
+	return errors.Join(waitErr, pm.Flush())
+}
+
+// lockState acquires the state lock. Lockers are re-entrant per instance, so monitors, the
+// janitor and batched saves of this manager would pass straight through a lock held by a
+// foreground operation and release it on their Unlock; stateMutex makes them wait instead.
+// Callers must not hold pm.mutex and must release the lock with unlockState.
+func (pm *ProcessManager) lockState() error {
+	pm.stateMutex.Lock()
+	if err := pm.lockManager.Lock(); err != nil {
+		pm.stateMutex.Unlock()
+		return fmt.Errorf("failed to acquire lock: %w", err)
+	}
+	return nil
+}
+
+// unlockState releases the state lock acquired by lockState
+func (pm *ProcessManager) unlockState() {
+	_ = pm.lockManager.Unlock() //nolint:errcheck // Unlock completes regardless
+	pm.stateMutex.Unlock()
+}
+
+// ActiveMonitors returns the number of running background monitors
+func (pm *ProcessManager) ActiveMonitors() int {
+	return int(pm.activeMonitors.Load())
+}
+
+// startMonitor launches the background monitor for a process unless monitoring is disabled
+func (pm *ProcessManager) startMonitor(process *ManagedProcess, noMonitor bool) bool {
+	pm.mutex.RLock()
+	disabled := pm.monitoringDisabled || noMonitor
+	pm.mutex.RUnlock()
+	if disabled {
+		pm.log().Debug("background monitoring disabled", "process_id", process.ID, "pid", process.PID)
+		return false
+	}
+
+	pm.mutex.Lock()
+	ctx := pm.backgroundContextLocked()
+	if ctx.Err() != nil {
+		pm.mutex.Unlock()
+		pm.log().Debug("process manager closed, not monitoring", "process_id", process.ID, "pid", process.PID)
+		return false
+	}
+	if pm.monitors == nil {
+		pm.monitors = make(map[string]int)
+	}
+	pm.monitors[process.ID]++
+	// Added under the mutex so Close cannot start waiting between the closed check and Add
+	pm.monitorWG.Add(1)
+	pm.mutex.Unlock()
+
+	pm.activeMonitors.Add(1)
+	go func() {
+		defer pm.monitorWG.Done()
+		defer pm.activeMonitors.Add(-1)
+		defer func() {
+			pm.mutex.Lock()
+			if pm.monitors[process.ID]--; pm.monitors[process.ID] <= 0 {
+				delete(pm.monitors, process.ID)
+			}
+			pm.mutex.Unlock()
+		}()
+		pm.monitorProcessInBackground(ctx, process)
+	}()
+	return true
+}
+
+// backgroundContextLocked returns the context background goroutines derive from, creating it on
+// first use. Callers hold pm.mutex.
+func (pm *ProcessManager) backgroundContextLocked() context.Context {
+	if pm.background == nil {
+		pm.background, pm.cancelBackground = context.WithCancel(context.Background())
+	}
+	return pm.background
+}
+
+// SetHealthCheck attaches, replaces or (with nil) removes the health check of a managed process
+// and persists it. A running process without a background monitor, such as one adopted by an
+// earlier command, gets one so the check starts running. Disabled checks are stored but not run.
+func (pm *ProcessManager) SetHealthCheck(id string, healthCheck *HealthCheck) error {
+	var updated *HealthCheck
+	if healthCheck != nil {
+		if err := healthCheck.Validate(); err != nil {
+			return err
+		}
+		updated = healthCheck.Clone()
+	}
+
+	if err := pm.lockState(); err != nil {
+		return err
+	}
+	defer pm.unlockState()
+
+	pm.mutex.Lock()
+	process, exists := pm.processes[id]
+	if !exists {
+		pm.mutex.Unlock()
+		return fmt.Errorf("%w: %s", ErrProcessNotFound, id)
+	}
+	// Replace rather than mutate so health checks already in flight keep a consistent copy
+	process.HealthCheck = updated
+	process.UpdatedAt = time.Now()
+	needsMonitor := updated != nil && process.IsRunning() && pm.monitors[id] == 0 && !process.NoMonitor
+	processesCopy := pm.snapshotLocked()
+	pm.mutex.Unlock()
+
+	if err := pm.stateStore.Save(processesCopy); err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+
+	if needsMonitor {
+		pm.startMonitor(process, false)
+	}
+	return nil
+}
+
+// SetProtected marks a process as protected from blanket cleanup, or clears the mark
+func (pm *ProcessManager) SetProtected(id string, protected bool) error {
+	if err := pm.lockState(); err != nil {
+		return err
+	}
+	defer pm.unlockState()
+
+	pm.mutex.Lock()
+	process, exists := pm.processes[id]
+	if !exists {
+		pm.mutex.Unlock()
+		return fmt.Errorf("%w: %s", ErrProcessNotFound, id)
+	}
+	process.Protected = protected
+	process.UpdatedAt = time.Now()
+	processesCopy := pm.snapshotLocked()
+	pm.mutex.Unlock()
+
+	if err := pm.stateStore.Save(processesCopy); err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+	return nil
+}
+
+// log returns the configured logger, or one that discards output when none is set
+func (pm *ProcessManager) log() *slog.Logger {
+	pm.mutex.RLock()
+	defer pm.mutex.RUnlock()
+	if pm.logger == nil {
+		return slog.New(slog.DiscardHandler)
+	}
+	return pm.logger
+}
+
+// activeStatuses are the statuses a health check result may replace. A process that left them
+// while its check was running, e.g. because it was stopped, keeps its newer status.
+var activeStatuses = []ProcessStatus{StatusPending, StatusRunning, StatusUnhealthy}
+
+// setStatus updates the process status and logs failures instead of returning them.
+// It is used by background operations that have no caller to report errors to.
+// With expected statuses, a process whose status changed in the meantime is left alone.
+func (pm *ProcessManager) setStatus(process *ManagedProcess, status ProcessStatus, expected ...ProcessStatus) {
+	previous, err := pm.swapProcessStatus(process.ID, status, expected...)
+	if errors.Is(err, ErrStatusChanged) {
+		pm.log().Debug("discarded stale status update",
+			"process_id", process.ID, "pid", process.PID, "status", status, "current", previous)
+		return
+	}
+	if err != nil {
+		pm.log().Warn("failed to update process status",
+			"process_id", process.ID, "pid", process.PID, "status", status, "error", err)
+		return
+	}
+	if previous != status {
+		pm.log().Debug("process status changed",
+			"process_id", process.ID, "pid", process.PID, "from", previous, "to", status)
+		pm.notifyTransition(process, previous, status)
+	}
+}
+
+const (
+	idHashLength    = 6  // Hex characters of the hash in a new process ID
+	maxIDSlugLength = 12 // Longest command slug used as an ID prefix
+)
+
+// idSkipWords are command words that add nothing to an ID slug
+var idSkipWords = map[string]bool{"run": true, "exec": true}
+
+// generateID generates a readable, unique process ID such as "npm-dev-a1b2c3" from the command.
+// Callers must hold pm.mutex so the ID cannot be taken before the process is stored.
 func (pm *ProcessManager) generateID(command string) string {
-	hash := sha256.Sum256([]byte(fmt.Sprintf("%s-%d", command, time.Now().UnixNano())))
-	return fmt.Sprintf("%x", hash)[:8] //nolint:perfsprint // TODO: Use hex.EncodeToString for better performance
+	slug := commandSlug(command)
+	for attempt := 0; ; attempt++ {
+		sum := sha256.Sum256(fmt.Appendf(nil, "%s-%d-%d", command, time.Now().UnixNano(), attempt))
+		if id, ok := pm.uniqueID(slug, hex.EncodeToString(sum[:])); ok {
+			return id
+		}
+	}
 }
 
-// ShouldStartNew determines if a new process should be started or an existing one reused
-func (pm *ProcessManager) ShouldStartNew(command string, portNum int) (bool, *ManagedProcess) {
+// uniqueID returns slug plus the shortest hash prefix, extended on collision, not already in use.
+// Callers must hold pm.mutex.
+func (pm *ProcessManager) uniqueID(slug, hash string) (string, bool) {
+	for length := idHashLength; length <= len(hash); length += 2 {
+		id := slug + "-" + hash[:length]
+		if _, exists := pm.processes[id]; !exists {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// commandSlug derives a short lowercase [a-z0-9-] prefix from the first meaningful words of a command
+func commandSlug(command string) string {
+	var words []string
+	for _, field := range strings.Fields(command) {
+		// Flags and assignments describe options, not the program
+		if strings.HasPrefix(field, "-") || strings.Contains(field, "=") {
+			continue
+		}
+		base := filepath.Base(field)
+		if trimmed := strings.TrimSuffix(base, filepath.Ext(base)); trimmed != "" {
+			base = trimmed
+		}
+		word := strings.Map(func(r rune) rune {
+			r = unicode.ToLower(r)
+			if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+				return r
+			}
+			return -1
+		}, base)
+		if word == "" || idSkipWords[word] {
+			continue
+		}
+		words = append(words, word)
+		if len(words) == 2 {
+			break
+		}
+	}
+
+	slug := strings.Join(words, "-")
+	if len(slug) > maxIDSlugLength {
+		slug = strings.TrimRight(slug[:maxIDSlugLength], "-")
+	}
+	if slug == "" {
+		return "proc"
+	}
+	return slug
+}
+
+// StartDecisionKind describes the outcome of duplicate and conflict detection
+type StartDecisionKind string
+
+// Start decision kinds
+const (
+	DecisionStartNew         StartDecisionKind = "start_new"         // No conflicts, a new process can be started
+	DecisionReuse            StartDecisionKind = "reuse"             // The same command is already running
+	DecisionConflictManaged  StartDecisionKind = "conflict_managed"  // A port is held by a different managed process
+	DecisionConflictExternal StartDecisionKind = "conflict_external" // A port is held by a process portguard does not manage
+)
+
+// StartDecision is the result of deciding whether a command can be started
+type StartDecision struct {
+	Kind    StartDecisionKind
+	Process *ManagedProcess // Process to reuse, or the managed process holding the port
+	Port    int             // Conflicting port for conflict decisions
+}
+
+// DecideStart determines whether a new process should be started, an existing one reused,
+// or whether a managed or external process holds one of the requested ports.
+// Additional ports are checked alongside the primary port for multi-port servers.
+// Only processes running in the current directory are reused.
+func (pm *ProcessManager) DecideStart(command string, portNum int, extraPorts ...int) StartDecision {
+	return pm.decideStart(command, effectiveWorkingDir(""), "", normalizePorts(portNum, extraPorts))
+}
+
+// decideStart implements DecideStart. Only processes running in workingDir are reused. With a
+// bind address, ports held only on interfaces that do not overlap it are free to use.
+func (pm *ProcessManager) decideStart(command, workingDir, bindAddress string, ports []int) StartDecision {
 	pm.mutex.RLock()
 	defer pm.mutex.RUnlock()
 
-	// 1. Check if exact command is already running
+	// 1. Check if exact command is already running in the same directory
 	for _, process := range pm.processes {
-		if process.Command == command && process.IsHealthy() {
-			return false, process // Reuse existing healthy process
+		if process.Command == command && process.IsHealthy() && process.runsIn(workingDir) {
+			return StartDecision{Kind: DecisionReuse, Process: process}
 		}
 	}
 
-	// 2. Check port availability if specified
-	//nolint:nestif // Complex port conflict logic is necessary for correctness
-	if portNum > 0 {
-		if pm.portScanner.IsPortInUse(portNum) {
-			// Check if the port is occupied by one of our managed processes
-			for _, process := range pm.processes {
-				if process.Port == portNum && process.IsRunning() {
-					// Only return the process if it's the same command
-					if process.Command == command {
-						return false, process // Same command, reuse process
-					}
-					// Different command using same port - this is a conflict
-					return false, nil // Port occupied by different command
+	// 2. Check availability of every requested port
+nextPort:
+	for _, requestedPort := range ports {
+		if !pm.portScanner.IsPortInUse(requestedPort) {
+			continue
+		}
+
+		// Check if the port is occupied by one of our managed processes
+		for _, process := range pm.processes {
+			if process.UsesPort(requestedPort) && process.IsRunning() {
+				if bindAddress != "" && !port.BindingsConflict(process.BindAddress, bindAddress) {
+					continue nextPort
+				}
+				if process.Command == command && process.runsIn(workingDir) {
+					return StartDecision{Kind: DecisionReuse, Process: process, Port: requestedPort}
 				}
+				return StartDecision{Kind: DecisionConflictManaged, Process: process, Port: requestedPort}
 			}
-			return false, nil // Port occupied by external process
 		}
+		if bindAddress != "" {
+			if info, err := pm.portScanner.GetPortInfo(requestedPort); err == nil && info.BindAddress != "" &&
+				!info.ConflictsWith(bindAddress) {
+				continue
+			}
+		}
+		return StartDecision{Kind: DecisionConflictExternal, Port: requestedPort}
+	}
+
+	// 3. Safe to start new process
+	return StartDecision{Kind: DecisionStartNew}
+}
+
+// ShouldStartNew determines if a new process should be started or an existing one reused.
+// It returns (false, nil) for both managed and external port conflicts; use DecideStart to tell them apart.
+func (pm *ProcessManager) ShouldStartNew(command string, portNum int, extraPorts ...int) (bool, *ManagedProcess) {
+	decision := pm.DecideStart(command, portNum, extraPorts...)
+	switch decision.Kind {
+	case DecisionStartNew:
+		return true, nil
+	case DecisionReuse:
+		return false, decision.Process
+	case DecisionConflictManaged, DecisionConflictExternal:
+		return false, nil
+	}
+	return false, nil
+}
+
+// normalizePorts combines a primary port with additional ports, dropping zero and duplicate entries
+func normalizePorts(primary int, extra []int) []int {
+	ports := make([]int, 0, len(extra)+1)
+	seen := make(map[int]bool, len(extra)+1)
+	for _, portNum := range append([]int{primary}, extra...) {
+		if portNum <= 0 || seen[portNum] {
+			continue
+		}
+		seen[portNum] = true
+		ports = append(ports, portNum)
+	}
+	return ports
+}
+
+// StartProcess starts a new process or returns an existing one.
+// When options.ReadyLogPattern is set it blocks until the pattern appears in the log file, and
+// when options.WaitHealthy is set until the health check passes, each bounded by options.WaitTimeout.
+func (pm *ProcessManager) StartProcess(command string, args []string, options StartOptions) (*ManagedProcess, error) {
+	if !options.RestartPolicy.IsValid() {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRestart, options.RestartPolicy)
+	}
+
+	var readyPattern *regexp.Regexp
+	if options.ReadyLogPattern != "" {
+		if options.LogFile == "" {
+			return nil, fmt.Errorf("%w: a log file is required", ErrInvalidReadyLog)
+		}
+		var err error
+		if readyPattern, err = regexp.Compile(options.ReadyLogPattern); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidReadyLog, err)
+		}
+	}
+
+	if len(options.PreStart) > 0 {
+		reused, err := pm.runHooksBeforeStart(command, args, options)
+		if err != nil || reused != nil {
+			return reused, err
+		}
+	}
+
+	process, started, err := pm.startProcessLocked(command, args, options)
+	if err != nil {
+		return nil, err
+	}
+
+	// Wait outside the lock so other portguard invocations are not blocked
+	if started && readyPattern != nil {
+		if err := pm.waitForLogPattern(process, readyPattern, options.WaitTimeout); err != nil {
+			return nil, err
+		}
+	}
+	if started && options.WaitHealthy {
+		if err := pm.waitForHealthy(process, options.WaitTimeout); err != nil {
+			return nil, err
+		}
+	}
+
+	return process, nil
+}
+
+// runHooksBeforeStart runs the pre-start hooks of a process that is about to be started.
+// Hooks can run for minutes, so they run without holding the state lock and
+// startProcessLocked decides again once it has the lock. A process that would be reused
+// is retained and returned without running the hooks.
+func (pm *ProcessManager) runHooksBeforeStart(command string, args []string, options StartOptions) (*ManagedProcess, error) {
+	if err := pm.lockState(); err != nil {
+		return nil, err
+	}
+	_, _, options, decision, err := pm.planStart(command, args, options)
+	if err == nil && decision.Kind == DecisionReuse {
+		pm.retainProcess(decision.Process)
+	}
+	pm.unlockState()
+
+	if err != nil {
+		return nil, err
+	}
+	if decision.Kind == DecisionReuse {
+		return decision.Process, nil
+	}
+	return nil, pm.runPreStartHooks(options)
+}
+
+// startProcessLocked performs duplicate detection and process execution under the lock.
+// It reports whether a new process was started rather than an existing one reused.
+func (pm *ProcessManager) startProcessLocked(command string, args []string, options StartOptions) (*ManagedProcess, bool, error) {
+	if err := pm.lockState(); err != nil {
+		return nil, false, err
+	}
+	defer pm.unlockState()
+
+	command, args, options, decision, err := pm.planStart(command, args, options)
+	if err != nil {
+		return nil, false, err
+	}
+	if decision.Kind == DecisionReuse {
+		pm.retainProcess(decision.Process)
+		return decision.Process, false, nil // Reuse existing process
+	}
+
+	if err := pm.checkPrivilegedPorts(options); err != nil {
+		return nil, false, err
+	}
+
+	release, err := pm.reserveProcessSlot()
+	if err != nil {
+		return nil, false, err
+	}
+	defer release()
+
+	// Fail before spawning a process that could not be tracked
+	if checker, ok := pm.stateStore.(WritableChecker); ok {
+		if err := checker.CheckWritable(); err != nil {
+			return nil, false, fmt.Errorf("%w: %w", ErrStateNotWritable, err)
+		}
+	}
+
+	// Actually start the process using the new executeProcess method
+	actualProcess, err := pm.executeProcess(command, args, options)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %w", ErrStartFailed, err)
+	}
+
+	// Assign the ID and store the process under one lock so concurrent starts cannot share an ID
+	pm.mutex.Lock()
+	actualProcess.ID = pm.generateID(actualProcess.Command)
+
+	if err := actualProcess.Validate(); err != nil {
+		pm.mutex.Unlock()
+		_ = pm.terminateProcess(actualProcess, true) //nolint:errcheck // Best effort, the validation error is what matters
+		return nil, false, fmt.Errorf("started process is invalid: %w", err)
+	}
+
+	pm.processes[actualProcess.ID] = actualProcess
+	pm.indexProcessPorts(actualProcess)
+	// Create a copy of the processes map for safe concurrent access to stateStore
+	processesCopy := pm.snapshotLocked()
+	pm.mutex.Unlock()
+
+	// Persist to storage using the copy to avoid race conditions
+	if err := pm.stateStore.Save(processesCopy); err != nil {
+		// Stop the process rather than leave it running where no later command can find it
+		pm.mutex.Lock()
+		delete(pm.processes, actualProcess.ID)
+		pm.unindexProcessPorts(actualProcess.ID)
+		pm.mutex.Unlock()
+		markStopRequested(actualProcess)
+		_ = pm.terminateProcess(actualProcess, true) //nolint:errcheck // Best effort, the save error is what matters
+		return nil, false, fmt.Errorf("%w: process %s was started and then stopped: %w", ErrStateNotWritable, actualProcess.ID, err)
+	}
+
+	// Start background monitoring for the process
+	pm.startMonitor(actualProcess, options.NoMonitor)
+
+	return actualProcess, true, nil
+}
+
+// planStart expands the command for its port and decides whether to start or reuse it,
+// moving to a free port when AutoPort allows. Port conflicts are returned as errors, so the
+// decision is either DecisionStartNew or DecisionReuse. Callers must hold the lock.
+func (pm *ProcessManager) planStart(rawCommand string, rawArgs []string, rawOptions StartOptions,
+) (string, []string, StartOptions, StartDecision, error) {
+	command, args, options, err := prepareCommand(rawCommand, rawArgs, rawOptions, rawOptions.Port)
+	if err != nil {
+		return "", nil, options, StartDecision{}, err
+	}
+
+	decision := pm.decideStart(command, effectiveWorkingDir(options.WorkingDir), options.BindAddress,
+		normalizePorts(options.Port, options.Ports))
+	if decision.Kind == DecisionConflictExternal && options.AutoPort && decision.Port == options.Port {
+		freePort, err := pm.findAutoPort(options)
+		if err != nil {
+			return "", nil, options, StartDecision{}, err
+		}
+		pm.log().Info("requested port busy, using next free port", "requested", options.Port, "port", freePort)
+		if command, args, options, err = prepareCommand(rawCommand, rawArgs, rawOptions, freePort); err != nil {
+			return "", nil, options, StartDecision{}, err
+		}
+
+		// The first decision stopped at the busy primary port; check the additional ports too
+		decision = pm.decideStart(command, effectiveWorkingDir(options.WorkingDir), options.BindAddress,
+			normalizePorts(options.Port, options.Ports))
+	}
+
+	switch decision.Kind {
+	case DecisionConflictManaged, DecisionConflictExternal:
+		return "", nil, options, decision, pm.PortConflict(decision)
+	case DecisionStartNew, DecisionReuse:
+	}
+	return command, args, options, decision, nil
+}
+
+// retainProcess records another caller sharing a reused process. Callers must hold the lock.
+func (pm *ProcessManager) retainProcess(process *ManagedProcess) {
+	if err := pm.syncReferences(process); err != nil {
+		pm.log().Warn("reference count may be stale", "process_id", process.ID, "error", err)
+	}
+
+	pm.mutex.Lock()
+	process.RefCount = process.References() + 1
+	refCount := process.RefCount
+	processesCopy := pm.snapshotLocked()
+	pm.mutex.Unlock()
+
+	pm.log().Debug("process reused", "process_id", process.ID, "ref_count", refCount)
+	if err := pm.stateStore.Save(processesCopy); err != nil {
+		pm.log().Warn("failed to save reference count", "process_id", process.ID, "error", err)
+	}
+}
+
+// syncReferences refreshes the reference count of process from the stored state, as other
+// invocations may have retained or released it since this manager loaded it. Callers hold
+// the state lock but not pm.mutex.
+func (pm *ProcessManager) syncReferences(process *ManagedProcess) error {
+	stored, err := pm.stateStore.Load()
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	if entry, exists := stored[process.ID]; exists {
+		pm.mutex.Lock()
+		process.RefCount = entry.RefCount
+		pm.mutex.Unlock()
+	}
+	return nil
+}
+
+// PortNeedsPrivileges reports whether the current user likely cannot bind port, when the
+// port scanner can tell; see PrivilegeChecker
+func (pm *ProcessManager) PortNeedsPrivileges(port int) bool {
+	checker, ok := pm.portScanner.(PrivilegeChecker)
+	return ok && checker.NeedsPrivileges(port)
+}
+
+// checkPrivilegedPorts rejects ports the current user cannot bind, unless the caller allows them,
+// e.g. because the binary has CAP_NET_BIND_SERVICE
+func (pm *ProcessManager) checkPrivilegedPorts(options StartOptions) error {
+	if options.AllowPrivilegedPort {
+		return nil
+	}
+	for _, portNum := range append([]int{options.Port}, options.Ports...) {
+		if pm.PortNeedsPrivileges(portNum) {
+			return fmt.Errorf("%w: port %d is below 1024 and portguard is not running as root; "+
+				"use a port of 1024 or higher or run with elevated privileges", ErrPrivilegedPort, portNum)
+		}
+	}
+	return nil
+}
+
+// findAutoPort returns the first port in the configured range that is neither in use
+// nor claimed by a managed process
+func (pm *ProcessManager) findAutoPort(options StartOptions) (int, error) {
+	rangeStart := options.PortRangeStart
+	if rangeStart <= 0 {
+		rangeStart = options.Port
+	}
+	rangeEnd := options.PortRangeEnd
+	if rangeEnd <= 0 {
+		rangeEnd = maxPortNumber
+	}
+
+	for candidate := rangeStart; candidate <= rangeEnd; candidate++ {
+		freePort, err := pm.portScanner.FindAvailablePort(candidate)
+		if err != nil || freePort > rangeEnd {
+			break
+		}
+
+		pm.mutex.RLock()
+		_, managed := pm.portIndex[freePort]
+		pm.mutex.RUnlock()
+		if !managed {
+			return freePort, nil
+		}
+		candidate = freePort
+	}
+
+	return 0, fmt.Errorf("%w: %d-%d", ErrNoAvailablePort, rangeStart, rangeEnd)
+}
+
+// prepareCommand expands port placeholders for portNum and applies the bind address. It
+// rejects CleanupWorkingDir without a WorkingDir.
+func prepareCommand(command string, args []string, options StartOptions, portNum int) (string, []string, StartOptions, error) {
+	if options.CleanupWorkingDir && options.WorkingDir == "" {
+		return "", nil, options, ErrCleanupNeedsDir
+	}
+	command, args, options = expandPortPlaceholders(command, args, options, portNum)
+	command, args, options, err := applyBindAddress(command, args, options)
+	if err != nil {
+		return "", nil, options, fmt.Errorf("failed to parse command: %w", err)
 	}
-
-	// 3. Safe to start new process
-	return true, nil
+	return command, args, options, nil
 }
 
-// StartProcess starts a new process or returns an existing one
-func (pm *ProcessManager) StartProcess(command string, args []string, options StartOptions) (*ManagedProcess, error) {
-	if err := pm.lockManager.Lock(); err != nil {
-		return nil, fmt.Errorf("failed to acquire lock: %w", err)
+// expandPortPlaceholders returns copies of the command, args and options with
+// PortPlaceholder replaced by portNum, and options.Port set to portNum
+func expandPortPlaceholders(command string, args []string, options StartOptions, portNum int) (string, []string, StartOptions) {
+	if portNum <= 0 {
+		return command, args, options
+	}
+	value := strconv.Itoa(portNum)
+
+	command = strings.ReplaceAll(command, PortPlaceholder, value)
+
+	expandedArgs := make([]string, len(args))
+	for i, arg := range args {
+		expandedArgs[i] = strings.ReplaceAll(arg, PortPlaceholder, value)
+	}
+	if args == nil {
+		expandedArgs = nil
 	}
-	defer func() { _ = pm.lockManager.Unlock() }() //nolint:errcheck // Defer unlock completes regardless //nolint:errcheck // Defer unlock completes regardless
 
-	// Check if we should start a new process
-	shouldStart, existing := pm.ShouldStartNew(command, options.Port)
-	if !shouldStart {
-		if existing != nil {
-			return existing, nil // Reuse existing process
+	if options.Environment != nil {
+		env := make(map[string]string, len(options.Environment))
+		for key, envValue := range options.Environment {
+			env[key] = strings.ReplaceAll(envValue, PortPlaceholder, value)
 		}
-		return nil, fmt.Errorf("%w: %d", ErrPortAlreadyInUse, options.Port)
+		options.Environment = env
 	}
 
-	// Actually start the process using the new executeProcess method
-	actualProcess, err := pm.executeProcess(command, args, options)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute process: %w", err)
+	if options.HealthCheck != nil {
+		healthCheck := *options.HealthCheck
+		healthCheck.Target = strings.ReplaceAll(healthCheck.Target, PortPlaceholder, value)
+		options.HealthCheck = &healthCheck
 	}
 
-	// Set the process ID for state management
-	actualProcess.ID = pm.generateID(actualProcess.Command)
+	options.Port = portNum
+	return command, expandedArgs, options
+}
 
-	// Store the process and create a copy for safe concurrent access
-	pm.mutex.Lock()
-	pm.processes[actualProcess.ID] = actualProcess
-	// Create a copy of the processes map for safe concurrent access to stateStore
-	processesCopy := make(map[string]*ManagedProcess)
-	for k, v := range pm.processes {
-		processesCopy[k] = v
+// waitForHealthy polls the process health check until it passes or the timeout elapses.
+// On timeout the process is left running with StatusUnhealthy.
+func (pm *ProcessManager) waitForHealthy(process *ManagedProcess, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = defaultWaitTimeout
 	}
-	pm.mutex.Unlock()
 
-	// Persist to storage using the copy to avoid race conditions
-	if err := pm.stateStore.Save(processesCopy); err != nil {
-		return nil, fmt.Errorf("failed to save state: %w", err)
+	pollInterval := defaultWaitPollInterval
+	if process.HealthCheck != nil && process.HealthCheck.Interval > 0 && process.HealthCheck.Interval < timeout {
+		pollInterval = process.HealthCheck.Interval
 	}
 
-	// Start background monitoring for the process
-	go pm.monitorProcessInBackground(actualProcess)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	var lastErr error
+	for {
+		if lastErr = pm.runHealthCheck(ctx, process); lastErr == nil {
+			pm.setStatus(process, StatusRunning, activeStatuses...)
+			return nil
+		}
 
-	return actualProcess, nil
+		select {
+		case <-ctx.Done():
+			pm.setStatus(process, StatusUnhealthy, activeStatuses...)
+			return fmt.Errorf("%w: process %s after %v: %w", ErrHealthWaitTimeout, process.ID, timeout, lastErr)
+		case <-ticker.C:
+		}
+	}
 }
 
 // AdoptProcess adopts an existing external process into management
 func (pm *ProcessManager) AdoptProcess(managedProcess *ManagedProcess) error {
-	if err := pm.lockManager.Lock(); err != nil {
-		return fmt.Errorf("failed to acquire lock: %w", err)
+	if err := pm.lockState(); err != nil {
+		return err
 	}
-	defer func() { _ = pm.lockManager.Unlock() }() //nolint:errcheck // Defer unlock completes regardless
+	defer pm.unlockState()
 
 	// Validate the process
 	if managedProcess == nil {
@@ -176,25 +1050,33 @@ func (pm *ProcessManager) AdoptProcess(managedProcess *ManagedProcess) error {
 		return fmt.Errorf("invalid PID: %d", managedProcess.PID)
 	}
 
-	// Generate ID if not set
-	if managedProcess.ID == "" {
-		managedProcess.ID = pm.generateID(managedProcess.Command)
+	if managedProcess.Command == "" && managedProcess.Config != nil {
+		managedProcess.Command = managedProcess.Config.Command
 	}
 
+	managedProcess.MigratePorts()
+
 	// Set adoption timestamp
 	managedProcess.CreatedAt = time.Now()
 	managedProcess.StartedAt = time.Now()
 	managedProcess.UpdatedAt = time.Now()
 	managedProcess.LastSeen = time.Now()
 
-	// Store the process
+	// Generate ID if not set, under the same lock that stores the process
 	pm.mutex.Lock()
+	if managedProcess.ID == "" {
+		managedProcess.ID = pm.generateID(managedProcess.Command)
+	}
+
+	if err := managedProcess.Validate(); err != nil {
+		pm.mutex.Unlock()
+		return fmt.Errorf("cannot adopt process: %w", err)
+	}
+
 	pm.processes[managedProcess.ID] = managedProcess
+	pm.indexProcessPorts(managedProcess)
 	// Create a copy of the processes map for safe concurrent access to stateStore
-	processesCopy := make(map[string]*ManagedProcess)
-	for k, v := range pm.processes {
-		processesCopy[k] = v
-	}
+	processesCopy := pm.snapshotLocked()
 	pm.mutex.Unlock()
 
 	// Persist to storage
@@ -202,42 +1084,62 @@ func (pm *ProcessManager) AdoptProcess(managedProcess *ManagedProcess) error {
 		// Remove from memory if save failed
 		pm.mutex.Lock()
 		delete(pm.processes, managedProcess.ID)
+		pm.unindexProcessPorts(managedProcess.ID)
 		pm.mutex.Unlock()
 		return fmt.Errorf("failed to save state: %w", err)
 	}
 
 	// Start background monitoring for the adopted process
-	go pm.monitorProcessInBackground(managedProcess)
+	pm.startMonitor(managedProcess, false)
 
 	return nil
 }
 
-// StopProcess stops a managed process
+// StopProcess stops a managed process. A process shared by several callers (see
+// ManagedProcess.RefCount) only drops one reference and keeps running, unless forceKill is set.
 func (pm *ProcessManager) StopProcess(id string, forceKill bool) error {
-	if err := pm.lockManager.Lock(); err != nil {
-		return fmt.Errorf("failed to acquire lock: %w", err)
+	if err := pm.lockState(); err != nil {
+		return err
 	}
-	defer func() { _ = pm.lockManager.Unlock() }() //nolint:errcheck // Defer unlock completes regardless //nolint:errcheck // Defer unlock completes regardless
+	defer pm.unlockState()
 
-	pm.mutex.Lock()
+	pm.mutex.RLock()
 	process, exists := pm.processes[id]
+	pm.mutex.RUnlock()
 	if !exists {
-		pm.mutex.Unlock()
 		return fmt.Errorf("%w: %s", ErrProcessNotFound, id)
 	}
+	if err := pm.syncReferences(process); err != nil {
+		return err
+	}
+
+	pm.mutex.Lock()
+	if !forceKill && process.IsRunning() && process.References() > 1 {
+		process.RefCount = process.References() - 1
+		refCount := process.RefCount
+		processesCopy := pm.snapshotLocked()
+		pm.mutex.Unlock()
+
+		pm.log().Info("process reference released", "process_id", id, "ref_count", refCount)
+		if err := pm.stateStore.Save(processesCopy); err != nil {
+			return fmt.Errorf("failed to save process state: %w", err)
+		}
+		return nil
+	}
+	markStopRequested(process)
 	pm.mutex.Unlock()
 
 	// Actually terminate the process using the new method
 	if err := pm.terminateProcess(process, forceKill); err != nil {
 		return fmt.Errorf("failed to terminate process: %w", err)
 	}
+	pm.runPostStopHooks(process)
 
 	// Update state in storage
 	pm.mutex.Lock()
-	processesCopy := make(map[string]*ManagedProcess)
-	for k, v := range pm.processes {
-		processesCopy[k] = v
-	}
+	process.RefCount = 0
+	pm.unindexProcessPorts(id)
+	processesCopy := pm.snapshotLocked()
 	pm.mutex.Unlock()
 
 	// Persist to storage using the copy to avoid race conditions
@@ -247,6 +1149,150 @@ func (pm *ProcessManager) StopProcess(id string, forceKill bool) error {
 	return nil
 }
 
+// RestartProcess stops a managed process and starts it again with its stored options,
+// keeping its ID. A process that already exited is simply started again.
+func (pm *ProcessManager) RestartProcess(id string, forceKill bool) error {
+	process, err := pm.stopForRestart(id, forceKill)
+	if err != nil {
+		return err
+	}
+
+	// Hooks can run for minutes, so they run without holding the state lock
+	pm.mutex.RLock()
+	options := storedStartOptions(process)
+	stopped := process.runtime
+	pm.mutex.RUnlock()
+	if err := pm.runPreStartHooks(options); err != nil {
+		return err
+	}
+
+	if err := pm.lockState(); err != nil {
+		return err
+	}
+	defer pm.unlockState()
+
+	// The process may have been removed or restarted while the hooks ran
+	pm.mutex.RLock()
+	current, exists := pm.processes[id]
+	pm.mutex.RUnlock()
+	if !exists || current != process {
+		return fmt.Errorf("%w: %s", ErrProcessNotFound, id)
+	}
+	if pm.superseded(process, stopped) {
+		return nil
+	}
+	return pm.respawnProcess(process, true)
+}
+
+// stopForRestart terminates a managed process under the state lock and runs its post-stop hooks
+func (pm *ProcessManager) stopForRestart(id string, forceKill bool) (*ManagedProcess, error) {
+	if err := pm.lockState(); err != nil {
+		return nil, err
+	}
+	defer pm.unlockState()
+
+	pm.mutex.Lock()
+	process, exists := pm.processes[id]
+	if !exists {
+		pm.mutex.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrProcessNotFound, id)
+	}
+	markStopRequested(process)
+	pm.mutex.Unlock()
+
+	if process.PID > 0 {
+		if err := pm.terminateProcess(process, forceKill); err != nil {
+			return nil, fmt.Errorf("failed to terminate process: %w", err)
+		}
+		pm.runPostStopHooks(process)
+	}
+
+	pm.mutex.Lock()
+	pm.unindexProcessPorts(id)
+	pm.mutex.Unlock()
+	return process, nil
+}
+
+// EnsureRunning makes sure a process for the command is up: a matching healthy process is reused,
+// an unhealthy one is restarted in place and a missing or dead one is started fresh. It reports
+// whether a process was spawned. Unlike StartProcess, reuse adds no reference, so scripts can
+// call it repeatedly.
+func (pm *ProcessManager) EnsureRunning(command string, args []string, options StartOptions) (*ManagedProcess, bool, error) {
+	prepared, preparedArgs, _, err := prepareCommand(command, args, options, options.Port)
+	if err != nil {
+		return nil, false, err
+	}
+
+	signature := CommandSignature(prepared, preparedArgs)
+	if existing, found := pm.findMatchingProcess(signature, effectiveWorkingDir(options.WorkingDir), (*ManagedProcess).IsRunning); found {
+		// Probe outside the lock since health checks can take up to their timeout
+		status, checkErr := pm.probeStatus(existing)
+		switch status {
+		case StatusRunning:
+			if err := pm.updateProcessStatus(existing.ID, status, activeStatuses...); err != nil {
+				return nil, false, err
+			}
+			return existing, false, nil
+		case StatusUnhealthy:
+			pm.log().Info("restarting unhealthy process", "process_id", existing.ID, "error", checkErr)
+			if err := pm.RestartProcess(existing.ID, false); err != nil {
+				return nil, false, fmt.Errorf("failed to restart unhealthy process %s: %w", existing.ID, err)
+			}
+			return existing, true, nil
+		default:
+			// The process is gone; record it so the start below does not reuse it
+			if err := pm.updateProcessStatus(existing.ID, status); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+
+	process, err := pm.StartProcess(command, args, options)
+	if err != nil {
+		return nil, false, err
+	}
+	return process, true, nil
+}
+
+// SignalProcess sends a signal to a managed process without changing its status.
+// Processes started by portguard lead their own process group, so the whole group is signaled.
+func (pm *ProcessManager) SignalProcess(id string, sig os.Signal) error {
+	pm.mutex.RLock()
+	process, exists := pm.processes[id]
+	var pid int
+	var group bool
+	if exists {
+		pid = process.PID
+		group = !process.IsExternal
+	}
+	pm.mutex.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrProcessNotFound, id)
+	}
+	if pid <= 0 {
+		return fmt.Errorf("invalid PID: %d", pid)
+	}
+
+	if err := pm.processExecutor().Signal(pid, group, sig); err != nil {
+		return fmt.Errorf("failed to send %v to process %s (PID %d): %w", sig, id, pid, err)
+	}
+	return nil
+}
+
+// ParseSignal converts a signal name such as "HUP", "SIGHUP" or a signal number into an os.Signal
+func ParseSignal(name string) (os.Signal, error) {
+	trimmed := strings.ToUpper(strings.TrimSpace(name))
+	if number, err := strconv.Atoi(trimmed); err == nil && number > 0 {
+		return syscall.Signal(number), nil
+	}
+
+	if sig, ok := signalsByName[strings.TrimPrefix(trimmed, "SIG")]; ok {
+		return sig, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownSignal, name)
+}
+
 // GetProcess retrieves a process by ID
 func (pm *ProcessManager) GetProcess(id string) (*ManagedProcess, bool) {
 	pm.mutex.RLock()
@@ -256,6 +1302,101 @@ func (pm *ProcessManager) GetProcess(id string) (*ManagedProcess, bool) {
 	return process, exists
 }
 
+// GetProcessByPort retrieves the running process bound to the given port
+func (pm *ProcessManager) GetProcessByPort(portNum int) (*ManagedProcess, bool) {
+	pm.mutex.RLock()
+	defer pm.mutex.RUnlock()
+
+	id, indexed := pm.portIndex[portNum]
+	if !indexed {
+		return nil, false
+	}
+
+	process, exists := pm.processes[id]
+	if !exists || !process.IsRunning() || !process.UsesPort(portNum) {
+		return nil, false
+	}
+	return process, true
+}
+
+// indexProcessPorts records the process as the owner of its ports. Callers must hold pm.mutex.
+func (pm *ProcessManager) indexProcessPorts(process *ManagedProcess) {
+	if pm.portIndex == nil {
+		pm.portIndex = make(map[int]string)
+	}
+	for _, portNum := range process.AllPorts() {
+		pm.portIndex[portNum] = process.ID
+	}
+}
+
+// unindexProcessPorts releases every port owned by the process. Callers must hold pm.mutex.
+func (pm *ProcessManager) unindexProcessPorts(id string) {
+	for portNum, ownerID := range pm.portIndex {
+		if ownerID == id {
+			delete(pm.portIndex, portNum)
+		}
+	}
+}
+
+// rebuildPortIndex recreates the port index from the running processes. Callers must hold pm.mutex.
+func (pm *ProcessManager) rebuildPortIndex() {
+	pm.portIndex = make(map[int]string)
+	for _, process := range pm.processes {
+		if process.IsRunning() {
+			pm.indexProcessPorts(process)
+		}
+	}
+}
+
+// Stats is a point-in-time summary of managed processes for monitoring
+type Stats struct {
+	ProcessesByStatus   map[ProcessStatus]int // Number of processes in each status
+	RestartsByProcess   map[string]int        // Restarts performed so far, by process ID
+	HealthCheckFailures uint64                // Failed health checks since the manager was created
+}
+
+// Stats returns counts of managed processes by status, their restart counts
+// and the number of failed health checks
+func (pm *ProcessManager) Stats() Stats {
+	pm.mutex.RLock()
+	defer pm.mutex.RUnlock()
+
+	stats := Stats{
+		ProcessesByStatus:   make(map[ProcessStatus]int),
+		RestartsByProcess:   make(map[string]int, len(pm.processes)),
+		HealthCheckFailures: pm.healthCheckFailures.Load(),
+	}
+	for id, process := range pm.processes {
+		stats.ProcessesByStatus[process.Status]++
+		stats.RestartsByProcess[id] = process.RestartCount
+	}
+	return stats
+}
+
+// ReloadState replaces the managed processes with the stored state, picking up processes
+// started or stopped by other portguard invocations. Processes started by this manager
+// are kept as they are, since their in-memory state is authoritative.
+func (pm *ProcessManager) ReloadState() error {
+	loaded, err := pm.stateStore.Load()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	if loaded == nil {
+		loaded = make(map[string]*ManagedProcess)
+	}
+
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+	for id, process := range pm.processes {
+		if process.runtime != nil {
+			loaded[id] = process
+		}
+	}
+	pm.processes = loaded
+	pm.rebuildPortIndex()
+	return nil
+}
+
 // ListProcesses returns all managed processes
 func (pm *ProcessManager) ListProcesses(options ProcessListOptions) []*ManagedProcess {
 	pm.mutex.RLock()
@@ -268,7 +1409,19 @@ func (pm *ProcessManager) ListProcesses(options ProcessListOptions) []*ManagedPr
 			continue
 		}
 
-		if options.FilterByPort > 0 && process.Port != options.FilterByPort {
+		if options.FilterByPort > 0 && !process.UsesPort(options.FilterByPort) {
+			continue
+		}
+
+		if options.FilterByCommand != "" && !process.MatchesCommand(options.FilterByCommand) {
+			continue
+		}
+
+		if !options.CreatedAfter.IsZero() && process.CreatedAt.Before(options.CreatedAfter) {
+			continue
+		}
+
+		if !options.CreatedBefore.IsZero() && process.CreatedAt.After(options.CreatedBefore) {
 			continue
 		}
 
@@ -278,12 +1431,20 @@ func (pm *ProcessManager) ListProcesses(options ProcessListOptions) []*ManagedPr
 	return result
 }
 
+// CleanupOptions selects the processes removed by CleanupProcesses
+type CleanupOptions struct {
+	Force            bool // Also remove running processes, terminating them
+	IncludeProtected bool // Also remove processes marked as protected
+}
+
 // CleanupProcesses removes stopped processes and cleans up resources
-func (pm *ProcessManager) CleanupProcesses(force bool) error {
-	if err := pm.lockManager.Lock(); err != nil {
-		return fmt.Errorf("failed to acquire lock: %w", err)
+func (pm *ProcessManager) CleanupProcesses(options CleanupOptions) error {
+	if err := pm.lockState(); err != nil {
+		return err
 	}
-	defer func() { _ = pm.lockManager.Unlock() }() //nolint:errcheck // Defer unlock completes regardless //nolint:errcheck // Defer unlock completes regardless
+	defer pm.unlockState()
+
+	logger := pm.log()
 
 	pm.mutex.Lock()
 	defer pm.mutex.Unlock()
@@ -292,9 +1453,10 @@ func (pm *ProcessManager) CleanupProcesses(force bool) error {
 	var cleanupErrors []error
 
 	for id, process := range pm.processes {
-		if force || process.Status == StatusStopped || process.Status == StatusFailed {
+		if shouldCleanup(process, options) {
+			markStopRequested(process)
 			// Actually clean up process resources
-			if err := pm.cleanupProcessResources(process, force); err != nil {
+			if err := pm.cleanupProcessResources(process, options.Force); err != nil {
 				cleanupErrors = append(cleanupErrors, fmt.Errorf("failed to cleanup process %s: %w", id, err))
 				// Continue with other processes even if one fails
 			}
@@ -302,16 +1464,18 @@ func (pm *ProcessManager) CleanupProcesses(force bool) error {
 		}
 	}
 
+	if len(toRemove) > 0 {
+		pm.backupState(logger, pm.backupRetention)
+	}
+
 	// Remove processes from memory
 	for _, id := range toRemove {
 		delete(pm.processes, id)
+		pm.unindexProcessPorts(id)
 	}
 
 	// Create a copy of the processes map for safe concurrent access to stateStore
-	processesCopy := make(map[string]*ManagedProcess)
-	for k, v := range pm.processes {
-		processesCopy[k] = v
-	}
+	processesCopy := pm.snapshotLocked()
 
 	if err := pm.stateStore.Save(processesCopy); err != nil {
 		return fmt.Errorf("failed to save process state: %w", err)
@@ -325,29 +1489,79 @@ func (pm *ProcessManager) CleanupProcesses(force bool) error {
 	return nil
 }
 
+// CleanupPlanEntry describes a process that cleanup would remove and the resources it would release
+type CleanupPlanEntry struct {
+	ID         string        `json:"id"`
+	Command    string        `json:"command"`
+	PID        int           `json:"pid"`
+	Status     ProcessStatus `json:"status"`
+	Terminate  bool          `json:"terminate"`             // Process is still running and would be terminated
+	LogFile    string        `json:"log_file,omitempty"`    // Log file that would be removed
+	WorkingDir string        `json:"working_dir,omitempty"` // Working directory that would be removed
+}
+
+// PlanCleanup reports what CleanupProcesses(options) would remove without changing any state
+func (pm *ProcessManager) PlanCleanup(options CleanupOptions) []CleanupPlanEntry {
+	pm.mutex.RLock()
+	defer pm.mutex.RUnlock()
+
+	var plan []CleanupPlanEntry
+	for _, process := range pm.processes {
+		if shouldCleanup(process, options) {
+			plan = append(plan, planProcessCleanup(process))
+		}
+	}
+	sort.Slice(plan, func(i, j int) bool { return plan[i].ID < plan[j].ID })
+	return plan
+}
+
+// shouldCleanup is the selection predicate shared by PlanCleanup and CleanupProcesses
+func shouldCleanup(process *ManagedProcess, options CleanupOptions) bool {
+	if process.Protected && !options.IncludeProtected {
+		return false
+	}
+	return options.Force || process.Status == StatusStopped || process.Status == StatusFailed
+}
+
+// planProcessCleanup determines which resources cleanup releases for a process
+func planProcessCleanup(process *ManagedProcess) CleanupPlanEntry {
+	entry := CleanupPlanEntry{
+		ID:        process.ID,
+		Command:   process.Command,
+		PID:       process.PID,
+		Status:    process.Status,
+		Terminate: process.IsRunning(),
+		LogFile:   process.LogFile,
+	}
+	// Remove the working directory only when the caller that created it asked for it
+	if process.CleanupWorkingDir {
+		entry.WorkingDir = process.WorkingDir
+	}
+	return entry
+}
+
 // cleanupProcessResources performs actual cleanup of process resources
 func (pm *ProcessManager) cleanupProcessResources(process *ManagedProcess, force bool) error {
 	var cleanupErrors []error
+	plan := planProcessCleanup(process)
 
 	// 1. Terminate the process if it's still running
-	if process.IsRunning() {
+	if plan.Terminate {
 		if err := pm.terminateProcess(process, force); err != nil {
 			cleanupErrors = append(cleanupErrors, fmt.Errorf("failed to terminate process: %w", err))
 		}
 	}
 
 	// 2. Clean up log files if they exist and are managed by us
-	if process.LogFile != "" {
-		if err := cleanupLogFile(process.LogFile); err != nil {
+	if plan.LogFile != "" {
+		if err := cleanupLogFile(plan.LogFile); err != nil {
 			cleanupErrors = append(cleanupErrors, fmt.Errorf("failed to cleanup log file: %w", err))
 		}
 	}
 
-	// 3. Clean up any temporary files or directories if specified
-	// This could be extended to handle process-specific cleanup
-	if process.WorkingDir != "" && strings.Contains(process.WorkingDir, "temp") {
-		// Only clean up directories that look like temp directories to avoid accidents
-		if err := cleanupTempDirectory(process.WorkingDir); err != nil {
+	// 3. Remove the working directory if it was created for the process
+	if plan.WorkingDir != "" {
+		if err := cleanupTempDirectory(plan.WorkingDir); err != nil {
 			cleanupErrors = append(cleanupErrors, fmt.Errorf("failed to cleanup working directory: %w", err))
 		}
 	}
@@ -379,15 +1593,17 @@ func cleanupLogFile(logFile string) error {
 	return nil
 }
 
-// cleanupTempDirectory removes temporary directories if they're safe to remove
+// cleanupTempDirectory removes a working directory that portguard created for a process.
+// Relative paths, the filesystem root and the home directory are always refused.
 func cleanupTempDirectory(workingDir string) error {
 	if workingDir == "" {
 		return nil
 	}
 
-	// Safety check: only clean up directories that contain "temp" in the path
-	if !strings.Contains(strings.ToLower(workingDir), "temp") {
-		return nil // Skip cleanup for safety
+	cleaned := filepath.Clean(workingDir)
+	homeDir, _ := os.UserHomeDir() //nolint:errcheck // Empty home never matches a cleaned absolute path
+	if !filepath.IsAbs(cleaned) || cleaned == filepath.Dir(cleaned) || cleaned == homeDir {
+		return fmt.Errorf("%w: %s", ErrUnsafeCleanupPath, workingDir)
 	}
 
 	// Check if directory exists
@@ -406,18 +1622,70 @@ func cleanupTempDirectory(workingDir string) error {
 // StartOptions defines options for starting a process
 type StartOptions struct {
 	Port        int               `json:"port"`
+	Ports       []int             `json:"ports"` // Additional ports the process binds (HMR, metrics, ...)
 	HealthCheck *HealthCheck      `json:"health_check"`
 	Environment map[string]string `json:"environment"`
-	WorkingDir  string            `json:"working_dir"`
-	LogFile     string            `json:"log_file"`
-	Background  bool              `json:"background"`
+	// EnvFile is a .env file loaded into the environment at start; Environment entries take precedence.
+	// Relative paths are resolved against WorkingDir when it is set.
+	EnvFile    string `json:"env_file"`
+	WorkingDir string `json:"working_dir"`
+	LogFile    string `json:"log_file"`
+	Background bool   `json:"background"`
+	// Detached starts the process in its own session, without a controlling terminal, so it
+	// survives portguard exiting and its terminal closing. Output goes to LogFile or is discarded.
+	Detached bool `json:"detached"`
+	// CleanupWorkingDir marks WorkingDir as created by the caller for this process,
+	// allowing cleanup to remove it. Never set it for a user's project directory. It requires
+	// WorkingDir: the current directory recorded in its place must never be removed.
+	CleanupWorkingDir bool          `json:"cleanup_working_dir"`
+	WaitHealthy       bool          `json:"wait_healthy"` // Block until the health check passes
+	WaitTimeout       time.Duration `json:"wait_timeout"` // Maximum time to wait when WaitHealthy or ReadyLogPattern is set
+	// ReadyLogPattern is a regular expression; when set, start blocks until a line of new
+	// output in LogFile matches it. Requires LogFile.
+	ReadyLogPattern string `json:"ready_log_pattern"`
+
+	RestartPolicy RestartPolicy `json:"restart_policy"` // When to restart the process after it exits
+	MaxRestarts   int           `json:"max_restarts"`   // Restart limit; 0 uses defaultMaxRestarts
+	NoMonitor     bool          `json:"no_monitor"`     // Skip background monitoring (also disables restarts)
+	Protected     bool          `json:"protected"`      // Keep the process through cleanup unless protected processes are included
+	// AllowPrivilegedPort skips the check that the current user may bind ports below 1024
+	AllowPrivilegedPort bool `json:"allow_privileged_port"`
+
+	// PreStart commands run in order in the working directory and environment of the process
+	// before it starts; the first failure aborts the start. PostStop commands run after the
+	// process is stopped and only log failures. Both are stored, so restarts run them again.
+	PreStart []string `json:"pre_start"`
+	PostStop []string `json:"post_stop"`
+
+	// BindAddress is the interface the server should listen on, e.g. 127.0.0.1 or 0.0.0.0.
+	// HostPlaceholder in the command, environment or health check target is replaced with it;
+	// without a placeholder, known dev servers get their host flag appended. Empty keeps the
+	// server's default. Listeners on other, non-overlapping interfaces are not conflicts.
+	BindAddress string `json:"bind_address"`
+
+	// AutoPort picks the next free port in [PortRangeStart, PortRangeEnd] when Port is held
+	// by an external process. PortPlaceholder in the command, environment or health check
+	// target is replaced with the chosen port.
+	AutoPort       bool `json:"auto_port"`
+	PortRangeStart int  `json:"port_range_start"` // Defaults to Port
+	PortRangeEnd   int  `json:"port_range_end"`   // Defaults to maxPortNumber
 }
 
+// PortPlaceholder is substituted with the process port in commands, environment values
+// and health check targets
+const PortPlaceholder = "{port}"
+
+// maxPortNumber is the highest valid TCP/UDP port
+const maxPortNumber = 65535
+
 // executeProcess executes a process with the given command and options
 func (pm *ProcessManager) executeProcess(command string, args []string, options StartOptions) (*ManagedProcess, error) {
 	// Parse command if args are empty (for backward compatibility with shell commands)
 	if len(args) == 0 {
-		parts := strings.Fields(command)
+		parts, err := SplitCommandLine(command)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse command: %w", err)
+		}
 		if len(parts) == 0 {
 			return nil, errors.New("empty command")
 		}
@@ -426,104 +1694,272 @@ func (pm *ProcessManager) executeProcess(command string, args []string, options
 			args = parts[1:]
 		}
 	}
+	return pm.spawnProcess(command, args, options)
+}
 
-	// Create command with context
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-	cmd := exec.CommandContext(ctx, command, args...)
-
-	// Set working directory if specified
-	if options.WorkingDir != "" {
-		cmd.Dir = options.WorkingDir
+// spawnProcess starts program with exactly the given arguments; unlike executeProcess it never
+// splits a command line, so stored programs with spaces or quoted arguments restart unchanged.
+func (pm *ProcessManager) spawnProcess(command string, args []string, options StartOptions) (*ManagedProcess, error) {
+	// Resolve the working directory up front; exec only reports a missing one as a confusing chdir error
+	workingDir, err := resolveWorkingDir(options.WorkingDir)
+	if err != nil {
+		return nil, err
 	}
+	// Record where the process runs so reuse never crosses into another project
+	options.WorkingDir = effectiveWorkingDir(workingDir)
 
-	// Set environment variables
-	if len(options.Environment) > 0 {
-		cmd.Env = os.Environ()
-		for key, value := range options.Environment {
-			cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, value))
-		}
+	spec := ExecSpec{
+		Command:  command,
+		Args:     args,
+		Dir:      options.WorkingDir,
+		Detached: options.Detached,
 	}
 
-	// Set up process group for signal management (platform-specific)
-	cmd.SysProcAttr = setSysProcAttr(nil)
+	// Set environment variables, letting explicit entries override the env file
+	spec.Env, err = hookEnvironment(options)
+	if err != nil {
+		return nil, err
+	}
 
 	// Set up log file if specified
+	var logFile *os.File
+	var logOffset int64
 	if options.LogFile != "" {
-		logFile, err := os.OpenFile(options.LogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		logFile, err = os.OpenFile(options.LogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
 		if err != nil {
 			return nil, fmt.Errorf("failed to open log file %s: %w", options.LogFile, err)
 		}
-		cmd.Stdout = logFile
-		cmd.Stderr = logFile
+		// Readiness detection must not match output of earlier runs
+		if info, statErr := logFile.Stat(); statErr == nil {
+			logOffset = info.Size()
+		}
+		spec.Output = logFile
 	}
 
 	// Start the process
-	if err := cmd.Start(); err != nil {
+	executor := pm.processExecutor()
+	pid, err := executor.Start(spec)
+	if err != nil {
+		if logFile != nil {
+			_ = logFile.Close() //nolint:errcheck // Start error takes precedence
+		}
 		return nil, fmt.Errorf("failed to start command '%s': %w", command, err)
 	}
 
+	// Reap the child in the background so its exit status reaches the monitor
+	runtime := &processRuntime{exited: make(chan error, 1), logOffset: logOffset}
+	go func() {
+		runtime.exited <- executor.Wait(pid)
+		if logFile != nil {
+			_ = logFile.Close() //nolint:errcheck // Child has exited, nothing left to flush
+		}
+	}()
+
+	// Primary port comes first; fall back to the first additional port if none was given
+	ports := normalizePorts(options.Port, options.Ports)
+	primaryPort := options.Port
+	if primaryPort <= 0 && len(ports) > 0 {
+		primaryPort = ports[0]
+	}
+
 	// Create managed process with actual PID
 	process := &ManagedProcess{
 		Command:     strings.Join(append([]string{command}, args...), " "),
+		Program:     command,
 		Args:        args,
-		Port:        options.Port,
-		PID:         cmd.Process.Pid,
+		Port:        primaryPort,
+		Ports:       ports,
+		PID:         pid,
 		Status:      StatusRunning,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 		LastSeen:    time.Now(),
 		Environment: options.Environment,
+		EnvFile:     options.EnvFile,
 		WorkingDir:  options.WorkingDir,
 		LogFile:     options.LogFile,
 		HealthCheck: options.HealthCheck,
+
+		CleanupWorkingDir: options.CleanupWorkingDir,
+		Protected:         options.Protected,
+		BindAddress:       options.BindAddress,
+		Detached:          options.Detached,
+		NoMonitor:         options.NoMonitor,
+		RestartPolicy:     options.RestartPolicy,
+		MaxRestarts:       options.MaxRestarts,
+		PreStart:          slices.Clone(options.PreStart),
+		PostStop:          slices.Clone(options.PostStop),
+		RefCount:          1,
+		runtime:           runtime,
 	}
 
 	return process, nil
 }
 
-// monitorProcessInBackground monitors a process in the background
-func (pm *ProcessManager) monitorProcessInBackground(process *ManagedProcess) {
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+// storedStartOptions rebuilds the options a process was started with. Callers hold pm.mutex.
+func storedStartOptions(process *ManagedProcess) StartOptions {
+	return StartOptions{
+		Port:              process.Port,
+		Ports:             process.Ports,
+		HealthCheck:       process.HealthCheck,
+		Environment:       process.Environment,
+		EnvFile:           process.EnvFile,
+		WorkingDir:        process.WorkingDir,
+		LogFile:           process.LogFile,
+		CleanupWorkingDir: process.CleanupWorkingDir,
+		BindAddress:       process.BindAddress,
+		Detached:          process.Detached,
+		NoMonitor:         process.NoMonitor,
+		RestartPolicy:     process.RestartPolicy,
+		MaxRestarts:       process.MaxRestarts,
+		PreStart:          slices.Clone(process.PreStart),
+		PostStop:          slices.Clone(process.PostStop),
+	}
+}
+
+// ExpandPath expands a leading ~ to the home directory and makes the path absolute
+func ExpandPath(path string) (string, error) {
+	if path == "" {
+		return path, nil
+	}
+
+	// Expand ~ to home directory
+	if path[:1] == "~" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		path = filepath.Join(homeDir, path[1:])
+	}
+
+	// Convert to absolute path
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	return abs, nil
+}
+
+// effectiveWorkingDir returns the absolute directory a process started with dir runs in: the
+// current directory when dir is empty. It returns "" when that cannot be determined.
+func effectiveWorkingDir(dir string) string {
+	if dir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return ""
+		}
+		return wd
+	}
+	expanded, err := ExpandPath(dir)
+	if err != nil {
+		return filepath.Clean(dir)
+	}
+	return expanded
+}
 
+// resolveWorkingDir expands a working directory and checks that it is an existing directory.
+// Empty means the current directory and is returned as is.
+func resolveWorkingDir(dir string) (string, error) {
+	if dir == "" {
+		return "", nil
+	}
+
+	expanded, err := ExpandPath(dir)
+	if err != nil {
+		return "", fmt.Errorf("invalid working directory %s: %w", dir, err)
+	}
+	info, err := os.Stat(expanded)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return "", fmt.Errorf("%w: %s", ErrWorkingDirMissing, expanded)
+	case err != nil:
+		return "", fmt.Errorf("cannot access working directory %s: %w", expanded, err)
+	case !info.IsDir():
+		return "", fmt.Errorf("%w: %s", ErrWorkingDirNotDir, expanded)
+	}
+	return expanded, nil
+}
+
+// resolveEnvironment merges the env file, if any, with the explicit environment.
+// Only the explicit entries are kept on the process, so secrets from the file are not persisted.
+func resolveEnvironment(options StartOptions) (map[string]string, error) {
+	if options.EnvFile == "" {
+		return options.Environment, nil
+	}
+
+	path := options.EnvFile
+	if !filepath.IsAbs(path) && options.WorkingDir != "" {
+		path = filepath.Join(options.WorkingDir, path)
+	}
+	environment, err := LoadEnvFile(path)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range options.Environment {
+		environment[key] = value
+	}
+	return environment, nil
+}
+
+// monitorProcessInBackground monitors a process in the background until it exits or ctx is cancelled
+func (pm *ProcessManager) monitorProcessInBackground(ctx context.Context, process *ManagedProcess) {
 	// Monitor the process
 	if err := pm.monitorProcess(ctx, process); err != nil {
+		if ctx.Err() != nil {
+			// The manager was closed; the process itself keeps running
+			pm.log().Debug("process monitoring stopped", "process_id", process.ID, "pid", process.PID)
+			return
+		}
 		// Log error but don't fail - this is a background operation
-		//nolint:errcheck // Background operation, error logged elsewhere
-		_ = pm.updateProcessStatus(process.ID, StatusFailed)
+		pm.log().Error("process monitoring failed", "process_id", process.ID, "pid", process.PID, "error", err)
+		pm.setStatus(process, StatusFailed)
 	}
 }
 
 // monitorProcess monitors a process and updates its status
 func (pm *ProcessManager) monitorProcess(ctx context.Context, process *ManagedProcess) error {
-	if process.PID <= 0 {
-		return fmt.Errorf("invalid PID: %d", process.PID)
+	// Each monitor watches one instance; a restart starts a new monitor for the next one
+	pm.mutex.RLock()
+	pid := process.PID
+	runtime := process.runtime // Exit results are only available for processes this manager started
+	checkInterval := pm.monitorInterval
+	pm.mutex.RUnlock()
+	if pid <= 0 {
+		return fmt.Errorf("invalid PID: %d", pid)
 	}
 
-	// Use shorter intervals for testing or configurable intervals
-	checkInterval := 500 * time.Millisecond // More frequent checks for testing
+	executor := pm.processExecutor()
+	if checkInterval <= 0 {
+		checkInterval = defaultMonitorInterval
+	}
 	ticker := time.NewTicker(checkInterval)
 	defer ticker.Stop()
-
-	// Do an immediate check first
-	osProcess, err := os.FindProcess(process.PID)
-	if err != nil {
-		//nolint:errcheck // Background monitoring, error logged elsewhere
-		_ = pm.updateProcessStatus(process.ID, StatusStopped)
-		return fmt.Errorf("process not found: %w", err)
+	var exited <-chan error
+	if runtime != nil {
+		exited = runtime.exited
 	}
 
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
+		case exitErr := <-exited:
+			return pm.handleProcessExit(process, runtime, exitErr)
 		case <-ticker.C:
+			// A restart hands monitoring over to the new instance's monitor
+			if pm.superseded(process, runtime) {
+				return nil
+			}
+
 			// Send signal 0 to check if process exists
-			if !isProcessAlive(osProcess) {
-				// Process has stopped
-				//nolint:errcheck // Background monitoring, error logged elsewhere
-				_ = pm.updateProcessStatus(process.ID, StatusStopped)
+			if !executor.IsAlive(pid) {
+				if exited != nil {
+					return pm.handleProcessExit(process, runtime, <-exited)
+				}
+				// Process has stopped; its exit status belongs to another parent
+				pm.log().Info("process exited", "process_id", process.ID, "pid", pid)
+				pm.recordExit(process, nil, exitReasonUnavailable)
+				pm.setStatus(process, StatusStopped)
 				return nil
 			}
 
@@ -534,50 +1970,249 @@ func (pm *ProcessManager) monitorProcess(ctx context.Context, process *ManagedPr
 			}
 			pm.mutex.Unlock()
 
-			// Run health check if configured
-			if process.HealthCheck != nil {
+			// Run health check if configured and due; it may be attached or replaced while monitoring
+			pm.mutex.RLock()
+			healthCheck := process.HealthCheck
+			due := healthCheckDue(process, time.Now())
+			pm.mutex.RUnlock()
+			if healthCheck != nil && due {
 				if err := pm.runHealthCheck(ctx, process); err != nil {
-					//nolint:errcheck // Background monitoring, error logged elsewhere
-					_ = pm.updateProcessStatus(process.ID, StatusUnhealthy)
+					if ctx.Err() != nil {
+						return ctx.Err()
+					}
+					pm.log().Warn("health check failed",
+						"process_id", process.ID, "type", healthCheck.Type, "target", healthCheck.Target, "error", err)
+					pm.setStatus(process, StatusUnhealthy, activeStatuses...)
 				} else {
-					//nolint:errcheck // Background monitoring, error logged elsewhere
-					_ = pm.updateProcessStatus(process.ID, StatusRunning)
+					pm.setStatus(process, StatusRunning, activeStatuses...)
 				}
 			}
 		}
 	}
 }
 
-// terminateProcess terminates a process
+// healthCheckDue reports whether the process's health check interval has elapsed since it
+// last ran. Callers must hold pm.mutex.
+func healthCheckDue(process *ManagedProcess, now time.Time) bool {
+	if process.HealthCheck == nil {
+		return false
+	}
+	interval := process.HealthCheck.Interval
+	if interval <= 0 {
+		interval = defaultHealthCheckInterval
+	}
+	return process.LastHealthCheck.IsZero() || now.Sub(process.LastHealthCheck) >= interval
+}
+
+// handleProcessExit records a process exit and restarts it when its restart policy allows
+func (pm *ProcessManager) handleProcessExit(process *ManagedProcess, runtime *processRuntime, exitErr error) error {
+	pm.mutex.RLock()
+	policy := process.RestartPolicy
+	crashRestarts := process.CrashRestarts
+	maxRestarts := process.MaxRestarts
+	pm.mutex.RUnlock()
+
+	// The process was restarted in the meantime; this exit belongs to its previous instance
+	if pm.superseded(process, runtime) {
+		return nil
+	}
+
+	exitCode, exitReason := exitResult(exitErr)
+	pm.recordExit(process, exitCode, exitReason)
+
+	logger := pm.log().With("process_id", process.ID, "pid", process.PID)
+	if runtime.stopRequested.Load() {
+		logger.Info("process stopped")
+		pm.setStatus(process, StatusStopped)
+		return nil
+	}
+
+	if exitErr != nil {
+		logger.Warn("process exited with error", "error", exitErr)
+	} else {
+		logger.Info("process exited")
+	}
+
+	if !shouldRestart(policy, exitErr) {
+		pm.setStatus(process, StatusStopped)
+		return nil
+	}
+
+	if maxRestarts <= 0 {
+		maxRestarts = defaultMaxRestarts
+	}
+	if crashRestarts >= maxRestarts {
+		logger.Error("restart limit reached", "crash_restarts", crashRestarts, "max_restarts", maxRestarts)
+		pm.setStatus(process, StatusFailed)
+		return nil
+	}
+
+	if err := pm.restartProcess(process); err != nil {
+		logger.Error("failed to restart process", "error", err)
+		pm.setStatus(process, StatusFailed)
+	}
+	return nil
+}
+
+// superseded reports whether the process has been restarted since runtime was its current one
+func (pm *ProcessManager) superseded(process *ManagedProcess, runtime *processRuntime) bool {
+	pm.mutex.RLock()
+	defer pm.mutex.RUnlock()
+	return process.runtime != runtime
+}
+
+// exitReasonUnavailable describes exits of processes portguard cannot reap, such as adopted ones
+const exitReasonUnavailable = "exited (exit status unavailable)"
+
+// exitResult converts the result of cmd.Wait into an exit code and reason.
+// The code is nil when the process was terminated by a signal or the result is not an exit status.
+func exitResult(exitErr error) (*int, string) {
+	if exitErr == nil {
+		code := 0
+		return &code, "exit status 0"
+	}
+
+	var exitError *exec.ExitError
+	if errors.As(exitErr, &exitError) {
+		reason := exitError.ProcessState.String()
+		if code := exitError.ExitCode(); code >= 0 {
+			return &code, reason
+		}
+		return nil, reason
+	}
+	return nil, exitErr.Error()
+}
+
+// recordExit stores the result of the process's last exit
+func (pm *ProcessManager) recordExit(process *ManagedProcess, code *int, reason string) {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+	process.ExitCode = code
+	process.ExitReason = reason
+}
+
+// markStopRequested records that portguard is stopping the process so it is not restarted.
+// Callers must hold pm.mutex.
+func markStopRequested(process *ManagedProcess) {
+	if process.runtime != nil {
+		process.runtime.stopRequested.Store(true)
+	}
+}
+
+// shouldRestart reports whether the policy asks for a restart after the given exit result
+func shouldRestart(policy RestartPolicy, exitErr error) bool {
+	switch policy {
+	case RestartAlways:
+		return true
+	case RestartOnFailure:
+		return exitErr != nil
+	case RestartNever:
+		return false
+	}
+	return false
+}
+
+// restartProcess runs the pre-start hooks of a process and re-executes it with its stored
+// options under the state lock, keeping its ID. Callers must not hold the state lock, since
+// hooks can run for minutes.
+func (pm *ProcessManager) restartProcess(process *ManagedProcess) error {
+	pm.mutex.RLock()
+	options := storedStartOptions(process)
+	exited := process.runtime
+	pm.mutex.RUnlock()
+
+	if err := pm.runPreStartHooks(options); err != nil {
+		return err
+	}
+
+	if err := pm.lockState(); err != nil {
+		return err
+	}
+	defer pm.unlockState()
+
+	// The process may have been removed or restarted while the hooks ran
+	pm.mutex.RLock()
+	current, exists := pm.processes[process.ID]
+	pm.mutex.RUnlock()
+	if !exists || current != process || pm.superseded(process, exited) {
+		return nil
+	}
+	return pm.respawnProcess(process, false)
+}
+
+// respawnProcess re-executes a process whose pre-start hooks have run, keeping its ID.
+// A manual restart resets the crash restart budget instead of using it up.
+func (pm *ProcessManager) respawnProcess(process *ManagedProcess, manual bool) error {
+	pm.mutex.RLock()
+	program, args := process.Program, slices.Clone(process.Args)
+	command := process.Command
+	options := storedStartOptions(process)
+	pm.mutex.RUnlock()
+
+	var restarted *ManagedProcess
+	var err error
+	if program != "" {
+		restarted, err = pm.spawnProcess(program, args, options)
+	} else {
+		// State written before the program was stored only has the joined command line
+		restarted, err = pm.executeProcess(strings.TrimSuffix(command, " "+strings.Join(args, " ")), args, options)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to execute process: %w", err)
+	}
+
+	pm.mutex.Lock()
+	process.PID = restarted.PID
+	process.runtime = restarted.runtime
+	process.RestartCount++
+	if manual {
+		process.CrashRestarts = 0
+	} else {
+		process.CrashRestarts++
+	}
+	process.Status = StatusRunning
+	process.StartedAt = restarted.CreatedAt
+	process.UpdatedAt = time.Now()
+	process.LastSeen = time.Now()
+	pm.indexProcessPorts(process)
+	restartCount := process.RestartCount
+	saveErr := pm.saveStatusLocked(process)
+	pm.mutex.Unlock()
+
+	pm.log().Info("process restarted", "process_id", process.ID, "pid", restarted.PID, "restart_count", restartCount)
+	if saveErr != nil {
+		pm.log().Warn("failed to save state after restart", "process_id", process.ID, "error", saveErr)
+	}
+
+	pm.startMonitor(process, options.NoMonitor)
+	return nil
+}
+
+// terminateProcess terminates a process, escalating to a kill when it does not exit gracefully.
+// Processes started by portguard are terminated with their whole process tree, so servers
+// forked by wrappers such as npm do not outlive them.
 func (pm *ProcessManager) terminateProcess(process *ManagedProcess, forceKill bool) error {
 	if process.PID <= 0 {
 		return fmt.Errorf("invalid PID: %d", process.PID)
 	}
 
-	osProcess, err := os.FindProcess(process.PID)
-	if err != nil {
-		// Process not found - update status and return success since the goal is achieved
-		process.Status = StatusStopped
-		process.UpdatedAt = time.Now()
-		//nolint:nilerr // Process not existing is the desired outcome for termination
-		return nil
-	}
+	executor := pm.processExecutor()
+	group := !process.IsExternal
 
 	// Check if process is still running before trying to terminate
-	if !isProcessAlive(osProcess) {
+	if !executor.IsAlive(process.PID) {
 		// Process is already dead - update status and return success since goal is achieved
 		process.Status = StatusStopped
 		process.UpdatedAt = time.Now()
-		//nolint:nilerr // Process being dead is the desired outcome for termination
 		return nil
 	}
 
-	// Try graceful termination first
+	// Try graceful termination first; platforms without SIGTERM fall back to a kill
 	//nolint:nestif // Complex termination logic with graceful fallback is necessary
 	if !forceKill {
-		if err := terminateProcess(osProcess); err != nil {
+		if err := executor.Signal(process.PID, group, syscall.SIGTERM); err != nil {
 			// If SIGTERM fails, the process might already be gone
-			if err.Error() == "os: process already finished" {
+			if errors.Is(err, os.ErrProcessDone) {
 				process.Status = StatusStopped
 				process.UpdatedAt = time.Now()
 				return nil
@@ -589,7 +2224,7 @@ func (pm *ProcessManager) terminateProcess(process *ManagedProcess, forceKill bo
 			time.Sleep(2 * time.Second)
 
 			// Check if process still exists
-			if isProcessAlive(osProcess) {
+			if executor.IsAlive(process.PID) {
 				// Process still running, force kill
 				forceKill = true
 			}
@@ -598,9 +2233,9 @@ func (pm *ProcessManager) terminateProcess(process *ManagedProcess, forceKill bo
 
 	// Force kill if requested or graceful termination failed
 	if forceKill {
-		if err := osProcess.Kill(); err != nil {
+		if err := executor.Kill(process.PID, group); err != nil {
 			// Process might have exited between checks
-			if err.Error() == "os: process already finished" {
+			if errors.Is(err, os.ErrProcessDone) {
 				process.Status = StatusStopped
 				process.UpdatedAt = time.Now()
 				return nil
@@ -616,8 +2251,14 @@ func (pm *ProcessManager) terminateProcess(process *ManagedProcess, forceKill bo
 	return nil
 }
 
-// findSimilarProcess finds a similar process that could be reused
-func (pm *ProcessManager) findSimilarProcess(command string) (*ManagedProcess, bool) {
+// findSimilarProcess finds a similar process in workingDir that could be reused
+func (pm *ProcessManager) findSimilarProcess(command, workingDir string) (*ManagedProcess, bool) {
+	return pm.findMatchingProcess(command, workingDir, (*ManagedProcess).IsHealthy)
+}
+
+// findMatchingProcess returns the newest process in workingDir with the command's signature that
+// accept allows. The same command in another directory serves another project and never matches.
+func (pm *ProcessManager) findMatchingProcess(command, workingDir string, accept func(*ManagedProcess) bool) (*ManagedProcess, bool) {
 	pm.mutex.RLock()
 	defer pm.mutex.RUnlock()
 
@@ -625,10 +2266,10 @@ func (pm *ProcessManager) findSimilarProcess(command string) (*ManagedProcess, b
 
 	var candidates []*ManagedProcess
 
-	// Find processes with matching command signature
+	// Find processes with matching command signature; the stored command already includes the args
 	for _, process := range pm.processes {
-		processSignature := pm.generateCommandSignature(process.Command, process.Args)
-		if processSignature == signature && process.IsHealthy() {
+		processSignature := pm.generateCommandSignature(process.Command, nil)
+		if processSignature == signature && process.runsIn(workingDir) && accept(process) {
 			candidates = append(candidates, process)
 		}
 	}
@@ -650,6 +2291,11 @@ func (pm *ProcessManager) findSimilarProcess(command string) (*ManagedProcess, b
 
 // generateCommandSignature generates a normalized signature for a command
 func (pm *ProcessManager) generateCommandSignature(command string, args []string) string {
+	return CommandSignature(command, args)
+}
+
+// CommandSignature normalizes a command and its arguments so equivalent invocations compare equal
+func CommandSignature(command string, args []string) string {
 	// Normalize command by joining with args and removing extra whitespace
 	fullCommand := strings.TrimSpace(command)
 	if len(args) > 0 {
@@ -661,8 +2307,53 @@ func (pm *ProcessManager) generateCommandSignature(command string, args []string
 	return strings.Join(parts, " ")
 }
 
-// updateProcessStatus updates the status of a process
-func (pm *ProcessManager) updateProcessStatus(processID string, status ProcessStatus) error {
+// updateProcessStatus updates the status of a process. When expected statuses are given, the
+// update is a compare-and-swap: it only applies while the current status is one of them and
+// fails with ErrStatusChanged otherwise.
+func (pm *ProcessManager) updateProcessStatus(processID string, status ProcessStatus, expected ...ProcessStatus) error {
+	_, err := pm.swapProcessStatus(processID, status, expected...)
+	return err
+}
+
+// swapProcessStatus is updateProcessStatus that also returns the status found before the update
+func (pm *ProcessManager) swapProcessStatus(processID string, status ProcessStatus, expected ...ProcessStatus) (ProcessStatus, error) {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+
+	process, exists := pm.processes[processID]
+	if !exists {
+		return "", fmt.Errorf("%w: %s", ErrProcessNotFound, processID)
+	}
+	previous := process.Status
+	if len(expected) > 0 && !slices.Contains(expected, previous) {
+		return previous, fmt.Errorf("%w: process %s is %s", ErrStatusChanged, processID, previous)
+	}
+
+	pm.applyStatusLocked(process, status)
+	return previous, pm.saveStatusLocked(process)
+}
+
+// statusVersion identifies a status observed for one run of a process. A restart changes the
+// PID and start time, so a probe of an earlier run does not match even if the status does.
+type statusVersion struct {
+	status    ProcessStatus
+	pid       int
+	startedAt time.Time
+}
+
+// statusVersion returns the current status version of the process. Callers must hold pm.mutex.
+func (p *ManagedProcess) statusVersion() statusVersion {
+	return statusVersion{status: p.Status, pid: p.PID, startedAt: p.StartedAt}
+}
+
+// matches reports whether two versions describe the same status of the same run
+func (v statusVersion) matches(other statusVersion) bool {
+	return v.status == other.status && v.pid == other.pid && v.startedAt.Equal(other.startedAt)
+}
+
+// compareAndSwapStatus records the status probed for a process, failing with ErrStatusChanged
+// when the process changed status or was restarted since observed was taken
+func (pm *ProcessManager) compareAndSwapStatus(processID string, status ProcessStatus, observed statusVersion) error {
 	pm.mutex.Lock()
 	defer pm.mutex.Unlock()
 
@@ -670,26 +2361,172 @@ func (pm *ProcessManager) updateProcessStatus(processID string, status ProcessSt
 	if !exists {
 		return fmt.Errorf("%w: %s", ErrProcessNotFound, processID)
 	}
+	if current := process.statusVersion(); !current.matches(observed) {
+		return fmt.Errorf("%w: process %s is %s (pid %d)", ErrStatusChanged, processID, current.status, current.pid)
+	}
+
+	pm.applyStatusLocked(process, status)
+	return pm.saveStatusLocked(process)
+}
 
+// applyStatusLocked sets the status of a process and keeps the port index in sync.
+// Callers must hold pm.mutex.
+func (pm *ProcessManager) applyStatusLocked(process *ManagedProcess, status ProcessStatus) {
 	process.Status = status
 	process.UpdatedAt = time.Now()
+	if process.IsRunning() {
+		pm.indexProcessPorts(process)
+	} else {
+		pm.unindexProcessPorts(process.ID)
+	}
+}
 
-	// Create a copy of the processes map for safe concurrent access to stateStore
-	processesCopy := make(map[string]*ManagedProcess)
-	for k, v := range pm.processes {
-		processesCopy[k] = v
+// RefreshStatuses synchronously reconciles the status of every active process with reality:
+// dead PIDs become stopped, and live ones are marked running or unhealthy by their health check.
+// A failing health check is a result, not an error. State is persisted once.
+func (pm *ProcessManager) RefreshStatuses() error {
+	if err := pm.lockState(); err != nil {
+		return err
+	}
+	defer pm.unlockState()
+
+	pm.mutex.RLock()
+	var candidates []*ManagedProcess
+	observed := make(map[string]statusVersion)
+	for _, process := range pm.processes {
+		if process.IsRunning() || process.Status == StatusPending {
+			candidates = append(candidates, process)
+			observed[process.ID] = process.statusVersion()
+		}
+	}
+	pm.mutex.RUnlock()
+
+	// Probe outside the mutex since health checks can take up to their timeout
+	var refreshErrors []error
+	statuses := make(map[string]ProcessStatus, len(candidates))
+	for _, process := range candidates {
+		status, checkErr := pm.probeStatus(process)
+		if checkErr != nil {
+			pm.log().Debug("health check failed", "process_id", process.ID, "error", checkErr)
+		}
+		statuses[process.ID] = status
+	}
+
+	logger := pm.log() // Fetched before locking since log() takes the read lock
+	pm.mutex.Lock()
+	now := time.Now()
+	for id, status := range statuses {
+		process, exists := pm.processes[id]
+		if !exists || !process.statusVersion().matches(observed[id]) {
+			continue // Removed, changed or restarted while probing; the probe result is stale
+		}
+		if process.Status != status {
+			logger.Debug("process status refreshed", "process_id", id, "from", process.Status, "to", status)
+		}
+		process.Status = status
+		process.UpdatedAt = now
+		if process.IsRunning() {
+			process.LastSeen = now
+			pm.indexProcessPorts(process)
+		} else {
+			pm.unindexProcessPorts(id)
+		}
 	}
+	processesCopy := pm.snapshotLocked()
+	pm.mutex.Unlock()
 
-	// Save to persistent storage
 	if err := pm.stateStore.Save(processesCopy); err != nil {
-		return fmt.Errorf("failed to save process state: %w", err)
+		refreshErrors = append(refreshErrors, fmt.Errorf("failed to save process state: %w", err))
 	}
 
-	return nil
+	return errors.Join(refreshErrors...)
+}
+
+// CheckHealth runs the process's health check now, records the resulting status and reports
+// whether the process is healthy. A process whose PID is gone is recorded as stopped and is
+// not healthy. Errors are reserved for unknown processes, missing checks and state failures.
+func (pm *ProcessManager) CheckHealth(id string) (bool, error) {
+	pm.mutex.RLock()
+	process, exists := pm.processes[id]
+	var healthCheck *HealthCheck
+	if exists {
+		healthCheck = process.HealthCheck
+	}
+	pm.mutex.RUnlock()
+
+	if !exists {
+		return false, fmt.Errorf("%w: %s", ErrProcessNotFound, id)
+	}
+	if healthCheck == nil || healthCheck.Type == HealthCheckNone {
+		return false, fmt.Errorf("%w for process %s", ErrNoHealthCheck, id)
+	}
+	if !healthCheck.Enabled {
+		return false, fmt.Errorf("%w for process %s (the check is disabled)", ErrNoHealthCheck, id)
+	}
+
+	// Probe before locking since health checks can take up to their timeout
+	pm.mutex.RLock()
+	observed := process.statusVersion()
+	pm.mutex.RUnlock()
+	status, checkErr := pm.probeStatus(process)
+	if checkErr != nil {
+		pm.log().Debug("health check failed", "process_id", id, "error", checkErr)
+	}
+
+	if err := pm.lockState(); err != nil {
+		return false, err
+	}
+	defer pm.unlockState()
+
+	// The result is stale if the process was stopped or restarted while probing
+	if err := pm.compareAndSwapStatus(id, status, observed); err != nil {
+		return false, err
+	}
+	if observed.status != status {
+		pm.notifyTransition(process, observed.status, status)
+	}
+	return status == StatusRunning, nil
+}
+
+// probeStatus determines a process's current status from PID liveness and its health check
+func (pm *ProcessManager) probeStatus(process *ManagedProcess) (ProcessStatus, error) {
+	pm.mutex.RLock()
+	pid := process.PID
+	pm.mutex.RUnlock()
+
+	if pid <= 0 {
+		return StatusStopped, nil
+	}
+	if !pm.processExecutor().IsAlive(pid) {
+		return StatusStopped, nil
+	}
+
+	if err := pm.runHealthCheck(context.Background(), process); err != nil {
+		return StatusUnhealthy, fmt.Errorf("health check failed: %w", err)
+	}
+	return StatusRunning, nil
 }
 
 // runHealthCheck runs a health check for a process
-func (pm *ProcessManager) runHealthCheck(ctx context.Context, process *ManagedProcess) error {
+func (pm *ProcessManager) runHealthCheck(ctx context.Context, process *ManagedProcess) (err error) {
+	defer func() {
+		if err != nil {
+			pm.healthCheckFailures.Add(1)
+		}
+	}()
+
+	// Work on a snapshot since SetHealthCheck may replace the check concurrently
+	original := process
+	pm.mutex.RLock()
+	process = &ManagedProcess{
+		ID:          process.ID,
+		PID:         process.PID,
+		Port:        process.Port,
+		BindAddress: process.BindAddress,
+		HealthCheck: process.HealthCheck,
+	}
+	pm.mutex.RUnlock()
+
 	if process.HealthCheck == nil {
 		return nil // No health check configured
 	}
@@ -698,8 +2535,16 @@ func (pm *ProcessManager) runHealthCheck(ctx context.Context, process *ManagedPr
 		return nil // Health checking disabled
 	}
 
+	pm.mutex.Lock()
+	original.LastHealthCheck = time.Now()
+	pm.mutex.Unlock()
+
 	// Set up timeout context
-	healthCtx, cancel := context.WithTimeout(ctx, process.HealthCheck.Timeout)
+	timeout := process.HealthCheck.Timeout
+	if timeout <= 0 {
+		timeout = defaultHealthCheckTimeout
+	}
+	healthCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	// Perform health check based on type
@@ -708,35 +2553,49 @@ func (pm *ProcessManager) runHealthCheck(ctx context.Context, process *ManagedPr
 		return pm.performHTTPHealthCheck(healthCtx, process)
 	case HealthCheckTCP:
 		return pm.performTCPHealthCheck(healthCtx, process)
+	case HealthCheckGRPC:
+		return pm.performGRPCHealthCheck(healthCtx, process)
 	case HealthCheckCommand:
 		return pm.performCommandHealthCheck(healthCtx, process)
 	case HealthCheckProcess:
 		// Process health check using PID
-		if process.PID > 0 {
-			if osProcess, err := os.FindProcess(process.PID); err == nil {
-				if isProcessAlive(osProcess) {
-					return nil // Process is running, consider it healthy
-				}
-			}
+		if process.PID > 0 && pm.processExecutor().IsAlive(process.PID) {
+			return nil // Process is running, consider it healthy
 		}
 		return fmt.Errorf("process %s failed process health check", process.ID)
 	case HealthCheckNone:
 		return nil // No health check
 	default:
 		// Fallback to basic process alive check
-		if process.PID > 0 {
-			if osProcess, err := os.FindProcess(process.PID); err == nil {
-				if isProcessAlive(osProcess) {
-					return nil // Process is running, consider it healthy
-				}
-			}
+		if process.PID > 0 && pm.processExecutor().IsAlive(process.PID) {
+			return nil // Process is running, consider it healthy
 		}
 		return fmt.Errorf("process %s failed basic health check", process.ID)
 	}
 }
 
-// cleanupStaleProcesses removes processes that haven't been seen for a while
+// cleanupStaleProcesses removes processes that haven't been seen for a while and are stopped
+// or whose PID is gone. A running process is kept however old its LastSeen, since only monitors
+// advance it. Protected processes are kept; only an explicit cleanup with IncludeProtected
+// removes them. It works on the latest state under the state lock, as other invocations may
+// have changed it since this manager loaded it.
 func (pm *ProcessManager) cleanupStaleProcesses(maxAge time.Duration) (int, error) {
+	logger := pm.log()
+	executor := pm.processExecutor()
+
+	if err := pm.lockState(); err != nil {
+		return 0, err
+	}
+	defer pm.unlockState()
+
+	// Write batched changes first so reloading does not drop them
+	if err := pm.flushLocked(); err != nil {
+		return 0, err
+	}
+	if err := pm.ReloadState(); err != nil {
+		return 0, err
+	}
+
 	pm.mutex.Lock()
 	defer pm.mutex.Unlock()
 
@@ -744,23 +2603,27 @@ func (pm *ProcessManager) cleanupStaleProcesses(maxAge time.Duration) (int, erro
 	cutoffTime := time.Now().Add(-maxAge)
 
 	for id, process := range pm.processes {
-		// Remove processes that haven't been seen recently (stale)
-		// This includes both running and non-running processes
-		if process.LastSeen.Before(cutoffTime) {
-			toRemove = append(toRemove, id)
+		if process.Protected || !process.LastSeen.Before(cutoffTime) {
+			continue
+		}
+		if process.IsRunning() && process.PID > 0 && executor.IsAlive(process.PID) {
+			continue
 		}
+		toRemove = append(toRemove, id)
+	}
+
+	if len(toRemove) > 0 {
+		pm.backupState(logger, pm.backupRetention)
 	}
 
 	for _, id := range toRemove {
 		delete(pm.processes, id)
+		pm.unindexProcessPorts(id)
 	}
 
 	if len(toRemove) > 0 {
 		// Create a copy of the processes map for safe concurrent access to stateStore
-		processesCopy := make(map[string]*ManagedProcess)
-		for k, v := range pm.processes {
-			processesCopy[k] = v
-		}
+		processesCopy := pm.snapshotLocked()
 
 		if err := pm.stateStore.Save(processesCopy); err != nil {
 			return 0, fmt.Errorf("failed to save process state: %w", err)
@@ -776,15 +2639,38 @@ func (pm *ProcessManager) performHTTPHealthCheck(ctx context.Context, process *M
 		return errors.New("HTTP health check target URL not specified")
 	}
 
+	healthCheck := process.HealthCheck
+	method := healthCheck.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
 	// Create HTTP request with context
-	req, err := http.NewRequestWithContext(ctx, "GET", process.HealthCheck.Target, http.NoBody)
+	req, err := http.NewRequestWithContext(ctx, method, healthCheck.Target, http.NoBody)
 	if err != nil {
 		return fmt.Errorf("failed to create HTTP request: %w", err)
 	}
+	for name, value := range healthCheck.Headers {
+		if strings.EqualFold(name, "Host") {
+			req.Host = value
+			continue
+		}
+		req.Header.Set(name, value)
+	}
+	if healthCheck.BasicAuthUser != "" || healthCheck.BasicAuthPassword != "" {
+		req.SetBasicAuth(healthCheck.BasicAuthUser, healthCheck.BasicAuthPassword)
+	}
+
+	transport, err := healthCheckTransport(healthCheck)
+	if err != nil {
+		return err
+	}
+	defer transport.CloseIdleConnections()
 
 	// Perform HTTP request with timeout
 	httpClient := &http.Client{
-		Timeout: process.HealthCheck.Timeout,
+		Timeout:   healthCheck.Timeout,
+		Transport: transport,
 	}
 
 	resp, err := httpClient.Do(req)
@@ -798,24 +2684,156 @@ func (pm *ProcessManager) performHTTPHealthCheck(ctx context.Context, process *M
 		return fmt.Errorf("HTTP health check failed with status %d", resp.StatusCode)
 	}
 
+	return checkHealthCheckBody(resp.Body, process.HealthCheck)
+}
+
+// healthCheckTransport builds the HTTP transport for a health check: the configured proxy,
+// or the proxy environment variables, and optionally no TLS certificate verification
+func healthCheckTransport(healthCheck *HealthCheck) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone() //nolint:forcetypeassert // The default transport is always an *http.Transport
+	transport.Proxy = http.ProxyFromEnvironment
+	if healthCheck.Proxy != "" {
+		proxyURL, err := url.Parse(healthCheck.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid proxy URL: %w", ErrInvalidHealthCheck, err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	if healthCheck.InsecureSkipVerify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec // Explicit opt-in for self-signed development certificates
+	}
+	return transport, nil
+}
+
+// checkHealthCheckBody validates the response body against the configured expectations.
+// The body is only read when an expectation is set, and never beyond maxHealthCheckBodySize.
+func checkHealthCheckBody(body io.Reader, healthCheck *HealthCheck) error {
+	if healthCheck.ExpectBody == "" && healthCheck.ExpectJSONPath == "" {
+		return nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, maxHealthCheckBodySize+1))
+	if err != nil {
+		return fmt.Errorf("failed to read HTTP health check response: %w", err)
+	}
+	if len(data) > maxHealthCheckBodySize {
+		return fmt.Errorf("%w: exceeds %d bytes", ErrBodyTooLarge, maxHealthCheckBodySize)
+	}
+
+	if healthCheck.ExpectBody != "" && !strings.Contains(string(data), healthCheck.ExpectBody) {
+		return fmt.Errorf("%w: body does not contain %q", ErrUnexpectedBody, healthCheck.ExpectBody)
+	}
+
+	if healthCheck.ExpectJSONPath != "" {
+		var document interface{}
+		if err := json.Unmarshal(data, &document); err != nil {
+			return fmt.Errorf("%w: body is not valid JSON: %w", ErrUnexpectedBody, err)
+		}
+
+		value, found := lookupJSONPath(document, healthCheck.ExpectJSONPath)
+		if !found {
+			return fmt.Errorf("%w: JSON path %q not found", ErrUnexpectedBody, healthCheck.ExpectJSONPath)
+		}
+		if healthCheck.ExpectJSONValue != "" && fmt.Sprint(value) != healthCheck.ExpectJSONValue {
+			return fmt.Errorf("%w: JSON path %q is %v, want %q",
+				ErrUnexpectedBody, healthCheck.ExpectJSONPath, value, healthCheck.ExpectJSONValue)
+		}
+	}
+
 	return nil
 }
 
+// lookupJSONPath resolves a dot-separated path of object keys and array indexes in a decoded JSON document
+func lookupJSONPath(document interface{}, path string) (interface{}, bool) {
+	current := document
+	for _, segment := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, exists := node[segment]
+			if !exists {
+				return nil, false
+			}
+			current = value
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, false
+			}
+			current = node[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, current != nil
+}
+
 // performTCPHealthCheck performs a TCP connection health check
 func (pm *ProcessManager) performTCPHealthCheck(ctx context.Context, process *ManagedProcess) error {
-	if process.HealthCheck.Target == "" {
+	// Without a target, dial the process port on the interface it was bound to
+	target := process.HealthCheck.Target
+	if target == "" && process.Port > 0 {
+		target = defaultTCPTarget(process.BindAddress, process.Port)
+	}
+	if target == "" {
 		return errors.New("TCP health check target address not specified")
 	}
 
 	// Create TCP connection with context
 	var dialer net.Dialer
-	conn, err := dialer.DialContext(ctx, "tcp", process.HealthCheck.Target)
+	conn, err := dialer.DialContext(ctx, "tcp", target)
 	if err != nil {
 		return fmt.Errorf("TCP health check failed: %w", err)
 	}
 	defer func() { _ = conn.Close() }() //nolint:errcheck // Cleanup operation
 
-	return nil
+	if process.HealthCheck.SendOnConnect == "" && process.HealthCheck.ExpectBanner == "" {
+		return nil
+	}
+	return tcpConversation(ctx, conn, process.HealthCheck.SendOnConnect, process.HealthCheck.ExpectBanner)
+}
+
+// tcpConversation writes send, if any, and then reads until the reply contains expect,
+// the peer closes the connection, the reply exceeds maxHealthCheckBannerSize or ctx expires
+func tcpConversation(ctx context.Context, conn net.Conn, send, expect string) error {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultHealthCheckTimeout)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return fmt.Errorf("TCP health check failed: %w", err)
+	}
+
+	if send != "" {
+		if _, err := io.WriteString(conn, send); err != nil {
+			return fmt.Errorf("TCP health check failed to send payload: %w", err)
+		}
+	}
+	if expect == "" {
+		return nil
+	}
+
+	reply := make([]byte, 0, 256)
+	buf := make([]byte, 256)
+	for len(reply) < maxHealthCheckBannerSize {
+		n, err := conn.Read(buf)
+		reply = append(reply, buf[:n]...)
+		if strings.Contains(string(reply), expect) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: want %q, got %q: %w", ErrUnexpectedBanner, expect, bannerExcerpt(reply), err)
+		}
+	}
+	return fmt.Errorf("%w: want %q within %d bytes, got %q", ErrUnexpectedBanner, expect, maxHealthCheckBannerSize, bannerExcerpt(reply))
+}
+
+// bannerExcerpt shortens a TCP reply for error messages
+func bannerExcerpt(reply []byte) string {
+	const maxExcerpt = 64
+	if len(reply) > maxExcerpt {
+		return string(reply[:maxExcerpt]) + "..."
+	}
+	return string(reply)
 }
 
 // performCommandHealthCheck performs a command-based health check
@@ -824,21 +2842,35 @@ func (pm *ProcessManager) performCommandHealthCheck(ctx context.Context, process
 		return errors.New("command health check target not specified")
 	}
 
-	// Parse command and arguments
-	parts := strings.Fields(process.HealthCheck.Target)
+	// Parse command and arguments, honouring quotes like start commands and hooks do
+	parts, err := SplitCommandLine(process.HealthCheck.Target)
+	if err != nil {
+		return fmt.Errorf("failed to parse health check command %q: %w", process.HealthCheck.Target, err)
+	}
 	if len(parts) == 0 {
 		return errors.New("empty health check command")
 	}
 
-	command := parts[0]
-	args := parts[1:]
+	inNamespace := process.HealthCheck.InNamespace
+	if inNamespace {
+		if parts, err = namespaceCommand(process.PID, parts); err != nil {
+			return err
+		}
+	}
 
 	// Execute command with context
-	cmd := exec.CommandContext(ctx, command, args...)
+	cmd := healthCommandContext(ctx, parts[0], parts[1:]...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
+		if inNamespace && strings.Contains(string(output), "Operation not permitted") {
+			return fmt.Errorf("%w: entering the namespace of PID %d needs root or CAP_SYS_ADMIN (output: %s)",
+				ErrNamespace, process.PID, strings.TrimSpace(string(output)))
+		}
 		return fmt.Errorf("command health check failed: %w (output: %s)", err, string(output))
 	}
 
 	return nil
 }
+
+// healthCommandContext creates the command run by command health checks; tests replace it
+var healthCommandContext = exec.CommandContext
@@ -1,8 +1,17 @@
 package process
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"sync"
+	"syscall"
 	"testing"
 	"time"
 
@@ -36,6 +45,16 @@ func (m *mockStateStore) Delete(id string) error {
 	return args.Error(0)
 }
 
+func (m *mockStateStore) BackupState() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *mockStateStore) CleanupOldBackups(maxAge time.Duration) error {
+	args := m.Called(maxAge)
+	return args.Error(0)
+}
+
 type mockLockManager struct {
 	mock.Mock
 }
@@ -215,6 +234,126 @@ func TestProcessManager_ShouldStartNew(t *testing.T) {
 	}
 }
 
+func TestProcessManager_ShouldStartNew_MultiplePorts(t *testing.T) {
+	t.Run("conflict_on_secondary_port_of_managed_process", func(t *testing.T) {
+		pm, _, _, mockPortScanner := setupTestProcessManager(t)
+		existing := createTestProcess("multi", "vite", 5173, StatusRunning)
+		existing.Ports = []int{5173, 24678}
+		pm.processes[existing.ID] = existing
+
+		mockPortScanner.On("IsPortInUse", 24678).Return(true)
+
+		shouldStart, returned := pm.ShouldStartNew("npm run storybook", 24678)
+		assert.False(t, shouldStart)
+		assert.Nil(t, returned)
+		mockPortScanner.AssertExpectations(t)
+	})
+
+	t.Run("conflict_on_requested_extra_port", func(t *testing.T) {
+		pm, _, _, mockPortScanner := setupTestProcessManager(t)
+
+		mockPortScanner.On("IsPortInUse", 3000).Return(false)
+		mockPortScanner.On("IsPortInUse", 9464).Return(true)
+
+		shouldStart, returned := pm.ShouldStartNew("npm run dev", 3000, 9464)
+		assert.False(t, shouldStart)
+		assert.Nil(t, returned)
+		mockPortScanner.AssertExpectations(t)
+	})
+
+	t.Run("all_ports_free", func(t *testing.T) {
+		pm, _, _, mockPortScanner := setupTestProcessManager(t)
+
+		mockPortScanner.On("IsPortInUse", 3000).Return(false)
+		mockPortScanner.On("IsPortInUse", 3001).Return(false)
+
+		shouldStart, _ := pm.ShouldStartNew("npm run dev", 3000, 3001, 3000, 0)
+		assert.True(t, shouldStart)
+		mockPortScanner.AssertExpectations(t)
+	})
+}
+
+func TestProcessManager_DecideStart(t *testing.T) {
+	tests := []struct {
+		name            string
+		command         string
+		port            int
+		existingProcess *ManagedProcess
+		portInUse       bool
+		expectedKind    StartDecisionKind
+		expectProcess   bool
+	}{
+		{
+			name:         "start_new_when_port_free",
+			command:      "npm run dev",
+			port:         3000,
+			expectedKind: DecisionStartNew,
+		},
+		{
+			name:            "reuse_same_command",
+			command:         "npm run dev",
+			port:            3000,
+			existingProcess: createTestProcess("same", "npm run dev", 3000, StatusRunning),
+			expectedKind:    DecisionReuse,
+			expectProcess:   true,
+		},
+		{
+			name:            "same_command_other_directory_starts_new",
+			command:         "npm run dev",
+			port:            3000,
+			existingProcess: withWorkingDir(createTestProcess("other-dir", "npm run dev", 3001, StatusRunning), "/work/other-project"),
+			expectedKind:    DecisionStartNew,
+		},
+		{
+			name:            "same_command_other_directory_conflicts_on_port",
+			command:         "npm run dev",
+			port:            3000,
+			existingProcess: withWorkingDir(createTestProcess("other-dir", "npm run dev", 3000, StatusRunning), "/work/other-project"),
+			portInUse:       true,
+			expectedKind:    DecisionConflictManaged,
+			expectProcess:   true,
+		},
+		{
+			name:            "conflict_with_managed_process",
+			command:         "npm run storybook",
+			port:            3000,
+			existingProcess: createTestProcess("other", "npm run dev", 3000, StatusRunning),
+			portInUse:       true,
+			expectedKind:    DecisionConflictManaged,
+			expectProcess:   true,
+		},
+		{
+			name:         "conflict_with_external_process",
+			command:      "npm run dev",
+			port:         3000,
+			portInUse:    true,
+			expectedKind: DecisionConflictExternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pm, _, _, mockPortScanner := setupTestProcessManager(t)
+			if tt.existingProcess != nil {
+				pm.processes[tt.existingProcess.ID] = tt.existingProcess
+			}
+			mockPortScanner.On("IsPortInUse", tt.port).Return(tt.portInUse).Maybe()
+
+			decision := pm.DecideStart(tt.command, tt.port)
+
+			assert.Equal(t, tt.expectedKind, decision.Kind)
+			if tt.expectProcess {
+				assert.Equal(t, tt.existingProcess, decision.Process)
+			} else {
+				assert.Nil(t, decision.Process)
+			}
+			if tt.portInUse {
+				assert.Equal(t, tt.port, decision.Port)
+			}
+		})
+	}
+}
+
 func TestProcessManager_StartProcess(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -297,6 +436,7 @@ func TestProcessManager_StartProcess(t *testing.T) {
 
 				// Clean up any running process
 				if process.PID > 0 {
+					mockStateStore.On("Load").Return(nil, os.ErrNotExist)
 					//nolint:errcheck // Test cleanup, error not critical
 					_ = pm.StopProcess(process.ID, true)
 				}
@@ -309,6 +449,367 @@ func TestProcessManager_StartProcess(t *testing.T) {
 	}
 }
 
+func TestProcessManager_StartProcess_WaitHealthy(t *testing.T) {
+	tests := []struct {
+		name           string
+		healthTarget   string
+		expectError    bool
+		expectedStatus ProcessStatus
+	}{
+		{
+			name:           "returns_once_healthy",
+			healthTarget:   "true",
+			expectedStatus: StatusRunning,
+		},
+		{
+			name:           "times_out_and_marks_unhealthy",
+			healthTarget:   "false",
+			expectError:    true,
+			expectedStatus: StatusUnhealthy,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pm, mockStateStore, mockLockManager, _ := setupTestProcessManager(t)
+			mockLockManager.On("Lock").Return(nil)
+			mockLockManager.On("Unlock").Return(nil)
+			mockStateStore.On("Save", mock.AnythingOfType("map[string]*process.ManagedProcess")).Return(nil)
+			mockStateStore.On("Load").Return(nil, os.ErrNotExist)
+
+			options := StartOptions{
+				HealthCheck: &HealthCheck{
+					Type:     HealthCheckCommand,
+					Target:   tt.healthTarget,
+					Interval: 50 * time.Millisecond,
+					Timeout:  time.Second,
+					Enabled:  true,
+				},
+				WaitHealthy: true,
+				WaitTimeout: 300 * time.Millisecond,
+			}
+
+			proc, err := pm.StartProcess("sleep", []string{"5"}, options)
+			if tt.expectError {
+				require.ErrorIs(t, err, ErrHealthWaitTimeout)
+				assert.Nil(t, proc)
+			} else {
+				require.NoError(t, err)
+				require.NotNil(t, proc)
+			}
+
+			// The process is left running either way
+			var started *ManagedProcess
+			for _, candidate := range pm.ListProcesses(ProcessListOptions{}) {
+				if candidate.Command == "sleep 5" {
+					started = candidate
+				}
+			}
+			require.NotNil(t, started)
+			assert.Equal(t, tt.expectedStatus, started.Status)
+
+			//nolint:errcheck // Test cleanup, error not critical
+			_ = pm.StopProcess(started.ID, true)
+		})
+	}
+}
+
+func TestProcessManager_StartProcess_AutoPort(t *testing.T) {
+	tests := []struct {
+		name         string
+		rangeEnd     int
+		managedPort  int
+		freePorts    map[int]int // FindAvailablePort start -> result
+		extraPorts   []int       // Additional ports, all held by another program
+		expectedPort int
+		expectError  error
+	}{
+		{
+			name:         "picks_next_free_port",
+			freePorts:    map[int]int{3000: 3001},
+			expectedPort: 3001,
+		},
+		{
+			name:         "skips_ports_claimed_by_managed_processes",
+			managedPort:  3001,
+			freePorts:    map[int]int{3000: 3001, 3002: 3002},
+			expectedPort: 3002,
+		},
+		{
+			name:        "errors_when_range_exhausted",
+			rangeEnd:    3005,
+			freePorts:   map[int]int{3000: 3010},
+			expectError: ErrNoAvailablePort,
+		},
+		{
+			name:        "rechecks_additional_ports",
+			freePorts:   map[int]int{3000: 3001},
+			extraPorts:  []int{24678},
+			expectError: ErrPortAlreadyInUse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pm, mockStateStore, mockLockManager, mockPortScanner := setupTestProcessManager(t)
+			mockLockManager.On("Lock").Return(nil)
+			mockLockManager.On("Unlock").Return(nil)
+			mockStateStore.On("Save", mock.AnythingOfType("map[string]*process.ManagedProcess")).Return(nil).Maybe()
+			mockStateStore.On("Load").Return(nil, os.ErrNotExist)
+			mockPortScanner.On("IsPortInUse", 3000).Return(true)
+			for _, extraPort := range tt.extraPorts {
+				mockPortScanner.On("IsPortInUse", extraPort).Return(true)
+			}
+			mockPortScanner.On("IsPortInUse", mock.Anything).Return(false)
+			mockPortScanner.On("GetPortInfo", mock.Anything).Return(nil, errors.New("not found")).Maybe()
+			for start, result := range tt.freePorts {
+				mockPortScanner.On("FindAvailablePort", start).Return(result, nil)
+			}
+			if tt.managedPort > 0 {
+				managed := createTestProcess("managed", "npm run storybook", tt.managedPort, StatusRunning)
+				pm.processes[managed.ID] = managed
+				pm.indexProcessPorts(managed)
+			}
+
+			proc, err := pm.StartProcess("sleep", []string{"1"}, StartOptions{
+				Port:         3000,
+				Ports:        tt.extraPorts,
+				AutoPort:     true,
+				PortRangeEnd: tt.rangeEnd,
+				Environment:  map[string]string{"PORT": PortPlaceholder},
+			})
+			if tt.expectError != nil {
+				require.ErrorIs(t, err, tt.expectError)
+				assert.Nil(t, proc)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedPort, proc.Port)
+			assert.Equal(t, strconv.Itoa(tt.expectedPort), proc.Environment["PORT"])
+
+			//nolint:errcheck // Test cleanup, error not critical
+			_ = pm.StopProcess(proc.ID, true)
+		})
+	}
+}
+
+func TestProcessManager_StartProcess_PortConflictError(t *testing.T) {
+	tests := []struct {
+		name        string
+		managed     bool
+		portInfo    *port.PortInfo
+		portInfoErr error
+		expected    PortConflictError
+		message     string
+	}{
+		{
+			name:     "managed_holder",
+			managed:  true,
+			expected: PortConflictError{Port: 3000, PID: 1009, ProcessName: "npm", Managed: true, ProcessID: "storybook"},
+			message:  "held by managed process storybook",
+		},
+		{
+			name:     "external_holder",
+			portInfo: &port.PortInfo{Port: 3000, PID: 4242, ProcessName: "python3", InUse: true},
+			expected: PortConflictError{Port: 3000, PID: 4242, ProcessName: "python3"},
+			message:  "held by python3, PID 4242",
+		},
+		{
+			name:        "external_holder_unknown",
+			portInfoErr: errors.New("lsof not available"),
+			expected:    PortConflictError{Port: 3000},
+			message:     "port is already in use: 3000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pm, _, mockLockManager, mockPortScanner := setupTestProcessManager(t)
+			mockLockManager.On("Lock").Return(nil)
+			mockLockManager.On("Unlock").Return(nil)
+			mockPortScanner.On("IsPortInUse", 3000).Return(true)
+			if tt.managed {
+				managed := createTestProcess("storybook", "npm run storybook", 3000, StatusRunning)
+				pm.processes[managed.ID] = managed
+				pm.indexProcessPorts(managed)
+			} else {
+				mockPortScanner.On("GetPortInfo", 3000).Return(tt.portInfo, tt.portInfoErr)
+			}
+
+			proc, err := pm.StartProcess("sleep", []string{"1"}, StartOptions{Port: 3000})
+			assert.Nil(t, proc)
+			require.ErrorIs(t, err, ErrPortAlreadyInUse)
+
+			var conflict *PortConflictError
+			require.ErrorAs(t, err, &conflict)
+			assert.Equal(t, tt.expected, *conflict)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+func TestExpandPortPlaceholders(t *testing.T) {
+	healthCheck := &HealthCheck{Type: HealthCheckHTTP, Target: "http://localhost:{port}/health"}
+	env := map[string]string{"PORT": "{port}", "NODE_ENV": "development"}
+
+	command, args, options := expandPortPlaceholders("vite", []string{"--port", "{port}"}, StartOptions{
+		Port:        5173,
+		Environment: env,
+		HealthCheck: healthCheck,
+	}, 5174)
+
+	assert.Equal(t, "vite", command)
+	assert.Equal(t, []string{"--port", "5174"}, args)
+	assert.Equal(t, 5174, options.Port)
+	assert.Equal(t, map[string]string{"PORT": "5174", "NODE_ENV": "development"}, options.Environment)
+	assert.Equal(t, "http://localhost:5174/health", options.HealthCheck.Target)
+
+	// Caller-owned values are left untouched
+	assert.Equal(t, "{port}", env["PORT"])
+	assert.Equal(t, "http://localhost:{port}/health", healthCheck.Target)
+}
+
+func TestProcessManager_StartProcess_MonitoringDisabled(t *testing.T) {
+	tests := []struct {
+		name            string
+		disableManager  bool
+		noMonitor       bool
+		expectedMonitor int
+	}{
+		{name: "monitors_by_default", expectedMonitor: 1},
+		{name: "start_option_skips_monitor", noMonitor: true},
+		{name: "manager_setting_skips_monitor", disableManager: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pm, mockStateStore, mockLockManager, _ := setupTestProcessManager(t)
+			mockLockManager.On("Lock").Return(nil)
+			mockLockManager.On("Unlock").Return(nil)
+			mockStateStore.On("Save", mock.AnythingOfType("map[string]*process.ManagedProcess")).Return(nil)
+			mockStateStore.On("Load").Return(nil, os.ErrNotExist)
+			pm.SetMonitoringDisabled(tt.disableManager)
+
+			proc, err := pm.StartProcess("sleep", []string{"5"}, StartOptions{NoMonitor: tt.noMonitor})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedMonitor, pm.ActiveMonitors())
+
+			//nolint:errcheck // Test cleanup, error not critical
+			_ = pm.StopProcess(proc.ID, true)
+		})
+	}
+}
+
+func TestProcessManager_RefreshStatuses(t *testing.T) {
+	pm, mockStateStore, mockLockManager, _ := setupTestProcessManager(t)
+	mockLockManager.On("Lock").Return(nil)
+	mockLockManager.On("Unlock").Return(nil)
+	mockStateStore.On("Save", mock.AnythingOfType("map[string]*process.ManagedProcess")).Return(nil).Once()
+
+	// A reaped child gives a PID that is known to be dead
+	exited := exec.Command("true")
+	require.NoError(t, exited.Run())
+
+	commandCheck := func(target string) *HealthCheck {
+		return &HealthCheck{Type: HealthCheckCommand, Target: target, Timeout: time.Second, Enabled: true}
+	}
+
+	recovered := createTestProcess("recovered", "npm run dev", 3000, StatusUnhealthy)
+	recovered.PID = os.Getpid()
+	recovered.HealthCheck = commandCheck("true")
+
+	failing := createTestProcess("failing", "npm run api", 3001, StatusRunning)
+	failing.PID = os.Getpid()
+	failing.HealthCheck = commandCheck("false")
+
+	dead := createTestProcess("dead", "npm run docs", 3002, StatusRunning)
+	dead.PID = exited.Process.Pid
+
+	stopped := createTestProcess("stopped", "npm run storybook", 3003, StatusStopped)
+
+	for _, proc := range []*ManagedProcess{recovered, failing, dead, stopped} {
+		pm.processes[proc.ID] = proc
+	}
+	pm.rebuildPortIndex()
+
+	// A failing health check marks the process unhealthy without being a refresh error
+	require.NoError(t, pm.RefreshStatuses())
+
+	assert.Equal(t, StatusRunning, recovered.Status)
+	assert.Equal(t, StatusUnhealthy, failing.Status)
+	assert.Equal(t, StatusStopped, dead.Status)
+	assert.Equal(t, StatusStopped, stopped.Status)
+
+	_, indexed := pm.GetProcessByPort(3002)
+	assert.False(t, indexed, "stopped processes should release their ports")
+
+	mockStateStore.AssertNumberOfCalls(t, "Save", 1)
+}
+
+func TestProcessManager_SignalProcess(t *testing.T) {
+	pm, mockStateStore, mockLockManager, _ := setupTestProcessManager(t)
+
+	marker := filepath.Join(t.TempDir(), "reloaded")
+	script := fmt.Sprintf(`trap 'touch %q' HUP; while :; do sleep 0.05; done`, marker)
+	child := exec.Command("sh", "-c", script)
+	child.SysProcAttr = setSysProcAttr(nil, false)
+	require.NoError(t, child.Start())
+	t.Cleanup(func() {
+		_ = child.Process.Kill() //nolint:errcheck // Test cleanup can fail
+		_ = child.Wait()         //nolint:errcheck // Test cleanup can fail
+	})
+
+	managed := createTestProcess("reloadable", "nginx", 8080, StatusRunning)
+	managed.PID = child.Process.Pid
+	pm.processes[managed.ID] = managed
+
+	// Give the shell a moment to install its trap
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, pm.SignalProcess(managed.ID, syscall.SIGHUP))
+
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(marker)
+		return err == nil
+	}, 2*time.Second, 20*time.Millisecond)
+	assert.Equal(t, StatusRunning, managed.Status)
+	assert.True(t, isProcessAlive(child.Process))
+
+	err := pm.SignalProcess("missing", syscall.SIGHUP)
+	require.ErrorIs(t, err, ErrProcessNotFound)
+
+	mockStateStore.AssertNotCalled(t, "Save", mock.Anything)
+	mockLockManager.AssertNotCalled(t, "Lock")
+}
+
+func TestParseSignal(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    os.Signal
+		wantErr bool
+	}{
+		{input: "HUP", want: syscall.SIGHUP},
+		{input: "sighup", want: syscall.SIGHUP},
+		{input: " TERM ", want: syscall.SIGTERM},
+		{input: "9", want: syscall.SIGKILL},
+		{input: "BOGUS", wantErr: true},
+		{input: "0", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			sig, err := ParseSignal(tt.input)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrUnknownSignal)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, sig)
+		})
+	}
+}
+
 func TestProcessManager_StopProcess(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -325,6 +826,7 @@ func TestProcessManager_StopProcess(t *testing.T) {
 				lockManager.On("Lock").Return(nil)
 				lockManager.On("Unlock").Return(nil)
 				stateStore.On("Save", mock.AnythingOfType("map[string]*process.ManagedProcess")).Return(nil)
+				stateStore.On("Load").Return(nil, os.ErrNotExist)
 			},
 			expectError: false,
 		},
@@ -395,6 +897,116 @@ func TestProcessManager_GetProcess(t *testing.T) {
 	assert.Nil(t, process)
 }
 
+func TestProcessManager_GetProcessByPort(t *testing.T) {
+	t.Run("port_is_reassigned_after_stop", func(t *testing.T) {
+		pm, mockStateStore, mockLockManager, mockPortScanner := setupTestProcessManager(t)
+		mockPortScanner.On("IsPortInUse", mock.AnythingOfType("int")).Return(false)
+		mockLockManager.On("Lock").Return(nil)
+		mockLockManager.On("Unlock").Return(nil)
+		mockStateStore.On("Save", mock.AnythingOfType("map[string]*process.ManagedProcess")).Return(nil)
+		mockStateStore.On("Load").Return(nil, os.ErrNotExist)
+
+		first, err := pm.StartProcess("sleep", []string{"5"}, StartOptions{Port: 4100, Ports: []int{4100, 4101}})
+		require.NoError(t, err)
+
+		found, exists := pm.GetProcessByPort(4101)
+		require.True(t, exists)
+		assert.Equal(t, first.ID, found.ID)
+
+		require.NoError(t, pm.StopProcess(first.ID, true))
+		_, exists = pm.GetProcessByPort(4100)
+		assert.False(t, exists)
+		_, exists = pm.GetProcessByPort(4101)
+		assert.False(t, exists)
+
+		second, err := pm.StartProcess("sleep", []string{"6"}, StartOptions{Port: 4100})
+		require.NoError(t, err)
+		defer func() {
+			//nolint:errcheck // Test cleanup, error not critical
+			_ = pm.StopProcess(second.ID, true)
+		}()
+
+		found, exists = pm.GetProcessByPort(4100)
+		require.True(t, exists)
+		assert.Equal(t, second.ID, found.ID)
+		_, exists = pm.GetProcessByPort(4101)
+		assert.False(t, exists)
+	})
+
+	t.Run("status_changes_update_index", func(t *testing.T) {
+		pm, mockStateStore, _, _ := setupTestProcessManager(t)
+		mockStateStore.On("Save", mock.AnythingOfType("map[string]*process.ManagedProcess")).Return(nil)
+
+		testProcess := createTestProcess("test-port", "test command", 9100, StatusRunning)
+		pm.processes[testProcess.ID] = testProcess
+		pm.rebuildPortIndex()
+
+		found, exists := pm.GetProcessByPort(9100)
+		require.True(t, exists)
+		assert.Equal(t, testProcess, found)
+
+		require.NoError(t, pm.updateProcessStatus(testProcess.ID, StatusFailed))
+		_, exists = pm.GetProcessByPort(9100)
+		assert.False(t, exists)
+
+		require.NoError(t, pm.updateProcessStatus(testProcess.ID, StatusUnhealthy))
+		_, exists = pm.GetProcessByPort(9100)
+		assert.True(t, exists)
+	})
+
+	t.Run("cleanup_releases_ports", func(t *testing.T) {
+		pm, mockStateStore, mockLockManager, _ := setupTestProcessManager(t)
+		mockLockManager.On("Lock").Return(nil)
+		mockLockManager.On("Unlock").Return(nil)
+		mockStateStore.On("BackupState").Return(nil)
+		mockStateStore.On("Save", mock.AnythingOfType("map[string]*process.ManagedProcess")).Return(nil)
+
+		testProcess := createTestProcess("test-clean", "test command", 9200, StatusRunning)
+		pm.processes[testProcess.ID] = testProcess
+		pm.rebuildPortIndex()
+
+		// Simulate the process exiting without going through StopProcess
+		testProcess.Status = StatusStopped
+
+		require.NoError(t, pm.CleanupProcesses(CleanupOptions{}))
+		assert.NotContains(t, pm.portIndex, 9200)
+		_, exists := pm.GetProcessByPort(9200)
+		assert.False(t, exists)
+	})
+
+	t.Run("unknown_port", func(t *testing.T) {
+		pm, _, _, _ := setupTestProcessManager(t)
+
+		proc, exists := pm.GetProcessByPort(9999)
+		assert.False(t, exists)
+		assert.Nil(t, proc)
+	})
+}
+
+func TestProcessManager_SetLogger(t *testing.T) {
+	pm, mockStateStore, _, _ := setupTestProcessManager(t)
+	mockStateStore.On("Save", mock.AnythingOfType("map[string]*process.ManagedProcess")).Return(nil)
+
+	var buf bytes.Buffer
+	pm.SetLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	// Status updates for unknown processes are logged instead of silently dropped
+	pm.setStatus(&ManagedProcess{ID: "missing", PID: 42}, StatusStopped)
+	assert.Contains(t, buf.String(), `"msg":"failed to update process status"`)
+	assert.Contains(t, buf.String(), `"process_id":"missing"`)
+
+	buf.Reset()
+	testProcess := createTestProcess("test-log", "test command", 9300, StatusRunning)
+	pm.processes[testProcess.ID] = testProcess
+	pm.setStatus(testProcess, StatusUnhealthy)
+	assert.Contains(t, buf.String(), `"msg":"process status changed"`)
+	assert.Contains(t, buf.String(), `"to":"unhealthy"`)
+
+	// A nil logger discards output without panicking
+	pm.SetLogger(nil)
+	assert.NotPanics(t, func() { pm.setStatus(testProcess, StatusRunning) })
+}
+
 func TestProcessManager_ListProcesses(t *testing.T) {
 	pm, _, _, _ := setupTestProcessManager(t)
 
@@ -402,6 +1014,8 @@ func TestProcessManager_ListProcesses(t *testing.T) {
 	runningProcess := createTestProcess("running", "npm start", 3000, StatusRunning)
 	stoppedProcess := createTestProcess("stopped", "npm build", 3001, StatusStopped)
 	unhealthyProcess := createTestProcess("unhealthy", "go run main.go", 8080, StatusUnhealthy)
+	stoppedProcess.CreatedAt = time.Now().Add(-10 * time.Minute)
+	unhealthyProcess.CreatedAt = time.Now().Add(-2 * time.Hour)
 
 	pm.processes["running"] = runningProcess
 	pm.processes["stopped"] = stoppedProcess
@@ -431,6 +1045,52 @@ func TestProcessManager_ListProcesses(t *testing.T) {
 			expectedCount: 1,
 			expectedIDs:   []string{"running"},
 		},
+		{
+			name:          "filter_by_command_case_insensitive",
+			options:       ProcessListOptions{IncludeStopped: true, FilterByCommand: "NPM"},
+			expectedCount: 2,
+			expectedIDs:   []string{"running", "stopped"},
+		},
+		{
+			name:          "filter_by_command_partial_match",
+			options:       ProcessListOptions{IncludeStopped: true, FilterByCommand: "main.g"},
+			expectedCount: 1,
+			expectedIDs:   []string{"unhealthy"},
+		},
+		{
+			name:          "filter_by_command_and_port",
+			options:       ProcessListOptions{IncludeStopped: true, FilterByCommand: "npm", FilterByPort: 3001},
+			expectedCount: 1,
+			expectedIDs:   []string{"stopped"},
+		},
+		{
+			name:          "filter_by_command_and_running",
+			options:       ProcessListOptions{FilterByCommand: "npm"},
+			expectedCount: 1,
+			expectedIDs:   []string{"running"},
+		},
+		{
+			name:          "filter_by_created_after",
+			options:       ProcessListOptions{IncludeStopped: true, CreatedAfter: time.Now().Add(-30 * time.Minute)},
+			expectedCount: 1,
+			expectedIDs:   []string{"stopped"},
+		},
+		{
+			name:          "filter_by_created_before",
+			options:       ProcessListOptions{IncludeStopped: true, CreatedBefore: time.Now().Add(-30 * time.Minute)},
+			expectedCount: 2,
+			expectedIDs:   []string{"running", "unhealthy"},
+		},
+		{
+			name: "filter_by_created_window",
+			options: ProcessListOptions{
+				IncludeStopped: true,
+				CreatedAfter:   time.Now().Add(-3 * time.Hour),
+				CreatedBefore:  time.Now().Add(-90 * time.Minute),
+			},
+			expectedCount: 1,
+			expectedIDs:   []string{"unhealthy"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -451,13 +1111,20 @@ func TestProcessManager_ListProcesses(t *testing.T) {
 }
 
 func TestProcessManager_CleanupProcesses(t *testing.T) {
+	protectedProcess := func(id string, status ProcessStatus) *ManagedProcess {
+		proc := createTestProcess(id, "postgres -D data", 5432, status)
+		proc.Protected = true
+		return proc
+	}
+
 	tests := []struct {
-		name            string
-		force           bool
-		processes       map[string]*ManagedProcess
-		mockSetup       func(*mockStateStore, *mockLockManager, *mockPortScanner)
-		expectError     bool
-		expectedCleanup int
+		name             string
+		force            bool
+		includeProtected bool
+		processes        map[string]*ManagedProcess
+		mockSetup        func(*mockStateStore, *mockLockManager, *mockPortScanner)
+		expectError      bool
+		expectedCleanup  int
 	}{
 		{
 			name:  "cleanup_stopped_processes",
@@ -470,6 +1137,7 @@ func TestProcessManager_CleanupProcesses(t *testing.T) {
 			mockSetup: func(stateStore *mockStateStore, lockManager *mockLockManager, portScanner *mockPortScanner) {
 				lockManager.On("Lock").Return(nil)
 				lockManager.On("Unlock").Return(nil)
+				stateStore.On("BackupState").Return(nil)
 				stateStore.On("Save", mock.AnythingOfType("map[string]*process.ManagedProcess")).Return(nil)
 			},
 			expectError:     false,
@@ -485,11 +1153,46 @@ func TestProcessManager_CleanupProcesses(t *testing.T) {
 			mockSetup: func(stateStore *mockStateStore, lockManager *mockLockManager, portScanner *mockPortScanner) {
 				lockManager.On("Lock").Return(nil)
 				lockManager.On("Unlock").Return(nil)
+				stateStore.On("BackupState").Return(nil)
 				stateStore.On("Save", mock.AnythingOfType("map[string]*process.ManagedProcess")).Return(nil)
 			},
 			expectError:     false,
 			expectedCleanup: 2, // all processes
 		},
+		{
+			name:  "force_cleanup_keeps_protected",
+			force: true,
+			processes: map[string]*ManagedProcess{
+				"database":       protectedProcess("database", StatusRunning),
+				"database-crash": protectedProcess("database-crash", StatusFailed),
+				"stopped":        createTestProcess("stopped", "npm build", 3001, StatusStopped),
+			},
+			mockSetup: func(stateStore *mockStateStore, lockManager *mockLockManager, portScanner *mockPortScanner) {
+				lockManager.On("Lock").Return(nil)
+				lockManager.On("Unlock").Return(nil)
+				stateStore.On("BackupState").Return(nil)
+				stateStore.On("Save", mock.AnythingOfType("map[string]*process.ManagedProcess")).Return(nil)
+			},
+			expectError:     false,
+			expectedCleanup: 1, // only the unprotected process
+		},
+		{
+			name:             "include_protected_override",
+			force:            true,
+			includeProtected: true,
+			processes: map[string]*ManagedProcess{
+				"database": protectedProcess("database", StatusRunning),
+				"stopped":  createTestProcess("stopped", "npm build", 3001, StatusStopped),
+			},
+			mockSetup: func(stateStore *mockStateStore, lockManager *mockLockManager, portScanner *mockPortScanner) {
+				lockManager.On("Lock").Return(nil)
+				lockManager.On("Unlock").Return(nil)
+				stateStore.On("BackupState").Return(nil)
+				stateStore.On("Save", mock.AnythingOfType("map[string]*process.ManagedProcess")).Return(nil)
+			},
+			expectError:     false,
+			expectedCleanup: 2,
+		},
 	}
 
 	for _, tt := range tests {
@@ -504,7 +1207,7 @@ func TestProcessManager_CleanupProcesses(t *testing.T) {
 
 			tt.mockSetup(mockStateStore, mockLockManager, mockPortScanner)
 
-			err := pm.CleanupProcesses(tt.force)
+			err := pm.CleanupProcesses(CleanupOptions{Force: tt.force, IncludeProtected: tt.includeProtected})
 
 			if tt.expectError {
 				require.Error(t, err)
@@ -521,6 +1224,109 @@ func TestProcessManager_CleanupProcesses(t *testing.T) {
 	}
 }
 
+func TestProcessManager_CleanupProcesses_BacksUpState(t *testing.T) {
+	t.Run("backup_before_save_and_prune", func(t *testing.T) {
+		pm, mockStateStore, mockLockManager, _ := setupTestProcessManager(t)
+		pm.SetBackupRetention(24 * time.Hour)
+		pm.processes["stopped"] = createTestProcess("stopped", "npm build", 3001, StatusStopped)
+
+		var calls []string
+		mockLockManager.On("Lock").Return(nil)
+		mockLockManager.On("Unlock").Return(nil)
+		mockStateStore.On("BackupState").Return(nil).Run(func(mock.Arguments) { calls = append(calls, "backup") })
+		mockStateStore.On("CleanupOldBackups", 24*time.Hour).Return(nil).Run(func(mock.Arguments) { calls = append(calls, "prune") })
+		mockStateStore.On("Save", mock.Anything).Return(nil).Run(func(mock.Arguments) { calls = append(calls, "save") })
+
+		require.NoError(t, pm.CleanupProcesses(CleanupOptions{}))
+		assert.Equal(t, []string{"backup", "prune", "save"}, calls)
+	})
+
+	t.Run("backup_failure_does_not_block", func(t *testing.T) {
+		pm, mockStateStore, mockLockManager, _ := setupTestProcessManager(t)
+		pm.SetBackupRetention(24 * time.Hour)
+		pm.processes["stopped"] = createTestProcess("stopped", "npm build", 3001, StatusStopped)
+
+		var buf bytes.Buffer
+		pm.SetLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+
+		mockLockManager.On("Lock").Return(nil)
+		mockLockManager.On("Unlock").Return(nil)
+		mockStateStore.On("BackupState").Return(errors.New("disk full"))
+		mockStateStore.On("Save", mock.Anything).Return(nil)
+
+		require.NoError(t, pm.CleanupProcesses(CleanupOptions{}))
+		assert.Empty(t, pm.processes)
+		assert.Contains(t, buf.String(), "failed to back up state")
+		mockStateStore.AssertNotCalled(t, "CleanupOldBackups", mock.Anything)
+	})
+
+	t.Run("nothing_to_remove_skips_backup", func(t *testing.T) {
+		pm, mockStateStore, mockLockManager, _ := setupTestProcessManager(t)
+		pm.processes["running"] = createTestProcess("running", "npm start", 3000, StatusRunning)
+
+		mockLockManager.On("Lock").Return(nil)
+		mockLockManager.On("Unlock").Return(nil)
+		mockStateStore.On("Save", mock.Anything).Return(nil)
+
+		require.NoError(t, pm.CleanupProcesses(CleanupOptions{}))
+		mockStateStore.AssertNotCalled(t, "BackupState")
+	})
+}
+
+func TestProcessManager_PlanCleanup(t *testing.T) {
+	pm, mockStateStore, mockLockManager, _ := setupTestProcessManager(t)
+	mockLockManager.On("Lock").Return(nil)
+	mockLockManager.On("Unlock").Return(nil)
+	mockStateStore.On("BackupState").Return(nil)
+	mockStateStore.On("Save", mock.AnythingOfType("map[string]*process.ManagedProcess")).Return(nil)
+
+	logFile := filepath.Join(t.TempDir(), "stopped.log")
+	require.NoError(t, os.WriteFile(logFile, []byte("log"), 0o644))
+	workingDir := filepath.Join(t.TempDir(), "scratch")
+	require.NoError(t, os.Mkdir(workingDir, 0o755))
+
+	running := createTestProcess("running", "npm run dev", 3000, StatusRunning)
+	stopped := createTestProcess("stopped", "go run main.go", 8080, StatusStopped)
+	stopped.LogFile = logFile
+	failed := createTestProcess("failed", "flask run", 5000, StatusFailed)
+	failed.WorkingDir = workingDir
+	failed.CleanupWorkingDir = true
+	for _, proc := range []*ManagedProcess{running, stopped, failed} {
+		pm.processes[proc.ID] = proc
+	}
+
+	t.Run("force_includes_running_processes", func(t *testing.T) {
+		plan := pm.PlanCleanup(CleanupOptions{Force: true})
+		require.Len(t, plan, 3)
+		assert.Equal(t, "running", plan[1].ID)
+		assert.True(t, plan[1].Terminate)
+	})
+
+	t.Run("preview_matches_cleanup", func(t *testing.T) {
+		plan := pm.PlanCleanup(CleanupOptions{})
+		require.Len(t, plan, 2)
+		assert.Equal(t, "failed", plan[0].ID)
+		assert.Equal(t, workingDir, plan[0].WorkingDir)
+		assert.Equal(t, "stopped", plan[1].ID)
+		assert.Equal(t, logFile, plan[1].LogFile)
+
+		// Planning must not touch anything
+		assert.Len(t, pm.processes, 3)
+		assert.FileExists(t, logFile)
+		assert.DirExists(t, workingDir)
+
+		require.NoError(t, pm.CleanupProcesses(CleanupOptions{}))
+		for _, entry := range plan {
+			_, exists := pm.GetProcess(entry.ID)
+			assert.False(t, exists, entry.ID)
+		}
+		_, exists := pm.GetProcess("running")
+		assert.True(t, exists)
+		assert.NoFileExists(t, logFile)
+		assert.NoDirExists(t, workingDir)
+	})
+}
+
 func TestProcessManager_ConcurrentOperations(t *testing.T) {
 	pm, mockStateStore, mockLockManager, mockPortScanner := setupTestProcessManager(t)
 
@@ -575,36 +1381,45 @@ func TestProcessManager_generateID(t *testing.T) {
 	pm, _, _, _ := setupTestProcessManager(t)
 
 	tests := []struct {
-		name    string
-		command string
+		name       string
+		command    string
+		expectSlug string
 	}{
 		{
-			name:    "simple_command",
-			command: "npm run dev",
+			name:       "simple_command",
+			command:    "npm run dev",
+			expectSlug: "npm-dev",
+		},
+		{
+			name:       "complex_command_with_args",
+			command:    "go run -ldflags='-X main.version=1.0' main.go --port=8080",
+			expectSlug: "go-main",
+		},
+		{
+			name:       "command_with_special_chars",
+			command:    "python3 -m http.server --bind 127.0.0.1",
+			expectSlug: "python3-http",
 		},
 		{
-			name:    "complex_command_with_args",
-			command: "go run -ldflags='-X main.version=1.0' main.go --port=8080",
+			name:       "absolute_path_is_trimmed_to_fit",
+			command:    "/usr/local/bin/webpack-dev-server serve",
+			expectSlug: "webpackdevse",
 		},
 		{
-			name:    "command_with_special_chars",
-			command: "python3 -m http.server --bind 127.0.0.1",
+			name:       "no_usable_words",
+			command:    "--- !!!",
+			expectSlug: "proc",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			id1 := pm.generateID(tt.command)
-
-			// Add small delay to ensure different timestamp
-			time.Sleep(time.Microsecond)
 			id2 := pm.generateID(tt.command)
 
-			// IDs should be non-empty and have expected length (8 hex chars)
-			assert.NotEmpty(t, id1)
-			assert.NotEmpty(t, id2)
-			assert.Len(t, id1, 8)
-			assert.Len(t, id2, 8)
+			// IDs are the command slug followed by 6 hex chars, safe for paths and URLs
+			assert.Regexp(t, "^"+regexp.QuoteMeta(tt.expectSlug)+"-[0-9a-f]{6}$", id1)
+			assert.Regexp(t, "^[a-z0-9-]+$", id2)
 
 			// IDs should be unique due to timestamp difference
 			assert.NotEqual(t, id1, id2, "Each generateID call should create unique ID due to timestamp")
@@ -618,6 +1433,27 @@ func TestProcessManager_generateID(t *testing.T) {
 	}
 }
 
+func TestProcessManager_uniqueID(t *testing.T) {
+	pm, _, _, _ := setupTestProcessManager(t)
+	hash := "a1b2c3d4e5f6"
+
+	id, ok := pm.uniqueID("npm-dev", hash)
+	require.True(t, ok)
+	assert.Equal(t, "npm-dev-a1b2c3", id)
+
+	// Collisions extend the hash instead of reusing an ID
+	pm.processes["npm-dev-a1b2c3"] = &ManagedProcess{ID: "npm-dev-a1b2c3"}
+	id, ok = pm.uniqueID("npm-dev", hash)
+	require.True(t, ok)
+	assert.Equal(t, "npm-dev-a1b2c3d4", id)
+
+	pm.processes["npm-dev-a1b2c3d4"] = &ManagedProcess{ID: "npm-dev-a1b2c3d4"}
+	pm.processes["npm-dev-a1b2c3d4e5"] = &ManagedProcess{ID: "npm-dev-a1b2c3d4e5"}
+	pm.processes["npm-dev-a1b2c3d4e5f6"] = &ManagedProcess{ID: "npm-dev-a1b2c3d4e5f6"}
+	_, ok = pm.uniqueID("npm-dev", hash)
+	assert.False(t, ok, "an exhausted hash asks the caller to reseed")
+}
+
 func TestNewProcessManager_WithExistingState(t *testing.T) {
 	mockStore := &mockStateStore{}
 	mockLock := &mockLockManager{}
@@ -631,7 +1467,7 @@ func TestNewProcessManager_WithExistingState(t *testing.T) {
 			Status: StatusRunning,
 		},
 		"process-2": {
-			ID:     "process-2", 
+			ID:     "process-2",
 			PID:    5678,
 			Status: StatusStopped,
 		},
@@ -664,6 +1500,7 @@ func TestProcessManager_AdoptProcess_Comprehensive(t *testing.T) {
 
 		processToAdopt := &ManagedProcess{
 			ID:         "test-process",
+			Command:    "npm run dev",
 			PID:        12345,
 			Status:     StatusRunning,
 			IsExternal: true,
@@ -721,4 +1558,495 @@ func TestProcessManager_AdoptProcess_Comprehensive(t *testing.T) {
 		mockStore.AssertExpectations(t)
 		mockLock.AssertExpectations(t)
 	})
+	t.Run("adopt_process_without_command", func(t *testing.T) {
+		mockStore := &mockStateStore{}
+		mockLock := &mockLockManager{}
+		mockPortScanner := &mockPortScanner{}
+
+		mockStore.On("Load").Return(nil, fmt.Errorf("no state file"))
+		mockLock.On("Lock").Return(nil)
+		mockLock.On("Unlock").Return(nil)
+
+		manager := NewProcessManager(mockStore, mockLock, mockPortScanner)
+
+		processToAdopt := &ManagedProcess{
+			ID:         "test-process",
+			PID:        12345,
+			Status:     StatusRunning,
+			IsExternal: true,
+		}
+
+		err := manager.AdoptProcess(processToAdopt)
+		require.ErrorIs(t, err, ErrEmptyCommand)
+		assert.NotContains(t, manager.processes, "test-process")
+		mockStore.AssertNotCalled(t, "Save", mock.Anything)
+	})
+
+	t.Run("adopt_process_command_from_config", func(t *testing.T) {
+		mockStore := &mockStateStore{}
+		mockLock := &mockLockManager{}
+		mockPortScanner := &mockPortScanner{}
+
+		mockStore.On("Load").Return(nil, fmt.Errorf("no state file"))
+		mockLock.On("Lock").Return(nil)
+		mockLock.On("Unlock").Return(nil)
+		mockStore.On("Save", mock.Anything).Return(nil)
+
+		manager := NewProcessManager(mockStore, mockLock, mockPortScanner)
+
+		processToAdopt := &ManagedProcess{
+			Config:     &ProcessConfig{Command: "vite"},
+			ID:         "test-process",
+			PID:        12345,
+			Status:     StatusRunning,
+			IsExternal: true,
+		}
+
+		require.NoError(t, manager.AdoptProcess(processToAdopt))
+		assert.Equal(t, "vite", manager.processes["test-process"].Command)
+	})
+}
+
+func TestExitResult(t *testing.T) {
+	code, reason := exitResult(nil)
+	require.NotNil(t, code)
+	assert.Equal(t, 0, *code)
+	assert.Equal(t, "exit status 0", reason)
+
+	err := exec.Command("sh", "-c", "exit 3").Run()
+	code, reason = exitResult(err)
+	require.NotNil(t, code)
+	assert.Equal(t, 3, *code)
+	assert.Equal(t, "exit status 3", reason)
+
+	err = exec.Command("sh", "-c", "kill -KILL $$").Run()
+	code, reason = exitResult(err)
+	assert.Nil(t, code)
+	assert.Equal(t, "signal: killed", reason)
+
+	code, reason = exitResult(errors.New("wait failed"))
+	assert.Nil(t, code)
+	assert.Equal(t, "wait failed", reason)
+}
+
+func TestProcessManager_SetProtected(t *testing.T) {
+	pm, _ := setupFakeExecutorManager(t)
+	pm.SetMonitoringDisabled(true)
+	store, ok := pm.stateStore.(*mockStateStore)
+	require.True(t, ok)
+
+	proc, err := pm.StartProcess("postgres", []string{"-D", "data"}, StartOptions{Protected: true})
+	require.NoError(t, err)
+	assert.True(t, proc.Protected)
+
+	require.NoError(t, pm.SetProtected(proc.ID, false))
+	assert.False(t, proc.Protected)
+
+	// The flag is persisted with the state
+	saved, ok := store.Calls[len(store.Calls)-1].Arguments.Get(0).(map[string]*ManagedProcess)
+	require.True(t, ok)
+	assert.False(t, saved[proc.ID].Protected)
+
+	require.ErrorIs(t, pm.SetProtected("missing", true), ErrProcessNotFound)
+}
+
+// unprivilegedPortScanner is a mock scanner for a user who cannot bind ports below 1024
+type unprivilegedPortScanner struct {
+	*mockPortScanner
+}
+
+func (unprivilegedPortScanner) NeedsPrivileges(port int) bool {
+	return port > 0 && port < 1024
+}
+
+func TestProcessManager_StartProcess_PrivilegedPort(t *testing.T) {
+	tests := []struct {
+		name        string
+		options     StartOptions
+		expectedErr error
+	}{
+		{name: "privileged_port", options: StartOptions{Port: 80}, expectedErr: ErrPrivilegedPort},
+		{name: "privileged_extra_port", options: StartOptions{Port: 8080, Ports: []int{443}}, expectedErr: ErrPrivilegedPort},
+		{name: "unprivileged_port", options: StartOptions{Port: 8080}},
+		{name: "allowed_privileged_port", options: StartOptions{Port: 80, AllowPrivilegedPort: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pm, executor := setupFakeExecutorManager(t)
+			pm.SetMonitoringDisabled(true)
+			scanner := &mockPortScanner{}
+			scanner.On("IsPortInUse", mock.AnythingOfType("int")).Return(false)
+			pm.portScanner = unprivilegedPortScanner{scanner}
+
+			_, err := pm.StartProcess("server", nil, tt.options)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+				assert.Zero(t, executor.startCount(), "nothing is spawned for a port that cannot be bound")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 1, executor.startCount())
+		})
+	}
+}
+
+func TestProcessManager_StartProcess_WorkingDir(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file.txt")
+	require.NoError(t, os.WriteFile(file, []byte("x"), 0o600))
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	require.NoError(t, os.Mkdir(filepath.Join(home, "project"), 0o755))
+
+	// The current directory is recorded so reuse can tell projects apart
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		workingDir  string
+		expectedDir string
+		expectedErr error
+	}{
+		{name: "existing_directory", workingDir: dir, expectedDir: dir},
+		{name: "home_relative", workingDir: "~/project", expectedDir: filepath.Join(home, "project")},
+		{name: "missing_directory", workingDir: filepath.Join(dir, "missing"), expectedErr: ErrWorkingDirMissing},
+		{name: "not_a_directory", workingDir: file, expectedErr: ErrWorkingDirNotDir},
+		{name: "empty_uses_current_directory", workingDir: "", expectedDir: wd},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pm, executor := setupFakeExecutorManager(t)
+			pm.SetMonitoringDisabled(true)
+
+			proc, err := pm.StartProcess("server", nil, StartOptions{WorkingDir: tt.workingDir})
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+				require.ErrorIs(t, err, ErrStartFailed)
+				assert.Contains(t, err.Error(), tt.workingDir)
+				assert.Zero(t, executor.startCount())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedDir, executor.starts[0].Dir)
+			assert.Equal(t, tt.expectedDir, proc.WorkingDir)
+		})
+	}
+
+	t.Run("cleanup_requires_explicit_directory", func(t *testing.T) {
+		pm, executor := setupFakeExecutorManager(t)
+		pm.SetMonitoringDisabled(true)
+
+		// The current directory would be recorded and could be removed by cleanup
+		_, err := pm.StartProcess("server", nil, StartOptions{CleanupWorkingDir: true})
+		require.ErrorIs(t, err, ErrCleanupNeedsDir)
+		_, _, err = pm.EnsureRunning("server", nil, StartOptions{CleanupWorkingDir: true})
+		require.ErrorIs(t, err, ErrCleanupNeedsDir)
+		assert.Zero(t, executor.startCount())
+
+		proc, err := pm.StartProcess("server", nil, StartOptions{WorkingDir: dir, CleanupWorkingDir: true})
+		require.NoError(t, err)
+		assert.Equal(t, dir, planProcessCleanup(proc).WorkingDir)
+	})
+
+	t.Run("relative_path_is_made_absolute", func(t *testing.T) {
+		t.Chdir(dir)
+		resolved, err := resolveWorkingDir(".")
+		require.NoError(t, err)
+		assert.True(t, filepath.IsAbs(resolved))
+	})
+}
+
+func TestProcessManager_RestartProcess(t *testing.T) {
+	t.Run("replaces_running_process", func(t *testing.T) {
+		pm, executor := setupFakeExecutorManager(t)
+
+		proc, err := pm.StartProcess("server", []string{"--port", "3000"}, StartOptions{})
+		require.NoError(t, err)
+		firstPID := proc.PID
+		pm.setStatus(proc, StatusUnhealthy)
+
+		require.NoError(t, pm.RestartProcess(proc.ID, true))
+
+		assert.Equal(t, []int{firstPID}, executor.killed)
+		require.Equal(t, 2, executor.startCount())
+		assert.Equal(t, executor.starts[0], executor.starts[1], "restarts reuse the original command and options")
+
+		restarted, exists := pm.GetProcess(proc.ID)
+		require.True(t, exists)
+		assert.Same(t, proc, restarted, "the restarted process keeps its ID")
+
+		// The exit of the replaced instance must not mark the new one stopped
+		time.Sleep(50 * time.Millisecond)
+		pm.mutex.RLock()
+		defer pm.mutex.RUnlock()
+		assert.NotEqual(t, firstPID, proc.PID)
+		assert.Equal(t, StatusRunning, proc.Status)
+		assert.Equal(t, 1, proc.RestartCount)
+	})
+
+	t.Run("starts_exited_process", func(t *testing.T) {
+		pm, executor := setupFakeExecutorManager(t)
+		pm.SetMonitoringDisabled(true)
+
+		proc, err := pm.StartProcess("server", nil, StartOptions{})
+		require.NoError(t, err)
+		executor.exitProcess(proc.PID, nil)
+
+		require.NoError(t, pm.RestartProcess(proc.ID, false))
+		assert.Empty(t, executor.killed)
+		assert.Equal(t, 2, executor.startCount())
+	})
+
+	t.Run("keeps_quoted_program_and_arguments", func(t *testing.T) {
+		pm, executor := setupFakeExecutorManager(t)
+		pm.SetMonitoringDisabled(true)
+
+		proc, err := pm.StartProcess(`"/opt/my tools/server"`, nil, StartOptions{})
+		require.NoError(t, err)
+		require.NoError(t, pm.RestartProcess(proc.ID, true))
+
+		withArgs, err := pm.StartProcess("server", []string{"--name", "a b", "a b"}, StartOptions{})
+		require.NoError(t, err)
+		require.NoError(t, pm.RestartProcess(withArgs.ID, true))
+
+		require.Equal(t, 4, executor.startCount())
+		assert.Equal(t, "/opt/my tools/server", executor.starts[1].Command)
+		assert.Empty(t, executor.starts[1].Args)
+		assert.Equal(t, executor.starts[2], executor.starts[3])
+	})
+
+	t.Run("unknown_process", func(t *testing.T) {
+		pm, _ := setupFakeExecutorManager(t)
+		require.ErrorIs(t, pm.RestartProcess("missing", false), ErrProcessNotFound)
+	})
+}
+
+func TestProcessManager_ReferenceCounting(t *testing.T) {
+	// startShared starts a process and reuses it from a second caller
+	startShared := func(t *testing.T, pm *ProcessManager) *ManagedProcess {
+		t.Helper()
+		first, err := pm.StartProcess("server", nil, StartOptions{})
+		require.NoError(t, err)
+		second, err := pm.StartProcess("server", nil, StartOptions{})
+		require.NoError(t, err)
+		require.Same(t, first, second, "the second start reuses the running process")
+		return first
+	}
+
+	t.Run("partial_stop_keeps_process", func(t *testing.T) {
+		pm, executor := setupFakeExecutorManager(t)
+		pm.SetMonitoringDisabled(true)
+
+		proc := startShared(t, pm)
+		assert.Equal(t, 1, executor.startCount())
+		assert.Equal(t, 2, proc.RefCount)
+
+		require.NoError(t, pm.StopProcess(proc.ID, false))
+		assert.Empty(t, executor.killed, "another caller still uses the process")
+		assert.True(t, executor.IsAlive(proc.PID))
+		assert.Equal(t, 1, proc.RefCount)
+		assert.Equal(t, StatusRunning, proc.Status)
+
+		// The last reference stops it; the graceful stop escalates because the fake ignores SIGTERM
+		require.NoError(t, pm.StopProcess(proc.ID, false))
+		assert.Equal(t, []int{proc.PID}, executor.killed)
+		assert.Equal(t, 0, proc.RefCount)
+		assert.Equal(t, StatusStopped, proc.Status)
+	})
+
+	t.Run("force_stop_ignores_references", func(t *testing.T) {
+		pm, executor := setupFakeExecutorManager(t)
+		pm.SetMonitoringDisabled(true)
+
+		proc := startShared(t, pm)
+		require.NoError(t, pm.StopProcess(proc.ID, true))
+		assert.Equal(t, []int{proc.PID}, executor.killed)
+		assert.Equal(t, 0, proc.RefCount)
+	})
+
+	t.Run("concurrent_reuse", func(t *testing.T) {
+		pm, executor := setupFakeExecutorManager(t)
+		pm.SetMonitoringDisabled(true)
+
+		proc, err := pm.StartProcess("server", nil, StartOptions{})
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := pm.StartProcess("server", nil, StartOptions{})
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, 1, executor.startCount())
+		pm.mutex.RLock()
+		defer pm.mutex.RUnlock()
+		assert.Equal(t, 9, proc.RefCount)
+	})
+
+	t.Run("managers_sharing_a_store", func(t *testing.T) {
+		store := &sharedStateStore{}
+		executor := newFakeExecutor()
+		newManager := func() *ProcessManager {
+			pm, _, lockManager, _ := setupTestProcessManager(t)
+			lockManager.On("Lock").Return(nil)
+			lockManager.On("Unlock").Return(nil)
+			pm.stateStore = store
+			pm.SetExecutor(executor)
+			pm.SetMonitoringDisabled(true)
+			return pm
+		}
+
+		// Two invocations load the same state, then one retains the process the other releases
+		first := newManager()
+		proc, err := first.StartProcess("server", nil, StartOptions{})
+		require.NoError(t, err)
+		second := newManager()
+		require.NoError(t, second.ReloadState())
+
+		_, err = first.StartProcess("server", nil, StartOptions{})
+		require.NoError(t, err)
+		require.NoError(t, second.StopProcess(proc.ID, false))
+
+		assert.Empty(t, executor.killed, "the retain from the other invocation is not lost")
+		stored, err := store.Load()
+		require.NoError(t, err)
+		assert.Equal(t, 1, stored[proc.ID].RefCount)
+		assert.Equal(t, StatusRunning, stored[proc.ID].Status)
+	})
+
+	t.Run("legacy_state_counts_as_one", func(t *testing.T) {
+		assert.Equal(t, 1, (&ManagedProcess{}).References())
+	})
+}
+
+// sharedStateStore keeps state in memory so several managers can share it like a state file
+type sharedStateStore struct {
+	mutex     sync.Mutex
+	processes map[string]*ManagedProcess
+}
+
+func (s *sharedStateStore) Save(processes map[string]*ManagedProcess) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.processes = make(map[string]*ManagedProcess, len(processes))
+	for id, process := range processes {
+		s.processes[id] = process.Clone()
+	}
+	return nil
+}
+
+func (s *sharedStateStore) Load() (map[string]*ManagedProcess, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	processes := make(map[string]*ManagedProcess, len(s.processes))
+	for id, process := range s.processes {
+		processes[id] = process.Clone()
+	}
+	return processes, nil
+}
+
+func (s *sharedStateStore) Delete(string) error                   { return nil }
+func (s *sharedStateStore) BackupState() error                    { return nil }
+func (s *sharedStateStore) CleanupOldBackups(time.Duration) error { return nil }
+
+func TestProcessManager_EnsureRunning(t *testing.T) {
+	t.Run("starts_missing_process", func(t *testing.T) {
+		pm, executor := setupFakeExecutorManager(t)
+		pm.SetMonitoringDisabled(true)
+
+		proc, started, err := pm.EnsureRunning("server", []string{"--port", "3000"}, StartOptions{})
+		require.NoError(t, err)
+		assert.True(t, started)
+		assert.Equal(t, "server --port 3000", proc.Command)
+		assert.Equal(t, 1, executor.startCount())
+	})
+
+	t.Run("reuses_healthy_process", func(t *testing.T) {
+		pm, executor := setupFakeExecutorManager(t)
+		pm.SetMonitoringDisabled(true)
+
+		first, started, err := pm.EnsureRunning("server", []string{"--port", "3000"}, StartOptions{})
+		require.NoError(t, err)
+		require.True(t, started)
+
+		second, started, err := pm.EnsureRunning("server", []string{"--port", "3000"}, StartOptions{})
+		require.NoError(t, err)
+		assert.False(t, started)
+		assert.Same(t, first, second)
+		assert.Equal(t, 1, executor.startCount())
+		assert.Equal(t, 1, second.References(), "ensuring is idempotent and adds no reference")
+	})
+
+	t.Run("restarts_unhealthy_process", func(t *testing.T) {
+		pm, executor := setupFakeExecutorManager(t)
+		pm.SetMonitoringDisabled(true)
+
+		failing := &HealthCheck{Type: HealthCheckCommand, Target: "false", Enabled: true, Timeout: time.Second}
+		first, _, err := pm.EnsureRunning("server", nil, StartOptions{HealthCheck: failing})
+		require.NoError(t, err)
+		oldPID := first.PID
+
+		// The graceful stop escalates because the fake ignores SIGTERM
+		proc, started, err := pm.EnsureRunning("server", nil, StartOptions{HealthCheck: failing})
+		require.NoError(t, err)
+		assert.True(t, started)
+		assert.Equal(t, first.ID, proc.ID, "the process is restarted in place")
+		assert.NotEqual(t, oldPID, proc.PID)
+		assert.Equal(t, []int{oldPID}, executor.killed)
+		assert.Equal(t, 2, executor.startCount())
+		assert.Equal(t, 1, proc.RestartCount)
+	})
+
+	t.Run("replaces_dead_process", func(t *testing.T) {
+		pm, executor := setupFakeExecutorManager(t)
+		pm.SetMonitoringDisabled(true)
+
+		first, _, err := pm.EnsureRunning("server", nil, StartOptions{})
+		require.NoError(t, err)
+		executor.exitProcess(first.PID, nil)
+
+		proc, started, err := pm.EnsureRunning("server", nil, StartOptions{})
+		require.NoError(t, err)
+		assert.True(t, started)
+		assert.NotEqual(t, first.ID, proc.ID)
+		assert.Equal(t, StatusStopped, first.Status)
+		assert.Equal(t, 2, executor.startCount())
+	})
+}
+
+func TestProcessManager_FakeExecutor_ReuseIsPerWorkingDir(t *testing.T) {
+	pm, executor := setupFakeExecutorManager(t)
+	pm.SetMonitoringDisabled(true)
+	// The port is free until the first server binds it
+	scanner := pm.portScanner.(*mockPortScanner)
+	scanner.On("IsPortInUse", 3000).Return(false).Once()
+	scanner.On("IsPortInUse", 3000).Return(true)
+	projectA, projectB := t.TempDir(), t.TempDir()
+
+	first, err := pm.StartProcess("npm run dev", nil, StartOptions{Port: 3000, WorkingDir: projectA})
+	require.NoError(t, err)
+	assert.Equal(t, projectA, first.WorkingDir)
+
+	again, err := pm.StartProcess("npm run dev", nil, StartOptions{Port: 3000, WorkingDir: projectA})
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, again.ID, "the same command in the same directory is reused")
+
+	_, err = pm.StartProcess("npm run dev", nil, StartOptions{Port: 3000, WorkingDir: projectB})
+	var conflict *PortConflictError
+	require.ErrorAs(t, err, &conflict, "the same command in another directory serves another app")
+	assert.Equal(t, first.ID, conflict.ProcessID)
+
+	_, _, err = pm.EnsureRunning("npm run dev", nil, StartOptions{Port: 3000, WorkingDir: projectB})
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, 1, executor.startCount())
 }
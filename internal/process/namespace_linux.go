@@ -0,0 +1,23 @@
+package process
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+)
+
+// lookPath finds nsenter; tests replace it
+var lookPath = exec.LookPath
+
+// namespaceCommand wraps a health check command with nsenter so it runs in the network
+// namespace of pid, e.g. inside the container the process runs in
+func namespaceCommand(pid int, command []string) ([]string, error) {
+	if pid <= 0 {
+		return nil, fmt.Errorf("%w: invalid PID %d", ErrNamespace, pid)
+	}
+	nsenter, err := lookPath("nsenter")
+	if err != nil {
+		return nil, fmt.Errorf("%w: nsenter not found (install util-linux): %w", ErrNamespace, err)
+	}
+	return append([]string{nsenter, "--net", "--target", strconv.Itoa(pid), "--"}, command...), nil
+}
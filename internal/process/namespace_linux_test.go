@@ -0,0 +1,113 @@
+package process
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubHealthCommand records the command health checks run and runs replacement instead
+func stubHealthCommand(t *testing.T, replacement ...string) *[]string {
+	t.Helper()
+
+	var invoked []string
+	original := healthCommandContext
+	healthCommandContext = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		invoked = append([]string{name}, args...)
+		return exec.CommandContext(ctx, replacement[0], replacement[1:]...)
+	}
+	t.Cleanup(func() { healthCommandContext = original })
+	return &invoked
+}
+
+// stubNsenter makes nsenter resolve to path, or fail to resolve when path is empty
+func stubNsenter(t *testing.T, path string) {
+	t.Helper()
+
+	original := lookPath
+	lookPath = func(string) (string, error) {
+		if path == "" {
+			return "", exec.ErrNotFound
+		}
+		return path, nil
+	}
+	t.Cleanup(func() { lookPath = original })
+}
+
+func namespaceCheckProcess(pid int, inNamespace bool) *ManagedProcess {
+	return &ManagedProcess{
+		ID:     "container",
+		PID:    pid,
+		Status: StatusRunning,
+		HealthCheck: &HealthCheck{
+			Type:        HealthCheckCommand,
+			Target:      "curl -fs http://localhost:8080/health",
+			Enabled:     true,
+			Timeout:     time.Second,
+			InNamespace: inNamespace,
+		},
+	}
+}
+
+func TestPerformCommandHealthCheck_InNamespace(t *testing.T) {
+	pm, _, _, _ := setupTestProcessManager(t)
+
+	t.Run("wraps_command_with_nsenter", func(t *testing.T) {
+		stubNsenter(t, "/usr/bin/nsenter")
+		invoked := stubHealthCommand(t, "true")
+
+		require.NoError(t, pm.performCommandHealthCheck(context.Background(), namespaceCheckProcess(4242, true)))
+		assert.Equal(t, []string{
+			"/usr/bin/nsenter", "--net", "--target", "4242", "--",
+			"curl", "-fs", "http://localhost:8080/health",
+		}, *invoked)
+	})
+
+	t.Run("host_namespace_by_default", func(t *testing.T) {
+		invoked := stubHealthCommand(t, "true")
+
+		require.NoError(t, pm.performCommandHealthCheck(context.Background(), namespaceCheckProcess(4242, false)))
+		assert.Equal(t, []string{"curl", "-fs", "http://localhost:8080/health"}, *invoked)
+	})
+
+	t.Run("nsenter_missing", func(t *testing.T) {
+		stubNsenter(t, "")
+		invoked := stubHealthCommand(t, "true")
+
+		err := pm.performCommandHealthCheck(context.Background(), namespaceCheckProcess(4242, true))
+		require.ErrorIs(t, err, ErrNamespace)
+		require.ErrorIs(t, err, exec.ErrNotFound)
+		assert.Empty(t, *invoked, "nothing runs without nsenter")
+	})
+
+	t.Run("invalid_pid", func(t *testing.T) {
+		stubNsenter(t, "/usr/bin/nsenter")
+		stubHealthCommand(t, "true")
+
+		err := pm.performCommandHealthCheck(context.Background(), namespaceCheckProcess(0, true))
+		require.ErrorIs(t, err, ErrNamespace)
+	})
+
+	t.Run("missing_privileges", func(t *testing.T) {
+		stubNsenter(t, "/usr/bin/nsenter")
+		stubHealthCommand(t, "sh", "-c", "echo 'nsenter: reassociate to namespace ns/net failed: Operation not permitted' >&2; exit 1")
+
+		err := pm.performCommandHealthCheck(context.Background(), namespaceCheckProcess(4242, true))
+		require.ErrorIs(t, err, ErrNamespace)
+		assert.Contains(t, err.Error(), "CAP_SYS_ADMIN")
+	})
+
+	t.Run("failing_check", func(t *testing.T) {
+		stubNsenter(t, "/usr/bin/nsenter")
+		stubHealthCommand(t, "false")
+
+		err := pm.performCommandHealthCheck(context.Background(), namespaceCheckProcess(4242, true))
+		require.Error(t, err)
+		assert.False(t, errors.Is(err, ErrNamespace), "a failing check is not a namespace problem")
+	})
+}
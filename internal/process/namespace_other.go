@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package process
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// namespaceCommand reports that network namespaces are unavailable on this platform
+func namespaceCommand(_ int, _ []string) ([]string, error) {
+	return nil, fmt.Errorf("%w: network namespaces are not supported on %s", ErrNamespace, runtime.GOOS)
+}
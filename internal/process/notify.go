@@ -0,0 +1,104 @@
+package process
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrWebhookStatus is returned when a webhook endpoint rejects a notification
+var ErrWebhookStatus = errors.New("webhook returned non-success status")
+
+// DefaultNotifyTimeout bounds how long a single notification may take
+const DefaultNotifyTimeout = 5 * time.Second
+
+// EventKind classifies a status transition worth notifying about
+type EventKind string
+
+// Event kind constants
+const (
+	EventUnhealthy EventKind = "unhealthy" // A running process started failing health checks
+	EventStopped   EventKind = "stopped"   // The process exited
+	EventFailed    EventKind = "failed"    // The process crashed or ran out of restarts
+	EventRecovered EventKind = "recovered" // An unhealthy process passes health checks again
+)
+
+// Event describes a status transition of a managed process
+type Event struct {
+	Kind      EventKind     `json:"kind"`
+	ProcessID string        `json:"process_id"`
+	Command   string        `json:"command"`
+	PID       int           `json:"pid"`
+	Port      int           `json:"port,omitempty"`
+	From      ProcessStatus `json:"from"`
+	To        ProcessStatus `json:"to"`
+	Time      time.Time     `json:"time"`
+}
+
+// Notifier delivers status transition events, e.g. to chat or alerting systems
+type Notifier interface {
+	Notify(ctx context.Context, event Event) error
+}
+
+// transitionEvent returns the event kind for a status change, or false if it is not notable
+func transitionEvent(from, to ProcessStatus) (EventKind, bool) {
+	if from == to {
+		return "", false
+	}
+	switch to {
+	case StatusUnhealthy:
+		return EventUnhealthy, true
+	case StatusStopped:
+		return EventStopped, true
+	case StatusFailed:
+		return EventFailed, true
+	case StatusRunning:
+		if from == StatusUnhealthy {
+			return EventRecovered, true
+		}
+	}
+	return "", false
+}
+
+// WebhookNotifier posts events as JSON to a URL
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewWebhookNotifier creates a notifier posting to url
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		URL:    url,
+		Client: &http.Client{},
+	}
+}
+
+// Notify posts the event and fails on non-2xx responses
+func (w *WebhookNotifier) Notify(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }() //nolint:errcheck // Response body is not read
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%w: %d", ErrWebhookStatus, resp.StatusCode)
+	}
+	return nil
+}
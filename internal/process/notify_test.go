@@ -0,0 +1,146 @@
+package process
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingNotifier forwards events to a channel
+type recordingNotifier struct {
+	events chan Event
+	block  bool // Wait for the context to expire before returning
+}
+
+func (r *recordingNotifier) Notify(ctx context.Context, event Event) error {
+	if r.block {
+		<-ctx.Done()
+	}
+	r.events <- event
+	return ctx.Err()
+}
+
+func TestTransitionEvent(t *testing.T) {
+	tests := []struct {
+		from    ProcessStatus
+		to      ProcessStatus
+		want    EventKind
+		notable bool
+	}{
+		{from: StatusRunning, to: StatusUnhealthy, want: EventUnhealthy, notable: true},
+		{from: StatusRunning, to: StatusStopped, want: EventStopped, notable: true},
+		{from: StatusUnhealthy, to: StatusFailed, want: EventFailed, notable: true},
+		{from: StatusUnhealthy, to: StatusRunning, want: EventRecovered, notable: true},
+		{from: StatusPending, to: StatusRunning},
+		{from: StatusUnhealthy, to: StatusUnhealthy},
+		{from: StatusRunning, to: StatusRunning},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"_to_"+string(tt.to), func(t *testing.T) {
+			kind, notable := transitionEvent(tt.from, tt.to)
+			assert.Equal(t, tt.notable, notable)
+			assert.Equal(t, tt.want, kind)
+		})
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	t.Run("posts_json_event", func(t *testing.T) {
+		received := make(chan Event, 1)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			var event Event
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+			received <- event
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		event := Event{Kind: EventUnhealthy, ProcessID: "web", Command: "npm run dev", PID: 42, Port: 3000,
+			From: StatusRunning, To: StatusUnhealthy, Time: time.Now().UTC().Truncate(time.Second)}
+		require.NoError(t, NewWebhookNotifier(server.URL).Notify(context.Background(), event))
+		assert.Equal(t, event, <-received)
+	})
+
+	t.Run("non_success_status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		err := NewWebhookNotifier(server.URL).Notify(context.Background(), Event{Kind: EventStopped})
+		require.ErrorIs(t, err, ErrWebhookStatus)
+	})
+}
+
+func TestProcessManager_NotifiesTransitions(t *testing.T) {
+	pm, mockStateStore, _, _ := setupTestProcessManager(t)
+	mockStateStore.On("Save", mock.AnythingOfType("map[string]*process.ManagedProcess")).Return(nil)
+
+	notifier := &recordingNotifier{events: make(chan Event, 4)}
+	pm.SetNotifier(notifier, time.Second)
+
+	proc := createTestProcess("web", "npm run dev", 3000, StatusRunning)
+	pm.processes[proc.ID] = proc
+
+	receive := func() Event {
+		t.Helper()
+		select {
+		case event := <-notifier.events:
+			return event
+		case <-time.After(2 * time.Second):
+			t.Fatal("expected a notification")
+			return Event{}
+		}
+	}
+
+	pm.setStatus(proc, StatusUnhealthy)
+	event := receive()
+	assert.Equal(t, EventUnhealthy, event.Kind)
+	assert.Equal(t, "web", event.ProcessID)
+	assert.Equal(t, 3000, event.Port)
+	assert.Equal(t, StatusRunning, event.From)
+
+	pm.setStatus(proc, StatusRunning)
+	assert.Equal(t, EventRecovered, receive().Kind)
+
+	// Repeating a status is not a transition
+	pm.setStatus(proc, StatusRunning)
+	select {
+	case event := <-notifier.events:
+		t.Fatalf("unexpected notification: %+v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestProcessManager_NotifyDoesNotBlockMonitoring(t *testing.T) {
+	pm, mockStateStore, _, _ := setupTestProcessManager(t)
+	mockStateStore.On("Save", mock.AnythingOfType("map[string]*process.ManagedProcess")).Return(nil)
+
+	notifier := &recordingNotifier{events: make(chan Event, 1), block: true}
+	pm.SetNotifier(notifier, 200*time.Millisecond)
+
+	proc := createTestProcess("web", "npm run dev", 3000, StatusRunning)
+	pm.processes[proc.ID] = proc
+
+	start := time.Now()
+	pm.setStatus(proc, StatusStopped)
+	assert.Less(t, time.Since(start), 100*time.Millisecond, "setStatus must not wait for the notifier")
+
+	// The notification is cut off by its timeout
+	select {
+	case event := <-notifier.events:
+		assert.Equal(t, EventStopped, event.Kind)
+	case <-time.After(2 * time.Second):
+		t.Fatal("notification did not time out")
+	}
+}
@@ -0,0 +1,27 @@
+//go:build !windows
+// +build !windows
+
+package process
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// trackProcessTree is a no-op on Unix: setSysProcAttr makes the child lead its own process group
+func trackProcessTree(int) {}
+
+// killProcess forcibly terminates the process, or its whole process group when group is set
+func killProcess(pid int, group bool) error {
+	// Fall back to the single process if it no longer leads a group
+	if group && syscall.Kill(-pid, syscall.SIGKILL) == nil {
+		return nil
+	}
+
+	osProcess, err := os.FindProcess(pid)
+	if err != nil {
+		return fmt.Errorf("failed to find process %d: %w", pid, err)
+	}
+	return osProcess.Kill()
+}
@@ -0,0 +1,78 @@
+//go:build windows
+// +build windows
+
+package process
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// jobs holds the job object of each process started by this portguard invocation
+var (
+	jobsMutex sync.Mutex
+	jobs      = make(map[int]windows.Handle)
+)
+
+// trackProcessTree puts the process into a job object so its descendants can be killed with it.
+// Failures are ignored; killProcess then falls back to taskkill.
+func trackProcessTree(pid int) {
+	job, err := windows.CreateJobObject(nil, nil)
+	if err != nil {
+		return
+	}
+	handle, err := windows.OpenProcess(windows.PROCESS_SET_QUOTA|windows.PROCESS_TERMINATE, false, uint32(pid)) //nolint:gosec // PIDs fit in uint32
+	if err != nil {
+		_ = windows.CloseHandle(job) //nolint:errcheck // Best effort cleanup
+		return
+	}
+	defer windows.CloseHandle(handle) //nolint:errcheck // Best effort cleanup
+
+	if err := windows.AssignProcessToJobObject(job, handle); err != nil {
+		_ = windows.CloseHandle(job) //nolint:errcheck // Best effort cleanup
+		return
+	}
+
+	jobsMutex.Lock()
+	jobs[pid] = job
+	jobsMutex.Unlock()
+}
+
+// killProcess forcibly terminates the process, or its whole process tree when group is set.
+// The tree is the process's job object when this invocation started it, and otherwise
+// whatever taskkill /T finds through parent PIDs.
+func killProcess(pid int, group bool) error {
+	if group {
+		jobsMutex.Lock()
+		job, tracked := jobs[pid]
+		delete(jobs, pid)
+		jobsMutex.Unlock()
+
+		if tracked {
+			err := windows.TerminateJobObject(job, 1)
+			_ = windows.CloseHandle(job) //nolint:errcheck // Best effort cleanup
+			if err == nil {
+				return nil
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if exec.CommandContext(ctx, "taskkill", "/T", "/F", "/PID", strconv.Itoa(pid)).Run() == nil {
+			return nil
+		}
+	}
+
+	osProcess, err := os.FindProcess(pid)
+	if err != nil {
+		return fmt.Errorf("failed to find process %d: %w", pid, err)
+	}
+	return osProcess.Kill()
+}
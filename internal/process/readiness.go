@@ -0,0 +1,75 @@
+package process
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"time"
+)
+
+// readyLogPollInterval is how often the log file is re-read while waiting for the ready pattern
+const readyLogPollInterval = 100 * time.Millisecond
+
+// waitForLogPattern follows the process log file until a line written since the process started
+// matches pattern, the process exits, or the timeout elapses. On timeout the process is left
+// running with StatusUnhealthy.
+func (pm *ProcessManager) waitForLogPattern(process *ManagedProcess, pattern *regexp.Regexp, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = defaultWaitTimeout
+	}
+
+	pm.mutex.RLock()
+	logPath, pid := process.LogFile, process.PID
+	var offset int64
+	if process.runtime != nil {
+		offset = process.runtime.logOffset
+	}
+	pm.mutex.RUnlock()
+
+	file, err := os.Open(logPath)
+	if err != nil {
+		return fmt.Errorf("failed to open log file %s: %w", logPath, err)
+	}
+	defer func() { _ = file.Close() }() //nolint:errcheck // Read-only file
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek log file %s: %w", logPath, err)
+	}
+
+	executor := pm.processExecutor()
+	deadline := time.Now().Add(timeout)
+	reader := bufio.NewReader(file)
+	line := ""
+	exited := false
+	for {
+		chunk, readErr := reader.ReadString('\n')
+		line += chunk
+		// Partial lines are matched too, so prompts without a trailing newline are seen
+		if line != "" && pattern.MatchString(line) {
+			return nil
+		}
+		if readErr == nil {
+			line = ""
+			continue
+		}
+		if !errors.Is(readErr, io.EOF) {
+			return fmt.Errorf("failed to read log file %s: %w", logPath, readErr)
+		}
+
+		// Everything the process wrote before exiting has been read
+		if exited {
+			return fmt.Errorf("%w: process %s", ErrExitedBeforeReady, process.ID)
+		}
+		if time.Now().After(deadline) {
+			pm.setStatus(process, StatusUnhealthy, activeStatuses...)
+			return fmt.Errorf("%w: process %s after %v", ErrReadyLogTimeout, process.ID, timeout)
+		}
+		if !executor.IsAlive(pid) {
+			exited = true // Read once more before giving up
+			continue
+		}
+		time.Sleep(readyLogPollInterval)
+	}
+}
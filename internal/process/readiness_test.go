@@ -0,0 +1,101 @@
+package process
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// appendLog appends text to a log file as a running process would. It may be called from
+// other goroutines, so failures are reported without stopping the test.
+func appendLog(t *testing.T, path, text string) {
+	t.Helper()
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = file.WriteString(text)
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+}
+
+func TestProcessManager_StartProcess_ReadyLogPattern(t *testing.T) {
+	t.Run("ready_when_log_matches", func(t *testing.T) {
+		pm, _ := setupFakeExecutorManager(t)
+		pm.SetMonitoringDisabled(true)
+		logFile := filepath.Join(t.TempDir(), "server.log")
+		// Output of an earlier run must not count
+		appendLog(t, logFile, "Server listening on 3000\n")
+
+		go func() {
+			time.Sleep(150 * time.Millisecond)
+			appendLog(t, logFile, "compiling...\nServer listen")
+			time.Sleep(150 * time.Millisecond)
+			appendLog(t, logFile, "ing on 3000\n")
+		}()
+
+		start := time.Now()
+		proc, err := pm.StartProcess("server", nil, StartOptions{
+			LogFile:         logFile,
+			ReadyLogPattern: `Server listening on \d+`,
+			WaitTimeout:     3 * time.Second,
+		})
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), 300*time.Millisecond, "start must wait for the new output")
+		assert.Equal(t, StatusRunning, proc.Status)
+	})
+
+	t.Run("timeout_marks_unhealthy", func(t *testing.T) {
+		pm, _ := setupFakeExecutorManager(t)
+		pm.SetMonitoringDisabled(true)
+		logFile := filepath.Join(t.TempDir(), "server.log")
+
+		_, err := pm.StartProcess("server", nil, StartOptions{
+			LogFile:         logFile,
+			ReadyLogPattern: "ready",
+			WaitTimeout:     200 * time.Millisecond,
+		})
+		require.ErrorIs(t, err, ErrReadyLogTimeout)
+
+		processes := pm.ListProcesses(ProcessListOptions{IncludeStopped: true})
+		require.Len(t, processes, 1)
+		assert.Equal(t, StatusUnhealthy, processes[0].Status)
+	})
+
+	t.Run("exit_before_ready", func(t *testing.T) {
+		pm, executor := setupFakeExecutorManager(t)
+		pm.SetMonitoringDisabled(true)
+		logFile := filepath.Join(t.TempDir(), "server.log")
+
+		go func() {
+			assert.Eventually(t, func() bool { return executor.startCount() == 1 }, time.Second, 10*time.Millisecond)
+			appendLog(t, logFile, "fatal: address in use\n")
+			executor.exitProcess(executor.nextPID, nil)
+		}()
+
+		_, err := pm.StartProcess("server", nil, StartOptions{
+			LogFile:         logFile,
+			ReadyLogPattern: "ready",
+			WaitTimeout:     3 * time.Second,
+		})
+		require.ErrorIs(t, err, ErrExitedBeforeReady)
+	})
+
+	t.Run("invalid_options", func(t *testing.T) {
+		pm, executor := setupFakeExecutorManager(t)
+
+		_, err := pm.StartProcess("server", nil, StartOptions{ReadyLogPattern: "ready"})
+		require.ErrorIs(t, err, ErrInvalidReadyLog, "a log file is required")
+
+		_, err = pm.StartProcess("server", nil, StartOptions{
+			LogFile:         filepath.Join(t.TempDir(), "server.log"),
+			ReadyLogPattern: "ready (",
+		})
+		require.ErrorIs(t, err, ErrInvalidReadyLog)
+		assert.Zero(t, executor.startCount(), "nothing is started for invalid options")
+	})
+}
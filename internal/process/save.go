@@ -0,0 +1,149 @@
+package process
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// SetSaveInterval batches the state saves caused by status changes, such as those made by
+// background monitors: changes are coalesced and written at most once per interval. Starts,
+// stops and other explicit operations still save right away, including any batched changes.
+// Batched changes are merged into the stored state under the state lock, so changes made
+// by other invocations meanwhile are kept. Zero saves every change immediately. Call Flush
+// before exiting so no change is lost.
+func (pm *ProcessManager) SetSaveInterval(interval time.Duration) {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+	pm.saveInterval = max(interval, 0)
+}
+
+// snapshotLocked deep-copies the processes for the state store, so marshaling never reads fields
+// that monitors change concurrently. Callers hold pm.mutex.
+func (pm *ProcessManager) snapshotLocked() map[string]*ManagedProcess {
+	snapshot := make(map[string]*ManagedProcess, len(pm.processes))
+	for id, process := range pm.processes {
+		snapshot[id] = process.Clone()
+	}
+	return snapshot
+}
+
+// saveStatusLocked persists a status change of process, immediately or batched.
+// Callers hold pm.mutex.
+func (pm *ProcessManager) saveStatusLocked(process *ManagedProcess) error {
+	if pm.saveInterval <= 0 {
+		// Create a copy of the processes map for safe concurrent access to stateStore
+		processesCopy := pm.snapshotLocked()
+		if err := pm.stateStore.Save(processesCopy); err != nil {
+			return fmt.Errorf("failed to save process state: %w", err)
+		}
+		return nil
+	}
+
+	if pm.pendingSaves == nil {
+		pm.pendingSaves = make(map[string]bool)
+	}
+	pm.pendingSaves[process.ID] = true
+	if pm.saveTimer == nil {
+		pm.saveTimer = time.AfterFunc(pm.saveInterval, func() {
+			if err := pm.Flush(); err != nil {
+				pm.log().Warn("failed to save batched process state", "error", err)
+			}
+		})
+	}
+	return nil
+}
+
+// Flush writes batched status changes now under the state lock. It does nothing when no
+// change is pending. A failed save stays pending and is retried by the next Flush.
+func (pm *ProcessManager) Flush() error {
+	pm.mutex.RLock()
+	pending := len(pm.pendingSaves) > 0
+	pm.mutex.RUnlock()
+	if !pending {
+		return nil
+	}
+
+	if err := pm.lockState(); err != nil {
+		return err
+	}
+	defer pm.unlockState()
+
+	return pm.flushLocked()
+}
+
+// flushLocked writes batched status changes into the latest stored state, as other
+// invocations may have changed it since this manager loaded it. Only the processes whose
+// status this manager changed are updated; entries removed meanwhile are not brought back.
+// Callers hold the state lock.
+func (pm *ProcessManager) flushLocked() error {
+	pm.saveMutex.Lock()
+	defer pm.saveMutex.Unlock()
+
+	pm.mutex.Lock()
+	if pm.saveTimer != nil {
+		pm.saveTimer.Stop()
+		pm.saveTimer = nil
+	}
+	if len(pm.pendingSaves) == 0 {
+		pm.mutex.Unlock()
+		return nil
+	}
+	changed := make(map[string]*ManagedProcess, len(pm.pendingSaves))
+	for id := range pm.pendingSaves {
+		if process, exists := pm.processes[id]; exists {
+			changed[id] = process.Clone()
+		}
+	}
+	pm.pendingSaves = nil
+	pm.mutex.Unlock()
+
+	stored, err := pm.stateStore.Load()
+	if err == nil || errors.Is(err, os.ErrNotExist) {
+		if stored == nil {
+			stored = make(map[string]*ManagedProcess)
+		}
+		merged := false
+		for id, process := range changed {
+			if current, exists := stored[id]; exists && mergeStatus(current, process) {
+				merged = true
+			}
+		}
+		if merged {
+			err = pm.stateStore.Save(stored)
+		}
+	}
+	if err != nil {
+		pm.mutex.Lock()
+		if pm.pendingSaves == nil {
+			pm.pendingSaves = make(map[string]bool)
+		}
+		for id := range changed {
+			pm.pendingSaves[id] = true
+		}
+		pm.mutex.Unlock()
+		return fmt.Errorf("failed to save process state: %w", err)
+	}
+	return nil
+}
+
+// mergeStatus copies the fields that monitors and crash restarts change from process into
+// its stored entry, unless another invocation updated the entry more recently. It reports
+// whether the entry was updated.
+func mergeStatus(stored, process *ManagedProcess) bool {
+	if stored.UpdatedAt.After(process.UpdatedAt) {
+		return false
+	}
+	stored.PID = process.PID
+	stored.Status = process.Status
+	stored.StartedAt = process.StartedAt
+	stored.UpdatedAt = process.UpdatedAt
+	stored.LastSeen = process.LastSeen
+	stored.LastHealthCheck = process.LastHealthCheck
+	stored.RestartCount = process.RestartCount
+	stored.CrashRestarts = process.CrashRestarts
+	stored.ExitCode = process.ExitCode
+	stored.ExitReason = process.ExitReason
+	return true
+}
@@ -0,0 +1,334 @@
+package process
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/paveg/portguard/internal/lock"
+)
+
+// setupSaveCountingManager returns a manager with one running process and a counter of Save calls
+func setupSaveCountingManager(t *testing.T, saveErr error) (*ProcessManager, *ManagedProcess, *atomic.Int32, *atomic.Value) {
+	t.Helper()
+
+	pm, mockStateStore, mockLockManager, _ := setupTestProcessManager(t)
+	mockLockManager.On("Lock").Return(nil)
+	mockLockManager.On("Unlock").Return(nil)
+	var saves atomic.Int32
+	var lastStatus atomic.Value
+	record := func(args mock.Arguments) {
+		saves.Add(1)
+		processes := args.Get(0).(map[string]*ManagedProcess)
+		lastStatus.Store(processes["proc"].Status)
+	}
+	if saveErr != nil {
+		mockStateStore.On("Save", mock.AnythingOfType("map[string]*process.ManagedProcess")).Return(saveErr).Run(record).Once()
+	}
+	mockStateStore.On("Save", mock.AnythingOfType("map[string]*process.ManagedProcess")).Return(nil).Run(record)
+
+	proc := createTestProcess("proc", "npm run dev", 3000, StatusRunning)
+	pm.processes[proc.ID] = proc
+	mockStateStore.On("Load").Return(map[string]*ManagedProcess{proc.ID: proc.Clone()}, nil)
+	return pm, proc, &saves, &lastStatus
+}
+
+// churn flips the status of proc as a flapping health check would
+func churn(t *testing.T, pm *ProcessManager, proc *ManagedProcess, updates int) {
+	t.Helper()
+	for i := 0; i < updates; i++ {
+		status := StatusUnhealthy
+		if i%2 == 1 {
+			status = StatusRunning
+		}
+		require.NoError(t, pm.updateProcessStatus(proc.ID, status))
+	}
+}
+
+func TestProcessManager_SaveBatching(t *testing.T) {
+	t.Run("immediate_by_default", func(t *testing.T) {
+		pm, proc, saves, _ := setupSaveCountingManager(t, nil)
+
+		churn(t, pm, proc, 100)
+		assert.Equal(t, int32(100), saves.Load())
+		require.NoError(t, pm.Flush())
+		assert.Equal(t, int32(100), saves.Load(), "nothing is pending without batching")
+	})
+
+	t.Run("rapid_updates_are_coalesced", func(t *testing.T) {
+		pm, proc, saves, lastStatus := setupSaveCountingManager(t, nil)
+		pm.SetSaveInterval(50 * time.Millisecond)
+
+		churn(t, pm, proc, 100)
+		churn(t, pm, proc, 1)
+
+		assert.Eventually(t, func() bool { return saves.Load() >= 1 }, 2*time.Second, 5*time.Millisecond)
+		time.Sleep(100 * time.Millisecond)
+		assert.Equal(t, int32(1), saves.Load(), "101 updates within the window are written once")
+		assert.Equal(t, StatusUnhealthy, lastStatus.Load(), "the latest status is written")
+	})
+
+	t.Run("flush_writes_pending_changes", func(t *testing.T) {
+		pm, proc, saves, lastStatus := setupSaveCountingManager(t, nil)
+		pm.SetSaveInterval(time.Hour)
+
+		churn(t, pm, proc, 100)
+		assert.Equal(t, int32(0), saves.Load())
+
+		require.NoError(t, pm.Flush())
+		assert.Equal(t, int32(1), saves.Load())
+		assert.Equal(t, StatusRunning, lastStatus.Load())
+
+		require.NoError(t, pm.Flush())
+		assert.Equal(t, int32(1), saves.Load(), "a second flush has nothing to write")
+	})
+
+	t.Run("failed_flush_stays_pending", func(t *testing.T) {
+		pm, proc, saves, _ := setupSaveCountingManager(t, errors.New("disk full"))
+		pm.SetSaveInterval(time.Hour)
+
+		churn(t, pm, proc, 10)
+		require.Error(t, pm.Flush())
+		require.NoError(t, pm.Flush(), "the change is retried rather than lost")
+		assert.Equal(t, int32(2), saves.Load())
+	})
+
+	t.Run("flush_merges_into_stored_state", func(t *testing.T) {
+		pm, store, lockManager, _ := setupTestProcessManager(t)
+		lockManager.On("Lock").Return(nil)
+		lockManager.On("Unlock").Return(nil)
+		pm.SetSaveInterval(time.Hour)
+
+		proc := createTestProcess("proc", "npm run dev", 3000, StatusRunning)
+		removed := createTestProcess("removed", "npm run api", 3001, StatusRunning)
+		newer := createTestProcess("newer", "npm run docs", 3002, StatusRunning)
+		for _, p := range []*ManagedProcess{proc, removed, newer} {
+			pm.processes[p.ID] = p
+		}
+
+		// Meanwhile another invocation started a process, removed one and stopped one
+		started := createTestProcess("started", "npm run web", 3003, StatusRunning)
+		stoppedNewer := newer.Clone()
+		stoppedNewer.Status = StatusStopped
+		stoppedNewer.UpdatedAt = time.Now().Add(time.Hour)
+		store.On("Load").Return(map[string]*ManagedProcess{
+			proc.ID:    proc.Clone(),
+			started.ID: started,
+			newer.ID:   stoppedNewer,
+		}, nil)
+		var saved map[string]*ManagedProcess
+		store.On("Save", mock.AnythingOfType("map[string]*process.ManagedProcess")).Return(nil).Run(func(args mock.Arguments) {
+			saved = args.Get(0).(map[string]*ManagedProcess)
+		}).Once()
+
+		require.NoError(t, pm.updateProcessStatus(proc.ID, StatusUnhealthy))
+		require.NoError(t, pm.updateProcessStatus(removed.ID, StatusUnhealthy))
+		require.NoError(t, pm.updateProcessStatus(newer.ID, StatusUnhealthy))
+		require.NoError(t, pm.Flush())
+
+		lockManager.AssertCalled(t, "Lock")
+		require.Len(t, saved, 3)
+		assert.Equal(t, StatusUnhealthy, saved[proc.ID].Status, "this manager's change is written")
+		assert.Contains(t, saved, started.ID, "processes started elsewhere are kept")
+		assert.NotContains(t, saved, removed.ID, "removed processes do not come back")
+		assert.Equal(t, StatusStopped, saved[newer.ID].Status, "a more recent change elsewhere wins")
+	})
+
+	t.Run("flush_waits_for_the_state_lock", func(t *testing.T) {
+		pm, proc, saves, _ := setupSaveCountingManager(t, nil)
+		fileLock := lock.NewFileLock(filepath.Join(t.TempDir(), "portguard.lock"), 5*time.Second)
+		pm.lockManager = fileLock
+		pm.SetSaveInterval(time.Hour)
+		churn(t, pm, proc, 1)
+
+		// The flush runs in another goroutine, like the batching timer's
+		require.NoError(t, pm.lockState())
+		flushed := make(chan error, 1)
+		go func() { flushed <- pm.Flush() }()
+		select {
+		case err := <-flushed:
+			t.Fatalf("flush ran under a lock held by another operation: %v", err)
+		case <-time.After(100 * time.Millisecond):
+		}
+		assert.True(t, fileLock.IsLocked())
+		assert.Equal(t, int32(0), saves.Load())
+
+		pm.unlockState()
+		require.NoError(t, <-flushed)
+		assert.Equal(t, int32(1), saves.Load())
+	})
+
+	t.Run("crash_restarts_are_batched", func(t *testing.T) {
+		pm, store, lockManager, _ := setupTestProcessManager(t)
+		lockManager.On("Lock").Return(nil)
+		lockManager.On("Unlock").Return(nil)
+		var saves atomic.Int32
+		store.On("Save", mock.AnythingOfType("map[string]*process.ManagedProcess")).Return(nil).Run(func(mock.Arguments) {
+			saves.Add(1)
+		})
+		executor := newFakeExecutor()
+		pm.SetExecutor(executor)
+		pm.SetSaveInterval(time.Hour)
+
+		proc, err := pm.StartProcess("server", nil, StartOptions{RestartPolicy: RestartAlways})
+		require.NoError(t, err)
+		require.Equal(t, int32(1), saves.Load(), "starts save right away")
+		pm.mutex.RLock()
+		stored := map[string]*ManagedProcess{proc.ID: proc.Clone()}
+		pid := proc.PID
+		pm.mutex.RUnlock()
+		store.On("Load").Return(stored, nil)
+
+		executor.exitProcess(pid, errors.New("exit status 1"))
+		require.Eventually(t, func() bool {
+			pm.mutex.RLock()
+			defer pm.mutex.RUnlock()
+			return proc.RestartCount == 1
+		}, 2*time.Second, 10*time.Millisecond)
+		assert.Equal(t, int32(1), saves.Load(), "the restart is batched")
+
+		require.NoError(t, pm.Flush())
+		assert.Equal(t, int32(2), saves.Load())
+		pm.mutex.RLock()
+		defer pm.mutex.RUnlock()
+		assert.Equal(t, proc.PID, stored[proc.ID].PID)
+		assert.Equal(t, 1, stored[proc.ID].RestartCount)
+	})
+}
+
+// marshalingStateStore serializes every snapshot like the JSON state store does
+type marshalingStateStore struct {
+	mockStateStore
+}
+
+func (m *marshalingStateStore) Save(processes map[string]*ManagedProcess) error {
+	_, err := json.Marshal(processes)
+	return err
+}
+
+// TestProcessManager_SaveSnapshotIsStable mutates a process the way monitors do while saves
+// marshal the state; run with -race to catch snapshots sharing memory with live processes.
+func TestProcessManager_SaveSnapshotIsStable(t *testing.T) {
+	pm, _, mockLockManager, _ := setupTestProcessManager(t)
+	mockLockManager.On("Lock").Return(nil)
+	mockLockManager.On("Unlock").Return(nil)
+	pm.stateStore = &marshalingStateStore{}
+	proc := createTestProcess("proc", "npm run dev", 3000, StatusRunning)
+	proc.Environment = map[string]string{"NODE_ENV": "development"}
+	pm.processes[proc.ID] = proc
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			pm.mutex.Lock()
+			proc.Status = StatusUnhealthy
+			proc.LastSeen = time.Now()
+			proc.Ports = append(proc.Ports[:0], 3000, 3001+i)
+			proc.Environment["ATTEMPT"] = strconv.Itoa(i)
+			pm.mutex.Unlock()
+		}
+	}()
+
+	for i := 0; i < 200; i++ {
+		require.NoError(t, pm.SetProtected(proc.ID, i%2 == 0))
+	}
+	<-done
+}
+
+func TestProcessManager_StatsAndReloadState(t *testing.T) {
+	pm, mockStateStore, _, _ := setupTestProcessManager(t)
+	executor := newFakeExecutor()
+	pm.SetExecutor(executor)
+	pm.SetMonitoringDisabled(true)
+
+	started := &ManagedProcess{ID: "web-a1b2c3", Status: StatusRunning, RestartCount: 2, runtime: &processRuntime{}}
+	stale := &ManagedProcess{ID: "old-d4e5f6", Status: StatusRunning}
+	pm.processes[started.ID] = started
+	pm.processes[stale.ID] = stale
+
+	stored := map[string]*ManagedProcess{
+		"api-112233": {ID: "api-112233", Status: StatusFailed, Port: 8080},
+	}
+	mockStateStore.On("Load").Return(stored, nil).Once()
+	require.NoError(t, pm.ReloadState())
+
+	stats := pm.Stats()
+	assert.Equal(t, map[ProcessStatus]int{StatusRunning: 1, StatusFailed: 1}, stats.ProcessesByStatus,
+		"processes started by this manager are kept, other entries come from the store")
+	assert.Equal(t, map[string]int{"web-a1b2c3": 2, "api-112233": 0}, stats.RestartsByProcess)
+	assert.Zero(t, stats.HealthCheckFailures)
+
+	// A missing state file means no stored processes
+	mockStateStore.On("Load").Return(nil, os.ErrNotExist).Once()
+	require.NoError(t, pm.ReloadState())
+	assert.Len(t, pm.Stats().RestartsByProcess, 1)
+
+	// Failed health checks are counted
+	started.PID = 4242 // Not a process the fake executor knows
+	started.HealthCheck = &HealthCheck{Type: HealthCheckProcess, Enabled: true}
+	require.Error(t, pm.runHealthCheck(context.Background(), started))
+	assert.Equal(t, uint64(1), pm.Stats().HealthCheckFailures)
+}
+
+// unwritableStateStore is a mock store whose up-front writability check fails
+type unwritableStateStore struct {
+	*mockStateStore
+}
+
+func (unwritableStateStore) CheckWritable() error {
+	return os.ErrPermission
+}
+
+func TestProcessManager_StartProcess_StateNotWritable(t *testing.T) {
+	t.Run("fails_before_spawning", func(t *testing.T) {
+		pm, executor := setupFakeExecutorManager(t)
+		store, ok := pm.stateStore.(*mockStateStore)
+		require.True(t, ok)
+		pm.stateStore = unwritableStateStore{store}
+
+		_, err := pm.StartProcess("server", nil, StartOptions{})
+		require.ErrorIs(t, err, ErrStateNotWritable)
+		require.ErrorIs(t, err, os.ErrPermission)
+		assert.Zero(t, executor.startCount(), "nothing is spawned when state cannot be saved")
+	})
+
+	t.Run("stops_process_when_save_fails", func(t *testing.T) {
+		pm, store, lockManager, _ := setupTestProcessManager(t)
+		lockManager.On("Lock").Return(nil)
+		lockManager.On("Unlock").Return(nil)
+		store.On("Save", mock.AnythingOfType("map[string]*process.ManagedProcess")).Return(os.ErrPermission)
+		executor := newFakeExecutor()
+		pm.SetExecutor(executor)
+
+		_, err := pm.StartProcess("server", nil, StartOptions{})
+		require.ErrorIs(t, err, ErrStateNotWritable)
+		require.NotErrorIs(t, err, ErrStartFailed)
+
+		require.Equal(t, 1, executor.startCount())
+		executor.mutex.Lock()
+		killed := executor.killed
+		executor.mutex.Unlock()
+		assert.Len(t, killed, 1, "the untracked process is killed")
+		assert.Empty(t, pm.ListProcesses(ProcessListOptions{IncludeStopped: true}))
+	})
+
+	t.Run("exec_failure_is_start_failed", func(t *testing.T) {
+		pm, _ := setupFakeExecutorManager(t)
+		pm.SetExecutor(NewOSExecutor())
+
+		_, err := pm.StartProcess("portguard-command-that-does-not-exist", nil, StartOptions{})
+		require.ErrorIs(t, err, ErrStartFailed)
+		require.NotErrorIs(t, err, ErrStateNotWritable)
+	})
+}
@@ -0,0 +1,87 @@
+package process
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnterminatedQuote is returned when a command line ends inside a quoted string
+var ErrUnterminatedQuote = errors.New("unterminated quote in command line")
+
+// SplitCommandLine splits a command line into words using POSIX shell quoting rules.
+// Single quotes preserve everything literally, double quotes allow \", \\, \$ and \`
+// escapes, and outside quotes a backslash escapes the next character. Variables,
+// globs and other shell expansions are not performed.
+func SplitCommandLine(line string) ([]string, error) {
+	var (
+		words   []string
+		current strings.Builder
+		inWord  bool // Distinguishes an empty quoted word ("") from no word at all
+	)
+
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		case r == '\\':
+			inWord = true
+			if i+1 < len(runes) {
+				i++
+				if runes[i] != '\n' { // Backslash-newline is a line continuation
+					current.WriteRune(runes[i])
+				}
+			}
+		case r == '\'':
+			inWord = true
+			end := indexRune(runes, i+1, '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("%w: %s", ErrUnterminatedQuote, line)
+			}
+			current.WriteString(string(runes[i+1 : end]))
+			i = end
+		case r == '"':
+			inWord = true
+			closed := false
+			for i++; i < len(runes); i++ {
+				if runes[i] == '"' {
+					closed = true
+					break
+				}
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`\n", runes[i+1]) {
+					i++
+					if runes[i] == '\n' {
+						continue
+					}
+				}
+				current.WriteRune(runes[i])
+			}
+			if !closed {
+				return nil, fmt.Errorf("%w: %s", ErrUnterminatedQuote, line)
+			}
+		default:
+			inWord = true
+			current.WriteRune(r)
+		}
+	}
+	if inWord {
+		words = append(words, current.String())
+	}
+	return words, nil
+}
+
+// indexRune returns the index of the first r in runes at or after start, or -1
+func indexRune(runes []rune, start int, r rune) int {
+	for i := start; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
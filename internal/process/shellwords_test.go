@@ -0,0 +1,68 @@
+package process
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitCommandLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		expected []string
+		wantErr  error
+	}{
+		{name: "plain_words", line: "  npm   run dev ", expected: []string{"npm", "run", "dev"}},
+		{name: "empty", line: "   ", expected: nil},
+		{
+			name:     "double_quoted_spaces",
+			line:     `python -c "import x; x.run()"`,
+			expected: []string{"python", "-c", "import x; x.run()"},
+		},
+		{
+			name:     "single_quotes_are_literal",
+			line:     `sh -c 'echo "$HOME" \n'`,
+			expected: []string{"sh", "-c", `echo "$HOME" \n`},
+		},
+		{
+			name:     "escaped_quotes_in_double_quotes",
+			line:     `echo "say \"hi\" to \\ and \$PATH, keep \n"`,
+			expected: []string{"echo", `say "hi" to \ and $PATH, keep \n`},
+		},
+		{name: "escaped_space", line: `cat my\ file.txt`, expected: []string{"cat", "my file.txt"}},
+		{name: "escaped_quote_outside", line: `echo it\'s`, expected: []string{"echo", "it's"}},
+		{name: "adjacent_segments", line: `--name="my app"'s'`, expected: []string{"--name=my apps"}},
+		{name: "empty_quoted_argument", line: `app "" ''`, expected: []string{"app", "", ""}},
+		{name: "unterminated_double", line: `echo "oops`, wantErr: ErrUnterminatedQuote},
+		{name: "unterminated_single", line: `echo 'oops`, wantErr: ErrUnterminatedQuote},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			words, err := SplitCommandLine(tt.line)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, words)
+		})
+	}
+}
+
+func TestProcessManager_ExecuteProcess_QuotedCommand(t *testing.T) {
+	pm, executor := setupFakeExecutorManager(t)
+	pm.SetMonitoringDisabled(true)
+
+	_, err := pm.StartProcess(`python -c "import app; app.run('0.0.0.0')"`, nil, StartOptions{})
+	require.NoError(t, err)
+
+	require.Len(t, executor.starts, 1)
+	assert.Equal(t, "python", executor.starts[0].Command)
+	assert.Equal(t, []string{"-c", "import app; app.run('0.0.0.0')"}, executor.starts[0].Args)
+
+	_, err = pm.StartProcess(`python -c "import app`, nil, StartOptions{})
+	require.ErrorIs(t, err, ErrUnterminatedQuote)
+}
@@ -4,17 +4,42 @@
 package process
 
 import (
+	"fmt"
 	"os"
 	"syscall"
 )
 
+// signalsByName maps signal names without the SIG prefix to signals accepted by ParseSignal
+var signalsByName = map[string]os.Signal{
+	"HUP":   syscall.SIGHUP,
+	"INT":   syscall.SIGINT,
+	"QUIT":  syscall.SIGQUIT,
+	"KILL":  syscall.SIGKILL,
+	"USR1":  syscall.SIGUSR1,
+	"USR2":  syscall.SIGUSR2,
+	"TERM":  syscall.SIGTERM,
+	"CONT":  syscall.SIGCONT,
+	"STOP":  syscall.SIGSTOP,
+	"TSTP":  syscall.SIGTSTP,
+	"WINCH": syscall.SIGWINCH,
+}
+
 // isProcessAlive checks if the process is still alive
 func isProcessAlive(proc *os.Process) bool {
 	err := proc.Signal(syscall.Signal(0))
 	return err == nil
 }
 
-// terminateProcess sends SIGTERM to the process
-func terminateProcess(proc *os.Process) error {
-	return proc.Signal(syscall.SIGTERM)
+// sendSignal delivers sig to the process, or to its whole process group when group is set
+func sendSignal(pid int, group bool, sig os.Signal) error {
+	sysSig, ok := sig.(syscall.Signal)
+	if !ok {
+		return fmt.Errorf("%w: %v", ErrUnknownSignal, sig)
+	}
+
+	// Fall back to the single process if it no longer leads a group
+	if group && syscall.Kill(-pid, sysSig) == nil {
+		return nil
+	}
+	return syscall.Kill(pid, sysSig)
 }
@@ -4,9 +4,21 @@
 package process
 
 import (
+	"fmt"
 	"os"
+	"syscall"
 )
 
+// signalsByName maps signal names without the SIG prefix to signals accepted by ParseSignal.
+// Windows can only deliver KILL; other signals fail when sent.
+var signalsByName = map[string]os.Signal{
+	"HUP":  syscall.SIGHUP,
+	"INT":  syscall.SIGINT,
+	"QUIT": syscall.SIGQUIT,
+	"KILL": syscall.SIGKILL,
+	"TERM": syscall.SIGTERM,
+}
+
 // isProcessAlive checks if the process is still alive (Windows implementation)
 func isProcessAlive(proc *os.Process) bool {
 	// On Windows, we try to get the process state
@@ -19,8 +31,11 @@ func isProcessAlive(proc *os.Process) bool {
 	return false
 }
 
-// terminateProcess terminates the process (Windows implementation)
-func terminateProcess(proc *os.Process) error {
-	// On Windows, we can only kill the process
-	return proc.Kill()
+// sendSignal delivers sig to the process (Windows implementation, process groups are not used)
+func sendSignal(pid int, _ bool, sig os.Signal) error {
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return fmt.Errorf("failed to find process %d: %w", pid, err)
+	}
+	return proc.Signal(sig)
 }
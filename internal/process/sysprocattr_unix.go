@@ -5,11 +5,18 @@ package process
 
 import "syscall"
 
-// setSysProcAttr sets the system process attributes for Unix-like systems
-func setSysProcAttr(attr *syscall.SysProcAttr) *syscall.SysProcAttr {
+// setSysProcAttr sets the system process attributes for Unix-like systems. The child leads its
+// own process group; a detached child leads a new session instead, which has no controlling
+// terminal, so closing portguard's terminal does not send it SIGHUP.
+func setSysProcAttr(attr *syscall.SysProcAttr, detached bool) *syscall.SysProcAttr {
 	if attr == nil {
 		attr = &syscall.SysProcAttr{}
 	}
-	attr.Setpgid = true
+	if detached {
+		// A session leader also leads a new process group; setpgid would fail on it
+		attr.Setsid = true
+	} else {
+		attr.Setpgid = true
+	}
 	return attr
 }
@@ -3,14 +3,21 @@
 
 package process
 
-import "syscall"
+import (
+	"syscall"
 
-// setSysProcAttr sets the system process attributes for Windows
-func setSysProcAttr(attr *syscall.SysProcAttr) *syscall.SysProcAttr {
+	"golang.org/x/sys/windows"
+)
+
+// setSysProcAttr sets the system process attributes for Windows. A detached child gets no
+// console and its own process group, so closing portguard's console does not end it.
+func setSysProcAttr(attr *syscall.SysProcAttr, detached bool) *syscall.SysProcAttr {
 	// Windows doesn't support Setpgid
-	// Return the attribute as-is or create a new one
 	if attr == nil {
 		attr = &syscall.SysProcAttr{}
 	}
+	if detached {
+		attr.CreationFlags |= windows.DETACHED_PROCESS | windows.CREATE_NEW_PROCESS_GROUP
+	}
 	return attr
 }
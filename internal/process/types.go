@@ -3,9 +3,29 @@
 package process
 
 import (
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
+// Errors returned by ManagedProcess.Validate
+var (
+	ErrEmptyCommand     = errors.New("process has empty command")
+	ErrInvalidPID       = errors.New("running process has no valid PID")
+	ErrZeroCreationTime = errors.New("process has zero creation time")
+	ErrPortOutOfRange   = errors.New("process port out of range")
+)
+
+// ErrInvalidHealthCheck is returned by HealthCheck.Validate
+var ErrInvalidHealthCheck = errors.New("invalid health check")
+
 // ProcessStatus represents the current status of a managed process
 type ProcessStatus string
 
@@ -26,18 +46,124 @@ const (
 	HealthCheckHTTP    HealthCheckType = "http"    // HTTP endpoint health check
 	HealthCheckTCP     HealthCheckType = "tcp"     // TCP connection health check
 	HealthCheckCommand HealthCheckType = "command" // Custom command health check
+	HealthCheckGRPC    HealthCheckType = "grpc"    // gRPC health checking protocol (grpc.health.v1)
 	HealthCheckProcess HealthCheckType = "process" // Process health check (PID-based)
 	HealthCheckNone    HealthCheckType = "none"    // No health check
 )
 
+// RestartPolicy controls whether an exited process is started again
+type RestartPolicy string
+
+// Restart policy constants
+const (
+	RestartNever     RestartPolicy = "never"      // Never restart (default)
+	RestartOnFailure RestartPolicy = "on-failure" // Restart when the process exits with an error
+	RestartAlways    RestartPolicy = "always"     // Restart whenever the process exits on its own
+)
+
+// IsValid checks if the policy is a known restart policy. An empty policy means never.
+func (r RestartPolicy) IsValid() bool {
+	switch r {
+	case "", RestartNever, RestartOnFailure, RestartAlways:
+		return true
+	}
+	return false
+}
+
 // HealthCheck defines how to check if a process is healthy
 type HealthCheck struct {
 	Type     HealthCheckType `json:"type"`
-	Target   string          `json:"target"`   // URL for HTTP, address for TCP, command for command
+	Target   string          `json:"target"`   // URL for HTTP, address for TCP and gRPC, command for command
 	Interval time.Duration   `json:"interval"` // How often to check
 	Timeout  time.Duration   `json:"timeout"`  // Timeout for each check
 	Retries  int             `json:"retries"`  // Number of retries before marking unhealthy
 	Enabled  bool            `json:"enabled"`  // Whether health checking is enabled
+
+	// HTTP response body expectations, checked after a successful status code
+	ExpectBody      string `json:"expect_body" mapstructure:"expect_body"`             // Substring the body must contain
+	ExpectJSONPath  string `json:"expect_json_path" mapstructure:"expect_json_path"`   // Dot-separated path into a JSON body, e.g. "checks.db.status"
+	ExpectJSONValue string `json:"expect_json_value" mapstructure:"expect_json_value"` // Expected value at ExpectJSONPath; empty only requires the path to exist
+
+	// HTTP request options
+	Method            string            `json:"method"`                                                 // Request method; empty means GET
+	Headers           map[string]string `json:"headers"`                                                // Extra request headers; "Host" sets the virtual host
+	BasicAuthUser     string            `json:"basic_auth_user" mapstructure:"basic_auth_user"`         // Username for HTTP basic authentication
+	BasicAuthPassword string            `json:"basic_auth_password" mapstructure:"basic_auth_password"` // Password for HTTP basic authentication
+	// Proxy is an http, https or socks5 proxy URL; empty uses HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+	Proxy string `json:"proxy"`
+	// InsecureSkipVerify accepts any TLS certificate, e.g. self-signed development certificates
+	InsecureSkipVerify bool `json:"insecure_skip_verify" mapstructure:"insecure_skip_verify"`
+
+	// TCP conversation, run after connecting
+	SendOnConnect string `json:"send_on_connect" mapstructure:"send_on_connect"` // Payload written first, e.g. "PING\r\n"
+	ExpectBanner  string `json:"expect_banner" mapstructure:"expect_banner"`     // Substring the reply must contain, e.g. "+PONG" or "220"
+
+	// Service is the gRPC service name to check; empty checks the server as a whole
+	Service string `json:"service,omitempty"`
+
+	// InNamespace runs a command check inside the network namespace of the process, e.g. to
+	// reach a container's internal port. Linux only; needs root or CAP_SYS_ADMIN.
+	InNamespace bool `json:"in_namespace,omitempty" mapstructure:"in_namespace"`
+}
+
+// Clone returns a deep copy of the health check; nil stays nil
+func (hc *HealthCheck) Clone() *HealthCheck {
+	if hc == nil {
+		return nil
+	}
+	clone := *hc
+	clone.Headers = maps.Clone(hc.Headers)
+	return &clone
+}
+
+// Validate checks that the health check can be run: a known type, a target matching
+// the type, and non-negative timings
+func (hc *HealthCheck) Validate() error {
+	if hc.Interval < 0 || hc.Timeout < 0 || hc.Retries < 0 {
+		return fmt.Errorf("%w: interval, timeout and retries cannot be negative", ErrInvalidHealthCheck)
+	}
+	if hc.InNamespace && hc.Type != HealthCheckCommand {
+		return fmt.Errorf("%w: in_namespace is only supported for command checks", ErrInvalidHealthCheck)
+	}
+
+	switch hc.Type {
+	case HealthCheckHTTP:
+		target, err := url.Parse(hc.Target)
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			return fmt.Errorf("%w: http target must be an http(s) URL, got %q", ErrInvalidHealthCheck, hc.Target)
+		}
+		if hc.Method != "" && strings.ContainsAny(hc.Method, " \t\r\n") {
+			return fmt.Errorf("%w: invalid http method %q", ErrInvalidHealthCheck, hc.Method)
+		}
+		if hc.Proxy != "" {
+			proxy, err := url.Parse(hc.Proxy)
+			if err != nil || !isProxyScheme(proxy.Scheme) || proxy.Host == "" {
+				return fmt.Errorf("%w: proxy must be an http, https or socks5 URL, got %q", ErrInvalidHealthCheck, hc.Proxy)
+			}
+		}
+	case HealthCheckTCP, HealthCheckGRPC:
+		// An empty target dials the process port on its bind address
+		if _, _, err := net.SplitHostPort(hc.Target); hc.Target != "" && err != nil {
+			return fmt.Errorf("%w: %s target must be host:port, got %q", ErrInvalidHealthCheck, hc.Type, hc.Target)
+		}
+	case HealthCheckCommand:
+		if hc.Target == "" {
+			return fmt.Errorf("%w: command target is empty", ErrInvalidHealthCheck)
+		}
+	case HealthCheckProcess, HealthCheckNone:
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidHealthCheck, hc.Type)
+	}
+	return nil
+}
+
+// isProxyScheme reports whether the HTTP client supports proxies with this URL scheme
+func isProxyScheme(scheme string) bool {
+	switch scheme {
+	case "http", "https", "socks5", "socks5h":
+		return true
+	}
+	return false
 }
 
 // ManagedProcess represents a process managed by portguard
@@ -47,6 +173,7 @@ type ManagedProcess struct {
 	Command     string            `json:"command"`      // Command that was executed
 	Args        []string          `json:"args"`         // Command arguments
 	Port        int               `json:"port"`         // Primary port the process is using
+	Ports       []int             `json:"ports"`        // All ports the process uses, primary first
 	PID         int               `json:"pid"`          // Process ID
 	Status      ProcessStatus     `json:"status"`       // Current status
 	HealthCheck *HealthCheck      `json:"health_check"` // Health check configuration
@@ -55,9 +182,73 @@ type ManagedProcess struct {
 	UpdatedAt   time.Time         `json:"updated_at"`   // Last status update
 	LastSeen    time.Time         `json:"last_seen"`    // Last time process was confirmed running
 	Environment map[string]string `json:"environment"`  // Environment variables
+	EnvFile     string            `json:"env_file"`     // .env file re-read on every start
 	WorkingDir  string            `json:"working_dir"`  // Working directory
 	LogFile     string            `json:"log_file"`     // Path to log file
 	IsExternal  bool              `json:"is_external"`  // Whether this is an externally started process
+
+	Program string `json:"program,omitempty"` // Executable as started; with Args it restarts the exact command
+
+	LogAttachError string `json:"log_attach_error,omitempty"` // Why the output of an adopted process could not be captured in LogFile
+
+	CleanupWorkingDir bool `json:"cleanup_working_dir"` // WorkingDir was created for this process and may be removed on cleanup
+	Protected         bool `json:"protected"`           // Skipped by cleanup unless protected processes are explicitly included
+
+	BindAddress string `json:"bind_address,omitempty"` // Interface the process was asked to listen on; empty for the server's default
+	Detached    bool   `json:"detached,omitempty"`     // Started in its own session, decoupled from portguard's terminal
+	NoMonitor   bool   `json:"no_monitor,omitempty"`   // Started without a background monitor, so never restarted by its policy
+
+	PreStart []string `json:"pre_start,omitempty"` // Commands run before every start
+	PostStop []string `json:"post_stop,omitempty"` // Commands run after every stop
+
+	RestartPolicy RestartPolicy `json:"restart_policy"` // When to restart the process after it exits
+	MaxRestarts   int           `json:"max_restarts"`   // Limit on crash restarts before the process is marked failed
+	RestartCount  int           `json:"restart_count"`  // Number of restarts performed so far, manual ones included
+	CrashRestarts int           `json:"crash_restarts"` // Restarts by the restart policy since the last manual restart
+
+	RefCount int `json:"ref_count"` // Callers sharing the process; a plain stop only terminates it at the last one
+
+	ExitCode   *int   `json:"exit_code,omitempty"`   // Exit code of the last exit, nil when unknown or killed by a signal
+	ExitReason string `json:"exit_reason,omitempty"` // Human-readable description of the last exit
+
+	LastHealthCheck time.Time `json:"last_health_check"` // When the health check last ran; checks are spaced by HealthCheck.Interval
+
+	runtime *processRuntime // In-memory handle for processes started by this manager
+}
+
+// processRuntime tracks a child process started by this manager
+type processRuntime struct {
+	exited        chan error  // Receives the exit result once the process has been reaped
+	stopRequested atomic.Bool // Set when portguard itself stops the process
+	logOffset     int64       // Size of the log file before the process started writing to it
+}
+
+// Clone returns a deep copy of the process that shares no mutable state with it.
+// The in-memory handle of a process started by this manager is not copied.
+func (p *ManagedProcess) Clone() *ManagedProcess {
+	if p == nil {
+		return nil
+	}
+	clone := *p
+	clone.runtime = nil
+	clone.Config = p.Config.Clone()
+	clone.Args = slices.Clone(p.Args)
+	clone.Ports = slices.Clone(p.Ports)
+	clone.PreStart = slices.Clone(p.PreStart)
+	clone.PostStop = slices.Clone(p.PostStop)
+	clone.HealthCheck = p.HealthCheck.Clone()
+	clone.Environment = maps.Clone(p.Environment)
+	if p.ExitCode != nil {
+		exitCode := *p.ExitCode
+		clone.ExitCode = &exitCode
+	}
+	return &clone
+}
+
+// References returns how many callers share the process. State saved before reference
+// counting has no count and is treated as a single caller.
+func (p *ManagedProcess) References() int {
+	return max(p.RefCount, 1)
 }
 
 // IsHealthy checks if the process is considered healthy
@@ -70,11 +261,79 @@ func (p *ManagedProcess) IsRunning() bool {
 	return p.Status == StatusRunning || p.Status == StatusUnhealthy
 }
 
+// AllPorts returns every port the process uses, with the primary port first
+func (p *ManagedProcess) AllPorts() []int {
+	if len(p.Ports) > 0 {
+		return p.Ports
+	}
+	if p.Port > 0 {
+		return []int{p.Port}
+	}
+	return nil
+}
+
+// UsesPort checks if the process is bound to the given port
+func (p *ManagedProcess) UsesPort(portNum int) bool {
+	if portNum <= 0 {
+		return false
+	}
+	for _, processPort := range p.AllPorts() {
+		if processPort == portNum {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesCommand reports whether the command line contains substr, ignoring case
+func (p *ManagedProcess) MatchesCommand(substr string) bool {
+	commandLine := strings.Join(append([]string{p.Command}, p.Args...), " ")
+	return strings.Contains(strings.ToLower(commandLine), strings.ToLower(substr))
+}
+
+// MigratePorts fills Ports from the legacy single Port field for state written before multi-port support
+func (p *ManagedProcess) MigratePorts() {
+	if len(p.Ports) == 0 && p.Port > 0 {
+		p.Ports = []int{p.Port}
+	}
+}
+
+// Validate checks the invariants every stored process must satisfy
+func (p *ManagedProcess) Validate() error {
+	if p.Command == "" {
+		return fmt.Errorf("%w: %s", ErrEmptyCommand, p.ID)
+	}
+
+	if p.IsRunning() && p.PID <= 0 {
+		return fmt.Errorf("%w: %s has status %s and PID %d", ErrInvalidPID, p.ID, p.Status, p.PID)
+	}
+
+	if p.CreatedAt.IsZero() {
+		return fmt.Errorf("%w: %s", ErrZeroCreationTime, p.ID)
+	}
+
+	// Port 0 means the process has no known port
+	ports := append([]int{p.Port}, p.Ports...)
+	for _, portNum := range ports {
+		if portNum < 0 || portNum > maxPortNumber {
+			return fmt.Errorf("%w: %s uses port %d", ErrPortOutOfRange, p.ID, portNum)
+		}
+	}
+
+	return nil
+}
+
 // Age returns how long the process has been running
 func (p *ManagedProcess) Age() time.Duration {
 	return time.Since(p.CreatedAt)
 }
 
+// runsIn reports whether the process runs in dir. Processes recorded without a working
+// directory, such as those from older state files, and an unknown dir match anything.
+func (p *ManagedProcess) runsIn(dir string) bool {
+	return p.WorkingDir == "" || dir == "" || filepath.Clean(p.WorkingDir) == filepath.Clean(dir)
+}
+
 // TimeSinceLastSeen returns how long since the process was last confirmed running
 func (p *ManagedProcess) TimeSinceLastSeen() time.Duration {
 	return time.Since(p.LastSeen)
@@ -94,15 +353,31 @@ type ProcessConfig struct {
 	Port        int               `json:"port"`         // Primary port
 	WorkingDir  string            `json:"working_dir"`  // Working directory
 	Environment map[string]string `json:"environment"`  // Environment variables
+	EnvFile     string            `json:"env_file"`     // .env file re-read on every start
 	LogFile     string            `json:"log_file"`     // Log file path
 	HealthCheck *HealthCheck      `json:"health_check"` // Health check configuration
 }
 
+// Clone returns a deep copy of the configuration; nil stays nil
+func (pc *ProcessConfig) Clone() *ProcessConfig {
+	if pc == nil {
+		return nil
+	}
+	clone := *pc
+	clone.Args = slices.Clone(pc.Args)
+	clone.Environment = maps.Clone(pc.Environment)
+	clone.HealthCheck = pc.HealthCheck.Clone()
+	return &clone
+}
+
 // ProcessListOptions defines options for listing processes
 type ProcessListOptions struct {
-	IncludeStopped bool `json:"include_stopped"` // Include stopped processes
-	JSONOutput     bool `json:"json_output"`     // Output in JSON format
-	FilterByPort   int  `json:"filter_by_port"`  // Filter by specific port
+	IncludeStopped  bool      `json:"include_stopped"`   // Include stopped processes
+	JSONOutput      bool      `json:"json_output"`       // Output in JSON format
+	FilterByPort    int       `json:"filter_by_port"`    // Filter by specific port
+	FilterByCommand string    `json:"filter_by_command"` // Case-insensitive command line substring
+	CreatedAfter    time.Time `json:"created_after"`     // Only processes created at or after this time
+	CreatedBefore   time.Time `json:"created_before"`    // Only processes created at or before this time
 }
 
 // PortScanOptions defines options for port scanning
@@ -1,10 +1,12 @@
 package process //nolint:testpackage // TODO: Consider moving to process_test package for better isolation
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestProcessStatus(t *testing.T) {
@@ -49,6 +51,123 @@ func TestManagedProcess_IsRunning(t *testing.T) {
 	assert.False(t, process.IsRunning())
 }
 
+func TestManagedProcess_Ports(t *testing.T) {
+	t.Run("legacy_single_port", func(t *testing.T) {
+		process := &ManagedProcess{Port: 3000}
+		assert.Equal(t, []int{3000}, process.AllPorts())
+		assert.True(t, process.UsesPort(3000))
+		assert.False(t, process.UsesPort(3001))
+	})
+
+	t.Run("multiple_ports", func(t *testing.T) {
+		process := &ManagedProcess{Port: 3000, Ports: []int{3000, 24678, 9464}}
+		assert.Equal(t, []int{3000, 24678, 9464}, process.AllPorts())
+		assert.True(t, process.UsesPort(24678))
+		assert.False(t, process.UsesPort(0))
+	})
+
+	t.Run("no_ports", func(t *testing.T) {
+		process := &ManagedProcess{}
+		assert.Empty(t, process.AllPorts())
+		assert.False(t, process.UsesPort(3000))
+	})
+
+	t.Run("migrate_ports", func(t *testing.T) {
+		process := &ManagedProcess{Port: 8080}
+		process.MigratePorts()
+		assert.Equal(t, []int{8080}, process.Ports)
+
+		empty := &ManagedProcess{}
+		empty.MigratePorts()
+		assert.Empty(t, empty.Ports)
+	})
+}
+
+func TestManagedProcess_MatchesCommand(t *testing.T) {
+	process := &ManagedProcess{Command: "npx", Args: []string{"Vite", "--port", "5173"}}
+
+	assert.True(t, process.MatchesCommand("vite"))
+	assert.True(t, process.MatchesCommand("NPX VITE"))
+	assert.True(t, process.MatchesCommand("port 51"), "arguments are part of the command line")
+	assert.False(t, process.MatchesCommand("webpack"))
+}
+
+func TestRestartPolicy(t *testing.T) {
+	t.Run("valid_policies", func(t *testing.T) {
+		for _, policy := range []RestartPolicy{"", RestartNever, RestartOnFailure, RestartAlways} {
+			assert.True(t, policy.IsValid(), policy)
+		}
+		assert.False(t, RestartPolicy("sometimes").IsValid())
+	})
+
+	t.Run("restart_state_is_persisted", func(t *testing.T) {
+		process := &ManagedProcess{ID: "restarts", RestartPolicy: RestartOnFailure, MaxRestarts: 5, RestartCount: 2, CrashRestarts: 1}
+
+		data, err := json.Marshal(process)
+		require.NoError(t, err)
+
+		var decoded ManagedProcess
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, RestartOnFailure, decoded.RestartPolicy)
+		assert.Equal(t, 5, decoded.MaxRestarts)
+		assert.Equal(t, 2, decoded.RestartCount)
+		assert.Equal(t, 1, decoded.CrashRestarts)
+	})
+}
+
+func TestManagedProcess_Validate(t *testing.T) {
+	valid := func() *ManagedProcess {
+		return &ManagedProcess{
+			ID:        "web",
+			Command:   "npm run dev",
+			Port:      3000,
+			Ports:     []int{3000, 3001},
+			PID:       4242,
+			Status:    StatusRunning,
+			CreatedAt: time.Now(),
+		}
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(p *ManagedProcess)
+		wantErr error
+	}{
+		{name: "valid", mutate: func(*ManagedProcess) {}},
+		{name: "empty command", mutate: func(p *ManagedProcess) { p.Command = "" }, wantErr: ErrEmptyCommand},
+		{name: "running without PID", mutate: func(p *ManagedProcess) { p.PID = 0 }, wantErr: ErrInvalidPID},
+		{name: "unhealthy with negative PID", mutate: func(p *ManagedProcess) {
+			p.Status = StatusUnhealthy
+			p.PID = -1
+		}, wantErr: ErrInvalidPID},
+		{name: "stopped without PID", mutate: func(p *ManagedProcess) {
+			p.Status = StatusStopped
+			p.PID = 0
+		}},
+		{name: "zero creation time", mutate: func(p *ManagedProcess) { p.CreatedAt = time.Time{} }, wantErr: ErrZeroCreationTime},
+		{name: "no port", mutate: func(p *ManagedProcess) {
+			p.Port = 0
+			p.Ports = nil
+		}},
+		{name: "negative port", mutate: func(p *ManagedProcess) { p.Port = -1 }, wantErr: ErrPortOutOfRange},
+		{name: "extra port too large", mutate: func(p *ManagedProcess) { p.Ports = []int{3000, 70000} }, wantErr: ErrPortOutOfRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := valid()
+			tt.mutate(p)
+
+			err := p.Validate()
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
 func TestManagedProcess_Age(t *testing.T) {
 	now := time.Now()
 	process := &ManagedProcess{
@@ -90,3 +209,102 @@ func TestHealthCheck(t *testing.T) {
 	assert.Equal(t, 5*time.Second, hc.Timeout)
 	assert.Equal(t, 3, hc.Retries)
 }
+
+func TestHealthCheck_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		healthCheck HealthCheck
+		expectError bool
+	}{
+		{name: "http", healthCheck: HealthCheck{Type: HealthCheckHTTP, Target: "http://localhost:3000/health"}},
+		{name: "https", healthCheck: HealthCheck{Type: HealthCheckHTTP, Target: "https://example.test/ready"}},
+		{name: "http_without_scheme", healthCheck: HealthCheck{Type: HealthCheckHTTP, Target: "localhost:3000"}, expectError: true},
+		{name: "http_empty", healthCheck: HealthCheck{Type: HealthCheckHTTP}, expectError: true},
+		{
+			name:        "http_socks_proxy",
+			healthCheck: HealthCheck{Type: HealthCheckHTTP, Target: "http://api.internal/health", Proxy: "socks5://127.0.0.1:1080"},
+		},
+		{
+			name:        "http_proxy_without_scheme",
+			healthCheck: HealthCheck{Type: HealthCheckHTTP, Target: "http://api.internal/health", Proxy: "127.0.0.1:8080"},
+			expectError: true,
+		},
+		{
+			name:        "http_invalid_method",
+			healthCheck: HealthCheck{Type: HealthCheckHTTP, Target: "http://localhost:3000/health", Method: "GET /"},
+			expectError: true,
+		},
+		{name: "tcp", healthCheck: HealthCheck{Type: HealthCheckTCP, Target: "localhost:5432"}},
+		{name: "tcp_without_port", healthCheck: HealthCheck{Type: HealthCheckTCP, Target: "localhost"}, expectError: true},
+		{name: "tcp_process_port", healthCheck: HealthCheck{Type: HealthCheckTCP}},
+		{name: "grpc", healthCheck: HealthCheck{Type: HealthCheckGRPC, Target: "localhost:50051", Service: "api.Users"}},
+		{name: "grpc_process_port", healthCheck: HealthCheck{Type: HealthCheckGRPC}},
+		{name: "grpc_without_port", healthCheck: HealthCheck{Type: HealthCheckGRPC, Target: "localhost"}, expectError: true},
+		{name: "command", healthCheck: HealthCheck{Type: HealthCheckCommand, Target: "pg_isready"}},
+		{name: "command_empty", healthCheck: HealthCheck{Type: HealthCheckCommand}, expectError: true},
+		{name: "command_in_namespace", healthCheck: HealthCheck{Type: HealthCheckCommand, Target: "curl -fs localhost:8080", InNamespace: true}},
+		{name: "http_in_namespace", healthCheck: HealthCheck{Type: HealthCheckHTTP, Target: "http://localhost:8080", InNamespace: true}, expectError: true},
+		{name: "process_needs_no_target", healthCheck: HealthCheck{Type: HealthCheckProcess}},
+		{name: "unknown_type", healthCheck: HealthCheck{Type: "ping", Target: "localhost"}, expectError: true},
+		{
+			name:        "negative_timeout",
+			healthCheck: HealthCheck{Type: HealthCheckTCP, Target: "localhost:5432", Timeout: -time.Second},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.healthCheck.Validate()
+			if tt.expectError {
+				require.ErrorIs(t, err, ErrInvalidHealthCheck)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestManagedProcess_Clone(t *testing.T) {
+	exitCode := 1
+	original := &ManagedProcess{
+		ID:          "web",
+		Command:     "npm",
+		Args:        []string{"run", "dev"},
+		Port:        3000,
+		Ports:       []int{3000, 3001},
+		Environment: map[string]string{"NODE_ENV": "development"},
+		HealthCheck: &HealthCheck{Type: HealthCheckHTTP, Headers: map[string]string{"Host": "app.test"}},
+		Config: &ProcessConfig{
+			Args:        []string{"run", "dev"},
+			Environment: map[string]string{"PORT": "3000"},
+			HealthCheck: &HealthCheck{Type: HealthCheckTCP},
+		},
+		ExitCode: &exitCode,
+		runtime:  &processRuntime{},
+	}
+
+	clone := original.Clone()
+	require.Equal(t, original.ID, clone.ID)
+	assert.Nil(t, clone.runtime)
+
+	clone.Args[0] = "exec"
+	clone.Ports[1] = 4000
+	clone.Environment["NODE_ENV"] = "production"
+	clone.HealthCheck.Headers["Host"] = "other.test"
+	clone.Config.Args[0] = "exec"
+	clone.Config.Environment["PORT"] = "4000"
+	clone.Config.HealthCheck.Type = HealthCheckNone
+	*clone.ExitCode = 2
+
+	assert.Equal(t, []string{"run", "dev"}, original.Args)
+	assert.Equal(t, []int{3000, 3001}, original.Ports)
+	assert.Equal(t, "development", original.Environment["NODE_ENV"])
+	assert.Equal(t, "app.test", original.HealthCheck.Headers["Host"])
+	assert.Equal(t, []string{"run", "dev"}, original.Config.Args)
+	assert.Equal(t, "3000", original.Config.Environment["PORT"])
+	assert.Equal(t, HealthCheckTCP, original.Config.HealthCheck.Type)
+	assert.Equal(t, 1, *original.ExitCode)
+
+	assert.Nil(t, (*ManagedProcess)(nil).Clone())
+}
@@ -20,8 +20,9 @@ var (
 	ErrNoVersionInfo      = errors.New("state file has no version information")
 	ErrUnsupportedVersion = errors.New("unsupported state file version")
 	ErrProcessIDMismatch  = errors.New("process ID mismatch")
-	ErrProcessEmptyCmd    = errors.New("process has empty command")
-	ErrProcessZeroTime    = errors.New("process has zero creation time")
+	ErrEmptyProcessEntry  = errors.New("empty process entry")
+	ErrProcessEmptyCmd    = process.ErrEmptyCommand
+	ErrProcessZeroTime    = process.ErrZeroCreationTime
 )
 
 // StateData represents the complete state stored in JSON
@@ -50,11 +51,7 @@ func NewJSONStore(filePath string) (*JSONStore, error) {
 		filePath: filePath,
 		data: &StateData{
 			Processes: make(map[string]*process.ManagedProcess),
-			Metadata: &Metadata{
-				Version:   "1.0",
-				CreatedAt: time.Now(),
-				UpdatedAt: time.Now(),
-			},
+			Metadata:  newMetadata(),
 		},
 	}
 
@@ -99,6 +96,18 @@ func (js *JSONStore) Save(processes map[string]*process.ManagedProcess) error {
 	return nil
 }
 
+// CheckWritable verifies that Save can write to the state directory by creating and
+// removing a temporary file next to the state file
+func (js *JSONStore) CheckWritable() error {
+	probe, err := os.CreateTemp(filepath.Dir(js.filePath), filepath.Base(js.filePath)+".probe-*")
+	if err != nil {
+		return fmt.Errorf("state directory is not writable: %w", err)
+	}
+	_ = probe.Close()           //nolint:errcheck // Empty probe file
+	_ = os.Remove(probe.Name()) //nolint:errcheck // Best effort cleanup of probe file
+	return nil
+}
+
 // Load reads the processes from JSON file
 func (js *JSONStore) Load() (map[string]*process.ManagedProcess, error) {
 	if err := js.load(); err != nil {
@@ -119,6 +128,13 @@ func (js *JSONStore) load() error {
 		return fmt.Errorf("failed to unmarshal state data: %w", err)
 	}
 
+	// Migrate state written before multi-port support
+	for _, proc := range js.data.Processes {
+		if proc != nil {
+			proc.MigratePorts()
+		}
+	}
+
 	return nil
 }
 
@@ -144,22 +160,26 @@ func (js *JSONStore) GetMetadata() *Metadata {
 
 // ValidateState performs validation on the loaded state
 func (js *JSONStore) ValidateState() error {
-	if js.data.Metadata.Version == "" {
+	return validateData(js.data)
+}
+
+// validateData checks the version and every process of a state document
+func validateData(data *StateData) error {
+	if data.Metadata == nil || data.Metadata.Version == "" {
 		return ErrNoVersionInfo
 	}
 
 	// Validate each process
-	for id, proc := range js.data.Processes {
+	for id, proc := range data.Processes {
+		if proc == nil {
+			return fmt.Errorf("%w: %s", ErrEmptyProcessEntry, id)
+		}
 		if proc.ID != id {
 			return fmt.Errorf("%w: key=%s, proc.ID=%s", ErrProcessIDMismatch, id, proc.ID)
 		}
 
-		if proc.Command == "" {
-			return fmt.Errorf("%w: %s", ErrProcessEmptyCmd, id)
-		}
-
-		if proc.CreatedAt.IsZero() {
-			return fmt.Errorf("%w: %s", ErrProcessZeroTime, id)
+		if err := proc.Validate(); err != nil {
+			return fmt.Errorf("invalid process %s: %w", id, err)
 		}
 	}
 
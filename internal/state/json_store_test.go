@@ -190,6 +190,26 @@ func TestJSONStore_SaveLoadRoundTrip(t *testing.T) {
 	}
 }
 
+func TestJSONStore_LoadMigratesLegacyPort(t *testing.T) {
+	store, filePath, cleanup := setupTestJSONStore(t)
+	defer cleanup()
+
+	// State written before multi-port support has no "ports" field
+	legacy := `{
+  "processes": {
+    "legacy": {"id": "legacy", "command": "npm run dev", "port": 3000, "status": "running"}
+  },
+  "metadata": {"version": "1.0"}
+}`
+	require.NoError(t, os.WriteFile(filePath, []byte(legacy), 0o600))
+
+	loaded, err := store.Load()
+	require.NoError(t, err)
+	require.Contains(t, loaded, "legacy")
+	assert.Equal(t, 3000, loaded["legacy"].Port)
+	assert.Equal(t, []int{3000}, loaded["legacy"].Ports)
+}
+
 func TestJSONStore_Delete(t *testing.T) {
 	store, _, cleanup := setupTestJSONStore(t)
 	defer cleanup()
@@ -320,6 +340,43 @@ func TestJSONStore_ValidateState(t *testing.T) {
 			expectError: true,
 			errorType:   ErrProcessZeroTime,
 		},
+		{
+			name: "running_without_pid",
+			setupData: func() *StateData {
+				proc := createTestManagedProcess("test", "npm start", 3000, process.StatusRunning)
+				proc.PID = 0
+				return &StateData{
+					Processes: map[string]*process.ManagedProcess{
+						"test": proc,
+					},
+					Metadata: &Metadata{
+						Version:   "1.0",
+						CreatedAt: time.Now(),
+						UpdatedAt: time.Now(),
+					},
+				}
+			},
+			expectError: true,
+			errorType:   process.ErrInvalidPID,
+		},
+		{
+			name: "port_out_of_range",
+			setupData: func() *StateData {
+				proc := createTestManagedProcess("test", "npm start", 70000, process.StatusStopped)
+				return &StateData{
+					Processes: map[string]*process.ManagedProcess{
+						"test": proc,
+					},
+					Metadata: &Metadata{
+						Version:   "1.0",
+						CreatedAt: time.Now(),
+						UpdatedAt: time.Now(),
+					},
+				}
+			},
+			expectError: true,
+			errorType:   process.ErrPortOutOfRange,
+		},
 	}
 
 	for _, tt := range tests {
@@ -503,6 +560,20 @@ func TestJSONStore_AtomicWrite(t *testing.T) {
 	assert.Equal(t, "atomic_test", loaded["atomic_test"].ID)
 }
 
+func TestJSONStore_CheckWritable(t *testing.T) {
+	store, filePath, cleanup := setupTestJSONStore(t)
+	defer cleanup()
+
+	require.NoError(t, store.CheckWritable())
+	probes, err := filepath.Glob(filePath + ".probe-*")
+	require.NoError(t, err)
+	assert.Empty(t, probes, "the probe file is removed")
+
+	// A state directory that disappeared cannot be written
+	require.NoError(t, os.RemoveAll(filepath.Dir(filePath)))
+	require.Error(t, store.CheckWritable())
+}
+
 func TestJSONStore_GetMetadata(t *testing.T) {
 	store, _, cleanup := setupTestJSONStore(t)
 	defer cleanup()
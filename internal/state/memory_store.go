@@ -0,0 +1,59 @@
+package state
+
+import (
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/paveg/portguard/internal/process"
+)
+
+// MemoryStore keeps state in memory only, for runs where the state directory
+// cannot or should not be written. Other portguard invocations do not see it.
+type MemoryStore struct {
+	mutex     sync.Mutex
+	processes map[string]*process.ManagedProcess
+}
+
+// NewMemoryStore creates an in-memory store seeded with processes, e.g. those read from
+// an existing state file so duplicate detection still sees them
+func NewMemoryStore(processes map[string]*process.ManagedProcess) *MemoryStore {
+	store := &MemoryStore{processes: make(map[string]*process.ManagedProcess, len(processes))}
+	maps.Copy(store.processes, processes)
+	return store
+}
+
+// Save replaces the stored processes
+func (ms *MemoryStore) Save(processes map[string]*process.ManagedProcess) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	ms.processes = maps.Clone(processes)
+	return nil
+}
+
+// Load returns a copy of the stored processes
+func (ms *MemoryStore) Load() (map[string]*process.ManagedProcess, error) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	loaded := make(map[string]*process.ManagedProcess, len(ms.processes))
+	maps.Copy(loaded, ms.processes)
+	return loaded, nil
+}
+
+// Delete removes a process from the store
+func (ms *MemoryStore) Delete(id string) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	delete(ms.processes, id)
+	return nil
+}
+
+// BackupState is a no-op; there is no file to back up
+func (ms *MemoryStore) BackupState() error {
+	return nil
+}
+
+// CleanupOldBackups is a no-op; no backups are written
+func (ms *MemoryStore) CleanupOldBackups(_ time.Duration) error {
+	return nil
+}
@@ -0,0 +1,39 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/paveg/portguard/internal/process"
+)
+
+func TestMemoryStore(t *testing.T) {
+	seed := map[string]*process.ManagedProcess{
+		"web": createTestManagedProcess("web", "npm run dev", 3000, process.StatusRunning),
+	}
+	store := NewMemoryStore(seed)
+	delete(seed, "web")
+
+	loaded, err := store.Load()
+	require.NoError(t, err)
+	assert.Contains(t, loaded, "web", "the store keeps its own copy of the seed")
+
+	loaded["api"] = createTestManagedProcess("api", "go run .", 8080, process.StatusRunning)
+	require.NoError(t, store.Save(loaded))
+	delete(loaded, "web")
+
+	reloaded, err := store.Load()
+	require.NoError(t, err)
+	assert.Len(t, reloaded, 2)
+
+	require.NoError(t, store.Delete("web"))
+	reloaded, err = store.Load()
+	require.NoError(t, err)
+	assert.Len(t, reloaded, 1)
+	assert.Contains(t, reloaded, "api")
+
+	require.NoError(t, store.BackupState())
+	require.NoError(t, store.CleanupOldBackups(0))
+}
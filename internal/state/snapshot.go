@@ -0,0 +1,138 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/paveg/portguard/internal/process"
+)
+
+// CurrentVersion is the newest state schema version this build reads and writes
+const CurrentVersion = "1.0"
+
+// Export writes the current state as an indented JSON snapshot
+func (js *JSONStore) Export(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(js.data); err != nil {
+		return fmt.Errorf("failed to encode state snapshot: %w", err)
+	}
+	return nil
+}
+
+// ReadSnapshot decodes and validates a state snapshot. Snapshots written by a newer
+// schema version are rejected with ErrUnsupportedVersion.
+func ReadSnapshot(r io.Reader) (*StateData, error) {
+	var snapshot StateData
+	if err := json.NewDecoder(r).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode state snapshot: %w", err)
+	}
+
+	if snapshot.Metadata == nil || snapshot.Metadata.Version == "" {
+		return nil, ErrNoVersionInfo
+	}
+	newer, err := isNewerVersion(snapshot.Metadata.Version, CurrentVersion)
+	if err != nil {
+		return nil, err
+	}
+	if newer {
+		return nil, fmt.Errorf("%w: snapshot is version %s, this build supports up to %s",
+			ErrUnsupportedVersion, snapshot.Metadata.Version, CurrentVersion)
+	}
+
+	if snapshot.Processes == nil {
+		snapshot.Processes = make(map[string]*process.ManagedProcess)
+	}
+	for _, proc := range snapshot.Processes {
+		if proc != nil {
+			proc.MigratePorts()
+		}
+	}
+	if err := validateData(&snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// Import backs up the state file and then replaces the stored processes with the snapshot's,
+// or with merge adds them to the existing ones. On an ID conflict the existing entry is kept,
+// so a re-imported baseline never takes a running process out of management.
+// Imported processes are recorded as stopped without a PID: the PIDs of the exported state
+// may belong to unrelated processes by now, so they must not be signaled or reused.
+func (js *JSONStore) Import(snapshot *StateData, merge bool) error {
+	if err := js.BackupState(); err != nil {
+		return err
+	}
+
+	processes := make(map[string]*process.ManagedProcess, len(snapshot.Processes))
+	if merge {
+		current, err := js.Load()
+		if err != nil {
+			return err
+		}
+		for id, proc := range current {
+			processes[id] = proc
+		}
+	}
+	now := time.Now()
+	for id, proc := range snapshot.Processes {
+		if _, exists := processes[id]; exists {
+			continue
+		}
+		imported := *proc
+		imported.PID = 0
+		if imported.IsRunning() || imported.Status == process.StatusPending {
+			imported.Status = process.StatusStopped
+			imported.UpdatedAt = now
+		}
+		processes[id] = &imported
+	}
+
+	return js.Save(processes)
+}
+
+// isNewerVersion reports whether a "major.minor" version is newer than current
+func isNewerVersion(version, current string) (bool, error) {
+	parse := func(v string) ([2]int, error) {
+		var parts [2]int
+		major, minor, _ := strings.Cut(v, ".")
+		for i, part := range []string{major, minor} {
+			if part == "" {
+				continue
+			}
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return parts, fmt.Errorf("%w: %q", ErrUnsupportedVersion, v)
+			}
+			parts[i] = n
+		}
+		return parts, nil
+	}
+
+	got, err := parse(version)
+	if err != nil {
+		return false, err
+	}
+	want, err := parse(current)
+	if err != nil {
+		return false, err
+	}
+	if got[0] != want[0] {
+		return got[0] > want[0], nil
+	}
+	return got[1] > want[1], nil
+}
+
+// newMetadata returns metadata for a fresh state file
+func newMetadata() *Metadata {
+	now := time.Now()
+	return &Metadata{
+		Version:   CurrentVersion,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
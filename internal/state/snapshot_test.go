@@ -0,0 +1,146 @@
+package state
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/paveg/portguard/internal/process"
+)
+
+func TestJSONStore_ExportImport(t *testing.T) {
+	source, _, cleanup := setupTestJSONStore(t)
+	defer cleanup()
+	require.NoError(t, source.Save(map[string]*process.ManagedProcess{
+		"web": createTestManagedProcess("web", "npm run dev", 3000, process.StatusRunning),
+	}))
+
+	var buf bytes.Buffer
+	require.NoError(t, source.Export(&buf))
+	snapshotJSON := buf.String()
+
+	newTarget := func(t *testing.T) (*JSONStore, string) {
+		t.Helper()
+		target, filePath, _ := setupTestJSONStore(t)
+		require.NoError(t, target.Save(map[string]*process.ManagedProcess{
+			"api": createTestManagedProcess("api", "go run .", 8080, process.StatusRunning),
+		}))
+		return target, filePath
+	}
+
+	t.Run("replace", func(t *testing.T) {
+		snapshot, err := ReadSnapshot(strings.NewReader(snapshotJSON))
+		require.NoError(t, err)
+
+		target, filePath := newTarget(t)
+		require.NoError(t, target.Import(snapshot, false))
+
+		processes, err := target.Load()
+		require.NoError(t, err)
+		assert.Len(t, processes, 1)
+		require.Contains(t, processes, "web")
+		assert.Equal(t, process.StatusStopped, processes["web"].Status, "exported PIDs may have been reused")
+		assert.Zero(t, processes["web"].PID)
+
+		backups, err := filepath.Glob(filePath + ".backup.*")
+		require.NoError(t, err)
+		assert.Len(t, backups, 1, "the previous state is backed up before importing")
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		snapshot, err := ReadSnapshot(strings.NewReader(snapshotJSON))
+		require.NoError(t, err)
+
+		target, _ := newTarget(t)
+		require.NoError(t, target.Import(snapshot, true))
+
+		processes, err := target.Load()
+		require.NoError(t, err)
+		assert.Len(t, processes, 2)
+		assert.Contains(t, processes, "web")
+		require.Contains(t, processes, "api")
+		assert.Equal(t, process.StatusRunning, processes["api"].Status, "existing processes are kept as they are")
+	})
+
+	t.Run("merge_keeps_existing_entry_on_id_conflict", func(t *testing.T) {
+		snapshot, err := ReadSnapshot(strings.NewReader(snapshotJSON))
+		require.NoError(t, err)
+
+		target, _, _ := setupTestJSONStore(t)
+		running := createTestManagedProcess("web", "npm run dev", 3000, process.StatusRunning)
+		running.PID = 4242
+		require.NoError(t, target.Save(map[string]*process.ManagedProcess{"web": running}))
+		require.NoError(t, target.Import(snapshot, true))
+
+		processes, err := target.Load()
+		require.NoError(t, err)
+		require.Len(t, processes, 1)
+		assert.Equal(t, process.StatusRunning, processes["web"].Status, "the live server stays managed")
+		assert.Equal(t, 4242, processes["web"].PID)
+	})
+}
+
+func TestReadSnapshot(t *testing.T) {
+	validProcess := `{"id":"web","command":"npm","port":3000,"pid":4242,"status":"running","created_at":"2024-01-01T00:00:00Z"}`
+
+	tests := []struct {
+		name        string
+		input       string
+		expectedErr error
+	}{
+		{
+			name:  "current_version",
+			input: `{"metadata":{"version":"1.0"},"processes":{"web":` + validProcess + `}}`,
+		},
+		{
+			name:  "older_minor_version",
+			input: `{"metadata":{"version":"0.9"},"processes":{}}`,
+		},
+		{
+			name:        "newer_minor_version",
+			input:       `{"metadata":{"version":"1.1"},"processes":{}}`,
+			expectedErr: ErrUnsupportedVersion,
+		},
+		{
+			name:        "newer_major_version",
+			input:       `{"metadata":{"version":"2.0"},"processes":{}}`,
+			expectedErr: ErrUnsupportedVersion,
+		},
+		{
+			name:        "missing_metadata",
+			input:       `{"processes":{}}`,
+			expectedErr: ErrNoVersionInfo,
+		},
+		{
+			name:        "id_mismatch",
+			input:       `{"metadata":{"version":"1.0"},"processes":{"api":` + validProcess + `}}`,
+			expectedErr: ErrProcessIDMismatch,
+		},
+		{
+			name:        "null_process",
+			input:       `{"metadata":{"version":"1.0"},"processes":{"web":null}}`,
+			expectedErr: ErrEmptyProcessEntry,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot, err := ReadSnapshot(strings.NewReader(tt.input))
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, snapshot.Processes)
+		})
+	}
+
+	t.Run("rejects_malformed_json", func(t *testing.T) {
+		_, err := ReadSnapshot(strings.NewReader("{"))
+		require.Error(t, err)
+	})
+}